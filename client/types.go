@@ -47,6 +47,7 @@ type ConvertOptions struct {
 	Profiles               []string
 	Provider
 	GenerateNetworkPolicies bool
+	DisableServiceLinks     bool
 }
 
 type Provider interface{}
@@ -0,0 +1,60 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/app"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Flags of the commands accessing the cluster or linting the objects, converting the compose files as convert does with these flags
+var (
+	ClusterNamespace  string
+	ClusterController string
+	ClusterProfiles   []string
+)
+
+// addClusterFlags adds the conversion flags of the commands accessing the cluster or linting the objects
+func addClusterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&ClusterNamespace, "namespace", "n", "", "Namespace of the objects, the namespace of the current kubectl context when empty")
+	cmd.Flags().StringVar(&ClusterController, "controller", "", `Set the output controller ("deployment"|"daemonSet"|"replicationController")`)
+	cmd.Flags().StringArrayVar(&ClusterProfiles, "profile", []string{}, `Specify the profile to use, can use multiple profiles`)
+}
+
+// clusterConvertOptions returns the options converting the compose files for the commands accessing the cluster or linting the objects
+func clusterConvertOptions() kobject.ConvertOptions {
+	opt := kobject.ConvertOptions{
+		InputFiles:            GlobalFiles,
+		Context:               GlobalContext,
+		Provider:              GlobalProvider,
+		Namespace:             ClusterNamespace,
+		Controller:            strings.ToLower(ClusterController),
+		Profiles:              ClusterProfiles,
+		Replicas:              1,
+		Volumes:               "persistentVolumeClaim",
+		YAMLIndent:            2,
+		WithKomposeAnnotation: true,
+	}
+	if err := app.ValidateComposeFile(&opt); err != nil {
+		log.Fatalf("Error validating compose file: %v", err)
+	}
+	return opt
+}
@@ -17,12 +17,15 @@ limitations under the License.
 package cmd
 
 import (
+	"os"
 	"strings"
 
 	"github.com/kubernetes/kompose/pkg/app"
 	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/validate"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -50,6 +53,7 @@ var (
 	ConvertPushImage             bool
 	ConvertNamespace             string
 	ConvertPushImageRegistry     string
+	ConvertPushImageDigest       bool
 	ConvertOpt                   kobject.ConvertOptions
 	ConvertYAMLIndent            int
 	GenerateNetworkPolicies      bool
@@ -77,14 +81,155 @@ var (
 	// be the default for kompose, but we must keep compatibility with the previous behavior.
 	// See https://github.com/kubernetes/kompose/issues/1280 for more details.
 	SecretsAsFiles bool
+
+	// DisableServiceLinks sets enableServiceLinks to false on every generated pod spec.
+	DisableServiceLinks bool
+
+	// RenameReport is the path of the JSON file the sanitized resource names are written to.
+	RenameReport string
+
+	// Merge keeps the manual edits made to the previously generated files of the output directory.
+	Merge bool
+
+	// Summary is the format of the conversion summary, none is printed when empty.
+	Summary string
+
+	// ConvertProjects are the compose projects converted each into its own namespace, as NAME=FILE[,FILE...].
+	ConvertProjects []string
+
+	// Labels and annotations added to every generated object, or to the pod templates only.
+	AddLabels         []string
+	AddAnnotations    []string
+	AddPodLabels      []string
+	AddPodAnnotations []string
+
+	// ExposeController is the kind of objects the services labeled with kompose.service.expose are exposed with.
+	ExposeController string
+	GatewayName      string
+	GatewayClassName string
+
+	// CreateNamespace generates the Namespace manifest of the namespace given with --namespace.
+	CreateNamespace bool
+
+	// SecretsAs is the kind of objects the compose secrets are converted to, SealedSecrets are encrypted
+	// with the certificate of the sealed-secrets controller.
+	SecretsAs          string
+	SealedSecretsCert  string
+	SealedSecretsScope string
+
+	// AllowUnsafeSysctls keeps the unsafe sysctls of the services, they must be allowed on the kubelets.
+	AllowUnsafeSysctls bool
+
+	// AllowHostNamespaces shares the host PID, IPC and network namespaces requested by the services.
+	AllowHostNamespaces bool
+
+	// WaitForDependencies adds init containers waiting for the depends_on services, running the WaitImage.
+	WaitForDependencies bool
+	WaitImage           string
+
+	// BuildPushSecret is the docker config Secret mounted in the Kaniko Jobs to push the images.
+	BuildPushSecret string
+
+	// ArgoCDApp generates the ArgoCD Application syncing the generated objects from the git repository.
+	ArgoCDApp           string
+	ArgoCDRepoURL       string
+	ArgoCDPath          string
+	ArgoCDDestNamespace string
+
+	// PushChart is the OCI repository the generated chart is pushed to.
+	PushChart string
+
+	// Validate checks the generated objects against the rules of the API server of KubernetesVersion.
+	Validate          bool
+	KubernetesVersion string
+
+	// DryRun sends the generated objects to the cluster with a server-side dry-run with "server".
+	DryRun string
+
+	// IncludeKinds and ExcludeKinds filter the kinds of the printed objects.
+	IncludeKinds []string
+	ExcludeKinds []string
+
+	// ConvertServices restricts the conversion to these services and their dependencies.
+	ConvertServices []string
+
+	// StandardLabels sets the app.kubernetes.io recommended labels on the objects.
+	StandardLabels bool
+
+	// AntiAffinity keeps the pods of the services on different nodes, preferred or required.
+	AntiAffinity string
+
+	// PodSecurity is the Pod Security Standard the workloads comply with.
+	PodSecurity string
+
+	// InspectImages completes the ports and the user of the services with their image in the registry.
+	InspectImages bool
+
+	// ResolveImageDigests references the images by the digest of their tag in the registry.
+	ResolveImageDigests bool
+
+	// Strict fails the conversion when a setting of the compose files is ignored.
+	Strict bool
+
+	// LoggingSidecar ships the logs of the services with a fluentd or gelf logging driver with a fluent-bit sidecar.
+	LoggingSidecar bool
+
+	// NativeSidecars adds the sidecars as native sidecars, init containers with the Always restart policy.
+	NativeSidecars bool
+
+	// DetectCluster adapts the generators to the APIs served by the cluster of the current kubectl context.
+	DetectCluster bool
+
+	// Quadlet is the kind of the systemd Quadlet units generated by the podman provider.
+	Quadlet string
+
+	// Interactive previews the objects of each service and lets the kinds and the key options be changed before writing them.
+	Interactive bool
+
+	// EnvConfigMapHash suffixes the names of the env_file ConfigMaps with a hash of the file and of its content.
+	EnvConfigMapHash bool
+
+	// OutputLayout is the layout of the files written to the output directory, flat or in a directory per service.
+	OutputLayout string
+
+	// Reproducible strips the command line and version annotations and the timestamps, for byte-identical outputs.
+	Reproducible bool
+
+	// Sign signs the generated chart package or bundle of manifests with cosign.
+	Sign bool
+
+	// SignKey is the cosign key file used by --sign, keyless signing without it.
+	SignKey string
+
+	// PrintMerged prints the compose model merged from the compose files instead of converting it.
+	PrintMerged bool
+
+	// EnvFiles are the env files used to interpolate the compose files, instead of the .env file.
+	EnvFiles []string
+
+	// Env are the KEY=VALUE variables used to interpolate the compose files, overriding the environment.
+	Env []string
+
+	// ConvertEnvironments are the environments converted as overlays of the base, as NAME=FILE|profile:PROFILE[,...].
+	ConvertEnvironments []string
+
+	// EnvironmentsFormat is the layout of the environments, Kustomize overlays or Helm values files.
+	EnvironmentsFormat string
+
+	// NamespaceBy places the services in a namespace per service group or per network.
+	NamespaceBy string
+
+	// AllowRemoteExtends fetches the extended files given as http(s) URLs or in git repositories.
+	AllowRemoteExtends bool
 )
 
 var convertCmd = &cobra.Command{
-	Use:   "convert",
+	Use:   "convert [SERVICE...]",
 	Short: "Convert a Compose file",
 	Example: `  kompose --file compose.yaml convert
   kompose -f first.yaml -f second.yaml convert
-  kompose --provider openshift --file compose.yaml convert`,
+  kompose --provider openshift --file compose.yaml convert
+  kompose convert web worker`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 
 		// Check that build-config wasn't passed in with --provider=kubernetes
@@ -100,6 +245,7 @@ var convertCmd = &cobra.Command{
 			GenerateJSON:                ConvertJSON,
 			Replicas:                    ConvertReplicas,
 			InputFiles:                  GlobalFiles,
+			Context:                     GlobalContext,
 			OutFile:                     ConvertOut,
 			Provider:                    GlobalProvider,
 			CreateD:                     ConvertDeployment,
@@ -110,6 +256,7 @@ var convertCmd = &cobra.Command{
 			BuildBranch:                 ConvertBuildBranch,
 			PushImage:                   ConvertPushImage,
 			PushImageRegistry:           ConvertPushImageRegistry,
+			PushImageDigest:             ConvertPushImageDigest,
 			CreateDeploymentConfig:      ConvertDeploymentConfig,
 			EmptyVols:                   ConvertEmptyVols,
 			Volumes:                     ConvertVolumes,
@@ -133,6 +280,83 @@ var convertCmd = &cobra.Command{
 			BuildCommand:                BuildCommand,
 			PushCommand:                 PushCommand,
 			Namespace:                   ConvertNamespace,
+			DisableServiceLinks:         DisableServiceLinks,
+			AllowUnsafeSysctls:          AllowUnsafeSysctls,
+			AllowHostNamespaces:         AllowHostNamespaces,
+			WaitForDependencies:         WaitForDependencies,
+			WaitImage:                   WaitImage,
+			RenameReport:                RenameReport,
+			Merge:                       Merge,
+			Summary:                     Summary,
+			ExposeController:            strings.ToLower(ExposeController),
+			GatewayName:                 GatewayName,
+			GatewayClassName:            GatewayClassName,
+			CreateNamespace:             CreateNamespace,
+			SecretsAs:                   strings.ToLower(SecretsAs),
+			SealedSecretsCert:           SealedSecretsCert,
+			SealedSecretsScope:          strings.ToLower(SealedSecretsScope),
+			BuildPushSecret:             BuildPushSecret,
+			ArgoCDApp:                   ArgoCDApp,
+			ArgoCDRepoURL:               ArgoCDRepoURL,
+			ArgoCDPath:                  ArgoCDPath,
+			ArgoCDDestNamespace:         ArgoCDDestNamespace,
+			PushChart:                   PushChart,
+			Validate:                    Validate,
+			KubernetesVersion:           KubernetesVersion,
+			DryRun:                      strings.ToLower(DryRun),
+			IncludeKinds:                IncludeKinds,
+			ExcludeKinds:                ExcludeKinds,
+			Services:                    ConvertServices,
+			StandardLabels:              StandardLabels,
+			AntiAffinity:                strings.ToLower(AntiAffinity),
+			PodSecurity:                 strings.ToLower(PodSecurity),
+			InspectImages:               InspectImages,
+			ResolveImageDigests:         ResolveImageDigests,
+			Strict:                      Strict,
+			LoggingSidecar:              LoggingSidecar,
+			NativeSidecars:              NativeSidecars,
+			DetectCluster:               DetectCluster,
+			Quadlet:                     strings.ToLower(Quadlet),
+			Interactive:                 Interactive,
+			EnvConfigMapHash:            EnvConfigMapHash,
+			OutputLayout:                strings.ToLower(OutputLayout),
+			Reproducible:                Reproducible,
+			Sign:                        Sign,
+			SignKey:                     SignKey,
+			EnvFiles:                    EnvFiles,
+			Env:                         Env,
+			EnvironmentsFormat:          strings.ToLower(EnvironmentsFormat),
+			NamespaceBy:                 strings.ToLower(NamespaceBy),
+			AllowRemoteExtends:          AllowRemoteExtends,
+		}
+
+		projects, err := app.ParseProjects(ConvertProjects)
+		if err != nil {
+			log.Fatalf("Error parsing --project: %v", err)
+		}
+		ConvertOpt.Projects = projects
+
+		environments, err := app.ParseEnvironments(ConvertEnvironments)
+		if err != nil {
+			log.Fatalf("Error parsing --environment: %v", err)
+		}
+		ConvertOpt.Environments = environments
+
+		for _, kv := range []struct {
+			flag   string
+			values []string
+			labels bool
+			target *map[string]string
+		}{
+			{"--add-label", AddLabels, true, &ConvertOpt.AddLabels},
+			{"--add-annotation", AddAnnotations, false, &ConvertOpt.AddAnnotations},
+			{"--add-pod-label", AddPodLabels, true, &ConvertOpt.AddPodLabels},
+			{"--add-pod-annotation", AddPodAnnotations, false, &ConvertOpt.AddPodAnnotations},
+		} {
+			*kv.target, err = app.ParseKeyValues(kv.values, kv.labels)
+			if err != nil {
+				log.Fatalf("Error parsing %s: %v", kv.flag, err)
+			}
 		}
 
 		if ServiceGroupMode == "" && MultipleContainerMode {
@@ -142,12 +366,37 @@ var convertCmd = &cobra.Command{
 		app.ValidateFlags(args, cmd, &ConvertOpt)
 
 		// Since ValidateComposeFiles returns an error, let's validate it and output the error appropriately if the validation fails
-		err := app.ValidateComposeFile(&ConvertOpt)
+		err = app.ValidateComposeFile(&ConvertOpt)
 		if err != nil {
 			log.Fatalf("Error validating compose file: %v", err)
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		if PrintMerged {
+			if err := app.PrintMerged(ConvertOpt, os.Stdout); err != nil {
+				log.Fatalf("Error: --print-merged: %v", err)
+			}
+			return
+		}
+
+		if Interactive {
+			// the preview is written on the standard error, the manifests can be written on the standard output
+			opt, write, err := app.Interactive(ConvertOpt, os.Stdin, os.Stderr)
+			if err != nil {
+				log.Fatalf("Error: --interactive: %v", err)
+			}
+			if !write {
+				return
+			}
+			ConvertOpt = opt
+		}
+
+		if len(ConvertOpt.Environments) > 0 {
+			if err := app.ConvertEnvironments(ConvertOpt); err != nil {
+				log.Fatalf("Error: --environment: %v", err)
+			}
+			return
+		}
 
 		app.Convert(ConvertOpt)
 	},
@@ -159,6 +408,7 @@ func init() {
 
 	// Kubernetes only
 	convertCmd.Flags().BoolVarP(&ConvertChart, "chart", "c", false, "Create a Helm chart for converted objects")
+	convertCmd.Flags().StringVar(&PushChart, "push-chart", "", "Package the chart created with --chart and push it to this OCI repository, as oci://REGISTRY/REPOSITORY")
 	convertCmd.Flags().BoolVar(&ConvertDaemonSet, "daemon-set", false, "Generate a Kubernetes daemonset object (deprecated, use --controller instead)")
 	convertCmd.Flags().BoolVarP(&ConvertDeployment, "deployment", "d", false, "Generate a Kubernetes deployment object (deprecated, use --controller instead)")
 	convertCmd.Flags().BoolVar(&ConvertReplicationController, "replication-controller", false, "Generate a Kubernetes replication controller object (deprecated, use --controller instead)")
@@ -175,6 +425,9 @@ func init() {
 	convertCmd.Flags().StringVar(&ServiceGroupName, "service-group-name", "", "Using with --service-group-mode=volume to specific a final service name for the group")
 	convertCmd.Flags().MarkDeprecated("multiple-container-mode", "use --service-group-mode=label")
 	convertCmd.Flags().BoolVar(&SecretsAsFiles, "secrets-as-files", false, "Always convert docker-compose secrets into files instead of symlinked directories")
+	convertCmd.Flags().StringVar(&ExposeController, "expose-controller", "ingress", `Set the objects exposing the services labeled with kompose.service.expose ("ingress"|"gateway-api")`)
+	convertCmd.Flags().StringVar(&GatewayName, "gateway", "kompose", "Gateway the HTTPRoutes are attached to with --expose-controller=gateway-api, as [NAMESPACE/]NAME")
+	convertCmd.Flags().StringVar(&GatewayClassName, "gateway-class", "", "Generate the Gateway the HTTPRoutes are attached to, with this GatewayClass")
 
 	// OpenShift only
 	convertCmd.Flags().BoolVar(&ConvertDeploymentConfig, "deployment-config", true, "Generate an OpenShift deploymentconfig object")
@@ -188,11 +441,23 @@ func init() {
 	convertCmd.Flags().MarkHidden("build-branch")
 
 	// Standard between the two
-	convertCmd.Flags().StringVar(&ConvertBuild, "build", "none", `Set the type of build ("local"|"build-config"(OpenShift only)|"none")`)
+	convertCmd.Flags().StringVar(&ConvertBuild, "build", "none", `Set the type of build ("local"|"build-config"(OpenShift only)|"kaniko"(Kubernetes only)|"none")`)
+	convertCmd.Flags().StringVar(&BuildPushSecret, "build-push-secret", "", "Docker config Secret the Kaniko Jobs push the images with, used with --build=kaniko")
 	convertCmd.Flags().BoolVar(&ConvertPushImage, "push-image", false, "If we should push the docker image we built")
 	convertCmd.Flags().StringVar(&BuildCommand, "build-command", "", `Set the command used to build the container image, which will override the docker build command. Should be used in conjuction with --push-command flag.`)
 	convertCmd.Flags().StringVar(&PushCommand, "push-command", "", `Set the command used to push the container image. override the docker push command. Should be used in conjuction with --build-command flag.`)
 	convertCmd.Flags().StringVar(&ConvertPushImageRegistry, "push-image-registry", "", "Specify registry for pushing image, which will override registry from image name")
+	convertCmd.Flags().BoolVar(&ConvertPushImageDigest, "push-image-digest", false, "Reference the pushed images by digest in the generated manifests")
+	// --push and --registry are accepted as the short forms of --push-image and --push-image-registry
+	convertCmd.Flags().SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		switch name {
+		case "push":
+			name = "push-image"
+		case "registry":
+			name = "push-image-registry"
+		}
+		return pflag.NormalizedName(name)
+	})
 	convertCmd.Flags().BoolVarP(&ConvertYaml, "yaml", "y", false, "Generate resource files into YAML format")
 	convertCmd.Flags().MarkDeprecated("yaml", "YAML is the default format now")
 	convertCmd.Flags().MarkShorthandDeprecated("y", "YAML is the default format now")
@@ -203,7 +468,58 @@ func init() {
 	convertCmd.Flags().StringVar(&ConvertVolumes, "volumes", "persistentVolumeClaim", `Volumes to be generated ("persistentVolumeClaim"|"emptyDir"|"hostPath" | "configMap")`)
 	convertCmd.Flags().StringVar(&ConvertPVCRequestSize, "pvc-request-size", "", `Specify the size of pvc storage requests in the generated resource spec`)
 	convertCmd.Flags().StringVarP(&ConvertNamespace, "namespace", "n", "", `Specify the namespace of the generated resources`)
+	convertCmd.Flags().BoolVar(&CreateNamespace, "create-namespace", false, "Generate the Namespace manifest of the namespace given with --namespace")
 	convertCmd.Flags().BoolVar(&GenerateNetworkPolicies, "generate-network-policies", false, "Specify whether to generate network policies or not")
+	convertCmd.Flags().StringVar(&SecretsAs, "secrets-as", "secret", `Set the objects the compose secrets are converted to ("secret"|"sealed")`)
+	convertCmd.Flags().StringVar(&SealedSecretsCert, "sealed-secrets-cert", "", "Certificate of the sealed-secrets controller used with --secrets-as=sealed, as fetched with kubeseal --fetch-cert")
+	convertCmd.Flags().StringVar(&SealedSecretsScope, "sealed-secrets-scope", "strict", `Scope of the sealed secrets ("strict"|"namespace-wide"|"cluster-wide")`)
+	convertCmd.Flags().BoolVar(&DisableServiceLinks, "disable-service-links", false, "Do not inject service environment variables into the generated pods")
+	convertCmd.Flags().BoolVar(&AllowUnsafeSysctls, "allow-unsafe-sysctls", false, "Keep the unsafe sysctls of the services, they must be allowed on the kubelets with --allowed-unsafe-sysctls")
+	convertCmd.Flags().BoolVar(&AllowHostNamespaces, "allow-host-namespaces", false, "Share the host PID, IPC and network namespaces of the services using pid, ipc or network_mode host")
+	convertCmd.Flags().BoolVar(&WaitForDependencies, "wait-for-dependencies", false, "Add init containers waiting for the depends_on services to be reachable")
+	convertCmd.Flags().StringVar(&WaitImage, "wait-image", "busybox:1.36", "Image of the init containers waiting for the depends_on services, it must provide sh and nc")
+	convertCmd.Flags().BoolVar(&Merge, "merge", false, "Merge the regenerated objects with the manual edits made to the files of the output directory")
+	convertCmd.Flags().StringArrayVar(&ConvertProjects, "project", []string{}, "Convert a compose project into its own namespace, as NAME=FILE[,FILE...] (can be repeated)")
+	convertCmd.Flags().StringArrayVar(&AddLabels, "add-label", []string{}, "Add a label to every generated object, as KEY=VALUE (can be repeated)")
+	convertCmd.Flags().StringArrayVar(&AddAnnotations, "add-annotation", []string{}, "Add an annotation to every generated object, as KEY=VALUE (can be repeated)")
+	convertCmd.Flags().StringArrayVar(&AddPodLabels, "add-pod-label", []string{}, "Add a label to every generated pod template, as KEY=VALUE (can be repeated)")
+	convertCmd.Flags().StringArrayVar(&AddPodAnnotations, "add-pod-annotation", []string{}, "Add an annotation to every generated pod template, as KEY=VALUE (can be repeated)")
+	convertCmd.Flags().StringVar(&Summary, "summary", "", `Print a summary of the generated objects and of the ignored or approximated keys ("table"|"json")`)
+	convertCmd.Flags().Lookup("summary").NoOptDefVal = "table"
+	convertCmd.Flags().StringVar(&ArgoCDApp, "argocd-app", "", "Generate the ArgoCD Application of this name syncing the generated objects, with sync waves following depends_on")
+	convertCmd.Flags().StringVar(&ArgoCDRepoURL, "argocd-repo-url", "", "Git repository of the ArgoCD Application (default remote origin)")
+	convertCmd.Flags().StringVar(&ArgoCDPath, "argocd-path", "", "Path of the generated objects in the git repository of the ArgoCD Application (default path of the output directory)")
+	convertCmd.Flags().StringVar(&ArgoCDDestNamespace, "argocd-dest-namespace", "", "Destination namespace of the ArgoCD Application (default --namespace, else default)")
+	convertCmd.Flags().BoolVar(&Validate, "validate", false, "Validate the generated objects offline as the API server of --kubernetes-version would, and fail the conversion with the invalid fields")
+	convertCmd.Flags().StringVar(&KubernetesVersion, "kubernetes-version", validate.DefaultKubernetesVersion, "Kubernetes version, as MAJOR.MINOR, the objects are generated and validated (with --validate) for")
+	convertCmd.Flags().StringVar(&DryRun, "dry-run", "none", `Send the generated objects to the cluster of the current kubectl context with a server-side dry-run before writing them ("none"|"server")`)
+	convertCmd.Flags().StringSliceVar(&ConvertServices, "services", []string{}, "Only convert these services, the services they depend on and the resources they use, as web,worker (also given as arguments)")
+	convertCmd.Flags().StringSliceVar(&IncludeKinds, "include-kinds", []string{}, "Only print the objects of these kinds, as Deployment,Service (case-insensitive)")
+	convertCmd.Flags().StringSliceVar(&ExcludeKinds, "exclude-kinds", []string{}, "Don't print the objects of these kinds, as Ingress,PersistentVolumeClaim (case-insensitive)")
+	convertCmd.Flags().BoolVar(&StandardLabels, "standard-labels", false, "Set the app.kubernetes.io recommended labels (name, instance, version, component, part-of, managed-by) on the objects and their selectors")
+	convertCmd.Flags().StringVar(&AntiAffinity, "anti-affinity", "", `Keep the replicas of the services on different nodes, "preferred" or "required" (overridden by the kompose.affinity.anti-affinity label)`)
+	convertCmd.Flags().StringVar(&PodSecurity, "pod-security", "", `Make the workloads comply with this Pod Security Standard, "restricted": non-root users, RuntimeDefault seccomp profile, no capabilities and no privilege escalation`)
+	convertCmd.Flags().BoolVar(&InspectImages, "inspect-images", false, "Read the EXPOSE and USER instructions of the images in their registry with skopeo for the services without ports or user (cached for 24 hours)")
+	convertCmd.Flags().BoolVar(&ResolveImageDigests, "resolve-image-digests", false, "Reference the images by the digest of their tag in the registry, as image@sha256:..., resolved with skopeo")
+	convertCmd.Flags().BoolVar(&Strict, "strict", false, "Fail when a setting of the compose files is ignored, instead of warning about it")
+	convertCmd.Flags().BoolVar(&LoggingSidecar, "logging-sidecar", false, "Ship the logs of the services with a fluentd or gelf logging driver with a fluent-bit sidecar")
+	convertCmd.Flags().BoolVar(&NativeSidecars, "native-sidecars", false, "Add the sidecars as native sidecars, init containers with the Always restart policy starting before the containers and stopping after them (Kubernetes 1.29+)")
+	convertCmd.Flags().BoolVar(&DetectCluster, "detect-cluster", false, "Query the APIs served by the cluster of the current kubectl context and generate Gateway API routes or SealedSecrets when they are available, unless --expose-controller or --secrets-as are set")
+	convertCmd.Flags().StringVar(&Quadlet, "quadlet", "kube", `Set the systemd Quadlet units generated with --provider podman, a .kube unit playing the kube YAML of the project or a .container unit for each container ("kube"|"container")`)
+	convertCmd.Flags().BoolVar(&Interactive, "interactive", false, "Preview the objects generated for each service, toggle their kinds and change the controller, the expose controller and the volumes before writing them")
+	convertCmd.Flags().BoolVar(&EnvConfigMapHash, "env-configmap-hash", false, "Suffix the names of the env_file ConfigMaps with a hash of the file path and of its content, keeping them unique and rolling out the pods when the content changes")
+	convertCmd.Flags().StringVar(&OutputLayout, "output-layout", "flat", `Set the layout of the files written to the output directory, all of them in the directory or the objects of each service in a directory named after it ("flat"|"service")`)
+	convertCmd.Flags().BoolVar(&Reproducible, "reproducible", false, "Strip the kompose.cmd and kompose.version annotations and the creation timestamps, and sort the keys of the JSON output, so that the same compose files always give the same files")
+	convertCmd.Flags().BoolVar(&Sign, "sign", false, "Sign the output with cosign: the package of the chart created with --chart, the output file, or a bundle of the files written to the output directory, writing the signature, the certificate and the bundle next to it")
+	convertCmd.Flags().StringVar(&SignKey, "sign-key", "", "Sign with this cosign key file instead of keyless signing with the OIDC identity of the user, used with --sign")
+	convertCmd.Flags().BoolVar(&PrintMerged, "print-merged", false, "Print the compose model merged from the compose files, the later files overriding the earlier ones, instead of converting it")
+	convertCmd.Flags().StringArrayVar(&EnvFiles, "env-file", []string{}, "Interpolate the variables of the compose files with this env file instead of the .env file of the project directory (can be repeated)")
+	convertCmd.Flags().StringArrayVar(&Env, "env", []string{}, "Interpolate the variables of the compose files with this KEY=VALUE, overriding the environment and the env files (can be repeated)")
+	convertCmd.Flags().StringArrayVar(&ConvertEnvironments, "environment", []string{}, "Convert an environment with its override files and its profiles added to the base ones, as NAME=FILE|profile:PROFILE[,...], writing the base objects and a layout per environment (can be repeated)")
+	convertCmd.Flags().StringVar(&EnvironmentsFormat, "environments-format", "kustomize", `Set the layout of the environments, a Kustomize overlay of the base objects per environment or a Helm chart with a values file per environment ("kustomize"|"helm")`)
+	convertCmd.Flags().BoolVar(&AllowRemoteExtends, "allow-remote-extends", false, "Fetch the files extended by the services given as http(s) URLs or in git repositories")
+	convertCmd.Flags().StringVar(&NamespaceBy, "namespace-by", "", `Place the services in a namespace named after their kompose.service.group label or their network, qualifying the host names of the other namespaces in their environment ("group"|"network")`)
+	convertCmd.Flags().StringVar(&RenameReport, "rename-report", "", "Write the mapping of compose names to sanitized Kubernetes names to this JSON file")
 
 	convertCmd.Flags().BoolVar(&WithKomposeAnnotation, "with-kompose-annotation", true, "Add kompose annotations to generated resource")
 	convertCmd.Flags().BoolVar(&NoInterpolate, "no-interpolate", false, "Keep environment variable names in the Compose file")
@@ -232,13 +548,18 @@ Available Commands:{{range .Commands}}{{if .IsAvailableCommand}}
 
 Kubernetes Flags:
   -c, --chart                    Create a Helm chart for converted objects
+      --build-push-secret        Docker config Secret the Kaniko Jobs push the images with, used with --build=kaniko
       --controller               Set the output controller ("deployment"|"daemonSet"|"replicationController")
+      --expose-controller        Set the objects exposing the services labeled with kompose.service.expose ("ingress"|"gateway-api")
+      --gateway                  Gateway the HTTPRoutes are attached to with --expose-controller=gateway-api, as [NAMESPACE/]NAME
+      --gateway-class            Generate the Gateway the HTTPRoutes are attached to, with this GatewayClass
+      --push-chart               Package the chart created with --chart and push it to this OCI repository, as oci://REGISTRY/REPOSITORY
       --service-group-mode       Group multiple service to create single workload by "label"("kompose.service.group") or "volume"(shared volumes)
       --service-group-name       Using with --service-group-mode=volume to specific a final service name for the group
 
 OpenShift Flags:
-      --build-branch             Specify repository branch to use for buildconfig (default is current branch name)
-      --build-repo               Specify source repository for buildconfig (default is current branch's remote url)
+      --build-branch             Specify repository branch to use for buildconfig, or the Kaniko Jobs (default is current branch name)
+      --build-repo               Specify source repository for buildconfig, or the Kaniko Jobs (default is current branch's remote url)
       --insecure-repository      Specify to use insecure docker repository while generating Openshift image stream object
 
 Flags:
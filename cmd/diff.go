@@ -0,0 +1,52 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/kubernetes/kompose/pkg/app"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd previews the changes applying the converted objects would make to the cluster
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Diff the converted objects against the live objects of the cluster",
+	Long: `Convert the compose files and print the differences between the generated objects and the live
+objects of the cluster of the current kubectl context, as kubectl diff does. The exit code is 1
+when the objects differ, and above 1 on errors.`,
+	Example: `  kompose diff
+  kompose --file compose.yaml diff --namespace shop`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		differ, err := app.Diff(clusterConvertOptions(), os.Stdout)
+		if err != nil {
+			log.Error(err)
+			os.Exit(2)
+		}
+		if differ {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addClusterFlags(diffCmd)
+	RootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/app"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// GraphFormat is the format of the conversion graph
+var GraphFormat string
+
+// graphCmd prints the graph of the conversion
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Print the graph of the services and of their converted objects",
+	Long: `Convert the compose files and print the graph of the services, of the objects generated for
+them, of the claims, ConfigMaps and Secrets their workloads mount, and of their depends_on
+dependencies, in the DOT language of Graphviz or as a Mermaid flowchart.`,
+	Example: `  kompose graph | dot -Tsvg -o graph.svg
+  kompose --file compose.yaml graph --format mermaid`,
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		GraphFormat = strings.ToLower(GraphFormat)
+		if err := app.ValidateGraphFormat(GraphFormat); err != nil {
+			log.Fatalf("Error validating --format: %v", err)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := app.Graph(clusterConvertOptions(), GraphFormat, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	addClusterFlags(graphCmd)
+	graphCmd.Flags().StringVar(&GraphFormat, "format", app.GraphDOT, `Format of the graph ("dot"|"mermaid")`)
+	RootCmd.AddCommand(graphCmd)
+}
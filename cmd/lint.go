@@ -0,0 +1,68 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/app"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// LintFailOn is the least severe finding failing the lint command
+var LintFailOn string
+
+// lintCmd reports the issues of the converted objects
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Report the issues of the converted objects",
+	Long: `Convert the compose files and report the issues of the containers of the generated workloads:
+privileged containers (error), hostPath volumes, images using the latest tag and containers without
+resource limits (warning), and containers without health check (info). The exit code is 1 when a
+finding is at least as severe as --fail-on, and above 1 on errors.`,
+	Example: `  kompose lint
+  kompose --file compose.yaml lint --fail-on warning`,
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		LintFailOn = strings.ToLower(LintFailOn)
+		if err := app.ValidateSeverity(LintFailOn); err != nil {
+			log.Fatalf("Error validating --fail-on: %v", err)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		findings, err := app.Lint(clusterConvertOptions())
+		if err != nil {
+			log.Error(err)
+			os.Exit(2)
+		}
+		if err := app.PrintFindings(findings, os.Stdout); err != nil {
+			log.Error(err)
+			os.Exit(2)
+		}
+		if app.LintFails(findings, LintFailOn) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addClusterFlags(lintCmd)
+	lintCmd.Flags().StringVar(&LintFailOn, "fail-on", app.SeverityError, `Least severe finding failing the command ("info"|"warning"|"error"|"none")`)
+	RootCmd.AddCommand(lintCmd)
+}
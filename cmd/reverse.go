@@ -0,0 +1,64 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/kubernetes/kompose/pkg/reverse"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// ReverseOut is the compose file written by the reverse command, the standard output when empty
+var ReverseOut string
+
+// reverseCmd converts Kubernetes manifests to a compose file
+var reverseCmd = &cobra.Command{
+	Use:   "reverse [file|directory]...",
+	Short: "Convert Kubernetes manifests to a compose file",
+	Long: `Convert the Deployments, StatefulSets, DaemonSets, Services, PersistentVolumeClaims and ConfigMaps
+of Kubernetes manifests to an approximated compose file, to run the workloads locally.
+The fields having no compose equivalent are ignored with a warning.`,
+	Example: `  kompose reverse manifests/ --out compose.yaml
+  kompose reverse deployment.yaml service.yaml`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		project, err := reverse.Convert(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+		data, err := project.MarshalYAML()
+		if err != nil {
+			log.Fatalf("Unable to marshal the compose file: %s", err)
+		}
+
+		if ReverseOut == "" || ReverseOut == "-" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(ReverseOut, data, 0644); err != nil {
+			log.Fatalf("Unable to write the compose file: %s", err)
+		}
+		log.Printf("Compose file %q created", ReverseOut)
+	},
+}
+
+func init() {
+	reverseCmd.Flags().StringVarP(&ReverseOut, "out", "o", "", "Specify the compose file to write, the standard output by default")
+	RootCmd.AddCommand(reverseCmd)
+}
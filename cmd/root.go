@@ -17,8 +17,15 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/redact"
+	"github.com/kubernetes/kompose/pkg/utils/remote"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -46,8 +53,75 @@ var (
 	GlobalSuppressWarnings bool
 	GlobalErrorOnWarning   bool
 	GlobalFiles            []string
+	GlobalConfig           string
+	GlobalContext          string
+	GlobalShowSecrets      bool
 )
 
+// configFileNames are the names of the config file looked up in the project directory
+var configFileNames = []string{".kompose.yaml", ".kompose.yml", ".kompose", "kompose.yaml", "kompose.yml"}
+
+// findConfigFile returns the config file of the project, in the directory of the first local compose file
+// or in the working directory
+func findConfigFile(files []string) string {
+	dir := "."
+	if len(files) > 0 && files[0] != "-" && !remote.IsRemote(files[0]) {
+		dir = filepath.Dir(files[0])
+	}
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		// .kompose may also be the directory of the --merge base manifests
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadConfigFile sets the flags of the command that aren't given on the command line from the
+// config file, a YAML mapping of flag names to their values
+func loadConfigFile(cmd *cobra.Command) error {
+	path := GlobalConfig
+	if path == "" {
+		path = findConfigFile(GlobalFiles)
+		if path == "" {
+			return nil
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read the config file %s: %w", path, err)
+	}
+	log.Debugf("Using the config file %s", path)
+
+	for _, key := range v.AllKeys() {
+		f := cmd.Flags().Lookup(key)
+		if f == nil {
+			log.Warnf("Ignoring %q in the config file %s, it isn't a flag of %q", key, path, cmd.CommandPath())
+			continue
+		}
+		if f.Changed {
+			continue
+		}
+		values := []string{v.GetString(key)}
+		if list, ok := v.Get(key).([]interface{}); ok {
+			values = nil
+			for _, item := range list {
+				values = append(values, fmt.Sprint(item))
+			}
+		}
+		for _, value := range values {
+			if err := cmd.Flags().Set(key, value); err != nil {
+				return fmt.Errorf("invalid %q in the config file %s: %w", key, path, err)
+			}
+		}
+	}
+	return nil
+}
+
 // RootCmd root level flags and commands
 var RootCmd = &cobra.Command{
 	Use:   "kompose",
@@ -62,17 +136,28 @@ var RootCmd = &cobra.Command{
 	// the child has overridden the functionality. This functionality was implemented to check / modify
 	// all global flag calls regardless of app call.
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Add extra logging when verbosity is passed
-		if GlobalVerbose {
-			log.SetLevel(log.DebugLevel)
-		}
-
 		// Disable the timestamp (Kompose is too fast!)
 		formatter := new(log.TextFormatter)
 		formatter.DisableTimestamp = true
 		formatter.ForceColors = true
 		log.SetFormatter(formatter)
 
+		// The flags not given on the command line default to the ones of the config file
+		if err := loadConfigFile(cmd); err != nil {
+			log.Fatal(err)
+		}
+
+		// Add extra logging when verbosity is passed
+		if GlobalVerbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		// Mask the values of the environment and of the secrets in the log messages, before the other hooks
+		redact.Show = GlobalShowSecrets
+		if !GlobalShowSecrets {
+			log.AddHook(redact.Hook{})
+		}
+
 		// Set the appropriate suppress warnings and error on warning flags
 		if GlobalSuppressWarnings {
 			log.SetLevel(log.ErrorLevel)
@@ -81,10 +166,9 @@ var RootCmd = &cobra.Command{
 			log.AddHook(hook)
 		}
 
-		// Error out of the user has not chosen Kubernetes or OpenShift
-		provider := strings.ToLower(GlobalProvider)
-		if provider != "kubernetes" && provider != "openshift" {
-			log.Fatalf("%s is an unsupported provider. Supported providers are: 'kubernetes', 'openshift'.", GlobalProvider)
+		// Error out of the user has not chosen a registered provider
+		if _, err := transformer.Get(GlobalProvider, kobject.ConvertOptions{}); err != nil {
+			log.Fatal(err)
 		}
 
 		v := viper.New()
@@ -109,6 +193,9 @@ func init() {
 	RootCmd.PersistentFlags().BoolVarP(&GlobalVerbose, "verbose", "v", false, "verbose output")
 	RootCmd.PersistentFlags().BoolVar(&GlobalSuppressWarnings, "suppress-warnings", false, "Suppress all warnings")
 	RootCmd.PersistentFlags().BoolVar(&GlobalErrorOnWarning, "error-on-warning", false, "Treat any warning as an error")
-	RootCmd.PersistentFlags().StringSliceVarP(&GlobalFiles, "file", "f", []string{}, "Specify an alternative compose file")
-	RootCmd.PersistentFlags().StringVar(&GlobalProvider, "provider", "kubernetes", "Specify a provider. Kubernetes or OpenShift.")
+	RootCmd.PersistentFlags().StringSliceVarP(&GlobalFiles, "file", "f", []string{}, "Specify an alternative compose file, - for stdin, an http(s) URL, or git::REPO[//PATH][?ref=REF]")
+	RootCmd.PersistentFlags().StringVar(&GlobalContext, "context", "", "Directory the relative paths of the compose files are resolved against (default directory of the first compose file, current directory for stdin and URLs)")
+	RootCmd.PersistentFlags().StringVar(&GlobalProvider, "provider", "kubernetes", fmt.Sprintf("Specify a provider, one of: %s.", strings.Join(transformer.Providers(), ", ")))
+	RootCmd.PersistentFlags().BoolVar(&GlobalShowSecrets, "show-secrets", false, "Show the values of the environment, of the build arguments and of the secrets in the logs and the reports, for local debugging")
+	RootCmd.PersistentFlags().StringVar(&GlobalConfig, "config", "", "Specify the config file setting the default flags (default .kompose.yaml or kompose.yaml in the project directory)")
 }
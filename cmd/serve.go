@@ -0,0 +1,61 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/kubernetes/kompose/pkg/app"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/validate"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// ServeAddress is the address the conversion API listens on
+var ServeAddress string
+
+// serveCmd serves the conversion API
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an HTTP API converting compose files",
+	Long: `Serve an HTTP API converting the compose files sent to it, so that the platforms can convert them
+without running kompose. POST /convert converts the compose file of the request body, or the "file"
+parts of a multipart form, and returns the generated manifests as YAML, or the Helm chart as a gzipped
+tarball with ?chart=NAME. The query parameters provider, namespace, controller, profile, service and
+kubernetes-version set the options of the conversion. GET /healthz tells that the server is up.`,
+	Example: `  kompose serve --address :8080
+  curl --data-binary @compose.yaml "http://localhost:8080/convert?namespace=shop"
+  curl -F file=@compose.yaml -F file=@compose.prod.yaml -o shop.tgz "http://localhost:8080/convert?chart=shop"`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opt := kobject.ConvertOptions{
+			Provider:              GlobalProvider,
+			Replicas:              1,
+			Volumes:               "persistentVolumeClaim",
+			YAMLIndent:            2,
+			WithKomposeAnnotation: true,
+			KubernetesVersion:     validate.DefaultKubernetesVersion,
+		}
+		if err := app.Serve(ServeAddress, opt); err != nil {
+			log.Fatalf("Error serving the conversion API: %v", err)
+		}
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&ServeAddress, "address", "localhost:8080", "Address the conversion API listens on, as HOST:PORT")
+	RootCmd.AddCommand(serveCmd)
+}
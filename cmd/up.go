@@ -0,0 +1,76 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kubernetes/kompose/pkg/app"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// Flags of the up command
+var (
+	UpWait           bool
+	UpTimeout        time.Duration
+	UpWatch          bool
+	UpForceConflicts bool
+)
+
+// upCmd applies the converted objects to the cluster
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply the converted objects to the cluster",
+	Long: `Convert the compose files and apply the generated objects to the cluster of the current kubectl
+context. The objects are applied server-side with the kompose field manager, the fields set by the other
+controllers are kept, and the fields owned by another manager fail to apply unless --force-conflicts is
+given. With --wait, the Deployments, StatefulSets and DaemonSets are watched until their rollout
+completes, and the exit code is non-zero when one of them fails or doesn't complete within --timeout.
+With --watch, the compose files, their env_files, the files of their configs and secrets and their
+bind-mounted host paths are watched, and the changed objects are applied again on each change.`,
+	Example: `  kompose up
+  kompose --file compose.yaml up --namespace shop --wait --timeout 2m
+  kompose up --watch`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if UpWatch {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if err := app.Watch(ctx, clusterConvertOptions(), UpWait, UpTimeout, UpForceConflicts); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+		if err := app.Up(clusterConvertOptions(), UpWait, UpTimeout, UpForceConflicts); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	addClusterFlags(upCmd)
+	upCmd.Flags().BoolVar(&UpWait, "wait", false, "Wait for the rollout of the Deployments, StatefulSets and DaemonSets to complete")
+	upCmd.Flags().DurationVar(&UpTimeout, "timeout", 5*time.Minute, "Maximum time to wait for the rollouts with --wait, 0 waits forever")
+	upCmd.Flags().BoolVar(&UpWatch, "watch", false, "Watch the compose files and the files they reference, and apply the changed objects on each change")
+	upCmd.Flags().BoolVar(&UpForceConflicts, "force-conflicts", false, "Take over the fields of the objects owned by another field manager instead of failing")
+	RootCmd.AddCommand(upCmd)
+}
@@ -17,13 +17,16 @@ limitations under the License.
 package app
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"os"
 
@@ -31,7 +34,13 @@ import (
 	"github.com/kubernetes/kompose/pkg/loader"
 	"github.com/kubernetes/kompose/pkg/transformer"
 	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
-	"github.com/kubernetes/kompose/pkg/transformer/openshift"
+	_ "github.com/kubernetes/kompose/pkg/transformer/openshift"
+	"github.com/kubernetes/kompose/pkg/transformer/podman"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	"github.com/kubernetes/kompose/pkg/utils/redact"
+	"github.com/kubernetes/kompose/pkg/utils/remote"
+	"github.com/kubernetes/kompose/pkg/validate"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -49,8 +58,13 @@ const (
 	ProviderKubernetes = "kubernetes"
 	// ProviderOpenshift is provider openshift
 	ProviderOpenshift = "openshift"
+	// ProviderPodman is provider podman
+	ProviderPodman = "podman"
 	// DefaultProvider - provider that will be used if there is no provider was explicitly set
 	DefaultProvider = ProviderKubernetes
+
+	// ProjectLabel is the label set on the objects of a project when several projects are converted
+	ProjectLabel = "io.kompose.project"
 )
 
 var inputFormat = "compose"
@@ -99,15 +113,40 @@ func ValidateFlags(args []string, cmd *cobra.Command, opt *kobject.ConvertOption
 		if controller == "daemonset" || controller == "replicationcontroller" || controller == "deployment" {
 			log.Fatalf("--controller= daemonset, replicationcontroller or deployment is a Kubernetes only flag")
 		}
+		if opt.Build == kubernetes.KanikoBuild {
+			log.Fatalf("--build=%s is a Kubernetes only flag", kubernetes.KanikoBuild)
+		}
+		if opt.BuildPushSecret != "" {
+			log.Fatalf("--build-push-secret is a Kubernetes only flag")
+		}
+	case provider == ProviderPodman:
+		if chart {
+			log.Fatalf("--chart, -c is not supported by the Podman provider")
+		}
+		if deploymentConfig || controller == "deploymentconfig" {
+			log.Fatalf("--deployment-config is an OpenShift only flag")
+		}
+		if opt.OutputLayout == kubernetes.OutputLayoutService {
+			log.Fatalf("--output-layout=%s is not supported by the Podman provider", kubernetes.OutputLayoutService)
+		}
+		if opt.Sign {
+			log.Fatalf("--sign is not supported by the Podman provider")
+		}
+		switch opt.Quadlet {
+		case "", podman.QuadletKube, podman.QuadletContainer:
+		default:
+			log.Fatalf("Error: --quadlet must be %q or %q, got %q", podman.QuadletKube, podman.QuadletContainer, opt.Quadlet)
+		}
 	case provider == ProviderKubernetes:
 		if deploymentConfig {
 			log.Fatalf("--deployment-config is an OpenShift only flag")
 		}
-		if buildRepo {
-			log.Fatalf("--build-repo is an Openshift only flag")
+		// the Kaniko Jobs clone the build contexts from the repository too
+		if buildRepo && opt.Build != kubernetes.KanikoBuild {
+			log.Fatalf("--build-repo is an Openshift only flag, or used with --build=%s", kubernetes.KanikoBuild)
 		}
-		if buildBranch {
-			log.Fatalf("--build-branch is an Openshift only flag")
+		if buildBranch && opt.Build != kubernetes.KanikoBuild {
+			log.Fatalf("--build-branch is an Openshift only flag, or used with --build=%s", kubernetes.KanikoBuild)
 		}
 		if controller == "deploymentconfig" {
 			log.Fatalf("--controller=deploymentConfig is an OpenShift only flag")
@@ -123,18 +162,161 @@ func ValidateFlags(args []string, cmd *cobra.Command, opt *kobject.ConvertOption
 		log.Fatalf("Error: chart cannot be generated when --stdout is specified")
 	}
 
+	if opt.PushChart != "" && !opt.CreateChart {
+		log.Fatalf("Error: --push-chart requires the chart generated with --chart")
+	}
+
+	if opt.Sign && opt.ToStdout {
+		log.Fatalf("Error: the output cannot be signed when --stdout is specified")
+	}
+
+	if opt.SignKey != "" && !opt.Sign {
+		log.Fatalf("Error: --sign-key is used with --sign")
+	}
+
+	switch opt.NamespaceBy {
+	case "":
+	case kubernetes.NamespaceByGroup, kubernetes.NamespaceByNetwork:
+		if opt.Provider != ProviderKubernetes {
+			log.Fatalf("Error: --namespace-by is a Kubernetes only flag")
+		}
+	default:
+		log.Fatalf("Error: --namespace-by must be %q or %q, got %q", kubernetes.NamespaceByGroup, kubernetes.NamespaceByNetwork, opt.NamespaceBy)
+	}
+
+	if len(opt.Environments) > 0 {
+		switch {
+		case opt.EnvironmentsFormat != kubernetes.EnvironmentsKustomize && opt.EnvironmentsFormat != kubernetes.EnvironmentsHelm:
+			log.Fatalf("Error: --environments-format must be %q or %q, got %q", kubernetes.EnvironmentsKustomize, kubernetes.EnvironmentsHelm, opt.EnvironmentsFormat)
+		case opt.ToStdout:
+			log.Fatalf("Error: the environments are written to a directory, they can't be printed with --stdout")
+		case transformer.Exists(opt.OutFile) && !isExistingDir(opt.OutFile):
+			log.Fatalf("Error: the environments are written to a directory, --out %s is a file", opt.OutFile)
+		case len(opt.Projects) > 0:
+			log.Fatalf("Error: --environment can't be used with --project")
+		case opt.CreateChart:
+			log.Fatalf("Error: --environment writes a chart with --environments-format=%s, not with --chart", kubernetes.EnvironmentsHelm)
+		case opt.Provider == ProviderPodman:
+			log.Fatalf("Error: --environment is not supported by the Podman provider")
+		}
+	}
+
+	for _, env := range opt.Env {
+		if key, _, found := strings.Cut(env, "="); !found || key == "" {
+			log.Fatalf("Error: invalid --env %q, the expected format is KEY=VALUE", env)
+		}
+	}
+	for _, file := range opt.EnvFiles {
+		if !transformer.Exists(file) {
+			log.Fatalf("Error: the --env-file %s doesn't exist", file)
+		}
+	}
+
+	if opt.KubernetesVersion == "" {
+		opt.KubernetesVersion = validate.DefaultKubernetesVersion
+	}
+	version, err := validate.ParseVersion(opt.KubernetesVersion)
+	if err != nil {
+		log.Fatalf("Error: --kubernetes-version: %v", err)
+	}
+	if opt.NativeSidecars && version.Less(validate.Version{Major: 1, Minor: 29}) {
+		log.Fatalf("Error: --native-sidecars requires Kubernetes 1.29 or later, got --kubernetes-version %s", version)
+	}
+
+	switch opt.DryRun {
+	case "", "none", "server":
+	default:
+		log.Fatalf("Error: --dry-run must be \"none\" or \"server\", got %q", opt.DryRun)
+	}
+
+	switch opt.OutputLayout {
+	case "", kubernetes.OutputLayoutFlat, kubernetes.OutputLayoutService:
+	default:
+		log.Fatalf("Error: --output-layout must be %q or %q, got %q", kubernetes.OutputLayoutFlat, kubernetes.OutputLayoutService, opt.OutputLayout)
+	}
+
+	switch opt.AntiAffinity {
+	case "", "preferred", "required":
+	default:
+		log.Fatalf("Error: --anti-affinity must be \"preferred\" or \"required\", got %q", opt.AntiAffinity)
+	}
+
+	switch opt.PodSecurity {
+	case "", kubernetes.PodSecurityRestricted:
+	default:
+		log.Fatalf("Error: --pod-security must be \"restricted\", got %q", opt.PodSecurity)
+	}
+
 	if opt.Replicas < 0 {
 		log.Fatalf("Error: --replicas cannot be negative")
 	}
 
-	if len(args) != 0 {
-		log.Fatal("Unknown Argument(s): ", strings.Join(args, ","))
-	}
+	// the arguments are the services to convert, as with --services
+	opt.Services = append(opt.Services, args...)
 
 	if opt.GenerateJSON && opt.GenerateYaml {
 		log.Fatalf("YAML and JSON format cannot be provided at the same time")
 	}
 
+	if opt.Summary != "" && opt.Summary != SummaryTable && opt.Summary != SummaryJSON {
+		log.Fatalf("Unknown summary format %q, possible values are: '%s' '%s'", opt.Summary, SummaryTable, SummaryJSON)
+	}
+
+	if opt.Interactive {
+		for _, file := range opt.InputFiles {
+			if file == "-" {
+				log.Fatalf("Error: --interactive reads the answers from the standard input, the compose file can't be read from it")
+			}
+		}
+	}
+
+	if opt.Namespace != "" {
+		if errs := validation.IsDNS1123Label(opt.Namespace); len(errs) > 0 {
+			log.Fatalf("Invalid namespace %q: %s", opt.Namespace, strings.Join(errs, ", "))
+		}
+	}
+
+	if opt.DetectCluster {
+		changed := func(flag string) bool { return cmd.Flags().Lookup(flag).Changed }
+		if err := DetectCluster(opt, changed); err != nil {
+			log.Fatalf("Error: --detect-cluster: %v", err)
+		}
+	}
+
+	switch opt.SecretsAs {
+	case "", kubernetes.SecretsAsSecret:
+	case kubernetes.SecretsAsSealed:
+		if opt.Reproducible {
+			log.Fatalf("--reproducible can't be used with --secrets-as=%s, the secrets are sealed with a random key", kubernetes.SecretsAsSealed)
+		}
+		if opt.SealedSecretsCert == "" {
+			log.Fatalf("--secrets-as=%s requires the certificate of the sealed-secrets controller, set it with --sealed-secrets-cert", kubernetes.SecretsAsSealed)
+		}
+		switch opt.SealedSecretsScope {
+		case "", kubernetes.SealedSecretsScopeStrict, kubernetes.SealedSecretsScopeNamespaceWide, kubernetes.SealedSecretsScopeClusterWide:
+		default:
+			log.Fatalf("Unknown sealed secrets scope %q, possible values are: '%s' '%s' '%s'", opt.SealedSecretsScope, kubernetes.SealedSecretsScopeStrict, kubernetes.SealedSecretsScopeNamespaceWide, kubernetes.SealedSecretsScopeClusterWide)
+		}
+	default:
+		log.Fatalf("Unknown secrets kind %q, possible values are: '%s' '%s'", opt.SecretsAs, kubernetes.SecretsAsSecret, kubernetes.SecretsAsSealed)
+	}
+
+	switch opt.ExposeController {
+	case "", kubernetes.IngressExposeController:
+		if opt.GatewayClassName != "" {
+			log.Fatalf("--gateway-class can only be used with --expose-controller=%s", kubernetes.GatewayAPIExposeController)
+		}
+	case kubernetes.GatewayAPIExposeController:
+		if opt.Provider == "openshift" {
+			log.Fatalf("--expose-controller=%s is not supported by the OpenShift provider, services are exposed with Routes", kubernetes.GatewayAPIExposeController)
+		}
+		if opt.GatewayClassName != "" && strings.Contains(opt.GatewayName, "/") {
+			log.Fatalf("--gateway-class generates the Gateway in the namespace of the converted objects, --gateway can't set its namespace")
+		}
+	default:
+		log.Fatalf("Unknown expose controller %q, possible values are: '%s' '%s'", opt.ExposeController, kubernetes.IngressExposeController, kubernetes.GatewayAPIExposeController)
+	}
+
 	if _, ok := kubernetes.ValidVolumeSet[opt.Volumes]; !ok {
 		validVolumesTypes := make([]string, 0)
 		for validVolumeType := range kubernetes.ValidVolumeSet {
@@ -146,6 +328,12 @@ func ValidateFlags(args []string, cmd *cobra.Command, opt *kobject.ConvertOption
 
 // ValidateComposeFile validates the compose file provided for conversion
 func ValidateComposeFile(opt *kobject.ConvertOptions) error {
+	if len(opt.Projects) > 0 {
+		if len(opt.InputFiles) > 0 {
+			return fmt.Errorf("--file and --project can't be used at the same time")
+		}
+		return nil
+	}
 	if len(opt.InputFiles) == 0 {
 		// Go through a range of "default" file names to see if tany ofthem exist in the current directory
 		for _, name := range DefaultComposeFiles {
@@ -163,9 +351,83 @@ func ValidateComposeFile(opt *kobject.ConvertOptions) error {
 	return nil
 }
 
-func validateControllers(opt *kobject.ConvertOptions) {
+// ParseProjects parses the projects given as NAME=FILE[,FILE...]
+func ParseProjects(values []string) ([]kobject.Project, error) {
+	var projects []kobject.Project
+	names := map[string]bool{}
+	for _, value := range values {
+		name, files, found := strings.Cut(value, "=")
+		if !found || files == "" {
+			return nil, fmt.Errorf("invalid project %q, the expected format is NAME=FILE[,FILE...]", value)
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid project name %q: %s", name, strings.Join(errs, ", "))
+		}
+		if names[name] {
+			return nil, fmt.Errorf("project %q is defined more than once", name)
+		}
+		names[name] = true
+		projects = append(projects, kobject.Project{Name: name, InputFiles: strings.Split(files, ",")})
+	}
+	return projects, nil
+}
+
+// ParseEnvironments parses the environments given as NAME=FILE|profile:PROFILE[,...], the override files
+// and the profiles of each environment
+func ParseEnvironments(values []string) ([]kobject.Environment, error) {
+	var environments []kobject.Environment
+	names := map[string]bool{}
+	for _, value := range values {
+		name, items, found := strings.Cut(value, "=")
+		if !found || items == "" {
+			return nil, fmt.Errorf("invalid environment %q, the expected format is NAME=FILE|profile:PROFILE[,...]", value)
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid environment name %q: %s", name, strings.Join(errs, ", "))
+		}
+		if names[name] {
+			return nil, fmt.Errorf("environment %q is defined more than once", name)
+		}
+		names[name] = true
+		environment := kobject.Environment{Name: name}
+		for _, item := range strings.Split(items, ",") {
+			if profile, ok := strings.CutPrefix(item, "profile:"); ok {
+				environment.Profiles = append(environment.Profiles, profile)
+			} else {
+				environment.InputFiles = append(environment.InputFiles, item)
+			}
+		}
+		environments = append(environments, environment)
+	}
+	return environments, nil
+}
+
+// ParseKeyValues parses the labels or annotations given as KEY=VALUE
+func ParseKeyValues(values []string, labels bool) (map[string]string, error) {
+	result := map[string]string{}
+	for _, value := range values {
+		key, val, found := strings.Cut(value, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid %q, the expected format is KEY=VALUE", value)
+		}
+		errs := validation.IsQualifiedName(key)
+		if labels {
+			errs = append(errs, validation.IsValidLabelValue(val)...)
+		}
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %q: %s", value, strings.Join(errs, ", "))
+		}
+		result[key] = val
+	}
+	return result, nil
+}
+
+// ValidateControllers sets the default controller of the provider and checks that a single kind of
+// controller is generated when the objects are printed to a single output
+func ValidateControllers(opt *kobject.ConvertOptions) error {
 	singleOutput := len(opt.OutFile) != 0 || opt.OutFile == "-" || opt.ToStdout
-	if opt.Provider == ProviderKubernetes {
+	// the podman provider plays the objects of the Kubernetes provider
+	if opt.Provider == ProviderKubernetes || opt.Provider == ProviderPodman {
 		// create deployment by default if no controller has been set
 		if !opt.CreateD && !opt.CreateDS && !opt.CreateRC && opt.Controller == "" {
 			opt.CreateD = true
@@ -182,7 +444,7 @@ func validateControllers(opt *kobject.ConvertOptions) {
 				count++
 			}
 			if count > 1 {
-				log.Fatalf("Error: only one kind of Kubernetes resource can be generated when --out or --stdout is specified")
+				return fmt.Errorf("Error: only one kind of Kubernetes resource can be generated when --out or --stdout is specified")
 			}
 		}
 	} else if opt.Provider == ProviderOpenshift {
@@ -199,38 +461,199 @@ func validateControllers(opt *kobject.ConvertOptions) {
 			// if opt.foo {count++}
 
 			if count > 1 {
-				log.Fatalf("Error: only one kind of OpenShift resource can be generated when --out or --stdout is specified")
+				return fmt.Errorf("Error: only one kind of OpenShift resource can be generated when --out or --stdout is specified")
 			}
 		}
 	}
+	return nil
 }
 
 // Convert transforms docker compose or dab file to k8s objects
 func Convert(opt kobject.ConvertOptions) ([]runtime.Object, error) {
-	validateControllers(&opt)
+	if err := ValidateControllers(&opt); err != nil {
+		log.Fatal(err)
+	}
 
-	// loader parses input from file into komposeObject.
-	l, err := loader.GetLoader(inputFormat)
+	// Record the dropped settings for the summary
+	var recorder *dropped.Recorder
+	if opt.Summary != "" {
+		recorder = dropped.Start()
+		defer recorder.Stop()
+	}
+
+	projects := opt.Projects
+	if len(projects) == 0 {
+		projects = []kobject.Project{{InputFiles: opt.InputFiles}}
+	}
+
+	var objects []runtime.Object
+	var renames []kobject.Rename
+	var summary conversionSummary
+	var printed []kobject.ConvertOptions
+	var printedObjects [][]runtime.Object
+	// each project is printed into its own directory, when printing to a directory
+	printPerProject := len(opt.Projects) > 0 && !opt.ToStdout &&
+		(opt.CreateChart || opt.OutFile == "" || strings.HasSuffix(opt.OutFile, "/") || isExistingDir(opt.OutFile))
+	for _, project := range projects {
+		projectOpt := opt
+		projectOpt.InputFiles = project.InputFiles
+		if project.Name != "" {
+			projectOpt.Namespace = project.Name
+		}
+
+		komposeObject, projectObjects, projectRenames, err := ConvertProject(project.Name, projectOpt)
+		if err != nil {
+			log.Fatalf(err.Error())
+		}
+		objects = append(objects, projectObjects...)
+		renames = append(renames, projectRenames...)
+
+		if printPerProject {
+			projectOpt.OutFile = filepath.Join(opt.OutFile, project.Name) + string(os.PathSeparator)
+			printed = append(printed, projectOpt)
+			printedObjects = append(printedObjects, projectObjects)
+		}
+		if opt.Summary != "" {
+			projectSummary := newSummary(komposeObject, projectObjects, nil)
+			for _, service := range projectSummary.Services {
+				if project.Name != "" {
+					service.Service = project.Name + "/" + service.Service
+				}
+				summary.Services = append(summary.Services, service)
+			}
+		}
+	}
+
+	if opt.RenameReport != "" {
+		if renames == nil {
+			renames = []kobject.Rename{}
+		}
+		data, err := json.MarshalIndent(renames, "", "  ")
+		if err != nil {
+			log.Fatalf("Unable to marshal rename report: %s", err)
+		}
+		if err := os.WriteFile(opt.RenameReport, append(data, '\n'), 0644); err != nil {
+			log.Fatalf("Unable to write rename report: %s", err)
+		}
+	}
+
+	// Print output, nothing is written until all the projects are converted
+	for i, projectOpt := range printed {
+		if err := printList(printedObjects[i], projectOpt); err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+	if !printPerProject {
+		if err := printList(objects, opt); err != nil {
+			log.Fatalf(err.Error())
+		}
+	}
+
+	if opt.Summary != "" {
+		summary.Warnings = recorder.Settings()
+		if summary.Warnings == nil {
+			summary.Warnings = []string{}
+		}
+		// keep stdout for the objects when they are printed there
+		out := os.Stdout
+		if opt.ToStdout {
+			out = os.Stderr
+		}
+		if err := printSummary(out, summary, opt.Summary); err != nil {
+			log.Fatalf("Unable to print the summary: %s", err)
+		}
+	}
+	return objects, nil
+}
+
+// ConvertProject loads a compose project and transforms it to the provider's objects,
+// it also returns the resources renamed to be valid Kubernetes names. With --strict, it
+// fails when a setting of the compose files is dropped.
+func ConvertProject(name string, opt kobject.ConvertOptions) (kobject.KomposeObject, []runtime.Object, []kobject.Rename, error) {
+	if !opt.Strict {
+		return convertProject(name, opt)
+	}
+
+	recorder := dropped.Start()
+	defer recorder.Stop()
+	komposeObject, objects, renames, err := convertProject(name, opt)
+	if err == nil {
+		err = strictError(recorder.Settings())
+	}
 	if err != nil {
-		log.Fatal(err)
+		return kobject.KomposeObject{}, nil, nil, err
+	}
+	return komposeObject, objects, renames, nil
+}
+
+// composeFiles returns the directory of the compose files and their local paths, the remote compose
+// files are loaded from their local copy
+func composeFiles(opt kobject.ConvertOptions) (string, []string, error) {
+	// Get the directory of the compose file
+	workDir, err := transformer.GetWorkingDir(opt)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Unable to get compose file directory")
 	}
 
-	komposeObject := kobject.KomposeObject{
-		ServiceConfigs: make(map[string]kobject.ServiceConfig),
+	files := make([]string, 0, len(opt.InputFiles))
+	for _, file := range opt.InputFiles {
+		local, err := remote.Fetch(file)
+		if err != nil {
+			return "", nil, err
+		}
+		files = append(files, local)
 	}
-	komposeObject, err = l.LoadFile(opt.InputFiles, opt.Profiles, opt.NoInterpolate)
+	return workDir, files, nil
+}
+
+// registerSecrets registers the contents of the secrets and the values of the env files to be masked in
+// the log messages of the conversion, the returned function forgets them. The env files which can't be read
+// are reported by the conversion.
+func registerSecrets(komposeObject kobject.KomposeObject, workDir string) (release func()) {
+	var values []string
+	for _, secret := range komposeObject.Secrets {
+		values = append(values, secret.Content)
+	}
+	noLookup := func(string) (string, bool) { return "", false }
+	for _, service := range komposeObject.ServiceConfigs {
+		for _, file := range service.EnvFile {
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(workDir, file)
+			}
+			envs, err := kubernetes.LoadEnvFiles(file, noLookup)
+			if err != nil {
+				continue
+			}
+			for _, value := range envs {
+				values = append(values, value)
+			}
+		}
+	}
+	return redact.Register(values...)
+}
+
+// convertProject loads a compose project and transforms it to the provider's objects
+func convertProject(name string, opt kobject.ConvertOptions) (kobject.KomposeObject, []runtime.Object, []kobject.Rename, error) {
+	// loader parses input from file into komposeObject.
+	l, err := loader.GetLoader(inputFormat, opt)
 	if err != nil {
-		log.Fatalf(err.Error())
+		return kobject.KomposeObject{}, nil, nil, err
 	}
 
-	komposeObject.Namespace = opt.Namespace
+	workDir, files, err := composeFiles(opt)
+	if err != nil {
+		return kobject.KomposeObject{}, nil, nil, err
+	}
 
-	// Get the directory of the compose file
-	workDir, err := transformer.GetComposeFileDir(opt.InputFiles)
+	komposeObject, err := l.LoadFile(name, files, workDir, opt.Profiles, opt.Services, opt.NoInterpolate, opt.EnvFiles, opt.Env)
 	if err != nil {
-		log.Fatalf("Unable to get compose file directory: %s", err)
+		return kobject.KomposeObject{}, nil, nil, err
 	}
 
+	release := registerSecrets(komposeObject, workDir)
+	defer release()
+	komposeObject.Namespace = opt.Namespace
+
 	// convert env_file from absolute to relative path
 	for _, service := range komposeObject.ServiceConfigs {
 		if len(service.EnvFile) <= 0 {
@@ -243,42 +666,116 @@ func Convert(opt kobject.ConvertOptions) ([]runtime.Object, error) {
 
 			relPath, err := filepath.Rel(workDir, envFile)
 			if err != nil {
-				log.Fatalf(err.Error())
+				return kobject.KomposeObject{}, nil, nil, err
 			}
 
 			service.EnvFile[i] = filepath.ToSlash(relPath)
 		}
 	}
 
+	if opt.InspectImages {
+		inspectImages(komposeObject)
+	}
+
+	if opt.ResolveImageDigests {
+		if err := resolveImageDigests(komposeObject); err != nil {
+			return kobject.KomposeObject{}, nil, nil, err
+		}
+	}
+
+	// Validate the whole project before generating anything
+	if err := kubernetes.CheckConflicts(komposeObject, opt); err != nil {
+		return kobject.KomposeObject{}, nil, nil, err
+	}
+
+	// Report the resources renamed to be valid Kubernetes names
+	renames, err := kubernetes.GetRenames(komposeObject, opt)
+	if err != nil {
+		return kobject.KomposeObject{}, nil, nil, err
+	}
+	for i, rename := range renames {
+		if rename.Kind == "ConfigMap" {
+			log.Warnf("ConfigMap name of env_file %q has been truncated to %q", rename.Original, rename.Name)
+		}
+		renames[i].Project = name
+	}
+
 	// Get a transformer that maps komposeObject to provider's primitives
-	t := getTransformer(opt)
+	t, err := transformer.Get(opt.Provider, opt)
+	if err != nil {
+		return kobject.KomposeObject{}, nil, nil, err
+	}
 
 	// Do the transformation
 	objects, err := t.Transform(komposeObject, opt)
-
 	if err != nil {
-		log.Fatalf(err.Error())
+		return kobject.KomposeObject{}, nil, nil, err
 	}
 
-	// Print output
-	err = kubernetes.PrintList(objects, opt)
-	if err != nil {
-		log.Fatalf(err.Error())
+	if err := kubernetes.AddLabelsAndAnnotations(objects, opt); err != nil {
+		return kobject.KomposeObject{}, nil, nil, err
 	}
-	return objects, err
+
+	if name != "" {
+		for _, object := range objects {
+			if accessor, err := meta.Accessor(object); err == nil {
+				labels := accessor.GetLabels()
+				if labels == nil {
+					labels = map[string]string{}
+				}
+				labels[ProjectLabel] = name
+				accessor.SetLabels(labels)
+			}
+		}
+	}
+
+	if opt.Reproducible {
+		if err := kubernetes.StripNonReproducible(objects, opt); err != nil {
+			return kobject.KomposeObject{}, nil, nil, err
+		}
+	}
+
+	if err := kubernetes.ApplyPodSecurity(objects, opt); err != nil {
+		return kobject.KomposeObject{}, nil, nil, err
+	}
+
+	if opt.StandardLabels {
+		if komposeObject.ProjectName == "" {
+			komposeObject.ProjectName = name
+		}
+		if err := kubernetes.AddStandardLabels(objects, komposeObject, opt); err != nil {
+			return kobject.KomposeObject{}, nil, nil, err
+		}
+	}
+
+	if opt.Validate {
+		if err := validate.Objects(objects, opt.KubernetesVersion); err != nil {
+			return kobject.KomposeObject{}, nil, nil, err
+		}
+	}
+
+	if opt.DryRun == "server" {
+		if err := validate.Server(objects); err != nil {
+			return kobject.KomposeObject{}, nil, nil, err
+		}
+	}
+	return komposeObject, objects, renames, nil
 }
 
-// Convenience method to return the appropriate Transformer based on
-// what provider we are using.
-func getTransformer(opt kobject.ConvertOptions) transformer.Transformer {
-	var t transformer.Transformer
-	if opt.Provider == DefaultProvider {
-		// Create/Init new Kubernetes object with CLI opts
-		t = &kubernetes.Kubernetes{Opt: opt}
-	} else {
-		// Create/Init new OpenShift object that is initialized with a newly
-		// created Kubernetes object. Openshift inherits from Kubernetes
-		t = &openshift.OpenShift{Kubernetes: kubernetes.Kubernetes{Opt: opt}}
-	}
-	return t
+func isExistingDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// printList prints the objects with the printer of the provider, as Kubernetes manifests unless
+// the provider prints its objects itself
+func printList(objects []runtime.Object, opt kobject.ConvertOptions) error {
+	t, err := transformer.Get(opt.Provider, opt)
+	if err != nil {
+		return err
+	}
+	if printer, ok := t.(transformer.Printer); ok {
+		return printer.PrintList(objects, opt)
+	}
+	return kubernetes.PrintList(objects, opt)
 }
@@ -0,0 +1,122 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/utils/redact"
+)
+
+func TestParseProjects(t *testing.T) {
+	projects, err := ParseProjects([]string{"shop=shop/compose.yaml", "blog=blog/compose.yaml,blog/compose.prod.yaml"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []kobject.Project{
+		{Name: "shop", InputFiles: []string{"shop/compose.yaml"}},
+		{Name: "blog", InputFiles: []string{"blog/compose.yaml", "blog/compose.prod.yaml"}},
+	}
+	if !reflect.DeepEqual(projects, expected) {
+		t.Errorf("Expected %v, got %v", expected, projects)
+	}
+
+	for _, values := range [][]string{
+		{"shop"},
+		{"shop="},
+		{"Shop_1=compose.yaml"},
+		{"shop=compose.yaml", "shop=other.yaml"},
+	} {
+		if _, err := ParseProjects(values); err == nil {
+			t.Errorf("Expected an error for %v", values)
+		}
+	}
+}
+
+func TestParseEnvironments(t *testing.T) {
+	environments, err := ParseEnvironments([]string{"dev=compose.dev.yaml", "prod=compose.prod.yaml,profile:monitoring"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []kobject.Environment{
+		{Name: "dev", InputFiles: []string{"compose.dev.yaml"}},
+		{Name: "prod", InputFiles: []string{"compose.prod.yaml"}, Profiles: []string{"monitoring"}},
+	}
+	if !reflect.DeepEqual(environments, expected) {
+		t.Errorf("Expected %v, got %v", expected, environments)
+	}
+
+	for _, values := range [][]string{
+		{"dev"},
+		{"dev="},
+		{"Dev_1=compose.yaml"},
+		{"dev=compose.yaml", "dev=profile:debug"},
+	} {
+		if _, err := ParseEnvironments(values); err == nil {
+			t.Errorf("Expected an error for %v", values)
+		}
+	}
+}
+
+func TestParseKeyValues(t *testing.T) {
+	values, err := ParseKeyValues([]string{"team=frontend", "example.com/cost-center=42", "empty="}, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{"team": "frontend", "example.com/cost-center": "42", "empty": ""}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+
+	if _, err := ParseKeyValues([]string{"policy=a, b"}, false); err != nil {
+		t.Errorf("Unexpected error for an annotation: %v", err)
+	}
+	for _, value := range []string{"team", "bad key=value", "policy=a, b"} {
+		if _, err := ParseKeyValues([]string{value}, true); err == nil {
+			t.Errorf("Expected an error for label %q", value)
+		}
+	}
+}
+
+func TestRegisterSecrets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "db.env"), []byte("DB_PASSWORD=hunter22\nDB_HOST=postgres\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"web": {
+			EnvFile:     []string{"db.env"},
+			Environment: []kobject.EnvVar{{Name: "MODE", Value: "production"}},
+		}},
+		Secrets: types.Secrets{"token": types.SecretConfig{Content: "s3cr3t-token"}},
+	}
+
+	release := registerSecrets(komposeObject, dir)
+	message := "connecting to postgres with hunter22 and s3cr3t-token in production"
+	if got, expected := redact.String(message), "connecting to ****** with ****** and ****** in production"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+	release()
+	if got := redact.String(message); got != message {
+		t.Errorf("Expected the values to be forgotten after the conversion, got %q", got)
+	}
+}
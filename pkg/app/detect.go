@@ -0,0 +1,67 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/kubernetes/kompose/pkg/utils/kubectl"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// API group/versions of the objects generated for the optional APIs of the cluster
+const (
+	GatewayAPIVersion    = "gateway.networking.k8s.io/v1"
+	SealedSecretsVersion = "bitnami.com/v1alpha1"
+	RouteAPIVersion      = "route.openshift.io/v1"
+)
+
+// DetectCluster queries the API groups served by the cluster of the current kubectl context and
+// adapts the generators to them, changed tells whether a flag was set on the command line
+func DetectCluster(opt *kobject.ConvertOptions, changed func(flag string) bool) error {
+	versions, err := kubectl.APIVersions()
+	if err != nil {
+		return errors.Wrap(err, "unable to list the API versions of the cluster")
+	}
+	return applyClusterCapabilities(opt, versions, changed)
+}
+
+// applyClusterCapabilities enables the generators whose API is served when their flag wasn't set,
+// the generators chosen on the command line are kept and fail when their API isn't served
+func applyClusterCapabilities(opt *kobject.ConvertOptions, versions map[string]bool, changed func(flag string) bool) error {
+	if opt.Provider == ProviderOpenshift && !versions[RouteAPIVersion] {
+		return errors.Errorf("the cluster doesn't serve the Routes (%s) of the OpenShift provider, convert with --provider %s", RouteAPIVersion, ProviderKubernetes)
+	}
+
+	switch {
+	case opt.ExposeController == kubernetes.GatewayAPIExposeController && !versions[GatewayAPIVersion]:
+		return errors.Errorf("the cluster doesn't serve the Gateway API (%s), install its CRDs or expose the services with --expose-controller=%s", GatewayAPIVersion, kubernetes.IngressExposeController)
+	case !changed("expose-controller") && opt.Provider == ProviderKubernetes && versions[GatewayAPIVersion]:
+		log.Infof("The cluster serves the Gateway API (%s), exposing the services with HTTPRoutes", GatewayAPIVersion)
+		opt.ExposeController = kubernetes.GatewayAPIExposeController
+	}
+
+	switch {
+	case opt.SecretsAs == kubernetes.SecretsAsSealed && !versions[SealedSecretsVersion]:
+		return errors.Errorf("the cluster doesn't serve the SealedSecrets (%s), install the sealed-secrets controller or convert the secrets with --secrets-as=%s", SealedSecretsVersion, kubernetes.SecretsAsSecret)
+	case !changed("secrets-as") && opt.SealedSecretsCert != "" && versions[SealedSecretsVersion]:
+		log.Infof("The cluster serves the SealedSecrets (%s), converting the secrets to SealedSecrets", SealedSecretsVersion)
+		opt.SecretsAs = kubernetes.SecretsAsSealed
+	}
+	return nil
+}
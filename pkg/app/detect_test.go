@@ -0,0 +1,133 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+func TestDetectCluster(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\nprintf 'apps/v1\\nbitnami.com/v1alpha1\\ngateway.networking.k8s.io/v1\\nv1\\n'\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	opt := kobject.ConvertOptions{Provider: ProviderKubernetes, ExposeController: "ingress", SecretsAs: "secret", SealedSecretsCert: "cert.pem"}
+	if err := DetectCluster(&opt, func(string) bool { return false }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opt.ExposeController != "gateway-api" || opt.SecretsAs != "sealed" {
+		t.Errorf("Expected the Gateway API and the SealedSecrets to be enabled, got %q and %q", opt.ExposeController, opt.SecretsAs)
+	}
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(args)) != "api-versions" {
+		t.Errorf("Expected the API versions to be listed, got %q", args)
+	}
+}
+
+func TestApplyClusterCapabilities(t *testing.T) {
+	all := map[string]bool{GatewayAPIVersion: true, SealedSecretsVersion: true, RouteAPIVersion: true}
+	none := map[string]bool{"v1": true}
+
+	testCases := map[string]struct {
+		opt              kobject.ConvertOptions
+		versions         map[string]bool
+		changed          string
+		exposeController string
+		secretsAs        string
+		err              string
+	}{
+		"Nothing served": {
+			opt:              kobject.ConvertOptions{Provider: ProviderKubernetes, ExposeController: "ingress", SecretsAs: "secret", SealedSecretsCert: "cert.pem"},
+			versions:         none,
+			exposeController: "ingress",
+			secretsAs:        "secret",
+		},
+		"Everything served": {
+			opt:              kobject.ConvertOptions{Provider: ProviderKubernetes, ExposeController: "ingress", SecretsAs: "secret", SealedSecretsCert: "cert.pem"},
+			versions:         all,
+			exposeController: "gateway-api",
+			secretsAs:        "sealed",
+		},
+		"Flags set on the command line": {
+			opt:              kobject.ConvertOptions{Provider: ProviderKubernetes, ExposeController: "ingress", SecretsAs: "secret", SealedSecretsCert: "cert.pem"},
+			versions:         all,
+			changed:          "expose-controller secrets-as",
+			exposeController: "ingress",
+			secretsAs:        "secret",
+		},
+		"SealedSecrets without certificate": {
+			opt:              kobject.ConvertOptions{Provider: ProviderKubernetes, ExposeController: "ingress", SecretsAs: "secret"},
+			versions:         all,
+			changed:          "expose-controller",
+			exposeController: "ingress",
+			secretsAs:        "secret",
+		},
+		"Routes of the OpenShift provider": {
+			opt:              kobject.ConvertOptions{Provider: ProviderOpenshift, ExposeController: "ingress", SecretsAs: "secret"},
+			versions:         all,
+			exposeController: "ingress",
+			secretsAs:        "secret",
+		},
+		"Gateway API not served": {
+			opt:      kobject.ConvertOptions{Provider: ProviderKubernetes, ExposeController: "gateway-api"},
+			versions: none,
+			err:      "doesn't serve the Gateway API",
+		},
+		"SealedSecrets not served": {
+			opt:      kobject.ConvertOptions{Provider: ProviderKubernetes, SecretsAs: "sealed", SealedSecretsCert: "cert.pem"},
+			versions: none,
+			err:      "doesn't serve the SealedSecrets",
+		},
+		"Routes not served": {
+			opt:      kobject.ConvertOptions{Provider: ProviderOpenshift},
+			versions: map[string]bool{GatewayAPIVersion: true},
+			err:      "doesn't serve the Routes",
+		},
+	}
+
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		opt := test.opt
+		changed := func(flag string) bool { return strings.Contains(test.changed, flag) }
+		err := applyClusterCapabilities(&opt, test.versions, changed)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Expected the error %q, got %v", test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+			continue
+		}
+		if opt.ExposeController != test.exposeController || opt.SecretsAs != test.secretsAs {
+			t.Errorf("Expected %q and %q, got %q and %q", test.exposeController, test.secretsAs, opt.ExposeController, opt.SecretsAs)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/utils/kubectl"
+	"github.com/pkg/errors"
+)
+
+// Diff converts the compose files and writes to out the differences between the generated objects and the
+// live objects of the cluster of the current kubectl context, as kubectl diff. It tells whether they differ.
+func Diff(opt kobject.ConvertOptions, out io.Writer) (bool, error) {
+	if err := ValidateControllers(&opt); err != nil {
+		return false, err
+	}
+	_, objects, _, err := ConvertProject("", opt)
+	if err != nil {
+		return false, err
+	}
+
+	list, err := kubectl.List(objects)
+	if err != nil {
+		return false, err
+	}
+	args := []string{"diff", "-f", "-"}
+	if opt.Namespace != "" {
+		args = append(args, "--namespace", opt.Namespace)
+	}
+	cmd, err := kubectl.Command(args...)
+	if err != nil {
+		return false, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(list)
+	cmd.Stdout = out
+	cmd.Stderr = &stderr
+
+	// kubectl diff exits with 1 when the objects differ, and above on errors
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Errorf("unable to diff the objects with the cluster:\n  %s", strings.Join(kubectl.Errors(stderr.String()), "\n  "))
+	}
+	return false, nil
+}
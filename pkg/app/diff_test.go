@@ -0,0 +1,66 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	compose := filepath.Join(dir, "compose.yaml")
+	if err := os.WriteFile(compose, []byte("services:\n  web:\n    image: nginx\n    ports:\n      - 80:80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the fake kubectl saves its arguments and reports a difference
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\necho '+  replicas: 1'\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	opt := kobject.ConvertOptions{InputFiles: []string{compose}, Provider: "kubernetes", Namespace: "shop", YAMLIndent: 2}
+	var out bytes.Buffer
+	differ, err := Diff(opt, &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !differ || !strings.Contains(out.String(), "replicas: 1") {
+		t.Errorf("Expected the differences of kubectl diff, got %v %q", differ, out.String())
+	}
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(args)) != "diff -f - --namespace shop" {
+		t.Errorf("Expected kubectl diff in the namespace, got %q", args)
+	}
+
+	script = "#!/bin/sh\necho 'error: the server doesn'\"'\"'t have a resource type' >&2\nexit 2\n"
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Diff(opt, &out); err == nil || !strings.Contains(err.Error(), "resource type") {
+		t.Errorf("Expected the error of kubectl, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/pkg/errors"
+)
+
+// ConvertEnvironments converts the compose files of the base, then each environment with its override
+// files and its profiles, and writes the base objects and the layout of the environments
+func ConvertEnvironments(opt kobject.ConvertOptions) error {
+	if err := ValidateControllers(&opt); err != nil {
+		return err
+	}
+	_, base, _, err := ConvertProject("", opt)
+	if err != nil {
+		return err
+	}
+
+	environments := make([]kubernetes.EnvironmentObjects, 0, len(opt.Environments))
+	for _, environment := range opt.Environments {
+		environmentOpt := opt
+		environmentOpt.InputFiles = append(append([]string{}, opt.InputFiles...), environment.InputFiles...)
+		environmentOpt.Profiles = append(append([]string{}, opt.Profiles...), environment.Profiles...)
+		_, objects, _, err := ConvertProject("", environmentOpt)
+		if err != nil {
+			return errors.Wrapf(err, "unable to convert the environment %s", environment.Name)
+		}
+		environments = append(environments, kubernetes.EnvironmentObjects{Name: environment.Name, Objects: objects})
+	}
+	return kubernetes.PrintEnvironments(base, environments, opt)
+}
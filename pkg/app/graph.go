@@ -0,0 +1,222 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/pkg/errors"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Formats of the conversion graph
+const (
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+)
+
+// graphNode is a compose service or a generated object
+type graphNode struct {
+	id      string
+	label   string
+	service bool
+	// volume tells the claims, the ConfigMaps and the Secrets, mounted by the workloads
+	volume bool
+}
+
+// graphEdge links a service to its objects (generates), a workload to its volumes (mounts) or
+// a service to the services it depends on (depends_on)
+type graphEdge struct {
+	from, to string
+	kind     string
+}
+
+// conversionGraph is the graph of the services, their objects, the volumes and the dependencies
+type conversionGraph struct {
+	nodes []graphNode
+	edges []graphEdge
+}
+
+// Graph converts the compose files and writes the graph of the services, of the objects generated
+// for them, of the volumes their workloads mount and of their depends_on dependencies, in the format
+func Graph(opt kobject.ConvertOptions, format string, out io.Writer) error {
+	if err := ValidateGraphFormat(format); err != nil {
+		return err
+	}
+	if err := ValidateControllers(&opt); err != nil {
+		return err
+	}
+	komposeObject, objects, _, err := ConvertProject("", opt)
+	if err != nil {
+		return err
+	}
+	graph := buildGraph(komposeObject, objects, opt)
+	if format == GraphMermaid {
+		return graph.writeMermaid(out)
+	}
+	return graph.writeDOT(out)
+}
+
+// ValidateGraphFormat checks the format given to kompose graph --format
+func ValidateGraphFormat(format string) error {
+	if format != GraphDOT && format != GraphMermaid {
+		return errors.Errorf("invalid graph format %q, expected %q or %q", format, GraphDOT, GraphMermaid)
+	}
+	return nil
+}
+
+// buildGraph returns the graph of the services and of the objects, the objects belong to the service
+// of their io.kompose.service label
+func buildGraph(komposeObject kobject.KomposeObject, objects []runtime.Object, opt kobject.ConvertOptions) conversionGraph {
+	var graph conversionGraph
+	names := map[string]string{}
+	for _, key := range kubernetes.SortedKeys(komposeObject.ServiceConfigs) {
+		name := komposeObject.ServiceConfigs[key].Name
+		if name == "" {
+			name = key
+		}
+		names[key] = name
+		graph.nodes = append(graph.nodes, graphNode{id: "service/" + name, label: name, service: true})
+	}
+	services := map[string]bool{}
+	for _, name := range names {
+		services[name] = true
+	}
+
+	k := kubernetes.Kubernetes{Opt: opt}
+	nodes := map[string]bool{}
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		id := kind + "/" + accessor.GetName()
+		if nodes[id] {
+			continue
+		}
+		nodes[id] = true
+		volume := kind == "PersistentVolumeClaim" || kind == "ConfigMap" || kind == "Secret"
+		graph.nodes = append(graph.nodes, graphNode{id: id, label: kind + " " + accessor.GetName(), volume: volume})
+		if service := accessor.GetLabels()[transformer.Selector]; services[service] {
+			graph.edges = append(graph.edges, graphEdge{from: "service/" + service, to: id, kind: "generates"})
+		}
+
+		_ = k.UpdateController(obj, func(template *api.PodTemplateSpec) error {
+			for _, mounted := range mountedObjects(template.Spec) {
+				graph.edges = append(graph.edges, graphEdge{from: id, to: mounted, kind: "mounts"})
+			}
+			return nil
+		}, func(*metav1.ObjectMeta) {})
+	}
+
+	for _, key := range kubernetes.SortedKeys(komposeObject.ServiceConfigs) {
+		dependencies := make([]string, 0, len(komposeObject.ServiceConfigs[key].DependsOn))
+		for dependency := range komposeObject.ServiceConfigs[key].DependsOn {
+			if name, ok := names[dependency]; ok {
+				dependencies = append(dependencies, name)
+			}
+		}
+		sort.Strings(dependencies)
+		for _, dependency := range dependencies {
+			graph.edges = append(graph.edges, graphEdge{from: "service/" + names[key], to: "service/" + dependency, kind: "depends_on"})
+		}
+	}
+	return graph
+}
+
+// mountedObjects returns the claims, ConfigMaps and Secrets mounted by the pod spec, as KIND/NAME
+func mountedObjects(spec api.PodSpec) []string {
+	var mounted []string
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			mounted = append(mounted, "PersistentVolumeClaim/"+volume.PersistentVolumeClaim.ClaimName)
+		case volume.ConfigMap != nil:
+			mounted = append(mounted, "ConfigMap/"+volume.ConfigMap.Name)
+		case volume.Secret != nil:
+			mounted = append(mounted, "Secret/"+volume.Secret.SecretName)
+		}
+	}
+	return mounted
+}
+
+// writeDOT writes the graph in the DOT language of Graphviz
+func (g conversionGraph) writeDOT(out io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph kompose {\n  rankdir=LR;\n")
+	for _, node := range g.nodes {
+		shape := "box"
+		switch {
+		case node.service:
+			shape = "box, style=bold"
+		case node.volume:
+			shape = "cylinder"
+		}
+		fmt.Fprintf(&b, "  %q [label=%q, shape=%s];\n", node.id, node.label, shape)
+	}
+	for _, edge := range g.edges {
+		style := ""
+		if edge.kind == "depends_on" {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q%s];\n", edge.from, edge.to, edge.kind, style)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// mermaidID replaces the characters not allowed in the identifiers of the Mermaid nodes
+var mermaidID = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// writeMermaid writes the graph as a Mermaid flowchart
+func (g conversionGraph) writeMermaid(out io.Writer) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, node := range g.nodes {
+		id := mermaidID.ReplaceAllString(node.id, "_")
+		switch {
+		case node.service:
+			fmt.Fprintf(&b, "  %s([%q])\n", id, node.label)
+		case node.volume:
+			fmt.Fprintf(&b, "  %s[(%q)]\n", id, node.label)
+		default:
+			fmt.Fprintf(&b, "  %s[%q]\n", id, node.label)
+		}
+	}
+	for _, edge := range g.edges {
+		from, to := mermaidID.ReplaceAllString(edge.from, "_"), mermaidID.ReplaceAllString(edge.to, "_")
+		if edge.kind == "depends_on" {
+			fmt.Fprintf(&b, "  %s -. %s .-> %s\n", from, edge.kind, to)
+		} else {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", from, edge.kind, to)
+		}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newGraph() conversionGraph {
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web": {Name: "web", DependsOn: map[string]string{"db": "service_started"}},
+			"db":  {Name: "db"},
+		},
+	}
+	labels := func(name string) map[string]string { return map[string]string{"io.kompose.service": name} }
+	objects := []runtime.Object{
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Labels: labels("db")},
+			Spec: appsv1.DeploymentSpec{
+				Template: api.PodTemplateSpec{
+					Spec: api.PodSpec{
+						Volumes: []api.Volume{{Name: "data", VolumeSource: api.VolumeSource{PersistentVolumeClaim: &api.PersistentVolumeClaimVolumeSource{ClaimName: "db-data"}}}},
+					},
+				},
+			},
+		},
+		&api.PersistentVolumeClaim{
+			TypeMeta:   metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "db-data", Labels: labels("db-data")},
+		},
+		&api.Service{
+			TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: labels("web")},
+		},
+	}
+	return buildGraph(komposeObject, objects, kobject.ConvertOptions{})
+}
+
+func TestGraphDOT(t *testing.T) {
+	var out bytes.Buffer
+	if err := newGraph().writeDOT(&out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `digraph kompose {
+  rankdir=LR;
+  "service/db" [label="db", shape=box, style=bold];
+  "service/web" [label="web", shape=box, style=bold];
+  "Deployment/db" [label="Deployment db", shape=box];
+  "PersistentVolumeClaim/db-data" [label="PersistentVolumeClaim db-data", shape=cylinder];
+  "Service/web" [label="Service web", shape=box];
+  "service/db" -> "Deployment/db" [label="generates"];
+  "Deployment/db" -> "PersistentVolumeClaim/db-data" [label="mounts"];
+  "service/web" -> "Service/web" [label="generates"];
+  "service/web" -> "service/db" [label="depends_on", style=dashed];
+}
+`
+	if out.String() != expected {
+		t.Errorf("Expected the graph:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestGraphMermaid(t *testing.T) {
+	var out bytes.Buffer
+	if err := newGraph().writeMermaid(&out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"flowchart LR\n",
+		`  service_db(["db"])`,
+		`  PersistentVolumeClaim_db_data[("PersistentVolumeClaim db-data")]`,
+		"  Deployment_db -->|mounts| PersistentVolumeClaim_db_data\n",
+		"  service_web -. depends_on .-> service_db\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the graph:\n%s", expected, out.String())
+		}
+	}
+
+	if err := ValidateGraphFormat("svg"); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/kubernetes/kompose/pkg/utils/docker"
+	"github.com/kubernetes/kompose/pkg/utils/registry"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// inspectImages completes the services without ports or user with the EXPOSE and USER
+// instructions of their image, read from the registry. The entrypoint of the image is kept
+// by the containers, it only shows up in the debug logs.
+func inspectImages(komposeObject kobject.KomposeObject) {
+	for _, name := range kubernetes.SortedKeys(komposeObject.ServiceConfigs) {
+		service := komposeObject.ServiceConfigs[name]
+		if service.Image == "" || (len(service.Port) > 0 && service.User != "") {
+			continue
+		}
+
+		config, err := registry.Inspect(service.Image)
+		if err != nil {
+			log.Warnf("Unable to inspect the image of service %s: %s", name, err)
+			continue
+		}
+		log.Debugf("Image %s of service %s runs %q %q", service.Image, name, config.Entrypoint, config.Cmd)
+
+		if len(service.Port) == 0 {
+			service.Port = exposedPorts(config.ExposedPorts)
+		}
+		if service.User == "" && numericUser(config.User) {
+			service.User = config.User
+		}
+		komposeObject.ServiceConfigs[name] = service
+	}
+}
+
+// resolveImageDigests references the images of the services by the digest of their tag in the registry,
+// as repository@sha256:..., so that the manifests always deploy the same images. The images built by
+// kompose are pinned when they are pushed, with --push-image-digest.
+func resolveImageDigests(komposeObject kobject.KomposeObject) error {
+	resolved := map[string]string{}
+	for _, name := range kubernetes.SortedKeys(komposeObject.ServiceConfigs) {
+		service := komposeObject.ServiceConfigs[name]
+		if service.Image == "" || strings.Contains(service.Image, "@") {
+			continue
+		}
+		if service.Build != "" {
+			log.Infof("Keeping the image %s of service %s which is built, use --push-image-digest to pin it", service.Image, name)
+			continue
+		}
+
+		reference, ok := resolved[service.Image]
+		if !ok {
+			image, err := docker.ParseImage(service.Image, "")
+			if err != nil {
+				return errors.Wrapf(err, "invalid image %s of service %s", service.Image, name)
+			}
+			digest, err := registry.Digest(service.Image)
+			if err != nil {
+				return err
+			}
+			reference = image.Repository + "@" + digest
+			resolved[service.Image] = reference
+		}
+		log.Debugf("Image %s of service %s resolved to %s", service.Image, name, reference)
+		service.Image = reference
+		komposeObject.ServiceConfigs[name] = service
+	}
+	return nil
+}
+
+// exposedPorts converts the ports of the EXPOSE instructions, as PORT/PROTOCOL, to the ports of a service
+func exposedPorts(exposed []string) []kobject.Ports {
+	var ports []kobject.Ports
+	for _, value := range exposed {
+		port, protocol, _ := strings.Cut(value, "/")
+		containerPort, err := strconv.ParseInt(port, 10, 32)
+		if err != nil {
+			log.Warnf("Ignoring the exposed port %s of the image, only single ports are supported", value)
+			continue
+		}
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		ports = append(ports, kobject.Ports{
+			ContainerPort: int32(containerPort),
+			Protocol:      strings.ToUpper(protocol),
+		})
+	}
+	return ports
+}
+
+// numericUser returns whether the user of the image is a UID or UID:GID, the names of the users can't be
+// resolved without the image
+func numericUser(user string) bool {
+	if user == "" {
+		return false
+	}
+	for _, part := range strings.Split(user, ":") {
+		if _, err := strconv.ParseInt(part, 10, 64); err != nil {
+			return false
+		}
+	}
+	return len(strings.Split(user, ":")) <= 2
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+func TestInspectImages(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho '{\"config\":{\"User\":\"1000\",\"ExposedPorts\":{\"8080/tcp\":{},\"9000-9001/tcp\":{}}}}'\n"
+	if err := os.WriteFile(filepath.Join(dir, "skopeo"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web":    {Image: "example.com/web:1.0"},
+			"api":    {Image: "example.com/api:1.0", Port: []kobject.Ports{{ContainerPort: 80, Protocol: "TCP"}}, User: "0"},
+			"worker": {Image: "example.com/worker:1.0", User: "2000:2000"},
+		},
+	}
+	inspectImages(komposeObject)
+
+	web := komposeObject.ServiceConfigs["web"]
+	if expected := []kobject.Ports{{ContainerPort: 8080, Protocol: "TCP"}}; !reflect.DeepEqual(web.Port, expected) {
+		t.Errorf("Expected the exposed ports %+v, got %+v", expected, web.Port)
+	}
+	if web.User != "1000" {
+		t.Errorf("Expected the user of the image, got %q", web.User)
+	}
+
+	api := komposeObject.ServiceConfigs["api"]
+	if len(api.Port) != 1 || api.Port[0].ContainerPort != 80 || api.User != "0" {
+		t.Errorf("Expected the ports and the user of the compose file to be kept, got %+v and %q", api.Port, api.User)
+	}
+
+	worker := komposeObject.ServiceConfigs["worker"]
+	if worker.User != "2000:2000" || len(worker.Port) != 1 {
+		t.Errorf("Expected the user to be kept and the ports to be exposed, got %q and %+v", worker.User, worker.Port)
+	}
+}
+
+func TestNumericUser(t *testing.T) {
+	for user, expected := range map[string]bool{
+		"":           false,
+		"1000":       true,
+		"1000:1000":  true,
+		"nginx":      false,
+		"1000:nginx": false,
+		"1:2:3":      false,
+	} {
+		if numericUser(user) != expected {
+			t.Errorf("Expected %q to be numeric: %v", user, expected)
+		}
+	}
+}
+
+func TestResolveImageDigests(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" >> " + filepath.Join(dir, "args") + "\necho sha256:0123456789abcdef\n"
+	if err := os.WriteFile(filepath.Join(dir, "skopeo"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web":    {Image: "registry.example.com/team/web:1.0"},
+			"worker": {Image: "registry.example.com/team/web:1.0"},
+			"db":     {Image: "registry.example.com/db@sha256:fedcba9876543210"},
+			"api":    {Image: "registry.example.com/team/api:dev", Build: "./api"},
+		},
+	}
+	if err := resolveImageDigests(komposeObject); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for name, expected := range map[string]string{
+		"web":    "registry.example.com/team/web@sha256:0123456789abcdef",
+		"worker": "registry.example.com/team/web@sha256:0123456789abcdef",
+		"db":     "registry.example.com/db@sha256:fedcba9876543210",
+		"api":    "registry.example.com/team/api:dev",
+	} {
+		if image := komposeObject.ServiceConfigs[name].Image; image != expected {
+			t.Errorf("Expected the image of service %s to be %s, got %s", name, expected, image)
+		}
+	}
+
+	calls, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(calls)), "\n"); len(lines) != 1 {
+		t.Errorf("Expected the shared image to be resolved once, got %q", lines)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// interactiveOptions are the options set in the interactive preview, with their possible values
+var interactiveOptions = map[string][]string{
+	"controller": {kubernetes.DeploymentController, kubernetes.DaemonSetController, kubernetes.StatefulStateController},
+	"expose":     {kubernetes.IngressExposeController, kubernetes.GatewayAPIExposeController},
+	"volumes":    {"persistentVolumeClaim", "emptyDir", "hostPath", "configMap"},
+}
+
+// Interactive previews the objects generated for each service on out and reads from in the kinds to
+// toggle and the options to change, until the output is written with w or the conversion is quit
+// with q. It returns the options of the conversion and whether to write it.
+func Interactive(opt kobject.ConvertOptions, in io.Reader, out io.Writer) (kobject.ConvertOptions, bool, error) {
+	scanner := bufio.NewScanner(in)
+	for {
+		previewOpt := opt
+		if err := ValidateControllers(&previewOpt); err != nil {
+			return opt, false, err
+		}
+		_, objects, _, err := ConvertProject("", previewOpt)
+		if err != nil {
+			return opt, false, err
+		}
+		kinds := printPreview(objects, opt, out)
+
+		fmt.Fprint(out, "Toggle the kinds by number (as 1,3), set an option (as controller statefulset), w writes the output, q quits: ")
+		if !scanner.Scan() {
+			return opt, false, scanner.Err()
+		}
+		answer := strings.TrimSpace(scanner.Text())
+		switch answer {
+		case "":
+		case "w":
+			return opt, true, nil
+		case "q":
+			return opt, false, nil
+		default:
+			if err := applyAnswer(&opt, answer, kinds); err != nil {
+				fmt.Fprintf(out, "%v\n", err)
+			}
+		}
+	}
+}
+
+// printPreview writes the objects of each service, the objects without service last, and the numbered
+// kinds with the selected ones checked. It returns the kinds in their order.
+func printPreview(objects []runtime.Object, opt kobject.ConvertOptions, out io.Writer) []string {
+	services := map[string][]string{}
+	seen := map[string]bool{}
+	var kinds []string
+	for _, obj := range objects {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if !seen[kind] {
+			seen[kind] = true
+			kinds = append(kinds, kind)
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil || !kindSelected(opt, kind) {
+			continue
+		}
+		service := accessor.GetLabels()[transformer.Selector]
+		services[service] = append(services[service], kind+"/"+accessor.GetName())
+	}
+	sort.Strings(kinds)
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	// the objects without service, like the Namespace, sort first with their empty name and are printed last
+	sort.Strings(names)
+	if len(names) > 0 && names[0] == "" {
+		names = append(names[1:], "")
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tOBJECTS")
+	for _, name := range names {
+		label := name
+		if label == "" {
+			label = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", label, strings.Join(services[name], ", "))
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\nKinds:")
+	for i, kind := range kinds {
+		check := " "
+		if kindSelected(opt, kind) {
+			check = "x"
+		}
+		fmt.Fprintf(out, "  %d [%s] %s\n", i+1, check, kind)
+	}
+
+	controller := opt.Controller
+	if controller == "" {
+		controller = kubernetes.DeploymentController
+	}
+	expose := opt.ExposeController
+	if expose == "" {
+		expose = kubernetes.IngressExposeController
+	}
+	fmt.Fprintf(out, "\nOptions: controller=%s expose=%s volumes=%s\n", controller, expose, opt.Volumes)
+	return kinds
+}
+
+// applyAnswer toggles the kinds of the numbers of the answer, or sets the option it names
+func applyAnswer(opt *kobject.ConvertOptions, answer string, kinds []string) error {
+	fields := strings.Fields(answer)
+	if values, ok := interactiveOptions[fields[0]]; ok {
+		if len(fields) != 2 {
+			return errors.Errorf("set %s to one of: %s", fields[0], strings.Join(values, ", "))
+		}
+		value := ""
+		for _, v := range values {
+			if strings.EqualFold(v, fields[1]) {
+				value = v
+			}
+		}
+		if value == "" {
+			return errors.Errorf("unknown %s %q, possible values are: %s", fields[0], fields[1], strings.Join(values, ", "))
+		}
+		switch fields[0] {
+		case "controller":
+			opt.CreateD, opt.CreateDS, opt.CreateRC = false, false, false
+			opt.Controller = value
+		case "expose":
+			if opt.Provider == ProviderOpenshift {
+				return errors.New("the OpenShift provider exposes the services with Routes")
+			}
+			opt.ExposeController = value
+		case "volumes":
+			opt.Volumes = value
+		}
+		return nil
+	}
+
+	var toggled []string
+	for _, number := range strings.Split(answer, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(number))
+		if err != nil || i < 1 || i > len(kinds) {
+			return errors.Errorf("unknown answer %q, give the numbers of the kinds or an option among controller, expose and volumes", answer)
+		}
+		toggled = append(toggled, kinds[i-1])
+	}
+	for _, kind := range toggled {
+		toggleKind(opt, kind)
+	}
+	return nil
+}
+
+// kindSelected tells whether the objects of the kind are printed with --include-kinds and --exclude-kinds
+func kindSelected(opt kobject.ConvertOptions, kind string) bool {
+	return (len(opt.IncludeKinds) == 0 || containsKind(opt.IncludeKinds, kind)) && !containsKind(opt.ExcludeKinds, kind)
+}
+
+// toggleKind excludes the kind when it is selected, and selects it otherwise
+func toggleKind(opt *kobject.ConvertOptions, kind string) {
+	if kindSelected(*opt, kind) {
+		opt.ExcludeKinds = append(opt.ExcludeKinds, kind)
+		return
+	}
+	var excluded []string
+	for _, k := range opt.ExcludeKinds {
+		if !strings.EqualFold(k, kind) {
+			excluded = append(excluded, k)
+		}
+	}
+	opt.ExcludeKinds = excluded
+	if len(opt.IncludeKinds) > 0 && !containsKind(opt.IncludeKinds, kind) {
+		opt.IncludeKinds = append(opt.IncludeKinds, kind)
+	}
+}
+
+func containsKind(kinds []string, kind string) bool {
+	for _, k := range kinds {
+		if strings.EqualFold(k, kind) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+func TestInteractive(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "compose.yaml")
+	if err := os.WriteFile(file, []byte(serveCompose), 0600); err != nil {
+		t.Fatal(err)
+	}
+	opt := newServeOptions()
+	opt.InputFiles = []string{file}
+
+	var out bytes.Buffer
+	answers := strings.NewReader("2\ncontroller statefulset\nvolumes nfs\nw\n")
+	opt, write, err := Interactive(opt, answers, &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !write {
+		t.Fatalf("Expected the output to be written")
+	}
+	if strings.Join(opt.ExcludeKinds, ",") != "Service" {
+		t.Errorf("Expected the Service kind to be excluded, got %v", opt.ExcludeKinds)
+	}
+	if opt.Controller != "statefulset" || opt.CreateD {
+		t.Errorf("Expected the statefulset controller, got %q", opt.Controller)
+	}
+	if opt.Volumes != "persistentVolumeClaim" {
+		t.Errorf("Expected the unknown volumes mode to be ignored, got %q", opt.Volumes)
+	}
+	for _, expected := range []string{
+		"web       Service/web, Deployment/web",
+		"  2 [ ] Service",
+		"web       StatefulSet/web\n",
+		`unknown volumes "nfs"`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the preview:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestInteractiveQuit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "compose.yaml")
+	if err := os.WriteFile(file, []byte(serveCompose), 0600); err != nil {
+		t.Fatal(err)
+	}
+	opt := newServeOptions()
+	opt.InputFiles = []string{file}
+
+	for _, answers := range []string{"q\n", ""} {
+		_, write, err := Interactive(opt, strings.NewReader(answers), &bytes.Buffer{})
+		if err != nil || write {
+			t.Errorf("Expected the conversion to be quit with %q, got %v, %v", answers, write, err)
+		}
+	}
+}
+
+func TestToggleKind(t *testing.T) {
+	opt := kobject.ConvertOptions{IncludeKinds: []string{"deployment"}, ExcludeKinds: []string{"Service"}}
+	toggleKind(&opt, "Deployment")
+	if kindSelected(opt, "Deployment") {
+		t.Errorf("Expected the Deployment kind to be excluded")
+	}
+	toggleKind(&opt, "Deployment")
+	toggleKind(&opt, "Service")
+	toggleKind(&opt, "Ingress")
+	for _, kind := range []string{"Deployment", "Service", "Ingress"} {
+		if !kindSelected(opt, kind) {
+			t.Errorf("Expected the %s kind to be selected, got --include-kinds %v --exclude-kinds %v", kind, opt.IncludeKinds, opt.ExcludeKinds)
+		}
+	}
+}
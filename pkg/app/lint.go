@@ -0,0 +1,168 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/pkg/errors"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Severities of the lint findings, from the least to the most severe
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+	// SeverityNone never fails the lint, given to --fail-on
+	SeverityNone = "none"
+)
+
+// severityLevels orders the severities
+var severityLevels = map[string]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+	SeverityNone:    4,
+}
+
+// Finding is an issue of a container of the generated objects
+type Finding struct {
+	Severity  string `json:"severity"`
+	Rule      string `json:"rule"`
+	Object    string `json:"object"`
+	Container string `json:"container"`
+	Message   string `json:"message"`
+}
+
+// Lint converts the compose files and returns the issues of the containers of the generated workloads:
+// privileged containers, hostPath volumes, images without a pinned tag, missing resource limits
+// and missing health checks
+func Lint(opt kobject.ConvertOptions) ([]Finding, error) {
+	if err := ValidateControllers(&opt); err != nil {
+		return nil, err
+	}
+	_, objects, _, err := ConvertProject("", opt)
+	if err != nil {
+		return nil, err
+	}
+	return lintObjects(objects, opt), nil
+}
+
+// lintObjects returns the issues of the pod templates of the objects, in the order of the objects
+func lintObjects(objects []runtime.Object, opt kobject.ConvertOptions) []Finding {
+	k := kubernetes.Kubernetes{Opt: opt}
+	var findings []Finding
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		object := kind + "/" + accessor.GetName()
+		// the jobs run to completion, they aren't probed
+		oneShot := kind == "Job" || kind == "CronJob"
+
+		_ = k.UpdateController(obj, func(template *api.PodTemplateSpec) error {
+			findings = append(findings, lintPodSpec(object, &template.Spec, oneShot)...)
+			return nil
+		}, func(*metav1.ObjectMeta) {})
+	}
+	return findings
+}
+
+// lintPodSpec returns the issues of the containers of the pod spec of the object
+func lintPodSpec(object string, spec *api.PodSpec, oneShot bool) []Finding {
+	var findings []Finding
+	add := func(severity, rule, container, message string) {
+		findings = append(findings, Finding{Severity: severity, Rule: rule, Object: object, Container: container, Message: message})
+	}
+
+	hostPaths := map[string]string{}
+	for _, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			hostPaths[volume.Name] = volume.HostPath.Path
+		}
+	}
+
+	for _, container := range spec.Containers {
+		if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+			add(SeverityError, "privileged", container.Name, "the container is privileged, it has all the capabilities of the node")
+		}
+		for _, mount := range container.VolumeMounts {
+			if path, ok := hostPaths[mount.Name]; ok {
+				add(SeverityWarning, "host-path", container.Name, fmt.Sprintf("the host directory %s is mounted in %s, the pod depends on the node it runs on", path, mount.MountPath))
+			}
+		}
+		if unpinnedImage(container.Image) {
+			add(SeverityWarning, "latest-tag", container.Name, fmt.Sprintf("the image %s uses the latest tag, the deployed version changes when it is pushed", container.Image))
+		}
+		if len(container.Resources.Limits) == 0 {
+			add(SeverityWarning, "resource-limits", container.Name, "the container has no resource limits, set deploy.resources.limits")
+		}
+		if !oneShot && container.LivenessProbe == nil && container.ReadinessProbe == nil {
+			add(SeverityInfo, "healthcheck", container.Name, "the container has no health check, set healthcheck")
+		}
+	}
+	return findings
+}
+
+// unpinnedImage returns whether the image uses the latest tag, given or implied
+func unpinnedImage(image string) bool {
+	if image == "" || strings.Contains(image, "@") {
+		return false
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, found := strings.Cut(name, ":")
+	return !found || tag == "latest"
+}
+
+// ValidateSeverity checks the severity given to --fail-on
+func ValidateSeverity(severity string) error {
+	if _, ok := severityLevels[severity]; !ok {
+		return errors.Errorf("invalid severity %q, expected %q, %q, %q or %q", severity, SeverityInfo, SeverityWarning, SeverityError, SeverityNone)
+	}
+	return nil
+}
+
+// LintFails returns whether one of the findings is at least as severe as failOn
+func LintFails(findings []Finding, failOn string) bool {
+	for _, finding := range findings {
+		if severityLevels[finding.Severity] >= severityLevels[failOn] {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintFindings writes the findings as a table
+func PrintFindings(findings []Finding, out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tOBJECT\tCONTAINER\tRULE\tMESSAGE")
+	for _, finding := range findings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", finding.Severity, finding.Object, finding.Container, finding.Rule, finding.Message)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestLintObjects(t *testing.T) {
+	privileged := true
+	web := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: appsv1.DeploymentSpec{
+			Template: api.PodTemplateSpec{
+				Spec: api.PodSpec{
+					Containers: []api.Container{{
+						Name:            "web",
+						Image:           "nginx",
+						SecurityContext: &api.SecurityContext{Privileged: &privileged},
+						VolumeMounts:    []api.VolumeMount{{Name: "logs", MountPath: "/var/log/nginx"}},
+					}},
+					Volumes: []api.Volume{{
+						Name:         "logs",
+						VolumeSource: api.VolumeSource{HostPath: &api.HostPathVolumeSource{Path: "/var/log"}},
+					}},
+				},
+			},
+		},
+	}
+	migrate := &batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "migrate"},
+		Spec: batchv1.JobSpec{
+			Template: api.PodTemplateSpec{
+				Spec: api.PodSpec{
+					Containers: []api.Container{{
+						Name:  "migrate",
+						Image: "registry.example.com:5000/migrate:1.0",
+						Resources: api.ResourceRequirements{
+							Limits: api.ResourceList{api.ResourceMemory: resource.MustParse("64Mi")},
+						},
+					}},
+				},
+			},
+		},
+	}
+	service := &api.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+	}
+
+	findings := lintObjects([]runtime.Object{web, migrate, service}, kobject.ConvertOptions{})
+
+	var rules []string
+	for _, finding := range findings {
+		if finding.Object != "Deployment/web" || finding.Container != "web" {
+			t.Errorf("Unexpected finding %+v", finding)
+		}
+		rules = append(rules, finding.Severity+":"+finding.Rule)
+	}
+	expected := "error:privileged warning:host-path warning:latest-tag warning:resource-limits info:healthcheck"
+	if strings.Join(rules, " ") != expected {
+		t.Errorf("Expected the findings %s, got %s", expected, strings.Join(rules, " "))
+	}
+
+	if !LintFails(findings, SeverityError) {
+		t.Errorf("Expected the privileged container to fail the lint")
+	}
+	if LintFails(findings, SeverityNone) {
+		t.Errorf("Expected the lint to never fail with %q", SeverityNone)
+	}
+	if LintFails(findings[1:], SeverityError) || !LintFails(findings[1:], SeverityWarning) {
+		t.Errorf("Expected the warnings to only fail the lint from %q", SeverityWarning)
+	}
+
+	var out bytes.Buffer
+	if err := PrintFindings(findings, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 6 || !strings.HasPrefix(lines[0], "SEVERITY") {
+		t.Errorf("Expected a table of the findings, got:\n%s", out.String())
+	}
+}
+
+func TestUnpinnedImage(t *testing.T) {
+	for image, expected := range map[string]bool{
+		"nginx":                           true,
+		"nginx:latest":                    true,
+		"nginx:1.27":                      false,
+		"registry.example.com:5000/web":   true,
+		"registry.example.com:5000/web:1": false,
+		"nginx@sha256:0123456789abcdef":   false,
+	} {
+		if unpinnedImage(image) != expected {
+			t.Errorf("Expected image %s to be unpinned: %v", image, expected)
+		}
+	}
+}
+
+func TestValidateSeverity(t *testing.T) {
+	for _, severity := range []string{SeverityInfo, SeverityWarning, SeverityError, SeverityNone} {
+		if err := ValidateSeverity(severity); err != nil {
+			t.Errorf("Unexpected error for %q: %v", severity, err)
+		}
+	}
+	if err := ValidateSeverity("critical"); err == nil {
+		t.Errorf("Expected an error for an unknown severity")
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/loader"
+)
+
+// PrintMerged writes the compose model of each project, its files merged with the override rules of the
+// compose specification, as it is converted. With several projects, each model starts with a comment
+// naming its project.
+func PrintMerged(opt kobject.ConvertOptions, out io.Writer) error {
+	l, err := loader.GetLoader(inputFormat, opt)
+	if err != nil {
+		return err
+	}
+
+	projects := opt.Projects
+	if len(projects) == 0 {
+		projects = []kobject.Project{{InputFiles: opt.InputFiles}}
+	}
+	for i, project := range projects {
+		projectOpt := opt
+		projectOpt.InputFiles = project.InputFiles
+		workDir, files, err := composeFiles(projectOpt)
+		if err != nil {
+			return err
+		}
+		data, err := l.MergedModel(project.Name, files, workDir, opt.Profiles, opt.Services, opt.NoInterpolate, opt.EnvFiles, opt.Env)
+		if err != nil {
+			return err
+		}
+
+		if len(projects) > 1 {
+			if i > 0 {
+				fmt.Fprintln(out, "---")
+			}
+			fmt.Fprintf(out, "# project %s\n", project.Name)
+		}
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
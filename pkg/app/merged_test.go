@@ -0,0 +1,67 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/utils/redact"
+)
+
+func TestPrintMerged(t *testing.T) {
+	dir := t.TempDir()
+	compose := filepath.Join(dir, "compose.yaml")
+	override := filepath.Join(dir, "compose.prod.yaml")
+	if err := os.WriteFile(compose, []byte("services:\n  web:\n    image: nginx:1.26\n    ports:\n      - 8080:80\n    environment:\n      MODE: dev\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(override, []byte("services:\n  web:\n    image: nginx:1.27\n    ports: !reset []\n    environment:\n      LOG_LEVEL: info\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	opt := kobject.ConvertOptions{InputFiles: []string{compose, override}}
+	if err := PrintMerged(opt, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	merged := out.String()
+	for _, expected := range []string{"image: nginx:1.27", "MODE: '******'", "LOG_LEVEL: '******'"} {
+		if !strings.Contains(merged, expected) {
+			t.Errorf("Expected %q in the merged model, got:\n%s", expected, merged)
+		}
+	}
+	if strings.Contains(merged, "published:") {
+		t.Errorf("Expected the ports to be reset, got:\n%s", merged)
+	}
+
+	redact.Show = true
+	defer func() { redact.Show = false }()
+	out.Reset()
+	if err := PrintMerged(opt, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{"MODE: dev", "LOG_LEVEL: info"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the merged model shown with --show-secrets, got:\n%s", expected, out.String())
+		}
+	}
+}
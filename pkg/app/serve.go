@@ -0,0 +1,281 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/kubernetes/kompose/pkg/validate"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// MaxComposeSize is the largest request body accepted by the conversion API
+const MaxComposeSize = 10 << 20
+
+const (
+	// serveHeaderTimeout bounds the time to read the headers of a request
+	serveHeaderTimeout = 10 * time.Second
+	// serveReadTimeout bounds the time to read a request, its compose files included
+	serveReadTimeout = time.Minute
+	// serveWriteTimeout bounds the time to convert the compose files and write the response, the
+	// conversions wait for each other
+	serveWriteTimeout = 5 * time.Minute
+)
+
+// Serve listens on the address and serves the conversion API, the conversions use the options
+// completed with the query parameters of each request
+func Serve(address string, opt kobject.ConvertOptions) error {
+	log.Infof("Serving the conversion API on %s", address)
+	server := &http.Server{
+		Addr:              address,
+		Handler:           ServeHandler(opt),
+		ReadHeaderTimeout: serveHeaderTimeout,
+		ReadTimeout:       serveReadTimeout,
+		WriteTimeout:      serveWriteTimeout,
+	}
+	return server.ListenAndServe()
+}
+
+// ServeHandler returns the handler of the conversion API: POST /convert converts the compose files
+// of the request body, given as is or as the "file" parts of a multipart form, and returns the
+// generated manifests, or the chart as a gzipped tarball with the chart query parameter. GET /healthz
+// tells that the server is up.
+func ServeHandler(opt kobject.ConvertOptions) http.Handler {
+	// the conversions share the logger and its hooks, they run one at a time
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/convert", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "the compose files are converted with POST", http.StatusMethodNotAllowed)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if err := serveConversion(w, r, opt); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+	return mux
+}
+
+// serveConversion converts the compose files of the request and writes the manifests or the chart, a
+// panic of the conversion is returned as its error
+func serveConversion(w http.ResponseWriter, r *http.Request, opt kobject.ConvertOptions) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = errors.Errorf("unexpected error: %v", recovered)
+		}
+	}()
+	dir, err := os.MkdirTemp("", "kompose-serve")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxComposeSize)
+	opt.InputFiles, err = saveComposeFiles(r, dir)
+	if err != nil {
+		return err
+	}
+	chart, err := requestOptions(r, &opt)
+	if err != nil {
+		return err
+	}
+	if err := ValidateControllers(&opt); err != nil {
+		return err
+	}
+	// the uploaded compose files only read the files they were uploaded with, and never fetch remote files
+	opt.Context = dir
+	opt.RestrictPaths = true
+	opt.AllowRemoteExtends = false
+	_, objects, _, err := ConvertProject("", opt)
+	if err != nil {
+		return err
+	}
+
+	if chart == "" {
+		data, err := kubernetes.MarshalObjects(objects, opt.YAMLIndent)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		_, err = w.Write(data)
+		return err
+	}
+
+	opt.CreateChart = true
+	opt.ToStdout = false
+	opt.OutFile = filepath.Join(dir, "out", chart)
+	if err := printList(objects, opt); err != nil {
+		return err
+	}
+	// the tarball is built before the response is written, so that its errors are reported
+	var tarball bytes.Buffer
+	if err := writeTarball(&tarball, filepath.Join(dir, "out")); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", chart+".tgz"))
+	_, err = w.Write(tarball.Bytes())
+	return err
+}
+
+// saveComposeFiles writes the compose files of the request to the directory, in the order of the request
+func saveComposeFiles(r *http.Request, dir string) ([]string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		file := filepath.Join(dir, "compose.yaml")
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the compose file")
+		}
+		if len(data) == 0 {
+			return nil, errors.New("the request has no compose file")
+		}
+		return []string{file}, os.WriteFile(file, data, 0600)
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the compose files")
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		file := filepath.Join(dir, fmt.Sprintf("compose-%d.yaml", len(files)))
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the compose files")
+		}
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, errors.New(`the request has no compose file, send them as the "file" parts of the form`)
+	}
+	return files, nil
+}
+
+// requestOptions sets the options of the query parameters of the request: provider, namespace, controller,
+// profile and service, which can be repeated, and kubernetes-version. It returns the name of the chart
+// requested with the chart parameter.
+func requestOptions(r *http.Request, opt *kobject.ConvertOptions) (string, error) {
+	query := r.URL.Query()
+	if provider := query.Get("provider"); provider != "" {
+		switch provider = strings.ToLower(provider); provider {
+		case ProviderKubernetes, ProviderOpenshift:
+			opt.Provider = provider
+		default:
+			return "", errors.Errorf("unknown provider %q, possible values are: '%s' '%s'", provider, ProviderKubernetes, ProviderOpenshift)
+		}
+	}
+	if namespace := query.Get("namespace"); namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return "", errors.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))
+		}
+		opt.Namespace = namespace
+	}
+	if controller := query.Get("controller"); controller != "" {
+		opt.Controller = strings.ToLower(controller)
+	}
+	opt.Profiles = append(opt.Profiles, query["profile"]...)
+	opt.Services = append(opt.Services, query["service"]...)
+	if version := query.Get("kubernetes-version"); version != "" {
+		if _, err := validate.ParseVersion(version); err != nil {
+			return "", err
+		}
+		opt.KubernetesVersion = version
+	}
+
+	chart := query.Get("chart")
+	if chart != "" {
+		if errs := validation.IsDNS1123Label(chart); len(errs) > 0 {
+			return "", errors.Errorf("invalid chart name %q: %s", chart, strings.Join(errs, ", "))
+		}
+	}
+	return chart, nil
+}
+
+// writeTarball writes the files of the directory as a gzipped tarball, named relatively to it
+func writeTarball(w io.Writer, dir string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+const serveCompose = "services:\n  web:\n    image: nginx:1.27\n    ports:\n      - 8080:80\n"
+
+func newServeOptions() kobject.ConvertOptions {
+	return kobject.ConvertOptions{Provider: ProviderKubernetes, Replicas: 1, Volumes: "persistentVolumeClaim", YAMLIndent: 2}
+}
+
+func TestServeManifests(t *testing.T) {
+	server := httptest.NewServer(ServeHandler(newServeOptions()))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/convert?namespace=shop", "application/yaml", strings.NewReader(serveCompose))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the manifests, got %d: %s", resp.StatusCode, body)
+	}
+	for _, expected := range []string{"kind: Deployment", "kind: Service", "namespace: shop"} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("Expected %q in the manifests:\n%s", expected, body)
+		}
+	}
+}
+
+func TestServeChart(t *testing.T) {
+	server := httptest.NewServer(ServeHandler(newServeOptions()))
+	defer server.Close()
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("file", "compose.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(serveCompose))
+	writer.Close()
+
+	resp, err := http.Post(server.URL+"/convert?chart=shop", writer.FormDataContentType(), &form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/gzip" {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("Expected the chart tarball, got %d: %s", resp.StatusCode, body)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]bool{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = true
+	}
+	for _, expected := range []string{"shop/Chart.yaml", "shop/values.yaml", "shop/templates/web-deployment.yaml"} {
+		if !files[expected] {
+			t.Errorf("Expected %s in the chart, got %v", expected, files)
+		}
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	server := httptest.NewServer(ServeHandler(newServeOptions()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/convert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET to be refused, got %d", resp.StatusCode)
+	}
+
+	for _, query := range []string{"?provider=swarm", "?namespace=Shop", "?chart=my_chart", "?kubernetes-version=latest"} {
+		resp, err := http.Post(server.URL+"/convert"+query, "application/yaml", strings.NewReader(serveCompose))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected %s to be a bad request, got %d", query, resp.StatusCode)
+		}
+	}
+
+	resp, err = http.Post(server.URL+"/convert", "application/yaml", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected an empty body to be a bad request, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeRestrictedPaths(t *testing.T) {
+	server := httptest.NewServer(ServeHandler(newServeOptions()))
+	defer server.Close()
+
+	testCases := map[string]string{
+		"absolute env file": "services:\n  web:\n    image: nginx\n    env_file: /etc/hostname\n",
+		"parent env file":   "services:\n  web:\n    image: nginx\n    env_file: ../server.env\n",
+		"bind mount":        "services:\n  web:\n    image: nginx\n    volumes:\n      - /etc:/host-etc\n",
+		"secret file":       "services:\n  web:\n    image: nginx\n    secrets:\n      - passwd\nsecrets:\n  passwd:\n    file: /etc/passwd\n",
+		"config file":       "services:\n  web:\n    image: nginx\n    configs:\n      - hosts\nconfigs:\n  hosts:\n    file: ../../etc/hosts\n",
+		"extended file":     "services:\n  web:\n    extends:\n      file: /etc/compose.yaml\n      service: web\n",
+		"remote extend":     "services:\n  web:\n    extends:\n      file: https://example.com/compose.yaml\n      service: web\n",
+		"included file":     "include:\n  - ../compose.yaml\nservices:\n  web:\n    image: nginx\n",
+	}
+	for name, compose := range testCases {
+		t.Run(name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/convert", "application/yaml", strings.NewReader(compose))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Expected the compose file to be refused, got %d: %s", resp.StatusCode, body)
+			}
+		})
+	}
+}
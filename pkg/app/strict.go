@@ -0,0 +1,32 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// strictError returns an error listing the settings dropped by the conversion, which --strict doesn't
+// allow, nil when none was dropped
+func strictError(settings []string) error {
+	if len(settings) == 0 {
+		return nil
+	}
+	return errors.Errorf("the conversion drops settings of the compose files, which --strict doesn't allow:\n  %s", strings.Join(settings, "\n  "))
+}
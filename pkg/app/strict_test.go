@@ -0,0 +1,42 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestStrictError(t *testing.T) {
+	recorder := dropped.Start()
+	defer recorder.Stop()
+
+	log.Warn("Ignoring the plain warnings")
+	if err := strictError(recorder.Settings()); err != nil {
+		t.Errorf("Expected only the dropped settings to fail, got %v", err)
+	}
+
+	dropped.Warnf("Ignoring sysctl %q of service %q, it isn't namespaced and can't be set for a pod", "kernel.shmmax", "web")
+	dropped.Warnf("External secrets %s is not currently supported - ignoring", "token")
+	err := strictError(recorder.Settings())
+	if err == nil || !strings.Contains(err.Error(), `Ignoring sysctl "kernel.shmmax" of service "web"`) || !strings.Contains(err.Error(), "External secrets token") {
+		t.Errorf("Expected the dropped settings to fail, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// SummaryTable prints the conversion summary as a table
+	SummaryTable = "table"
+	// SummaryJSON prints the conversion summary as JSON
+	SummaryJSON = "json"
+
+	// projectResources groups the resources not generated for a single service
+	projectResources = "(project)"
+)
+
+type summaryResource struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type serviceSummary struct {
+	Service   string            `json:"service"`
+	Resources []summaryResource `json:"resources"`
+}
+
+type conversionSummary struct {
+	Services []serviceSummary `json:"services"`
+	// Warnings lists the compose settings ignored or approximated during the conversion, the other
+	// warnings are only logged
+	Warnings []string `json:"warnings"`
+}
+
+// WarningCollector is a logrus hook keeping the warnings logged during the conversion, the warnings
+// may be logged from several goroutines
+type WarningCollector struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (c *WarningCollector) Levels() []log.Level {
+	return []log.Level{log.WarnLevel}
+}
+
+func (c *WarningCollector) Fire(entry *log.Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.warnings = append(c.warnings, strings.TrimSpace(entry.Message))
+	return nil
+}
+
+// Warnings returns the warnings collected so far
+func (c *WarningCollector) Warnings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.warnings...)
+}
+
+// newSummary groups the generated objects by the compose service they were generated for
+func newSummary(komposeObject kobject.KomposeObject, objects []runtime.Object, warnings []string) conversionSummary {
+	services := make([]string, 0, len(komposeObject.ServiceConfigs))
+	for name := range komposeObject.ServiceConfigs {
+		services = append(services, name)
+	}
+	// longest names first, so that a resource is attributed to the most specific service
+	kubernetes.SortServicesByLength(services)
+
+	resources := map[string][]summaryResource{}
+	for _, object := range objects {
+		accessor, err := meta.Accessor(object)
+		if err != nil {
+			continue
+		}
+		resource := summaryResource{Kind: object.GetObjectKind().GroupVersionKind().Kind, Name: accessor.GetName()}
+		service := kubernetes.ServiceOf(services, accessor.GetLabels()[transformer.Selector], accessor.GetName())
+		if service == "" {
+			service = projectResources
+		}
+		resources[service] = append(resources[service], resource)
+	}
+
+	summary := conversionSummary{Warnings: warnings}
+	if summary.Warnings == nil {
+		summary.Warnings = []string{}
+	}
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		summary.Services = append(summary.Services, serviceSummary{Service: name, Resources: resources[name]})
+	}
+	return summary
+}
+
+func printSummary(w io.Writer, summary conversionSummary, format string) error {
+	if format == SummaryJSON {
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tKIND\tNAME")
+	for _, service := range summary.Services {
+		for i, resource := range service.Resources {
+			name := ""
+			if i == 0 {
+				name = service.Service
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, resource.Kind, resource.Name)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(summary.Warnings) > 0 {
+		fmt.Fprintln(w, "\nIgnored or approximated:")
+		for _, warning := range summary.Warnings {
+			fmt.Fprintf(w, "  - %s\n", warning)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSummary(t *testing.T) {
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web":    {Name: "web"},
+			"web-db": {Name: "web-db"},
+		},
+	}
+	objects := []runtime.Object{
+		&api.Namespace{TypeMeta: metav1.TypeMeta{Kind: "Namespace"}, ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		&api.Service{TypeMeta: metav1.TypeMeta{Kind: "Service"}, ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: transformer.ConfigLabels("web")}},
+		&appsv1.Deployment{TypeMeta: metav1.TypeMeta{Kind: "Deployment"}, ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: transformer.ConfigLabels("web")}},
+		&api.ConfigMap{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap"}, ObjectMeta: metav1.ObjectMeta{Name: "app-env", Labels: transformer.ConfigLabels("web-db-app-env")}},
+		&api.PersistentVolumeClaim{TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim"}, ObjectMeta: metav1.ObjectMeta{Name: "web-db-claim0"}},
+	}
+
+	summary := newSummary(komposeObject, objects, []string{"Ignoring PID key for service \"web\""})
+
+	var out bytes.Buffer
+	if err := printSummary(&out, summary, SummaryTable); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `SERVICE     KIND                    NAME
+(project)   Namespace               shop
+web         Service                 web
+            Deployment              web
+web-db      ConfigMap               app-env
+            PersistentVolumeClaim   web-db-claim0
+
+Ignored or approximated:
+  - Ignoring PID key for service "web"
+`
+	if out.String() != expected {
+		t.Errorf("Expected summary:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
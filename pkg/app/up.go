@@ -0,0 +1,160 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/utils/kubectl"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// rolledOutKinds are the kinds whose rollout is waited for with kubectl rollout status
+var rolledOutKinds = map[string]string{
+	"Deployment":  "deployment",
+	"StatefulSet": "statefulset",
+	"DaemonSet":   "daemonset",
+}
+
+// FieldManager is the field manager of the objects applied server-side by kompose up
+const FieldManager = "kompose"
+
+// Up converts the compose files and applies server-side the generated objects to the cluster of the current
+// kubectl context, the fields of the objects set by the other managers, like the autoscalers, are kept. With
+// forceConflicts, kompose takes over the fields it sets that another manager owns, instead of failing.
+// With wait, it then waits for the rollout of the Deployments, the StatefulSets and the DaemonSets,
+// within timeout when it isn't zero, and fails when one of them doesn't complete.
+func Up(opt kobject.ConvertOptions, wait bool, timeout time.Duration, forceConflicts bool) error {
+	if err := ValidateControllers(&opt); err != nil {
+		return err
+	}
+	_, objects, _, err := ConvertProject("", opt)
+	if err != nil {
+		return err
+	}
+
+	if err := apply(objects, opt.Namespace, "apply", forceConflicts); err != nil {
+		return err
+	}
+	if !wait {
+		return nil
+	}
+	return waitForRollouts(objects, opt.Namespace, timeout)
+}
+
+// apply applies server-side, or deletes with "delete", the objects with kubectl, logging the changed objects.
+// The objects are applied with the kompose field manager, forceConflicts takes over the conflicting fields.
+func apply(objects []runtime.Object, namespace string, action string, forceConflicts bool) error {
+	args := []string{action, "-f", "-"}
+	switch action {
+	case "apply":
+		args = append(args, "--server-side", "--field-manager", FieldManager)
+		if forceConflicts {
+			args = append(args, "--force-conflicts")
+		}
+	case "delete":
+		args = append(args, "--ignore-not-found")
+	}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	output, err := kubectl.Run(objects, args...)
+	if err != nil {
+		return errors.Errorf("unable to %s the objects in the cluster:\n  %s", action, err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			log.Info(line)
+		}
+	}
+	return nil
+}
+
+// waitForRollouts waits for the rollout of the objects one after the other, logging their progress
+func waitForRollouts(objects []runtime.Object, namespace string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	var failed []string
+	for _, obj := range objects {
+		kind, ok := rolledOutKinds[obj.GetObjectKind().GroupVersionKind().Kind]
+		if !ok {
+			continue
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		resource := kind + "/" + accessor.GetName()
+
+		args := []string{"rollout", "status", resource}
+		if ns := accessor.GetNamespace(); ns != "" {
+			args = append(args, "--namespace", ns)
+		} else if namespace != "" {
+			args = append(args, "--namespace", namespace)
+		}
+		if timeout > 0 {
+			remaining := time.Until(deadline).Round(time.Second)
+			if remaining <= 0 {
+				failed = append(failed, fmt.Sprintf("%s: timed out", resource))
+				continue
+			}
+			args = append(args, "--timeout", remaining.String())
+		}
+
+		if err := rolloutStatus(resource, args); err != nil {
+			log.Errorf("%s: %s", resource, err)
+			failed = append(failed, fmt.Sprintf("%s: %s", resource, err))
+			continue
+		}
+		log.Infof("%s: rolled out", resource)
+	}
+	if len(failed) > 0 {
+		return errors.Errorf("the rollout of %d objects didn't complete:\n  %s", len(failed), strings.Join(failed, "\n  "))
+	}
+	return nil
+}
+
+// rolloutStatus runs kubectl rollout status, logging the progress it reports for the resource
+func rolloutStatus(resource string, args []string) error {
+	cmd, err := kubectl.Command(args...)
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		log.Infof("%s: %s", resource, scanner.Text())
+	}
+	if err := cmd.Wait(); err != nil {
+		return errors.New(strings.Join(kubectl.Errors(stderr.String()), "; "))
+	}
+	return nil
+}
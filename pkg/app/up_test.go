@@ -0,0 +1,76 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+)
+
+func TestUp(t *testing.T) {
+	dir := t.TempDir()
+	compose := filepath.Join(dir, "compose.yaml")
+	if err := os.WriteFile(compose, []byte("services:\n  web:\n    image: nginx\n  db:\n    image: postgres\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the fake kubectl records its calls, the rollout of db fails
+	script := `#!/bin/sh
+echo "$@" >> ` + filepath.Join(dir, "calls") + `
+case "$1 $3" in
+"apply "*) echo "deployment.apps/web serverside-applied"; echo "deployment.apps/db serverside-applied" ;;
+"rollout deployment/db") echo "error: deployment \"db\" exceeded its progress deadline" >&2; exit 1 ;;
+"rollout "*) echo "deployment \"web\" successfully rolled out" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	opt := kobject.ConvertOptions{InputFiles: []string{compose}, Provider: "kubernetes", Namespace: "shop", YAMLIndent: 2}
+	err := Up(opt, true, time.Minute, false)
+	if err == nil || !strings.Contains(err.Error(), "deployment/db: error: deployment \"db\" exceeded its progress deadline") ||
+		strings.Contains(err.Error(), "deployment/web") {
+		t.Errorf("Expected the rollout of db only to fail, got %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "calls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(calls) != 3 || calls[0] != "apply -f - --server-side --field-manager kompose --namespace shop" ||
+		!strings.HasPrefix(calls[1], "rollout status deployment/db --namespace shop --timeout ") ||
+		!strings.HasPrefix(calls[2], "rollout status deployment/web --namespace shop --timeout ") {
+		t.Errorf("Expected kubectl apply server-side then the rollouts, got %q", calls)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "calls")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Up(opt, false, 0, true); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "calls")); strings.TrimSpace(string(data)) != "apply -f - --server-side --field-manager kompose --force-conflicts --namespace shop" {
+		t.Errorf("Expected kubectl apply only without --wait, forcing the conflicts, got %q", data)
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/utils/remote"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// watchDebounce is the delay without changes after which the objects are converted again, the editors
+// write a file with several events
+const watchDebounce = 300 * time.Millisecond
+
+// appliedObject is an object applied to the cluster, with its JSON to detect its changes
+type appliedObject struct {
+	object runtime.Object
+	data   []byte
+}
+
+// Watch applies the objects as Up, then converts the compose files again each time they change, or
+// their env_files, the files of their configs and secrets, or their bind-mounted host paths change.
+// Only the changed objects are applied, the objects no longer generated are deleted. It returns when
+// the context is done.
+func Watch(ctx context.Context, opt kobject.ConvertOptions, wait bool, timeout time.Duration, forceConflicts bool) error {
+	if err := ValidateControllers(&opt); err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "unable to watch the compose files")
+	}
+	defer watcher.Close()
+
+	applied := map[string]appliedObject{}
+	watched := map[string]bool{}
+	update := func() error {
+		komposeObject, objects, _, err := ConvertProject("", opt)
+		if err != nil {
+			return err
+		}
+		// the files are watched through their directories, the editors replace the files they write
+		paths := watchedPaths(opt, komposeObject)
+		watched = map[string]bool{}
+		for _, path := range paths {
+			watched[path] = true
+			if err := watcher.Add(filepath.Dir(path)); err != nil {
+				log.Warnf("Unable to watch %s: %s", path, err)
+			}
+		}
+
+		changed, removed, current := objectsDelta(applied, objects)
+		if len(changed) == 0 && len(removed) == 0 {
+			log.Info("The objects are unchanged")
+			return nil
+		}
+		if len(changed) > 0 {
+			if err := apply(changed, opt.Namespace, "apply", forceConflicts); err != nil {
+				return err
+			}
+		}
+		if len(removed) > 0 {
+			if err := apply(removed, opt.Namespace, "delete", false); err != nil {
+				return err
+			}
+		}
+		applied = current
+		if wait {
+			return waitForRollouts(changed, opt.Namespace, timeout)
+		}
+		return nil
+	}
+
+	if err := update(); err != nil {
+		return err
+	}
+	log.Info("Watching the compose files for changes, press Ctrl+C to stop")
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if watched[filepath.Clean(event.Name)] && !event.Has(fsnotify.Chmod) {
+				log.Debugf("%s changed", event.Name)
+				debounce = time.After(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warnf("Error watching the compose files: %s", err)
+		case <-debounce:
+			debounce = nil
+			log.Info("The compose files changed, converting them again")
+			// the errors are reported and the previous objects are kept until the next change
+			if err := update(); err != nil {
+				log.Error(err)
+			}
+		}
+	}
+}
+
+// watchedPaths returns the absolute paths of the compose files and of the files they reference
+func watchedPaths(opt kobject.ConvertOptions, komposeObject kobject.KomposeObject) []string {
+	set := map[string]bool{}
+	add := func(path string) {
+		if path == "" || path == "-" || remote.IsRemote(path) {
+			return
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			set[filepath.Clean(abs)] = true
+		}
+	}
+	for _, file := range opt.InputFiles {
+		add(file)
+	}
+	for _, service := range komposeObject.ServiceConfigs {
+		for _, file := range service.EnvFile {
+			add(file)
+		}
+		for _, config := range service.ConfigsMetaData {
+			add(config.File)
+		}
+		// the named volumes have no host path
+		for _, volume := range service.Volumes {
+			if filepath.IsAbs(volume.Host) {
+				add(volume.Host)
+			}
+		}
+	}
+	for _, secret := range komposeObject.Secrets {
+		add(secret.File)
+	}
+
+	paths := make([]string, 0, len(set))
+	for path := range set {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// objectsDelta compares the objects with the applied ones, it returns the new or changed objects, the
+// applied objects no longer generated, and the objects once applied
+func objectsDelta(applied map[string]appliedObject, objects []runtime.Object) (changed []runtime.Object, removed []runtime.Object, current map[string]appliedObject) {
+	current = map[string]appliedObject{}
+	for _, obj := range objects {
+		key := objectKey(obj)
+		data, err := json.Marshal(obj)
+		if err != nil {
+			data = nil
+		}
+		current[key] = appliedObject{object: obj, data: data}
+		if previous, ok := applied[key]; !ok || data == nil || string(previous.data) != string(data) {
+			changed = append(changed, obj)
+		}
+	}
+
+	keys := make([]string, 0, len(applied))
+	for key := range applied {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		removed = append(removed, applied[key].object)
+	}
+	return changed, removed, current
+}
+
+// objectKey identifies an object by its kind, namespace and name
+func objectKey(obj runtime.Object) string {
+	key := obj.GetObjectKind().GroupVersionKind().GroupKind().String()
+	if accessor, err := meta.Accessor(obj); err == nil {
+		key += "/" + accessor.GetNamespace() + "/" + accessor.GetName()
+	}
+	return key
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWatchedPaths(t *testing.T) {
+	opt := kobject.ConvertOptions{InputFiles: []string{"/project/compose.yaml"}}
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web": {
+				EnvFile:         []string{"/project/web.env"},
+				ConfigsMetaData: types.Configs{"nginx": {File: "/project/nginx.conf"}},
+				Volumes: []kobject.Volumes{
+					{Host: "/project/html", Container: "/usr/share/nginx/html"},
+					{VolumeName: "data", Container: "/data"},
+				},
+			},
+		},
+		Secrets: types.Secrets{"token": {File: "/project/token"}},
+	}
+	expected := []string{"/project/compose.yaml", "/project/html", "/project/nginx.conf", "/project/token", "/project/web.env"}
+	for i := range expected {
+		expected[i] = filepath.FromSlash(expected[i])
+	}
+	if paths := watchedPaths(opt, komposeObject); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+}
+
+func TestObjectsDelta(t *testing.T) {
+	configMap := func(name, value string) runtime.Object {
+		return &api.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Data:       map[string]string{"key": value},
+		}
+	}
+
+	changed, removed, applied := objectsDelta(map[string]appliedObject{}, []runtime.Object{configMap("a", "1"), configMap("b", "1")})
+	if len(changed) != 2 || len(removed) != 0 {
+		t.Errorf("Expected all the objects to be applied first, got %v %v", changed, removed)
+	}
+
+	changed, removed, _ = objectsDelta(applied, []runtime.Object{configMap("a", "1"), configMap("c", "1"), configMap("b", "2")})
+	if !reflect.DeepEqual(changed, []runtime.Object{configMap("c", "1"), configMap("b", "2")}) {
+		t.Errorf("Expected the new and the changed objects, got %v", changed)
+	}
+	if len(removed) != 0 {
+		t.Errorf("Expected no removed objects, got %v", removed)
+	}
+
+	changed, removed, _ = objectsDelta(applied, []runtime.Object{configMap("a", "1")})
+	if len(changed) != 0 || !reflect.DeepEqual(removed, []runtime.Object{configMap("b", "1")}) {
+		t.Errorf("Expected the b ConfigMap to be removed only, got %v %v", changed, removed)
+	}
+}
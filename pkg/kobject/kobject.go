@@ -42,6 +42,38 @@ type KomposeObject struct {
 
 	// Namespace is the namespace where all the generated objects would be assigned to
 	Namespace string
+
+	// InternalNetworks are the networks defined with "internal: true", without access to the outside
+	InternalNetworks map[string]bool
+
+	// ProjectName is the name of the compose project
+	ProjectName string
+
+	// KubernetesPatches are the patches of the top level x-kubernetes extension, merged into the
+	// generated objects of their kind or added as new objects
+	KubernetesPatches []map[string]interface{}
+}
+
+// Rename records a name rewritten by kompose to make it a valid Kubernetes resource name
+type Rename struct {
+	Kind     string `json:"kind"`
+	Original string `json:"original"`
+	Name     string `json:"name"`
+	Project  string `json:"project,omitempty"`
+}
+
+// Project holds a compose project converted along with other projects
+type Project struct {
+	Name       string
+	InputFiles []string
+}
+
+// Environment is an environment of the project, converted with its override files and its profiles
+// added to the ones of the base
+type Environment struct {
+	Name       string
+	InputFiles []string
+	Profiles   []string
 }
 
 // ConvertOptions holds all options that controls transformation process
@@ -57,6 +89,7 @@ type ConvertOptions struct {
 	Profiles                    []string
 	PushImage                   bool
 	PushImageRegistry           string
+	PushImageDigest             bool
 	CreateChart                 bool
 	GenerateYaml                bool
 	GenerateJSON                bool
@@ -93,6 +126,62 @@ type ConvertOptions struct {
 	SecretsAsFiles          bool
 	GenerateNetworkPolicies bool
 	NoInterpolate           bool
+	DisableServiceLinks     bool
+	RenameReport            string
+	Merge                   bool
+	Summary                 string
+	Projects                []Project
+	AddLabels               map[string]string
+	AddAnnotations          map[string]string
+	AddPodLabels            map[string]string
+	AddPodAnnotations       map[string]string
+	ExposeController        string
+	GatewayName             string
+	GatewayClassName        string
+	CreateNamespace         bool
+	SecretsAs               string
+	SealedSecretsCert       string
+	SealedSecretsScope      string
+	AllowUnsafeSysctls      bool
+	AllowHostNamespaces     bool
+	WaitForDependencies     bool
+	WaitImage               string
+	BuildPushSecret         string
+	ArgoCDApp               string
+	ArgoCDRepoURL           string
+	ArgoCDPath              string
+	ArgoCDDestNamespace     string
+	PushChart               string
+	Validate                bool
+	KubernetesVersion       string
+	DryRun                  string
+	Context                 string
+	IncludeKinds            []string
+	ExcludeKinds            []string
+	Services                []string
+	StandardLabels          bool
+	AntiAffinity            string
+	PodSecurity             string
+	InspectImages           bool
+	ResolveImageDigests     bool
+	Strict                  bool
+	LoggingSidecar          bool
+	NativeSidecars          bool
+	DetectCluster           bool
+	Quadlet                 string
+	Interactive             bool
+	EnvConfigMapHash        bool
+	OutputLayout            string
+	Reproducible            bool
+	Sign                    bool
+	SignKey                 string
+	EnvFiles                []string
+	Env                     []string
+	Environments            []Environment
+	EnvironmentsFormat      string
+	NamespaceBy             string
+	AllowRemoteExtends      bool
+	RestrictPaths           bool
 }
 
 // IsPodController indicate if the user want to use a controller
@@ -106,6 +195,7 @@ type ServiceConfigGroup []ServiceConfig
 // ServiceConfig holds the basic struct of a container
 // which should not introduce any kubernetes specific struct
 type ServiceConfig struct {
+	OriginalName                  string `compose:""`
 	Name                          string
 	ContainerName                 string
 	Image                         string             `compose:"image"`
@@ -121,6 +211,7 @@ type ServiceConfig struct {
 	VolList                       []string           `compose:"volumes"`
 	NetworkMode                   string             `compose:"network_mode"`
 	Network                       []string           `compose:"network"`
+	NetworkAliases                []string           `compose:"networks.aliases"`
 	Labels                        map[string]string  `compose:"labels"`
 	Annotations                   map[string]string  `compose:""`
 	CPUSet                        string             `compose:"cpuset"`
@@ -128,26 +219,42 @@ type ServiceConfig struct {
 	CPUQuota                      int64              `compose:"cpu_quota"`
 	CPULimit                      int64              `compose:""`
 	CPUReservation                int64              `compose:""`
+	GPUs                          int64              `compose:""`
+	GPUResource                   string             `compose:"kompose.gpu.resource"`
+	RuntimeClassName              string             `compose:"runtime"`
 	CapAdd                        []string           `compose:"cap_add"`
 	CapDrop                       []string           `compose:"cap_drop"`
 	Expose                        []string           `compose:"expose"`
 	ImagePullPolicy               string             `compose:"kompose.image-pull-policy"`
 	Pid                           string             `compose:"pid"`
+	Ipc                           string             `compose:"ipc"`
 	Privileged                    bool               `compose:"privileged"`
 	Restart                       string             `compose:"restart"`
 	User                          string             `compose:"user"`
 	VolumesFrom                   []string           `compose:"volumes_from"`
 	ServiceType                   string             `compose:"kompose.service.type"`
 	ServiceExternalTrafficPolicy  string             `compose:"kompose.service.external-traffic-policy"`
+	ServiceInternalTrafficPolicy  string             `compose:"kompose.service.internal-traffic-policy"`
+	SessionAffinity               string             `compose:"kompose.service.session-affinity"`
+	SessionAffinityTimeout        int32              `compose:"kompose.service.session-affinity.timeout"`
 	NodePortPort                  int32              `compose:"kompose.service.nodeport.port"`
+	NodePortPorts                 map[int32]int32    `compose:"kompose.service.nodeport.port"`
+	LoadBalancerIP                string             `compose:"kompose.service.loadbalancerip"`
+	LoadBalancerClass             string             `compose:"kompose.service.loadbalancer-class"`
+	ServiceAnnotations            map[string]string  `compose:"kompose.service.annotation.*"`
 	StopGracePeriod               string             `compose:"stop_grace_period"`
 	Build                         string             `compose:"build"`
 	BuildArgs                     map[string]*string `compose:"build-args"`
 	ExposeContainerToHost         bool               `compose:"kompose.controller.port.expose"`
+	EnableServiceLinks            *bool              `compose:"kompose.enable-service-links"`
 	ExposeService                 string             `compose:"kompose.service.expose"`
 	ExposeServicePath             string             `compose:"kompose.service.expose.path"`
 	BuildLabels                   map[string]string  `compose:"build-labels"`
 	BuildTarget                   string             `compose:""`
+	BuildStrategy                 string             `compose:"kompose.build.strategy"`
+	BuildBuilderImage             string             `compose:"kompose.build.builder-image"`
+	BuildWebhooks                 []string           `compose:"kompose.build.webhooks"`
+	BuildOutputTag                string             `compose:"kompose.build.output-tag"`
 	ExposeServiceTLS              string             `compose:"kompose.service.expose.tls-secret"`
 	ExposeServiceIngressClassName string             `compose:"kompose.service.expose.ingress-class-name"`
 	ImagePullSecret               string             `compose:"kompose.image-pull-secret"`
@@ -158,20 +265,51 @@ type ServiceConfig struct {
 	DeployMode                    string             `compose:""`
 	VolumeMountSubPath            string             `compose:"kompose.volume.subpath"`
 	// DeployLabels mapping to kubernetes labels
-	DeployLabels             map[string]string         `compose:""`
-	DeployUpdateConfig       types.UpdateConfig        `compose:""`
-	TmpFs                    []string                  `compose:"tmpfs"`
-	Dockerfile               string                    `compose:"dockerfile"`
-	Replicas                 int                       `compose:"replicas"`
-	GroupAdd                 []int64                   `compose:"group_add"`
+	DeployLabels       map[string]string  `compose:""`
+	DeployUpdateConfig types.UpdateConfig `compose:""`
+	TmpFs              []string           `compose:"tmpfs"`
+	Dockerfile         string             `compose:"dockerfile"`
+	Replicas           *int               `compose:"replicas"`
+	Paused             bool               `compose:"kompose.controller.paused"`
+	GroupAdd           []int64            `compose:"group_add"`
+	Sysctls            map[string]string  `compose:"sysctls"`
+	ExtraHosts         types.HostsList    `compose:"extra_hosts"`
+	DNS                []string           `compose:"dns"`
+	DNSSearch          []string           `compose:"dns_search"`
+	DNSOpts            []string           `compose:"dns_opt"`
+	// DependsOn maps the dependencies of the service to their depends_on condition
+	DependsOn map[string]string `compose:"depends_on"`
+	// LoggingDriver and LoggingOptions are the logging driver of the service and its options
+	LoggingDriver  string            `compose:"logging"`
+	LoggingOptions map[string]string `compose:""`
+	// Sidecars are the additional containers of the pods of the service
+	Sidecars []Sidecar `compose:"kompose.sidecar.*"`
+	// InitContainers are the init containers of the pods of the service
+	InitContainers []InitContainer `compose:"kompose.init.containers.*"`
+	// StatefulSet holds the settings of the StatefulSet of the service
+	StatefulSet StatefulSetConfig `compose:"kompose.statefulset.*"`
+	// Route holds the TLS settings of the OpenShift Routes exposing the service
+	Route RouteConfig `compose:"kompose.service.expose.route.*"`
+	// DeploymentStrategy is recreate or rollingupdate, the strategy depends on the volumes when empty
+	DeploymentStrategy string `compose:"kompose.deployment.strategy"`
+	// KubernetesPatches are merged into the generated objects of the service
+	KubernetesPatches        []map[string]interface{}  `compose:"x-kubernetes"`
 	FsGroup                  int64                     `compose:"kompose.security-context.fsgroup"`
 	CronJobSchedule          string                    `compose:"kompose.cronjob.schedule"`
 	CronJobConcurrencyPolicy batchv1.ConcurrencyPolicy `compose:"kompose.cronjob.concurrency_policy"`
 	CronJobBackoffLimit      *int32                    `compose:"kompose.cronjob.backoff_limit"`
+	RestartMaxAttempts       *int32                    `compose:""`
+	JobPodFailurePolicy      *batchv1.PodFailurePolicy `compose:""`
+	JobParallelism           *int32                    `compose:"kompose.job.parallelism"`
+	JobCompletions           *int32                    `compose:"kompose.job.completions"`
+	JobTTLSecondsAfterFinish *int32                    `compose:"kompose.job.ttl_seconds_after_finished"`
+	JobActiveDeadlineSeconds *int64                    `compose:"kompose.job.active_deadline_seconds"`
 	Volumes                  []Volumes                 `compose:""`
 	Secrets                  []types.ServiceSecretConfig
 	HealthChecks             HealthChecks `compose:""`
 	Placement                Placement    `compose:""`
+	AntiAffinity             string       `compose:"kompose.affinity.anti-affinity"`
+	AntiAffinityTopologyKey  string       `compose:"kompose.affinity.topology-key"`
 	//This is for long LONG SYNTAX link(https://docs.docker.com/compose/compose-file/#long-syntax)
 	Configs []types.ServiceConfigObjConfig `compose:""`
 	//This is for SHORT SYNTAX link(https://docs.docker.com/compose/compose-file/#configs)
@@ -181,10 +319,11 @@ type ServiceConfig struct {
 	InGroup               bool
 }
 
-// HealthChecks used to distinguish between liveness and readiness
+// HealthChecks used to distinguish between liveness, readiness and startup
 type HealthChecks struct {
 	Liveness  HealthCheck
 	Readiness HealthCheck
+	Startup   HealthCheck
 }
 
 // HealthCheck the healthcheck configuration for a service
@@ -199,9 +338,56 @@ type HealthCheck struct {
 	Disable     bool
 	HTTPPath    string
 	HTTPPort    int32
+	HTTPHeaders map[string]string
+	HTTPScheme  string
 	TCPPort     int32
 }
 
+// Sidecar is an additional container of the pods of the service, set with the kompose.sidecar.<name>.* labels
+type Sidecar struct {
+	Name    string
+	Image   string
+	Command []string
+	Ports   []Ports
+	Mounts  []SidecarMount
+}
+
+// InitContainer is an init container of the pods of the service, set with the kompose.init.containers.<name>.* labels
+type InitContainer struct {
+	Name    string
+	Image   string
+	Command []string
+	Env     []EnvVar
+	Mounts  []SidecarMount
+}
+
+// StatefulSetConfig holds the settings of the StatefulSet of a service, set with the kompose.statefulset.* labels
+type StatefulSetConfig struct {
+	PodManagementPolicy     v1.PodManagementPolicyType
+	UpdateStrategy          v1.StatefulSetUpdateStrategyType
+	ServiceName             string
+	PVCRetentionWhenDeleted v1.PersistentVolumeClaimRetentionPolicyType
+	PVCRetentionWhenScaled  v1.PersistentVolumeClaimRetentionPolicyType
+}
+
+// RouteConfig holds the TLS settings of the OpenShift Routes of a service, set with the kompose.service.expose.route.* labels,
+// the certificates are the paths of PEM files relative to the compose file
+type RouteConfig struct {
+	Termination                   string
+	InsecureEdgeTerminationPolicy string
+	Certificate                   string
+	Key                           string
+	CACertificate                 string
+	DestinationCACertificate      string
+}
+
+// SidecarMount mounts in a sidecar or an init container the volume mounted at Path in the container of the service
+type SidecarMount struct {
+	Path      string
+	MountPath string
+	ReadOnly  bool
+}
+
 // EnvVar holds the environment variable struct of a container
 type EnvVar struct {
 	Name  string
@@ -214,6 +400,8 @@ type Ports struct {
 	ContainerPort int32
 	HostIP        string
 	Protocol      string // Upper string
+	// AppProtocol is the application protocol of the port, as https
+	AppProtocol string
 }
 
 // ID returns an unique id for this port settings, to avoid conflict
@@ -223,16 +411,20 @@ func (port *Ports) ID() string {
 
 // Volumes holds the volume struct of container
 type Volumes struct {
-	SvcName       string // Service name to which volume is linked
-	MountPath     string // Mountpath extracted from docker-compose file
-	VFrom         string // denotes service name from which volume is coming
-	VolumeName    string // name of volume if provided explicitly
-	Host          string // host machine address
-	Container     string // Mountpath
-	Mode          string // access mode for volume
-	PVCName       string // name of PVC
-	PVCSize       string // PVC size
-	SelectorValue string // Value of the label selector
+	SvcName       string            // Service name to which volume is linked
+	MountPath     string            // Mountpath extracted from docker-compose file
+	VFrom         string            // denotes service name from which volume is coming
+	VolumeName    string            // name of volume if provided explicitly
+	Host          string            // host machine address
+	Container     string            // Mountpath
+	Mode          string            // access mode for volume
+	PVCName       string            // name of PVC
+	PVCSize       string            // PVC size
+	SelectorValue string            // Value of the label selector
+	StorageClass  string            // storage class of the PVC
+	AccessMode    string            // access mode of the PVC
+	DriverOpts    map[string]string // driver_opts of the top level volume
+	CSIDriver     string            // CSI driver of the inline volume replacing the PVC
 }
 
 // Placement holds the placement struct of container
@@ -280,32 +472,61 @@ func (s *ServiceConfig) GetConfigMapKeyFromMeta(name string) (string, error) {
 
 // GetKubernetesUpdateStrategy from compose update_config
 // 1. only apply to Deployment, but the check is not happened here
-// 2. only support `parallelism` and `order`
+// 2. the parallelism is the number of pods updated at once, and the order ("stop-first" by default)
+// says whether the old pods are stopped before the new ones are started
 // return nil if not support
 func (s *ServiceConfig) GetKubernetesUpdateStrategy() *v1.RollingUpdateDeployment {
 	config := s.DeployUpdateConfig
-	r := v1.RollingUpdateDeployment{}
-	if config.Order == "stop-first" {
-		if config.Parallelism != nil {
-			v := intstr.FromInt(cast.ToInt(*config.Parallelism))
-			r.MaxUnavailable = &v
-		}
-
-		v := intstr.FromInt(0)
-		r.MaxSurge = &v
-		return &r
+	if config.Order == "" && config.Parallelism == nil {
+		return nil
 	}
 
+	r := v1.RollingUpdateDeployment{}
+	v := intstr.FromInt(0)
 	if config.Order == "start-first" {
-		if config.Parallelism != nil {
-			v := intstr.FromInt(cast.ToInt(*config.Parallelism))
-			r.MaxSurge = &v
-		}
-		v := intstr.FromInt(0)
+		r.MaxSurge = updateParallelism(config.Parallelism)
 		r.MaxUnavailable = &v
 		return &r
 	}
-	return nil
+
+	r.MaxUnavailable = updateParallelism(config.Parallelism)
+	r.MaxSurge = &v
+	return &r
+}
+
+// GetKubernetesStatefulSetUpdateStrategy from compose update_config
+// the pods of a StatefulSet are always stopped before they are replaced, the parallelism is the
+// maximum number of unavailable pods (MaxUnavailableStatefulSet feature gate)
+// return nil if not support
+func (s *ServiceConfig) GetKubernetesStatefulSetUpdateStrategy() *v1.StatefulSetUpdateStrategy {
+	config := s.DeployUpdateConfig
+	if config.Parallelism == nil {
+		return nil
+	}
+	return &v1.StatefulSetUpdateStrategy{
+		Type: v1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &v1.RollingUpdateStatefulSetStrategy{
+			MaxUnavailable: updateParallelism(config.Parallelism),
+		},
+	}
+}
+
+// GetKubernetesMinReadySeconds from compose update_config, the delay between the updates of the
+// groups of pods is the time a new pod has to be ready before the next ones are updated
+func (s *ServiceConfig) GetKubernetesMinReadySeconds() int32 {
+	return int32(time.Duration(s.DeployUpdateConfig.Delay).Seconds())
+}
+
+// updateParallelism is the number of pods updated at once, all of them with 0
+func updateParallelism(parallelism *uint64) *intstr.IntOrString {
+	if parallelism == nil {
+		return nil
+	}
+	v := intstr.FromInt(cast.ToInt(*parallelism))
+	if *parallelism == 0 {
+		v = intstr.FromString("100%")
+	}
+	return &v
 }
 
 // GetOSUpdateStrategy ...
@@ -325,7 +546,8 @@ func (s *ServiceConfig) GetOSUpdateStrategy() *deployapi.RollingDeploymentStrate
 			v := intstr.FromInt(cast.ToInt(*config.Parallelism))
 			r.MaxUnavailable = &v
 		}
-		*r.MaxSurge = intstr.FromInt(0)
+		v := intstr.FromInt(0)
+		r.MaxSurge = &v
 		r.UpdatePeriodSeconds = &interval
 		return &r
 	}
@@ -0,0 +1,104 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kompose is the library entry point of kompose. It converts compose projects to the
+// objects of a provider without printing them, writing files or exiting the program, so that
+// other tools can embed the conversion.
+package kompose
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kubernetes/kompose/pkg/app"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/utils/redact"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ConvertOptions are the options of a conversion, the ones of the convert command
+type ConvertOptions = kobject.ConvertOptions
+
+// Report describes a conversion
+type Report struct {
+	// Renames lists the resources renamed to be valid Kubernetes names
+	Renames []kobject.Rename
+	// Warnings lists the warnings logged during the conversion, like the ignored compose keys
+	Warnings []string
+}
+
+// conversionLock serializes the conversions, they swap the hooks of the standard logger
+var conversionLock sync.Mutex
+
+// Convert loads the compose files of the options and transforms them to the objects of the provider.
+// The output options are ignored, the objects are returned instead of being printed. The projects are
+// converted one after the other, the conversion stops between two projects when the context is done.
+func Convert(ctx context.Context, opt ConvertOptions) (objects []runtime.Object, report Report, err error) {
+	opt.OutFile = ""
+	opt.ToStdout = false
+	if opt.Provider == "" {
+		opt.Provider = app.DefaultProvider
+	}
+	if err := app.ValidateComposeFile(&opt); err != nil {
+		return nil, report, err
+	}
+	if err := app.ValidateControllers(&opt); err != nil {
+		return nil, report, err
+	}
+
+	conversionLock.Lock()
+	defer conversionLock.Unlock()
+
+	logger := log.StandardLogger()
+	previous := make(log.LevelHooks)
+	for level, hooks := range logger.Hooks {
+		previous[level] = append(previous[level], hooks...)
+	}
+	defer logger.ReplaceHooks(previous)
+	// the values of the environment and of the secrets are masked in the report, unless redact.Show is set
+	logger.AddHook(redact.Hook{})
+	collector := &app.WarningCollector{}
+	logger.AddHook(collector)
+	defer func() { report.Warnings = collector.Warnings() }()
+
+	projects := opt.Projects
+	if len(projects) == 0 {
+		projects = []kobject.Project{{InputFiles: opt.InputFiles}}
+	}
+	for _, project := range projects {
+		if err := ctx.Err(); err != nil {
+			return nil, report, err
+		}
+
+		projectOpt := opt
+		projectOpt.InputFiles = project.InputFiles
+		if project.Name != "" {
+			projectOpt.Namespace = project.Name
+		}
+		_, projectObjects, renames, err := app.ConvertProject(project.Name, projectOpt)
+		if err != nil {
+			if project.Name != "" {
+				err = errors.Wrapf(err, "project %q", project.Name)
+			}
+			return nil, report, err
+		}
+		objects = append(objects, projectObjects...)
+		report.Renames = append(report.Renames, renames...)
+	}
+	return objects, report, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kompose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeComposeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "compose.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Unable to write the compose file: %v", err)
+	}
+	return path
+}
+
+func TestConvert(t *testing.T) {
+	file := writeComposeFile(t, `
+services:
+  web:
+    image: nginx
+    ports:
+      - "80:80"
+    restart: unless-stopped
+`)
+	objects, report, err := Convert(context.Background(), ConvertOptions{InputFiles: []string{file}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	kinds := map[string]bool{}
+	for _, obj := range objects {
+		kinds[obj.GetObjectKind().GroupVersionKind().Kind] = true
+	}
+	if !kinds["Deployment"] || !kinds["Service"] {
+		t.Errorf("Expected a Deployment and a Service, got %v", kinds)
+	}
+
+	found := false
+	for _, warning := range report.Warnings {
+		if strings.Contains(warning, "unless-stopped") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a warning about the restart policy, got %v", report.Warnings)
+	}
+}
+
+func TestConvertError(t *testing.T) {
+	file := writeComposeFile(t, `
+services:
+  web:
+    image: nginx
+    secrets:
+      - token
+secrets:
+  token:
+    file: ./missing.txt
+`)
+	_, _, err := Convert(context.Background(), ConvertOptions{InputFiles: []string{file}})
+	if err == nil || !strings.Contains(err.Error(), "missing.txt") {
+		t.Errorf("Expected the secret file error, got %v", err)
+	}
+}
+
+func TestConvertCanceled(t *testing.T) {
+	file := writeComposeFile(t, `
+services:
+  web:
+    image: nginx
+`)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := Convert(ctx, ConvertOptions{InputFiles: []string{file}}); err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
@@ -19,8 +19,11 @@ package compose
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -31,11 +34,15 @@ import (
 	"github.com/google/shlex"
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	"github.com/kubernetes/kompose/pkg/utils/redact"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
 	batchv1 "k8s.io/api/batch/v1"
 	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // StdinData is data bytes read from stdin
@@ -43,6 +50,11 @@ var StdinData []byte
 
 // Compose is docker compose file loader, implements Loader interface
 type Compose struct {
+	// RemoteResources fetches the extended files given as http(s) URLs or in git repositories
+	RemoteResources bool
+	// RestrictPaths rejects the extended files, the env files, the bind mounts and the files of the secrets
+	// and the configs outside of the working directory, for the compose files which aren't trusted
+	RestrictPaths bool
 }
 
 // checkUnsupportedKey checks if compose-go project contains
@@ -59,14 +71,7 @@ func checkUnsupportedKey(composeProject *types.Project) []string {
 		"CPUSet":        false,
 		"CPUShares":     false,
 		"Devices":       false,
-		"DependsOn":     false,
-		"DNS":           false,
-		"DNSSearch":     false,
-		"EnvFile":       false,
 		"ExternalLinks": false,
-		"ExtraHosts":    false,
-		"Ipc":           false,
-		"Logging":       false,
 		"MacAddress":    false,
 		"MemSwapLimit":  false,
 		"NetworkMode":   false,
@@ -75,10 +80,7 @@ func checkUnsupportedKey(composeProject *types.Project) []string {
 		"StopSignal":    false,
 		"VolumeDriver":  false,
 		"Uts":           false,
-		"ReadOnly":      false,
 		"Ulimits":       false,
-		"Net":           false,
-		"Sysctls":       false,
 		//"Networks":    false, // We shall be spporting network now. There are special checks for Network in checkUnsupportedKey function
 		"Links": false,
 	}
@@ -91,11 +93,6 @@ func checkUnsupportedKey(composeProject *types.Project) []string {
 		log.Debug("Default network found")
 	}
 
-	// Root level volumes are not yet supported
-	if len(composeProject.Volumes) > 0 {
-		keysFound = append(keysFound, "root level volumes")
-	}
-
 	for _, serviceConfig := range composeProject.AllServices() {
 		// this reflection is used in check for empty arrays
 		val := reflect.ValueOf(serviceConfig)
@@ -123,6 +120,12 @@ func checkUnsupportedKey(composeProject *types.Project) []string {
 						}
 					}
 
+					// the host network is converted to hostNetwork, and the network of another service to a
+					// container of its pod
+					if f.Name() == "NetworkMode" && (serviceConfig.NetworkMode == "host" || serviceConfig.NetworkMode == "bridge" || strings.HasPrefix(serviceConfig.NetworkMode, "service:")) {
+						continue
+					}
+
 					if linksArray := val.FieldByName(f.Name()); f.Name() == "Links" && linksArray.Kind() == reflect.Slice {
 						//Links has "SERVICE:ALIAS" style, we don't support SERVICE != ALIAS
 						findUnsupportedLinksFlag := false
@@ -150,44 +153,180 @@ func checkUnsupportedKey(composeProject *types.Project) []string {
 	return keysFound
 }
 
-// LoadFile loads a compose file into KomposeObject
-func (c *Compose) LoadFile(files []string, profiles []string, noInterpolate bool) (kobject.KomposeObject, error) {
-	// Gather the working directory
-	workingDir, err := transformer.GetComposeFileDir(files)
+// LoadFile loads a compose file into KomposeObject, the project name is
+// taken from the compose file or its directory when empty. The relative paths
+// are resolved against workingDir, the directory of the first file when empty.
+// When services are given, only them, the services they depend on and the
+// resources they reference are loaded. The variables are interpolated with the
+// environment, the env files, or the .env file of workingDir without them, and
+// the KEY=VALUE of env overriding them.
+func (c *Compose) LoadFile(projectName string, files []string, workingDir string, profiles []string, services []string, noInterpolate bool, envFiles []string, env []string) (kobject.KomposeObject, error) {
+	project, err := c.loadProject(projectName, files, workingDir, profiles, services, noInterpolate, envFiles, env)
 	if err != nil {
 		return kobject.KomposeObject{}, err
 	}
 
-	projectOptions, err := cli.NewProjectOptions(
-		files, cli.WithOsEnv,
+	// Finding 0 services means two things:
+	// 1. The compose project is empty
+	// 2. The profile that is configured in the compose project is different than the one defined in Kompose convert options
+	// In both cases we should provide the user with a warning indicating that we didn't find any service.
+	if len(project.Services) == 0 {
+		log.Warning("No service selected. The profile specified in services of your compose yaml may not exist.")
+	}
+
+	for _, key := range checkUnsupportedKey(project) {
+		dropped.Warnf("Unsupported %s key - ignoring", key)
+	}
+
+	komposeObject, err := dockerComposeToKomposeMapping(project)
+	if err != nil {
+		return kobject.KomposeObject{}, err
+	}
+	return komposeObject, nil
+}
+
+// MergedModel returns the compose model of the files merged with the override rules of the compose
+// specification, as YAML, before its conversion. The arguments are the ones of LoadFile.
+func (c *Compose) MergedModel(projectName string, files []string, workingDir string, profiles []string, services []string, noInterpolate bool, envFiles []string, env []string) ([]byte, error) {
+	project, err := c.loadProject(projectName, files, workingDir, profiles, services, noInterpolate, envFiles, env)
+	if err != nil {
+		return nil, err
+	}
+	redactProject(project)
+	return project.MarshalYAML()
+}
+
+// redactProject masks the values of the environment, of the build arguments and of the secrets of the
+// project, unless they are shown with --show-secrets
+func redactProject(project *types.Project) {
+	for name, service := range project.Services {
+		redactMapping(service.Environment)
+		if service.Build != nil {
+			redactMapping(service.Build.Args)
+		}
+		project.Services[name] = service
+	}
+	for name, secret := range project.Secrets {
+		secret.Content = redact.Value(secret.Content)
+		project.Secrets[name] = secret
+	}
+}
+
+func redactMapping(mapping types.MappingWithEquals) {
+	for key, value := range mapping {
+		if value != nil {
+			masked := redact.Value(*value)
+			mapping[key] = &masked
+		}
+	}
+}
+
+// loadProject loads the compose files as a single project, the later files override the earlier ones
+// with the merge rules of the compose specification, !reset and !override included. The remote extended
+// files are fetched with RemoteResources, and the files are kept in the working directory with RestrictPaths.
+func (c *Compose) loadProject(projectName string, files []string, workingDir string, profiles []string, services []string, noInterpolate bool, envFiles []string, env []string) (*types.Project, error) {
+	// Gather the working directory
+	if workingDir == "" {
+		var err error
+		if workingDir, err = transformer.GetComposeFileDir(files); err != nil {
+			return nil, err
+		}
+	}
+
+	options := []cli.ProjectOptionsFn{
+		cli.WithOsEnv,
 		cli.WithWorkingDirectory(workingDir),
 		cli.WithInterpolation(!noInterpolate),
 		cli.WithProfiles(profiles),
-		cli.WithEnvFiles([]string{}...),
+		cli.WithEnvFiles(envFiles...),
 		cli.WithDotEnv,
-	)
+		cli.WithEnv(env),
+		// the extends of the services are resolved by compose-go, across the files, the remote ones when allowed
+		cli.WithResourceLoader(remoteResourceLoader{allowed: c.RemoteResources}),
+	}
+	if c.RestrictPaths {
+		// the env files of the included files are read before their paths can be checked
+		if err := checkNoInclude(files); err != nil {
+			return nil, err
+		}
+		// the env files are read once their paths are checked
+		options = append(options, cli.WithResourceLoader(restrictedResourceLoader{dir: workingDir}), cli.WithoutEnvironmentResolution)
+	}
+	if projectName != "" {
+		options = append(options, cli.WithName(projectName))
+	}
+	projectOptions, err := cli.NewProjectOptions(files, options...)
 	if err != nil {
-		return kobject.KomposeObject{}, errors.Wrap(err, "Unable to create compose options")
+		return nil, errors.Wrap(err, "Unable to create compose options")
 	}
 
 	project, err := cli.ProjectFromOptions(context.Background(), projectOptions)
 	if err != nil {
-		return kobject.KomposeObject{}, errors.Wrap(err, "Unable to load files")
+		return nil, errors.Wrap(err, "Unable to load files")
+	}
+	if c.RestrictPaths {
+		if err := checkRestrictedPaths(project, workingDir); err != nil {
+			return nil, err
+		}
+		if project, err = project.WithServicesEnvironmentResolved(false); err != nil {
+			return nil, errors.Wrap(err, "Unable to load files")
+		}
 	}
 
-	// Finding 0 services means two things:
-	// 1. The compose project is empty
-	// 2. The profile that is configured in the compose project is different than the one defined in Kompose convert options
-	// In both cases we should provide the user with a warning indicating that we didn't find any service.
-	if len(project.Services) == 0 {
-		log.Warning("No service selected. The profile specified in services of your compose yaml may not exist.")
+	if len(services) > 0 {
+		project, err = project.WithSelectedServices(services)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to select the services")
+		}
+		project = project.WithoutUnnecessaryResources()
 	}
 
-	komposeObject, err := dockerComposeToKomposeMapping(project)
-	if err != nil {
-		return kobject.KomposeObject{}, err
+	return project, nil
+}
+
+// checkNoInclude returns an error when a compose file includes other files
+func checkNoInclude(files []string) error {
+	for _, file := range files {
+		data, err := ReadFile(file)
+		if err != nil {
+			return errors.Wrap(err, "Unable to load files")
+		}
+		var model struct {
+			Include any `yaml:"include"`
+		}
+		if err := yaml.Unmarshal(data, &model); err == nil && model.Include != nil {
+			return errors.Errorf("the compose file %s includes other files, which can't be checked to stay in the directory of the compose files", file)
+		}
 	}
-	return komposeObject, nil
+	return nil
+}
+
+// checkRestrictedPaths returns an error when an env file, a bind mount, or the file of a secret or a config
+// of the project is outside of dir
+func checkRestrictedPaths(project *types.Project, dir string) error {
+	for _, service := range project.Services {
+		for _, envFile := range service.EnvFiles {
+			if !transformer.InDir(dir, envFile.Path) {
+				return errors.Errorf("the env file %s of service %q is outside of the directory of the compose files", envFile.Path, service.Name)
+			}
+		}
+		for _, volume := range service.Volumes {
+			if volume.Type == types.VolumeTypeBind && !transformer.InDir(dir, volume.Source) {
+				return errors.Errorf("the bind mount %s of service %q is outside of the directory of the compose files", volume.Source, service.Name)
+			}
+		}
+	}
+	for name, secret := range project.Secrets {
+		if secret.File != "" && !transformer.InDir(dir, secret.File) {
+			return errors.Errorf("the file %s of secret %q is outside of the directory of the compose files", secret.File, name)
+		}
+	}
+	for name, config := range project.Configs {
+		if config.File != "" && !transformer.InDir(dir, config.File) {
+			return errors.Errorf("the file %s of config %q is outside of the directory of the compose files", config.File, name)
+		}
+	}
+	return nil
 }
 
 func loadPlacement(placement types.Placement) kobject.Placement {
@@ -259,6 +398,11 @@ func convertDockerLabel(dockerLabel string) (string, error) {
 func loadVolumes(volumes []types.ServiceVolumeConfig) []string {
 	var volArray []string
 	for _, vol := range volumes {
+		// the tmpfs are loaded with the tmpfs key
+		if vol.Type == types.VolumeTypeTmpfs {
+			continue
+		}
+
 		// There will *always* be Source when parsing
 		v := vol.Source
 
@@ -275,6 +419,22 @@ func loadVolumes(volumes []types.ServiceVolumeConfig) []string {
 	return volArray
 }
 
+// loadTmpfsVolumes converts the volumes of type tmpfs to the short syntax of the tmpfs key, with their size
+func loadTmpfsVolumes(volumes []types.ServiceVolumeConfig) []string {
+	var tmpfs []string
+	for _, vol := range volumes {
+		if vol.Type != types.VolumeTypeTmpfs {
+			continue
+		}
+		v := vol.Target
+		if vol.Tmpfs != nil && vol.Tmpfs.Size > 0 {
+			v = fmt.Sprintf("%s:size=%d", v, vol.Tmpfs.Size)
+		}
+		tmpfs = append(tmpfs, v)
+	}
+	return tmpfs
+}
+
 // Convert Compose ports to kobject.Ports
 // expose ports will be treated as TCP ports
 func loadPorts(ports []types.ServicePortConfig, expose []string) []kobject.Ports {
@@ -288,6 +448,7 @@ func loadPorts(ports []types.ServicePortConfig, expose []string) []kobject.Ports
 			ContainerPort: int32(port.Target),
 			HostIP:        port.HostIP,
 			Protocol:      strings.ToUpper(port.Protocol),
+			AppProtocol:   port.AppProtocol,
 		})
 		exist[cast.ToString(port.Target)+port.Protocol] = true
 	}
@@ -314,46 +475,97 @@ func loadPorts(ports []types.ServicePortConfig, expose []string) []kobject.Ports
 	return komposePorts
 }
 
+// healthCheckLabels are the keys of the labels defining a probe
+type healthCheckLabels struct {
+	disable, test, interval, timeout, retries, startPeriod, httpGetPath, httpGetPort, httpHeaders, httpScheme, tcpPort string
+}
+
+var readinessLabels = healthCheckLabels{
+	disable:     HealthCheckReadinessDisable,
+	test:        HealthCheckReadinessTest,
+	interval:    HealthCheckReadinessInterval,
+	timeout:     HealthCheckReadinessTimeout,
+	retries:     HealthCheckReadinessRetries,
+	startPeriod: HealthCheckReadinessStartPeriod,
+	httpGetPath: HealthCheckReadinessHTTPGetPath,
+	httpGetPort: HealthCheckReadinessHTTPGetPort,
+	httpHeaders: HealthCheckReadinessHTTPHeaders,
+	httpScheme:  HealthCheckReadinessHTTPScheme,
+	tcpPort:     HealthCheckReadinessTCPPort,
+}
+
+var startupLabels = healthCheckLabels{
+	disable:     HealthCheckStartupDisable,
+	test:        HealthCheckStartupTest,
+	interval:    HealthCheckStartupInterval,
+	timeout:     HealthCheckStartupTimeout,
+	retries:     HealthCheckStartupRetries,
+	startPeriod: HealthCheckStartupStartPeriod,
+	httpGetPath: HealthCheckStartupHTTPGetPath,
+	httpGetPort: HealthCheckStartupHTTPGetPort,
+	httpHeaders: HealthCheckStartupHTTPHeaders,
+	httpScheme:  HealthCheckStartupHTTPScheme,
+	tcpPort:     HealthCheckStartupTCPPort,
+}
+
 /*
 	Convert the HealthCheckConfig as designed by Docker to
 
 a Kubernetes-compatible format.
 */
 func parseHealthCheckReadiness(labels types.Labels) (kobject.HealthCheck, error) {
+	return parseHealthCheckLabels(readinessLabels, labels)
+}
+
+// parseHealthCheckStartup parses the startup probe labels
+func parseHealthCheckStartup(labels types.Labels) (kobject.HealthCheck, error) {
+	return parseHealthCheckLabels(startupLabels, labels)
+}
+
+func parseHealthCheckLabels(keys healthCheckLabels, labels types.Labels) (kobject.HealthCheck, error) {
 	var test []string
-	var httpPath string
+	var httpPath, httpScheme string
+	var httpHeaders map[string]string
 	var httpPort, tcpPort, timeout, interval, retries, startPeriod int32
 	var disable bool
 
 	for key, value := range labels {
 		switch key {
-		case HealthCheckReadinessDisable:
+		case keys.disable:
 			disable = cast.ToBool(value)
-		case HealthCheckReadinessTest:
+		case keys.test:
 			if len(value) > 0 {
 				test, _ = shlex.Split(value)
 			}
-		case HealthCheckReadinessHTTPGetPath:
+		case keys.httpGetPath:
 			httpPath = value
-		case HealthCheckReadinessHTTPGetPort:
+		case keys.httpGetPort:
 			httpPort = cast.ToInt32(value)
-		case HealthCheckReadinessTCPPort:
+		case keys.httpHeaders:
+			headers, err := handleHTTPHeaders(value)
+			if err != nil {
+				return kobject.HealthCheck{}, errors.Wrap(err, "unable to parse health check http headers")
+			}
+			httpHeaders = headers
+		case keys.httpScheme:
+			httpScheme = strings.ToUpper(value)
+		case keys.tcpPort:
 			tcpPort = cast.ToInt32(value)
-		case HealthCheckReadinessInterval:
+		case keys.interval:
 			parse, err := time.ParseDuration(value)
 			if err != nil {
 				return kobject.HealthCheck{}, errors.Wrap(err, "unable to parse health check interval variable")
 			}
 			interval = int32(parse.Seconds())
-		case HealthCheckReadinessTimeout:
+		case keys.timeout:
 			parse, err := time.ParseDuration(value)
 			if err != nil {
 				return kobject.HealthCheck{}, errors.Wrap(err, "unable to parse health check timeout variable")
 			}
 			timeout = int32(parse.Seconds())
-		case HealthCheckReadinessRetries:
+		case keys.retries:
 			retries = cast.ToInt32(value)
-		case HealthCheckReadinessStartPeriod:
+		case keys.startPeriod:
 			parse, err := time.ParseDuration(value)
 			if err != nil {
 				return kobject.HealthCheck{}, errors.Wrap(err, "unable to parse health check startPeriod variable")
@@ -377,6 +589,8 @@ func parseHealthCheckReadiness(labels types.Labels) (kobject.HealthCheck, error)
 		Test:        test,
 		HTTPPath:    httpPath,
 		HTTPPort:    httpPort,
+		HTTPHeaders: httpHeaders,
+		HTTPScheme:  httpScheme,
 		TCPPort:     tcpPort,
 		Timeout:     timeout,
 		Interval:    interval,
@@ -386,6 +600,40 @@ func parseHealthCheckReadiness(labels types.Labels) (kobject.HealthCheck, error)
 	}, nil
 }
 
+// hasHealthCheckHandler returns whether the probe runs a command, an HTTP GET or a TCP check
+func hasHealthCheckHandler(healthCheck kobject.HealthCheck) bool {
+	return len(healthCheck.Test) > 0 || (healthCheck.HTTPPath != "" && healthCheck.HTTPPort != 0) || healthCheck.TCPPort != 0
+}
+
+// inheritHealthCheckHandler sets the command, HTTP GET or TCP check of the probe to the ones of another probe
+func inheritHealthCheckHandler(healthCheck *kobject.HealthCheck, from kobject.HealthCheck) {
+	healthCheck.Test = from.Test
+	healthCheck.HTTPPath = from.HTTPPath
+	healthCheck.HTTPPort = from.HTTPPort
+	healthCheck.HTTPHeaders = from.HTTPHeaders
+	healthCheck.HTTPScheme = from.HTTPScheme
+	healthCheck.TCPPort = from.TCPPort
+}
+
+// startupFromLiveness converts the start_interval of a compose health check to a startup probe:
+// the liveness check runs every start_interval until it succeeds, for at most start_period.
+// The liveness probe then starts once the startup probe succeeded, without initial delay.
+func startupFromLiveness(composeHealthCheck types.HealthCheckConfig, liveness *kobject.HealthCheck) kobject.HealthCheck {
+	if composeHealthCheck.StartInterval == nil || liveness.StartPeriod == 0 {
+		return kobject.HealthCheck{}
+	}
+	startInterval := int32(time.Duration(*composeHealthCheck.StartInterval).Seconds())
+	if startInterval < 1 {
+		startInterval = 1
+	}
+	startup := *liveness
+	startup.Interval = startInterval
+	startup.Retries = (liveness.StartPeriod + startInterval - 1) / startInterval
+	startup.StartPeriod = 0
+	liveness.StartPeriod = 0
+	return startup
+}
+
 /*
 	Convert the HealthCheckConfig as designed by Docker to
 
@@ -394,7 +642,8 @@ a Kubernetes-compatible format.
 func parseHealthCheck(composeHealthCheck types.HealthCheckConfig, labels types.Labels) (kobject.HealthCheck, error) {
 	var httpPort, tcpPort, timeout, interval, retries, startPeriod int32
 	var test []string
-	var httpPath string
+	var httpPath, httpScheme string
+	var httpHeaders map[string]string
 
 	// Here we convert the timeout from 1h30s (example) to 36030 seconds.
 	if composeHealthCheck.Timeout != nil {
@@ -435,6 +684,14 @@ func parseHealthCheck(composeHealthCheck types.HealthCheckConfig, labels types.L
 			httpPath = value
 		case HealthCheckLivenessHTTPGetPort:
 			httpPort = cast.ToInt32(value)
+		case HealthCheckLivenessHTTPHeaders:
+			headers, err := handleHTTPHeaders(value)
+			if err != nil {
+				return kobject.HealthCheck{}, errors.Wrap(err, "unable to parse health check http headers")
+			}
+			httpHeaders = headers
+		case HealthCheckLivenessHTTPScheme:
+			httpScheme = strings.ToUpper(value)
 		case HealthCheckLivenessTCPPort:
 			tcpPort = cast.ToInt32(value)
 		}
@@ -446,6 +703,8 @@ func parseHealthCheck(composeHealthCheck types.HealthCheckConfig, labels types.L
 		TCPPort:     tcpPort,
 		HTTPPath:    httpPath,
 		HTTPPort:    httpPort,
+		HTTPHeaders: httpHeaders,
+		HTTPScheme:  httpScheme,
 		Timeout:     timeout,
 		Interval:    interval,
 		Retries:     retries,
@@ -459,12 +718,50 @@ func dockerComposeToKomposeMapping(composeObject *types.Project) (kobject.Kompos
 		ServiceConfigs: make(map[string]kobject.ServiceConfig),
 		LoadedFrom:     "compose",
 		Secrets:        composeObject.Secrets,
+		ProjectName:    composeObject.Name,
+	}
+
+	for key, network := range composeObject.Networks {
+		if !network.Internal {
+			continue
+		}
+		netName := network.Name
+		if netName == "" {
+			netName = key
+		}
+		normalizedNetworkName, err := normalizeNetworkNames(netName)
+		if err != nil {
+			return kobject.KomposeObject{}, errors.Wrap(err, "Unable to normalize network name")
+		}
+		if komposeObject.InternalNetworks == nil {
+			komposeObject.InternalNetworks = map[string]bool{}
+		}
+		komposeObject.InternalNetworks[normalizedNetworkName] = true
+	}
+
+	kubernetesPatches, err := parseKubernetesPatches(composeObject.Extensions[KubernetesExtension])
+	if err != nil {
+		return kobject.KomposeObject{}, errors.Wrapf(err, "invalid top level %s", KubernetesExtension)
 	}
+	komposeObject.KubernetesPatches = kubernetesPatches
 
 	// Step 2. Parse through the object and convert it to kobject.KomposeObject!
 	// Here we "clean up" the service configuration so we return something that includes
 	// all relevant information as well as avoid the unsupported keys as well.
+	normalizedNames := make(map[string]string)
 	for _, composeServiceConfig := range composeObject.Services {
+		// The x-kompose extension is an alternative to the kompose labels
+		labels, err := mergeKomposeExtension(composeServiceConfig.Labels, composeServiceConfig.Extensions[KomposeExtension])
+		if err != nil {
+			return kobject.KomposeObject{}, errors.Wrapf(err, "invalid %s on service %q", KomposeExtension, composeServiceConfig.Name)
+		}
+		composeServiceConfig.Labels = labels
+
+		kubernetesPatches, err := parseKubernetesPatches(composeServiceConfig.Extensions[KubernetesExtension])
+		if err != nil {
+			return kobject.KomposeObject{}, errors.Wrapf(err, "invalid %s on service %q", KubernetesExtension, composeServiceConfig.Name)
+		}
+
 		// Standard import
 		// No need to modify before importation
 		name := parseResourceName(composeServiceConfig.Name, composeServiceConfig.Labels)
@@ -490,6 +787,12 @@ func dockerComposeToKomposeMapping(composeObject *types.Project) (kobject.Kompos
 		serviceConfig.DomainName = composeServiceConfig.DomainName
 		serviceConfig.Secrets = composeServiceConfig.Secrets
 		serviceConfig.NetworkMode = composeServiceConfig.NetworkMode
+		serviceConfig.Pid = composeServiceConfig.Pid
+		serviceConfig.Ipc = composeServiceConfig.Ipc
+		// runc is the default runtime of docker, the runtime class of the cluster is kept
+		if composeServiceConfig.Runtime != "runc" {
+			serviceConfig.RuntimeClassName = composeServiceConfig.Runtime
+		}
 
 		if composeServiceConfig.StopGracePeriod != nil {
 			serviceConfig.StopGracePeriod = composeServiceConfig.StopGracePeriod.String()
@@ -503,7 +806,20 @@ func dockerComposeToKomposeMapping(composeObject *types.Project) (kobject.Kompos
 			return kobject.KomposeObject{}, err
 		}
 
-		serviceConfig.Restart = composeServiceConfig.Restart
+		restart, maxAttempts, err := handleRestartMaxAttempts(composeServiceConfig.Restart)
+		if err != nil {
+			return kobject.KomposeObject{}, errors.Wrapf(err, "invalid restart policy in service %s", name)
+		}
+		serviceConfig.Restart = restart
+		serviceConfig.RestartMaxAttempts = maxAttempts
+
+		// scale: is only merged into deploy.replicas by compose-go when deploy is set
+		serviceConfig.Replicas = composeServiceConfig.Scale
+
+		if composeServiceConfig.Logging != nil {
+			serviceConfig.LoggingDriver = composeServiceConfig.Logging.Driver
+			serviceConfig.LoggingOptions = composeServiceConfig.Logging.Options
+		}
 
 		if composeServiceConfig.Deploy != nil {
 			// Deploy keys
@@ -516,11 +832,15 @@ func dockerComposeToKomposeMapping(composeObject *types.Project) (kobject.Kompos
 			// see: https://docs.docker.com/compose/compose-file/#restart_policy
 			if composeServiceConfig.Deploy.RestartPolicy != nil {
 				serviceConfig.Restart = composeServiceConfig.Deploy.RestartPolicy.Condition
+				if composeServiceConfig.Deploy.RestartPolicy.MaxAttempts != nil {
+					maxAttempts := cast.ToInt32(*composeServiceConfig.Deploy.RestartPolicy.MaxAttempts)
+					serviceConfig.RestartMaxAttempts = &maxAttempts
+				}
 			}
 
 			// replicas:
 			if composeServiceConfig.Deploy.Replicas != nil {
-				serviceConfig.Replicas = int(*composeServiceConfig.Deploy.Replicas)
+				serviceConfig.Replicas = composeServiceConfig.Deploy.Replicas
 			}
 
 			// placement:
@@ -544,6 +864,24 @@ func dockerComposeToKomposeMapping(composeObject *types.Project) (kobject.Kompos
 			}
 		}
 
+		// HealthCheck Startup, the labels take precedence over the start_interval of the health check
+		var startup, errStartup = parseHealthCheckStartup(composeServiceConfig.Labels)
+		if errStartup != nil {
+			return kobject.KomposeObject{}, errors.Wrap(errStartup, "Unable to parse health check")
+		}
+		if startup.Disable {
+			startup = kobject.HealthCheck{}
+		} else if reflect.DeepEqual(startup, kobject.HealthCheck{}) && composeServiceConfig.HealthCheck != nil && !composeServiceConfig.HealthCheck.Disable {
+			startup = startupFromLiveness(*composeServiceConfig.HealthCheck, &serviceConfig.HealthChecks.Liveness)
+		} else if !reflect.DeepEqual(startup, kobject.HealthCheck{}) && !hasHealthCheckHandler(startup) {
+			// the startup labels only tuning the probe run the check of the liveness probe
+			if !hasHealthCheckHandler(serviceConfig.HealthChecks.Liveness) {
+				return kobject.KomposeObject{}, errors.Errorf("the startup probe of service %q needs a test, an http_get_path and http_get_port or a tcp_port, or a healthcheck", name)
+			}
+			inheritHealthCheckHandler(&startup, serviceConfig.HealthChecks.Liveness)
+		}
+		serviceConfig.HealthChecks.Startup = startup
+
 		// HealthCheck Readiness
 		var readiness, errReadiness = parseHealthCheckReadiness(composeServiceConfig.Labels)
 		if !readiness.Disable {
@@ -583,6 +921,10 @@ func dockerComposeToKomposeMapping(composeObject *types.Project) (kobject.Kompos
 		// Again, in v3, we use the "long syntax" for volumes in terms of parsing
 		// https://docs.docker.com/compose/compose-file/#long-syntax-3
 		serviceConfig.VolList = loadVolumes(composeServiceConfig.Volumes)
+		serviceConfig.TmpFs = append(append([]string{}, serviceConfig.TmpFs...), loadTmpfsVolumes(composeServiceConfig.Volumes)...)
+		if err := validateKomposeLabels(composeServiceConfig.Labels); err != nil {
+			return kobject.KomposeObject{}, errors.Wrapf(err, "invalid labels on service %q", composeServiceConfig.Name)
+		}
 		if err := parseKomposeLabels(composeServiceConfig.Labels, &serviceConfig); err != nil {
 			return kobject.KomposeObject{}, err
 		}
@@ -592,6 +934,20 @@ func dockerComposeToKomposeMapping(composeObject *types.Project) (kobject.Kompos
 			log.Infof("Service name in docker-compose has been changed from %q to %q", name, normalizeServiceNames(name))
 		}
 
+		originalName := composeServiceConfig.Name
+		if labelValue, ok := composeServiceConfig.Labels[LabelNameOverride]; ok {
+			originalName = labelValue
+		}
+		if normalizeServiceNames(name) != originalName {
+			serviceConfig.OriginalName = originalName
+		}
+
+		// Two services normalized to the same name would silently overwrite each other
+		if other, ok := normalizedNames[normalizeServiceNames(name)]; ok {
+			return kobject.KomposeObject{}, fmt.Errorf("services %q and %q are both converted to %q", other, originalName, normalizeServiceNames(name))
+		}
+		normalizedNames[normalizeServiceNames(name)] = originalName
+
 		serviceConfig.Configs = composeServiceConfig.Configs
 		serviceConfig.ConfigsMetaData = composeObject.Configs
 
@@ -601,6 +957,20 @@ func dockerComposeToKomposeMapping(composeObject *types.Project) (kobject.Kompos
 			return kobject.KomposeObject{}, errors.Wrap(err, "GroupAdd should be mentioned in gid format, not a group name")
 		}
 		serviceConfig.GroupAdd = groupAdd
+		serviceConfig.Sysctls = composeServiceConfig.Sysctls
+		serviceConfig.ExtraHosts = composeServiceConfig.ExtraHosts
+		serviceConfig.KubernetesPatches = kubernetesPatches
+
+		for dependency, config := range composeServiceConfig.DependsOn {
+			if serviceConfig.DependsOn == nil {
+				serviceConfig.DependsOn = map[string]string{}
+			}
+			serviceConfig.DependsOn[normalizeServiceNames(dependency)] = config.Condition
+		}
+
+		if err := parseDNS(&composeServiceConfig, &serviceConfig); err != nil {
+			return kobject.KomposeObject{}, errors.Wrapf(err, "invalid dns configuration in service %s", name)
+		}
 
 		// Final step, add to the array!
 		komposeObject.ServiceConfigs[normalizeServiceNames(name)] = serviceConfig
@@ -640,9 +1010,33 @@ func parseNetwork(composeServiceConfig *types.ServiceConfig, serviceConfig *kobj
 		}
 	}
 
+	serviceConfig.NetworkAliases = parseNetworkAliases(composeServiceConfig.Networks, serviceConfig.Name)
 	return nil
 }
 
+// parseNetworkAliases returns the aliases of the service on all its networks, as the names of the
+// Services selecting its pods. The aliases with a dot can't be the name of a Service, they are ignored.
+func parseNetworkAliases(networks map[string]*types.ServiceNetworkConfig, name string) []string {
+	var aliases []string
+	for _, network := range networks {
+		if network == nil {
+			continue
+		}
+		for _, alias := range network.Aliases {
+			if strings.Contains(alias, ".") {
+				dropped.Warnf("Ignoring the network alias %q of service %q, it isn't a valid Service name", alias, name)
+				continue
+			}
+			alias = normalizeServiceNames(alias)
+			if alias != name && !slices.Contains(aliases, alias) {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
 func parseResources(composeServiceConfig *types.ServiceConfig, serviceConfig *kobject.ServiceConfig) error {
 	serviceConfig.MemLimit = composeServiceConfig.MemLimit
 
@@ -667,6 +1061,80 @@ func parseResources(composeServiceConfig *types.ServiceConfig, serviceConfig *ko
 			}
 		}
 	}
+
+	serviceConfig.GPUs = parseGPUs(composeServiceConfig)
+	return nil
+}
+
+// parseGPUs counts the GPUs requested by the gpus key and by the devices reserved with the gpu capability
+func parseGPUs(composeServiceConfig *types.ServiceConfig) int64 {
+	var devices []types.DeviceRequest
+	for _, device := range composeServiceConfig.Gpus {
+		// the gpus key only requests GPUs, its capabilities are implied
+		device.Capabilities = []string{"gpu"}
+		devices = append(devices, device)
+	}
+	if composeServiceConfig.Deploy != nil && composeServiceConfig.Deploy.Resources.Reservations != nil {
+		devices = append(devices, composeServiceConfig.Deploy.Resources.Reservations.Devices...)
+	}
+
+	var gpus int64
+	for _, device := range devices {
+		isGPU := false
+		for _, capability := range device.Capabilities {
+			if capability == "gpu" {
+				isGPU = true
+			}
+		}
+		if !isGPU {
+			dropped.Warnf("Ignoring the devices reserved with capabilities %v in service %q, only GPUs are supported", device.Capabilities, composeServiceConfig.Name)
+			continue
+		}
+
+		switch {
+		case len(device.IDs) > 0:
+			log.Warnf("Kubernetes can't request specific GPUs, the device_ids of service %q are converted to a count of %d", composeServiceConfig.Name, len(device.IDs))
+			gpus += int64(len(device.IDs))
+		case device.Count > 0:
+			gpus += int64(device.Count)
+		default:
+			// no count or "all" requests all the GPUs of the host
+			log.Warnf("Kubernetes can't request all the GPUs of a node, 1 GPU is requested for service %q", composeServiceConfig.Name)
+			gpus++
+		}
+	}
+
+	// the nvidia runtime gives all the GPUs of the host to the containers without reservation
+	if gpus == 0 && composeServiceConfig.Runtime == "nvidia" {
+		log.Warnf("Kubernetes can't give all the GPUs of a node to the nvidia runtime, 1 GPU is requested for service %q", composeServiceConfig.Name)
+		gpus = 1
+	}
+	return gpus
+}
+
+// maxDNSNameservers and maxDNSSearches are the limits of the DNS configuration of a pod
+const (
+	maxDNSNameservers = 3
+	maxDNSSearches    = 32
+)
+
+// parseDNS checks the dns, dns_search and dns_opt keys fit in the DNS configuration of a pod
+func parseDNS(composeServiceConfig *types.ServiceConfig, serviceConfig *kobject.ServiceConfig) error {
+	if len(composeServiceConfig.DNS) > maxDNSNameservers {
+		return errors.Errorf("%d dns servers are set, Kubernetes supports at most %d", len(composeServiceConfig.DNS), maxDNSNameservers)
+	}
+	for _, nameserver := range composeServiceConfig.DNS {
+		if net.ParseIP(nameserver) == nil {
+			return errors.Errorf("dns server %q is not an IP address", nameserver)
+		}
+	}
+	if len(composeServiceConfig.DNSSearch) > maxDNSSearches {
+		return errors.Errorf("%d dns search domains are set, Kubernetes supports at most %d", len(composeServiceConfig.DNSSearch), maxDNSSearches)
+	}
+
+	serviceConfig.DNS = composeServiceConfig.DNS
+	serviceConfig.DNSSearch = composeServiceConfig.DNSSearch
+	serviceConfig.DNSOpts = composeServiceConfig.DNSOpts
 	return nil
 }
 
@@ -725,6 +1193,83 @@ func handleCronJobBackoffLimit(backoffLimit string) (*int32, error) {
 	return &limit, nil
 }
 
+// handleRestartMaxAttempts splits a restart value such as "on-failure:3"
+// into the restart condition and its maximum number of retries
+func handleRestartMaxAttempts(restart string) (string, *int32, error) {
+	condition, attempts, found := strings.Cut(restart, ":")
+	if !found {
+		return restart, nil, nil
+	}
+	if condition != types.RestartPolicyOnFailure {
+		return "", nil, fmt.Errorf("max attempts are only supported with on-failure, got: %s", restart)
+	}
+
+	limit, err := cast.ToInt32E(attempts)
+	if err != nil || limit < 0 {
+		return "", nil, fmt.Errorf("invalid restart max attempts: %s", attempts)
+	}
+	return condition, &limit, nil
+}
+
+// handleJobPodFailurePolicyExitCodes creates a FailJob rule matching the given comma separated exit codes
+func handleJobPodFailurePolicyExitCodes(exitCodes string) (batchv1.PodFailurePolicyRule, error) {
+	var values []int32
+	for _, code := range strings.Split(exitCodes, ",") {
+		value, err := cast.ToInt32E(strings.TrimSpace(code))
+		if err != nil || value == 0 {
+			return batchv1.PodFailurePolicyRule{}, fmt.Errorf("invalid job pod failure policy exit code: %s", code)
+		}
+		values = append(values, value)
+	}
+
+	return batchv1.PodFailurePolicyRule{
+		Action: batchv1.PodFailurePolicyActionFailJob,
+		OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+			Operator: batchv1.PodFailurePolicyOnExitCodesOpIn,
+			Values:   values,
+		},
+	}, nil
+}
+
+// handleJobPodFailurePolicyIgnoreDisruptions creates an Ignore rule for disrupted pods
+func handleJobPodFailurePolicyIgnoreDisruptions(ignore string) (*batchv1.PodFailurePolicyRule, error) {
+	enabled, err := cast.ToBoolE(ignore)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job pod failure policy ignore disruptions value: %s", ignore)
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	return &batchv1.PodFailurePolicyRule{
+		Action: batchv1.PodFailurePolicyActionIgnore,
+		OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{
+			{
+				Type:   api.DisruptionTarget,
+				Status: api.ConditionTrue,
+			},
+		},
+	}, nil
+}
+
+// handleJobInt32 parses a non negative int32 value of the given job label
+func handleJobInt32(label, value string) (*int32, error) {
+	i, err := cast.ToInt32E(value)
+	if err != nil || i < 0 {
+		return nil, fmt.Errorf("invalid %s value: %s", label, value)
+	}
+	return &i, nil
+}
+
+// handleJobActiveDeadlineSeconds parses the kompose.job.active_deadline_seconds label
+func handleJobActiveDeadlineSeconds(value string) (*int64, error) {
+	i, err := cast.ToInt64E(value)
+	if err != nil || i <= 0 {
+		return nil, fmt.Errorf("invalid %s value: %s", LabelJobActiveDeadlineSeconds, value)
+	}
+	return &i, nil
+}
+
 func handleCronJobSchedule(schedule string) (string, error) {
 	if schedule == "" {
 		return "", fmt.Errorf("cronjob schedule cannot be empty")
@@ -743,9 +1288,18 @@ func parseKomposeLabels(labels map[string]string, serviceConfig *kobject.Service
 		serviceConfig.Labels = make(map[string]string)
 	}
 
+	var failExitCodesRule, ignoreDisruptionsRule *batchv1.PodFailurePolicyRule
+	sidecars := map[string]*kobject.Sidecar{}
+	initContainers := map[string]*kobject.InitContainer{}
 	for key, value := range labels {
 		switch key {
 		case LabelServiceType:
+			// the hostport services publish their ports on the node of their pods, with a ClusterIP service
+			if strings.EqualFold(value, "hostport") {
+				serviceConfig.ServiceType = string(api.ServiceTypeClusterIP)
+				serviceConfig.ExposeContainerToHost = true
+				continue
+			}
 			serviceType, err := handleServiceType(value)
 			if err != nil {
 				return errors.Wrap(err, "handleServiceType failed")
@@ -759,22 +1313,104 @@ func parseKomposeLabels(labels map[string]string, serviceConfig *kobject.Service
 			}
 
 			serviceConfig.ServiceExternalTrafficPolicy = serviceExternalTypeTrafficPolicy
+		case LabelServiceInternalTrafficPolicy:
+			serviceInternalTrafficPolicy, err := handleServiceInternalTrafficPolicy(value)
+			if err != nil {
+				return errors.Wrap(err, "handleServiceInternalTrafficPolicy failed")
+			}
+
+			serviceConfig.ServiceInternalTrafficPolicy = serviceInternalTrafficPolicy
+		case LabelServiceSessionAffinity:
+			sessionAffinity, err := handleServiceSessionAffinity(value)
+			if err != nil {
+				return errors.Wrap(err, "handleServiceSessionAffinity failed")
+			}
+
+			serviceConfig.SessionAffinity = sessionAffinity
+		case LabelServiceSessionAffinityTimeout:
+			timeout, err := cast.ToInt32E(value)
+			if err != nil || timeout <= 0 || timeout > 86400 {
+				return errors.Errorf("invalid %s value %q, a number of seconds between 1 and 86400 is expected", LabelServiceSessionAffinityTimeout, value)
+			}
+
+			serviceConfig.SessionAffinityTimeout = timeout
 		case LabelSecurityContextFsGroup:
 			serviceConfig.FsGroup = cast.ToInt64(value)
+		case LabelControllerPaused:
+			paused, err := cast.ToBoolE(value)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s value", LabelControllerPaused)
+			}
+
+			serviceConfig.Paused = paused
+		case LabelDeploymentStrategy:
+			serviceConfig.DeploymentStrategy = strings.ToLower(value)
+		case LabelEnableServiceLinks:
+			enableServiceLinks, err := cast.ToBoolE(value)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s value", LabelEnableServiceLinks)
+			}
+
+			serviceConfig.EnableServiceLinks = &enableServiceLinks
 		case LabelExposeContainerToHost:
 			serviceConfig.ExposeContainerToHost = cast.ToBool(value)
 		case LabelServiceExpose:
 			serviceConfig.ExposeService = strings.Trim(value, " ,")
 		case LabelNodePortPort:
-			serviceConfig.NodePortPort = cast.ToInt32(value)
+			nodePort, nodePorts, err := handleNodePorts(value)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s value", LabelNodePortPort)
+			}
+			serviceConfig.NodePortPort = nodePort
+			serviceConfig.NodePortPorts = nodePorts
 		case LabelServiceExposeTLSSecret:
 			serviceConfig.ExposeServiceTLS = value
 		case LabelServiceExposeIngressClassName:
 			serviceConfig.ExposeServiceIngressClassName = value
+		case LabelRouteTLSTermination:
+			serviceConfig.Route.Termination = strings.ToLower(value)
+		case LabelRouteInsecureEdgeTerminationPolicy:
+			serviceConfig.Route.InsecureEdgeTerminationPolicy = strings.ToLower(value)
+		case LabelRouteCertificate:
+			serviceConfig.Route.Certificate = value
+		case LabelRouteKey:
+			serviceConfig.Route.Key = value
+		case LabelRouteCACertificate:
+			serviceConfig.Route.CACertificate = value
+		case LabelRouteDestinationCACertificate:
+			serviceConfig.Route.DestinationCACertificate = value
 		case LabelImagePullSecret:
 			serviceConfig.ImagePullSecret = value
 		case LabelImagePullPolicy:
 			serviceConfig.ImagePullPolicy = value
+		case LabelGPUResource:
+			serviceConfig.GPUResource = value
+		case LabelRuntimeClass:
+			serviceConfig.RuntimeClassName = value
+		case LabelBuildStrategy:
+			serviceConfig.BuildStrategy = strings.ToLower(value)
+		case LabelBuildBuilderImage:
+			serviceConfig.BuildBuilderImage = value
+		case LabelBuildWebhooks:
+			for _, webhook := range strings.Split(value, ",") {
+				serviceConfig.BuildWebhooks = append(serviceConfig.BuildWebhooks, strings.ToLower(strings.TrimSpace(webhook)))
+			}
+		case LabelBuildOutputTag:
+			serviceConfig.BuildOutputTag = value
+		case LabelAntiAffinity:
+			serviceConfig.AntiAffinity = strings.ToLower(value)
+		case LabelAntiAffinityTopologyKey:
+			serviceConfig.AntiAffinityTopologyKey = value
+		case LabelStatefulSetPodManagementPolicy:
+			serviceConfig.StatefulSet.PodManagementPolicy = handlePodManagementPolicy(value)
+		case LabelStatefulSetUpdateStrategy:
+			serviceConfig.StatefulSet.UpdateStrategy = handleStatefulSetUpdateStrategy(value)
+		case LabelStatefulSetServiceName:
+			serviceConfig.StatefulSet.ServiceName = value
+		case LabelStatefulSetPVCRetentionWhenDeleted:
+			serviceConfig.StatefulSet.PVCRetentionWhenDeleted = handlePVCRetentionPolicy(value)
+		case LabelStatefulSetPVCRetentionWhenScaled:
+			serviceConfig.StatefulSet.PVCRetentionWhenScaled = handlePVCRetentionPolicy(value)
 		case LabelContainerVolumeSubpath:
 			serviceConfig.VolumeMountSubPath = value
 		case LabelCronJobSchedule:
@@ -798,15 +1434,98 @@ func parseKomposeLabels(labels map[string]string, serviceConfig *kobject.Service
 			}
 
 			serviceConfig.CronJobBackoffLimit = cronJobBackoffLimit
+		case LabelJobPodFailurePolicyFailExitCodes:
+			rule, err := handleJobPodFailurePolicyExitCodes(value)
+			if err != nil {
+				return errors.Wrap(err, "handleJobPodFailurePolicyExitCodes failed")
+			}
+
+			failExitCodesRule = &rule
+		case LabelJobPodFailurePolicyIgnoreDisruptions:
+			rule, err := handleJobPodFailurePolicyIgnoreDisruptions(value)
+			if err != nil {
+				return errors.Wrap(err, "handleJobPodFailurePolicyIgnoreDisruptions failed")
+			}
+
+			ignoreDisruptionsRule = rule
+		case LabelJobParallelism, LabelJobCompletions, LabelJobTTLSecondsAfterFinished:
+			i, err := handleJobInt32(key, value)
+			if err != nil {
+				return errors.Wrap(err, "handleJobInt32 failed")
+			}
+
+			switch key {
+			case LabelJobParallelism:
+				serviceConfig.JobParallelism = i
+			case LabelJobCompletions:
+				serviceConfig.JobCompletions = i
+			default:
+				serviceConfig.JobTTLSecondsAfterFinish = i
+			}
+		case LabelJobActiveDeadlineSeconds:
+			activeDeadlineSeconds, err := handleJobActiveDeadlineSeconds(value)
+			if err != nil {
+				return errors.Wrap(err, "handleJobActiveDeadlineSeconds failed")
+			}
+
+			serviceConfig.JobActiveDeadlineSeconds = activeDeadlineSeconds
 		case LabelNameOverride:
 			// generate a valid k8s resource name
 			normalizedName := normalizeServiceNames(value)
 			serviceConfig.Name = normalizedName
+		case LabelServiceLoadBalancerIP:
+			serviceConfig.LoadBalancerIP = value
+		case LabelServiceLoadBalancerClass:
+			serviceConfig.LoadBalancerClass = value
 		default:
+			if annotation := strings.TrimPrefix(key, LabelServiceAnnotationPrefix); annotation != key && annotation != "" {
+				if serviceConfig.ServiceAnnotations == nil {
+					serviceConfig.ServiceAnnotations = map[string]string{}
+				}
+				serviceConfig.ServiceAnnotations[annotation] = value
+				continue
+			}
+			if sidecar := strings.TrimPrefix(key, LabelSidecarPrefix); sidecar != key {
+				if err := parseSidecarLabel(sidecars, sidecar, value); err != nil {
+					return errors.Wrapf(err, "invalid label %s", key)
+				}
+				continue
+			}
+			// the labels of the single init container, kompose.init.containers.image and co, have no name
+			if initContainer := strings.TrimPrefix(key, LabelInitContainersPrefix); initContainer != key && strings.Contains(initContainer, ".") {
+				if err := parseInitContainerLabel(initContainers, initContainer, value); err != nil {
+					return errors.Wrapf(err, "invalid label %s", key)
+				}
+				continue
+			}
 			serviceConfig.Labels[key] = value
 		}
 	}
 
+	sidecarNames := make([]string, 0, len(sidecars))
+	for name := range sidecars {
+		sidecarNames = append(sidecarNames, name)
+	}
+	sort.Strings(sidecarNames)
+	for _, name := range sidecarNames {
+		if sidecars[name].Image == "" {
+			return errors.Errorf("the sidecar %s has no image, set %s%s.image", name, LabelSidecarPrefix, name)
+		}
+		serviceConfig.Sidecars = append(serviceConfig.Sidecars, *sidecars[name])
+	}
+
+	initContainerNames := make([]string, 0, len(initContainers))
+	for name := range initContainers {
+		initContainerNames = append(initContainerNames, name)
+	}
+	sort.Strings(initContainerNames)
+	for _, name := range initContainerNames {
+		if initContainers[name].Image == "" {
+			return errors.Errorf("the init container %s has no image, set %s%s.image", name, LabelInitContainersPrefix, name)
+		}
+		serviceConfig.InitContainers = append(serviceConfig.InitContainers, *initContainers[name])
+	}
+
 	if serviceConfig.ExposeService == "" && serviceConfig.ExposeServiceTLS != "" {
 		return errors.New("kompose.service.expose.tls-secret was specified without kompose.service.expose")
 	}
@@ -815,14 +1534,47 @@ func parseKomposeLabels(labels map[string]string, serviceConfig *kobject.Service
 		return errors.New("kompose.service.expose.ingress-class-name was specified without kompose.service.expose")
 	}
 
-	if serviceConfig.ServiceType != string(api.ServiceTypeNodePort) && serviceConfig.NodePortPort != 0 {
+	if serviceConfig.Route != (kobject.RouteConfig{}) {
+		if err := validateRouteConfig(serviceConfig.Route, serviceConfig.ExposeService); err != nil {
+			return err
+		}
+	}
+
+	if serviceConfig.SessionAffinityTimeout != 0 && serviceConfig.SessionAffinity != string(api.ServiceAffinityClientIP) {
+		return errors.New("kompose.service.session-affinity must be clientip when the session affinity timeout is set")
+	}
+
+	if serviceConfig.ServiceType != string(api.ServiceTypeNodePort) && (serviceConfig.NodePortPort != 0 || len(serviceConfig.NodePortPorts) > 0) {
 		return errors.New("kompose.service.type must be nodeport when assign node port value")
 	}
 
+	for published := range serviceConfig.NodePortPorts {
+		found := false
+		for _, port := range serviceConfig.Port {
+			if port.HostPort == published || (port.HostPort == 0 && port.ContainerPort == published) {
+				found = true
+			}
+		}
+		if !found {
+			return errors.Errorf("cannot set the node port of the port %d in kompose.service.nodeport.port, it isn't published", published)
+		}
+	}
+
 	if len(serviceConfig.Port) > 1 && serviceConfig.NodePortPort != 0 {
 		return errors.New("cannot set kompose.service.nodeport.port when service has multiple ports")
 	}
 
+	// rules are evaluated in order, so disruptions are ignored before exit codes are matched
+	if failExitCodesRule != nil || ignoreDisruptionsRule != nil {
+		serviceConfig.JobPodFailurePolicy = &batchv1.PodFailurePolicy{}
+		if ignoreDisruptionsRule != nil {
+			serviceConfig.JobPodFailurePolicy.Rules = append(serviceConfig.JobPodFailurePolicy.Rules, *ignoreDisruptionsRule)
+		}
+		if failExitCodesRule != nil {
+			serviceConfig.JobPodFailurePolicy.Rules = append(serviceConfig.JobPodFailurePolicy.Rules, *failExitCodesRule)
+		}
+	}
+
 	if serviceConfig.Restart == "always" && serviceConfig.CronJobConcurrencyPolicy != "" {
 		log.Infof("cronjob restart policy will be converted from '%s' to 'on-failure'", serviceConfig.Restart)
 		serviceConfig.Restart = "on-failure"
@@ -831,6 +1583,63 @@ func parseKomposeLabels(labels map[string]string, serviceConfig *kobject.Service
 	return nil
 }
 
+// parseSidecarLabel sets a field of a sidecar from a kompose.sidecar.<name>.<field> label, given without its prefix
+func parseSidecarLabel(sidecars map[string]*kobject.Sidecar, label, value string) error {
+	name, field, _ := strings.Cut(label, ".")
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return errors.Errorf("invalid sidecar name %q: %s", name, strings.Join(errs, ", "))
+	}
+	sidecar, ok := sidecars[name]
+	if !ok {
+		sidecar = &kobject.Sidecar{Name: name}
+		sidecars[name] = sidecar
+	}
+
+	var err error
+	switch field {
+	case "image":
+		sidecar.Image = value
+	case "command":
+		sidecar.Command, err = shlex.Split(value)
+	case "ports":
+		sidecar.Ports, err = handleSidecarPorts(value)
+	case "mounts":
+		sidecar.Mounts, err = handleSidecarMounts(value)
+	default:
+		err = errors.Errorf("unknown field %q of the sidecar %s, supported fields are 'image, command, ports, mounts'", field, name)
+	}
+	return err
+}
+
+// parseInitContainerLabel sets a field of an init container from a kompose.init.containers.<name>.<field>
+// label, given without its prefix
+func parseInitContainerLabel(initContainers map[string]*kobject.InitContainer, label, value string) error {
+	name, field, _ := strings.Cut(label, ".")
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return errors.Errorf("invalid init container name %q: %s", name, strings.Join(errs, ", "))
+	}
+	initContainer, ok := initContainers[name]
+	if !ok {
+		initContainer = &kobject.InitContainer{Name: name}
+		initContainers[name] = initContainer
+	}
+
+	var err error
+	switch field {
+	case "image":
+		initContainer.Image = value
+	case "command":
+		initContainer.Command, err = shlex.Split(value)
+	case "env":
+		initContainer.Env, err = handleEnvVars(value)
+	case "mounts":
+		initContainer.Mounts, err = handleSidecarMounts(value)
+	default:
+		err = errors.Errorf("unknown field %q of the init container %s, supported fields are 'image, command, env, mounts'", field, name)
+	}
+	return err
+}
+
 func handleVolume(komposeObject *kobject.KomposeObject, volumes *types.Volumes) {
 	for name := range komposeObject.ServiceConfigs {
 		// retrieve volumes of service
@@ -838,15 +1647,8 @@ func handleVolume(komposeObject *kobject.KomposeObject, volumes *types.Volumes)
 		if err != nil {
 			errors.Wrap(err, "could not retrieve vvolume")
 		}
-		for volName, vol := range vols {
-			size, selector := getVolumeLabels(vol.VolumeName, volumes)
-			if len(size) > 0 || len(selector) > 0 {
-				// We can't assign value to struct field in map while iterating over it, so temporary variable `temp` is used here
-				var temp = vols[volName]
-				temp.PVCSize = size
-				temp.SelectorValue = selector
-				vols[volName] = temp
-			}
+		for volName := range vols {
+			setVolumeLabels(&vols[volName], volumes)
 		}
 		// We can't assign value to struct field in map while iterating over it, so temporary variable `temp` is used here
 		var temp = komposeObject.ServiceConfigs[name]
@@ -949,20 +1751,27 @@ func getVol(toFind kobject.Volumes, Vols []kobject.Volumes) (bool, kobject.Volum
 	return false, kobject.Volumes{}
 }
 
-func getVolumeLabels(name string, volumes *types.Volumes) (string, string) {
-	size, selector := "", ""
-
-	if volume, ok := (*volumes)[name]; ok {
-		for key, value := range volume.Labels {
-			if key == "kompose.volume.size" {
-				size = value
-			} else if key == "kompose.volume.selector" {
-				selector = value
-			}
+// setVolumeLabels sets the claim options of the kompose labels of its top level volume to the volume
+func setVolumeLabels(vol *kobject.Volumes, volumes *types.Volumes) {
+	volume, ok := (*volumes)[vol.VolumeName]
+	if !ok {
+		return
+	}
+	vol.DriverOpts = volume.DriverOpts
+	for key, value := range volume.Labels {
+		switch key {
+		case LabelVolumeSize:
+			vol.PVCSize = value
+		case LabelVolumeSelector:
+			vol.SelectorValue = value
+		case LabelVolumeStorageClass, LabelVolumeStorageClassName:
+			vol.StorageClass = value
+		case LabelVolumeAccessMode:
+			vol.AccessMode = value
+		case LabelVolumeCSIDriver:
+			vol.CSIDriver = value
 		}
 	}
-
-	return size, selector
 }
 
 // getGroupAdd will return group in int64 format
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -28,9 +29,15 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	api "k8s.io/api/core/v1"
 )
 
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
 func durationTypesPtr(value time.Duration) *types.Duration {
 	target := types.Duration(value)
 	return &target
@@ -87,6 +94,22 @@ func TestParseHealthCheck(t *testing.T) {
 				StartPeriod: 3,
 			},
 		},
+		"HTTPSGetWithHeaders": {
+			input: input{
+				labels: types.Labels{
+					"kompose.service.healthcheck.liveness.http_get_path": "/health",
+					"kompose.service.healthcheck.liveness.http_get_port": "8443",
+					"kompose.service.healthcheck.liveness.http_scheme":   "https",
+					"kompose.service.healthcheck.liveness.http_headers":  "Host=example.com, X-Probe=liveness",
+				},
+			},
+			expected: kobject.HealthCheck{
+				HTTPPath:    "/health",
+				HTTPPort:    8443,
+				HTTPScheme:  "HTTPS",
+				HTTPHeaders: map[string]string{"Host": "example.com", "X-Probe": "liveness"},
+			},
+		},
 		"TCPSocket": {
 			input: input{
 				healthCheck: types.HealthCheckConfig{
@@ -192,6 +215,83 @@ func TestParseHealthCheckReadiness(t *testing.T) {
 	}
 }
 
+func TestParseHealthCheckStartup(t *testing.T) {
+	output, err := parseHealthCheckStartup(types.Labels{
+		"kompose.service.healthcheck.startup.http_get_path": "/started",
+		"kompose.service.healthcheck.startup.http_get_port": "8080",
+		"kompose.service.healthcheck.startup.interval":      "5s",
+		"kompose.service.healthcheck.startup.retries":       "30",
+		"kompose.service.healthcheck.startup.http_headers":  "Host=example.com",
+		// readiness labels are not part of the startup probe
+		"kompose.service.healthcheck.readiness.tcp_port": "8081",
+	})
+	if err != nil {
+		t.Errorf("Unable to convert HealthCheckConfig: %s", err)
+	}
+	expected := kobject.HealthCheck{HTTPPath: "/started", HTTPPort: 8080, HTTPHeaders: map[string]string{"Host": "example.com"}, Interval: 5, Retries: 30}
+	if !reflect.DeepEqual(output, expected) {
+		t.Errorf("Structs are not equal, expected: %v, output: %v", expected, output)
+	}
+}
+
+func TestLoadFileStartupWithoutHandler(t *testing.T) {
+	testCases := map[string]struct {
+		compose  string
+		expected []string
+	}{
+		"without healthcheck": {"services:\n  web:\n    image: nginx\n    labels:\n      kompose.service.healthcheck.startup.retries: \"30\"\n", nil},
+		"with healthcheck":    {"services:\n  web:\n    image: nginx\n    healthcheck:\n      test: [\"CMD\", \"curl\", \"localhost\"]\n    labels:\n      kompose.service.healthcheck.startup.retries: \"30\"\n", []string{"curl", "localhost"}},
+	}
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(dir+"/compose.yaml", []byte(test.compose), 0644); err != nil {
+				t.Fatal(err)
+			}
+			komposeObject, err := new(Compose).LoadFile("shop", []string{dir + "/compose.yaml"}, "", nil, nil, false, nil, nil)
+			if test.expected == nil {
+				if err == nil {
+					t.Errorf("Expected an error for a startup probe without handler")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			startup := komposeObject.ServiceConfigs["web"].HealthChecks.Startup
+			if !reflect.DeepEqual(startup.Test, test.expected) || startup.Retries != 30 {
+				t.Errorf("Expected the startup probe to run %v 30 times, got %+v", test.expected, startup)
+			}
+		})
+	}
+}
+
+func TestStartupFromLiveness(t *testing.T) {
+	startPeriod := types.Duration(time.Minute)
+	startInterval := types.Duration(7 * time.Second)
+	healthCheck := types.HealthCheckConfig{StartPeriod: &startPeriod, StartInterval: &startInterval}
+	liveness := kobject.HealthCheck{Test: []string{"pg_isready"}, Interval: 30, Retries: 3, StartPeriod: 60}
+
+	startup := startupFromLiveness(healthCheck, &liveness)
+	expectedStartup := kobject.HealthCheck{Test: []string{"pg_isready"}, Interval: 7, Retries: 9}
+	if !reflect.DeepEqual(startup, expectedStartup) {
+		t.Errorf("Expected startup probe %v, got %v", expectedStartup, startup)
+	}
+	expectedLiveness := kobject.HealthCheck{Test: []string{"pg_isready"}, Interval: 30, Retries: 3}
+	if !reflect.DeepEqual(liveness, expectedLiveness) {
+		t.Errorf("Expected liveness probe %v, got %v", expectedLiveness, liveness)
+	}
+
+	// without start_interval, the start period stays an initial delay of the liveness probe
+	liveness = kobject.HealthCheck{Test: []string{"pg_isready"}, StartPeriod: 60}
+	if startup := startupFromLiveness(types.HealthCheckConfig{StartPeriod: &startPeriod}, &liveness); !reflect.DeepEqual(startup, kobject.HealthCheck{}) {
+		t.Errorf("Expected no startup probe, got %v", startup)
+	}
+	if liveness.StartPeriod != 60 {
+		t.Errorf("Expected the liveness start period to be kept, got %d", liveness.StartPeriod)
+	}
+}
+
 func TestLoadV3Volumes(t *testing.T) {
 	vol := types.ServiceVolumeConfig{
 		Type:     "volume",
@@ -208,6 +308,55 @@ func TestLoadV3Volumes(t *testing.T) {
 	}
 }
 
+func TestLoadTmpfsVolumes(t *testing.T) {
+	volumes := []types.ServiceVolumeConfig{
+		{Type: "volume", Source: "data", Target: "/data"},
+		{Type: "tmpfs", Target: "/scratch", Tmpfs: &types.ServiceVolumeTmpfs{Size: 64 * 1024 * 1024}},
+		{Type: "tmpfs", Target: "/cache"},
+	}
+	if output := loadVolumes(volumes); !reflect.DeepEqual(output, []string{"data:/data"}) {
+		t.Errorf("Expected the tmpfs volumes to be skipped, got %v", output)
+	}
+	expected := []string{"/scratch:size=67108864", "/cache"}
+	if output := loadTmpfsVolumes(volumes); !reflect.DeepEqual(output, expected) {
+		t.Errorf("Expected %v, got %v", expected, output)
+	}
+}
+
+func TestSetVolumeLabels(t *testing.T) {
+	volumes := types.Volumes{
+		"data": types.VolumeConfig{Labels: types.Labels{
+			LabelVolumeSize:         "20Gi",
+			LabelVolumeSelector:     "fast-disks",
+			LabelVolumeStorageClass: "fast",
+			LabelVolumeAccessMode:   "ReadWriteMany",
+		}},
+		"legacy": types.VolumeConfig{Labels: types.Labels{LabelVolumeStorageClassName: "standard"}},
+		"smb": types.VolumeConfig{
+			DriverOpts: types.Options{"source": "//smb.example.com/share"},
+			Labels:     types.Labels{LabelVolumeCSIDriver: "smb.csi.k8s.io"},
+		},
+	}
+	vol := kobject.Volumes{VolumeName: "data"}
+	setVolumeLabels(&vol, &volumes)
+	expected := kobject.Volumes{VolumeName: "data", PVCSize: "20Gi", SelectorValue: "fast-disks", StorageClass: "fast", AccessMode: "ReadWriteMany"}
+	if !reflect.DeepEqual(vol, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, vol)
+	}
+
+	vol = kobject.Volumes{VolumeName: "legacy"}
+	setVolumeLabels(&vol, &volumes)
+	if vol.StorageClass != "standard" {
+		t.Errorf("Expected the storage class standard, got %q", vol.StorageClass)
+	}
+
+	vol = kobject.Volumes{VolumeName: "smb"}
+	setVolumeLabels(&vol, &volumes)
+	if vol.CSIDriver != "smb.csi.k8s.io" || vol.DriverOpts["source"] != "//smb.example.com/share" {
+		t.Errorf("Expected the CSI driver and its options, got %+v", vol)
+	}
+}
+
 func TestLoadV3Ports(t *testing.T) {
 	for _, tt := range []struct {
 		desc   string
@@ -351,6 +500,12 @@ func TestLoadPorts(t *testing.T) {
 				{ContainerPort: 8080, Protocol: string(api.ProtocolTCP)},
 			},
 		},
+		{
+			ports: []types.ServicePortConfig{{Target: 8443, Published: "443", Protocol: "tcp", AppProtocol: "https"}},
+			want: []kobject.Ports{
+				{HostPort: 443, ContainerPort: 8443, Protocol: string(api.ProtocolTCP), AppProtocol: "https"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -530,6 +685,22 @@ func TestUnsupportedKeys(t *testing.T) {
 		},
 	}
 
+	projectWithUnsupportedKeys := &types.Project{
+		Services: types.Services{
+			"foo": types.ServiceConfig{
+				Name:        "foo",
+				Image:       "foo/bar",
+				SecurityOpt: []string{"no-new-privileges"},
+			},
+			"bar": types.ServiceConfig{
+				Name:        "bar",
+				Image:       "bar/foo",
+				NetworkMode: "service:foo",
+				SecurityOpt: []string{"no-new-privileges"},
+			},
+		},
+	}
+
 	// define all test cases for checkUnsupportedKey function
 	testCases := map[string]struct {
 		composeProject          *types.Project
@@ -537,8 +708,12 @@ func TestUnsupportedKeys(t *testing.T) {
 	}{
 		"With Networks (service and root level)": {
 			projectWithNetworks,
-			//root level network and network are now supported"
-			[]string{"root level volumes"},
+			// root level networks and volumes, and service networks are supported
+			[]string(nil),
+		},
+		"With unsupported keys": {
+			projectWithUnsupportedKeys,
+			[]string{"security_opt"},
 		},
 		"Default root level Network": {
 			projectWithDefaultNetwork,
@@ -574,6 +749,30 @@ func TestNormalizeServiceNames(t *testing.T) {
 	}
 }
 
+func TestServiceNameCollision(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"web_app": types.ServiceConfig{Name: "web_app", Image: "nginx"},
+			"Web":     types.ServiceConfig{Name: "Web", Image: "nginx"},
+		},
+	}
+	komposeObject, err := dockerComposeToKomposeMapping(project)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := komposeObject.ServiceConfigs["web-app"].OriginalName; got != "web_app" {
+		t.Errorf("expected original name %q, got %q", "web_app", got)
+	}
+	if got := komposeObject.ServiceConfigs["web"].OriginalName; got != "Web" {
+		t.Errorf("expected original name %q, got %q", "Web", got)
+	}
+
+	project.Services["web.app"] = types.ServiceConfig{Name: "web.app", Image: "nginx"}
+	if _, err := dockerComposeToKomposeMapping(project); err == nil {
+		t.Errorf("expected an error for services converted to the same name")
+	}
+}
+
 func TestNormalizeNetworkNames(t *testing.T) {
 	testCases := []struct {
 		composeNetworkName    string
@@ -648,6 +847,493 @@ func checkConstraints(t *testing.T, caseName string, output, expected map[string
 	}
 }
 
+func TestHandleRestartMaxAttempts(t *testing.T) {
+	tests := []struct {
+		restart     string
+		condition   string
+		maxAttempts *int32
+		wantErr     bool
+	}{
+		{"on-failure", "on-failure", nil, false},
+		{"always", "always", nil, false},
+		{"on-failure:3", "on-failure", int32Ptr(3), false},
+		{"on-failure:-1", "", nil, true},
+		{"on-failure:x", "", nil, true},
+		{"always:3", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		condition, maxAttempts, err := handleRestartMaxAttempts(tt.restart)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("handleRestartMaxAttempts(%q) error = %v, wantErr %v", tt.restart, err, tt.wantErr)
+			continue
+		}
+		if condition != tt.condition || !reflect.DeepEqual(maxAttempts, tt.maxAttempts) {
+			t.Errorf("handleRestartMaxAttempts(%q) = %q, %v, want %q, %v", tt.restart, condition, maxAttempts, tt.condition, tt.maxAttempts)
+		}
+	}
+}
+
+func TestParseGPUs(t *testing.T) {
+	gpuReservation := func(devices ...types.DeviceRequest) *types.DeployConfig {
+		return &types.DeployConfig{Resources: types.Resources{Reservations: &types.Resource{Devices: devices}}}
+	}
+	tests := map[string]struct {
+		service types.ServiceConfig
+		want    int64
+	}{
+		"No devices":               {types.ServiceConfig{}, 0},
+		"GPU count":                {types.ServiceConfig{Deploy: gpuReservation(types.DeviceRequest{Capabilities: []string{"gpu"}, Count: 2})}, 2},
+		"GPU ids":                  {types.ServiceConfig{Deploy: gpuReservation(types.DeviceRequest{Capabilities: []string{"gpu", "utility"}, IDs: []string{"0", "3"}})}, 2},
+		"All GPUs":                 {types.ServiceConfig{Deploy: gpuReservation(types.DeviceRequest{Capabilities: []string{"gpu"}, Count: -1})}, 1},
+		"Other device":             {types.ServiceConfig{Deploy: gpuReservation(types.DeviceRequest{Capabilities: []string{"tpu"}, Count: 4})}, 0},
+		"Gpus key":                 {types.ServiceConfig{Gpus: []types.DeviceRequest{{Count: 3}}}, 3},
+		"Nvidia runtime":           {types.ServiceConfig{Runtime: "nvidia"}, 1},
+		"Nvidia runtime with GPUs": {types.ServiceConfig{Runtime: "nvidia", Gpus: []types.DeviceRequest{{Count: 2}}}, 2},
+	}
+
+	for name, tt := range tests {
+		if got := parseGPUs(&tt.service); got != tt.want {
+			t.Errorf("%s: parseGPUs() = %d, want %d", name, got, tt.want)
+		}
+	}
+}
+
+func TestParseDNS(t *testing.T) {
+	tests := map[string]struct {
+		service types.ServiceConfig
+		wantErr bool
+	}{
+		"Nameservers":            {types.ServiceConfig{DNS: types.StringList{"1.1.1.1", "8.8.8.8"}, DNSSearch: types.StringList{"example.com"}}, false},
+		"Too many nameservers":   {types.ServiceConfig{DNS: types.StringList{"1.1.1.1", "1.0.0.1", "8.8.8.8", "8.8.4.4"}}, true},
+		"Nameserver not an IP":   {types.ServiceConfig{DNS: types.StringList{"dns.example.com"}}, true},
+		"Too many search domain": {types.ServiceConfig{DNSSearch: make(types.StringList, 33)}, true},
+	}
+
+	for name, tt := range tests {
+		serviceConfig := kobject.ServiceConfig{}
+		err := parseDNS(&tt.service, &serviceConfig)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseDNS() error = %v, wantErr %v", name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(serviceConfig.DNS, []string(tt.service.DNS)) {
+			t.Errorf("%s: expected dns %v, got %v", name, tt.service.DNS, serviceConfig.DNS)
+		}
+	}
+}
+
+func TestMergeKomposeExtension(t *testing.T) {
+	tests := map[string]struct {
+		labels    types.Labels
+		extension interface{}
+		want      types.Labels
+		wantErr   string
+	}{
+		"No extension": {types.Labels{LabelServiceType: "nodeport"}, nil, types.Labels{LabelServiceType: "nodeport"}, ""},
+		"Flat and nested keys": {
+			types.Labels{"app": "web"},
+			map[string]interface{}{
+				"service.type": "nodeport",
+				"hpa":          map[string]interface{}{"replicas": map[string]interface{}{"max": 10}},
+				"controller":   map[string]interface{}{"paused": true},
+			},
+			types.Labels{"app": "web", LabelServiceType: "nodeport", LabelHpaMaxReplicas: "10", LabelControllerPaused: "true"},
+			"",
+		},
+		"Same value as the label": {
+			types.Labels{LabelServiceType: "nodeport"},
+			map[string]interface{}{"service.type": "nodeport"},
+			types.Labels{LabelServiceType: "nodeport"},
+			"",
+		},
+		"Conflict with the label": {types.Labels{LabelServiceType: "nodeport"}, map[string]interface{}{"service.type": "clusterip"}, nil, "service.type is \"clusterip\" while the label kompose.service.type is \"nodeport\""},
+		"Unknown key":             {nil, map[string]interface{}{"service.typo": "nodeport"}, nil, "unknown key service.typo, did you mean service.type?"},
+		"List value":              {nil, map[string]interface{}{"job.pod_failure_policy.fail_exit_codes": []interface{}{42}}, nil, "job.pod_failure_policy.fail_exit_codes must be a scalar value, lists are given as comma separated strings"},
+		"Not a mapping":           {nil, "daemonset", nil, "a mapping of kompose labels is expected"},
+	}
+
+	for name, tt := range tests {
+		got, err := mergeKomposeExtension(tt.labels, tt.extension)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%s: expected error %q, got %v", name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected labels %v, got %v", name, tt.want, got)
+		}
+	}
+}
+
+func TestParseKubernetesPatches(t *testing.T) {
+	patch := map[string]interface{}{"kind": "Deployment", "spec": map[string]interface{}{"replicas": 2}}
+	tests := map[string]struct {
+		extension interface{}
+		want      int
+		wantErr   bool
+	}{
+		"No extension":    {nil, 0, false},
+		"Single patch":    {patch, 1, false},
+		"List of patches": {[]interface{}{patch, patch}, 2, false},
+		"Missing kind":    {[]interface{}{map[string]interface{}{"spec": nil}}, 0, true},
+		"Not a mapping":   {[]interface{}{"Deployment"}, 0, true},
+		"Scalar":          {"Deployment", 0, true},
+	}
+
+	for name, tt := range tests {
+		patches, err := parseKubernetesPatches(tt.extension)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseKubernetesPatches() error = %v, wantErr %v", name, err, tt.wantErr)
+			continue
+		}
+		if len(patches) != tt.want {
+			t.Errorf("%s: expected %d patches, got %d", name, tt.want, len(patches))
+		}
+	}
+}
+
+func TestParseJobPodFailurePolicyLabels(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{Restart: "on-failure"}
+	labels := types.Labels{
+		LabelJobPodFailurePolicyFailExitCodes:     "42, 43",
+		LabelJobPodFailurePolicyIgnoreDisruptions: "true",
+	}
+	if err := parseKomposeLabels(labels, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+
+	expected := &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{
+			{
+				Action:          batchv1.PodFailurePolicyActionIgnore,
+				OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{{Type: api.DisruptionTarget, Status: api.ConditionTrue}},
+			},
+			{
+				Action:      batchv1.PodFailurePolicyActionFailJob,
+				OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{Operator: batchv1.PodFailurePolicyOnExitCodesOpIn, Values: []int32{42, 43}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(serviceConfig.JobPodFailurePolicy, expected) {
+		t.Errorf("Expected pod failure policy %v, got %v", expected, serviceConfig.JobPodFailurePolicy)
+	}
+
+	if err := parseKomposeLabels(types.Labels{LabelJobPodFailurePolicyFailExitCodes: "0"}, &kobject.ServiceConfig{}); err == nil {
+		t.Errorf("Expected an error for exit code 0")
+	}
+}
+
+func TestParseJobLabels(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{Restart: "on-failure"}
+	labels := types.Labels{
+		LabelJobParallelism:             "2",
+		LabelJobCompletions:             "5",
+		LabelJobTTLSecondsAfterFinished: "0",
+		LabelJobActiveDeadlineSeconds:   "600",
+	}
+	if err := parseKomposeLabels(labels, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+
+	if *serviceConfig.JobParallelism != 2 || *serviceConfig.JobCompletions != 5 || *serviceConfig.JobTTLSecondsAfterFinish != 0 || *serviceConfig.JobActiveDeadlineSeconds != 600 {
+		t.Errorf("Unexpected job settings: %+v", serviceConfig)
+	}
+
+	for _, invalid := range []types.Labels{
+		{LabelJobParallelism: "-1"},
+		{LabelJobCompletions: "five"},
+		{LabelJobActiveDeadlineSeconds: "0"},
+	} {
+		if err := parseKomposeLabels(invalid, &kobject.ServiceConfig{}); err == nil {
+			t.Errorf("Expected an error for labels %v", invalid)
+		}
+	}
+}
+
+func TestParseNodePortLabels(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{Port: []kobject.Ports{{HostPort: 80, ContainerPort: 8080}, {ContainerPort: 443}}}
+	labels := types.Labels{LabelServiceType: "nodeport", LabelNodePortPort: "80:30080, 443:30443"}
+	if err := parseKomposeLabels(labels, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+	if expected := map[int32]int32{80: 30080, 443: 30443}; !reflect.DeepEqual(serviceConfig.NodePortPorts, expected) {
+		t.Errorf("Expected the node ports %v, got %v", expected, serviceConfig.NodePortPorts)
+	}
+
+	serviceConfig = kobject.ServiceConfig{Port: []kobject.Ports{{HostPort: 80, ContainerPort: 8080}}}
+	if err := parseKomposeLabels(types.Labels{LabelServiceType: "hostport"}, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+	if serviceConfig.ServiceType != "ClusterIP" || !serviceConfig.ExposeContainerToHost {
+		t.Errorf("Expected a ClusterIP service exposing the container on the host, got %q %v", serviceConfig.ServiceType, serviceConfig.ExposeContainerToHost)
+	}
+
+	for _, invalid := range []types.Labels{
+		{LabelServiceType: "nodeport", LabelNodePortPort: "8080:30080"},
+		{LabelServiceType: "nodeport", LabelNodePortPort: "80:high"},
+		{LabelServiceType: "clusterip", LabelNodePortPort: "80:30080"},
+	} {
+		serviceConfig := kobject.ServiceConfig{Port: []kobject.Ports{{HostPort: 80, ContainerPort: 8080}}}
+		if err := parseKomposeLabels(invalid, &serviceConfig); err == nil {
+			t.Errorf("Expected an error for labels %v", invalid)
+		}
+	}
+}
+
+func TestParseServiceAnnotationLabels(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{}
+	labels := types.Labels{
+		LabelServiceType:              "loadbalancer",
+		LabelServiceLoadBalancerIP:    "203.0.113.10",
+		LabelServiceLoadBalancerClass: "service.k8s.aws/nlb",
+		LabelServiceAnnotationPrefix + "service.beta.kubernetes.io/aws-load-balancer-scheme": "internal",
+	}
+	if err := parseKomposeLabels(labels, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+	if serviceConfig.LoadBalancerIP != "203.0.113.10" || serviceConfig.LoadBalancerClass != "service.k8s.aws/nlb" {
+		t.Errorf("Expected the load balancer IP and class, got %q and %q", serviceConfig.LoadBalancerIP, serviceConfig.LoadBalancerClass)
+	}
+	if expected := map[string]string{"service.beta.kubernetes.io/aws-load-balancer-scheme": "internal"}; !reflect.DeepEqual(serviceConfig.ServiceAnnotations, expected) {
+		t.Errorf("Expected the service annotations %v, got %v", expected, serviceConfig.ServiceAnnotations)
+	}
+	if len(serviceConfig.Labels) != 0 {
+		t.Errorf("Expected the kompose labels to be consumed, got %v", serviceConfig.Labels)
+	}
+}
+
+func TestParseServiceTrafficLabels(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{}
+	labels := types.Labels{
+		LabelServiceInternalTrafficPolicy:  "local",
+		LabelServiceSessionAffinity:        "ClientIP",
+		LabelServiceSessionAffinityTimeout: "3600",
+	}
+	if err := parseKomposeLabels(labels, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+	if serviceConfig.ServiceInternalTrafficPolicy != "Local" || serviceConfig.SessionAffinity != "ClientIP" || serviceConfig.SessionAffinityTimeout != 3600 {
+		t.Errorf("Expected the Local internal traffic policy and a ClientIP affinity of 3600 seconds, got %q, %q and %d",
+			serviceConfig.ServiceInternalTrafficPolicy, serviceConfig.SessionAffinity, serviceConfig.SessionAffinityTimeout)
+	}
+
+	for _, invalid := range []types.Labels{
+		{LabelServiceSessionAffinityTimeout: "3600"},
+		{LabelServiceSessionAffinity: "none", LabelServiceSessionAffinityTimeout: "3600"},
+		{LabelServiceSessionAffinity: "clientip", LabelServiceSessionAffinityTimeout: "100000"},
+		{LabelServiceInternalTrafficPolicy: "node"},
+	} {
+		if err := parseKomposeLabels(invalid, &kobject.ServiceConfig{}); err == nil {
+			t.Errorf("Expected an error for labels %v", invalid)
+		}
+	}
+}
+
+func TestParseStatefulSetLabels(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{}
+	labels := types.Labels{
+		LabelStatefulSetPodManagementPolicy:     "Parallel",
+		LabelStatefulSetUpdateStrategy:          "OnDelete",
+		LabelStatefulSetServiceName:             "db-headless",
+		LabelStatefulSetPVCRetentionWhenDeleted: "delete",
+	}
+	if err := parseKomposeLabels(labels, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+	expected := kobject.StatefulSetConfig{
+		PodManagementPolicy:     appsv1.ParallelPodManagement,
+		UpdateStrategy:          appsv1.OnDeleteStatefulSetStrategyType,
+		ServiceName:             "db-headless",
+		PVCRetentionWhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+	}
+	if serviceConfig.StatefulSet != expected {
+		t.Errorf("Expected the StatefulSet settings %+v, got %+v", expected, serviceConfig.StatefulSet)
+	}
+}
+
+func TestParseRouteLabels(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{}
+	labels := types.Labels{
+		LabelServiceExpose:                      "example.com",
+		LabelRouteTLSTermination:                "Edge",
+		LabelRouteInsecureEdgeTerminationPolicy: "Redirect",
+		LabelRouteCertificate:                   "tls.crt",
+		LabelRouteKey:                           "tls.key",
+	}
+	if err := parseKomposeLabels(labels, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+	expected := kobject.RouteConfig{Termination: "edge", InsecureEdgeTerminationPolicy: "redirect", Certificate: "tls.crt", Key: "tls.key"}
+	if serviceConfig.Route != expected {
+		t.Errorf("Expected the route settings %+v, got %+v", expected, serviceConfig.Route)
+	}
+
+	for _, invalid := range []types.Labels{
+		{LabelRouteTLSTermination: "edge"},
+		{LabelServiceExpose: "true", LabelRouteCertificate: "tls.crt", LabelRouteKey: "tls.key"},
+		{LabelServiceExpose: "true", LabelRouteTLSTermination: "edge", LabelRouteCertificate: "tls.crt"},
+		{LabelServiceExpose: "true", LabelRouteTLSTermination: "passthrough", LabelRouteCertificate: "tls.crt", LabelRouteKey: "tls.key"},
+		{LabelServiceExpose: "true", LabelRouteTLSTermination: "passthrough", LabelRouteInsecureEdgeTerminationPolicy: "allow"},
+		{LabelServiceExpose: "true", LabelRouteTLSTermination: "edge", LabelRouteDestinationCACertificate: "ca.crt"},
+	} {
+		if err := parseKomposeLabels(invalid, &kobject.ServiceConfig{}); err == nil {
+			t.Errorf("Expected an error for labels %v", invalid)
+		}
+	}
+}
+
+func TestParseNetworkAliases(t *testing.T) {
+	networks := map[string]*types.ServiceNetworkConfig{
+		"front": {Aliases: []string{"Web_App", "web"}},
+		"back":  {Aliases: []string{"api", "web-app", "api.internal"}},
+		"other": nil,
+	}
+	expected := []string{"api", "web-app"}
+	if aliases := parseNetworkAliases(networks, "web"); !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("Expected the aliases %v, got %v", expected, aliases)
+	}
+}
+
+func TestParseSidecarLabels(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{}
+	labels := types.Labels{
+		LabelSidecarPrefix + "proxy.image":   "gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.11",
+		LabelSidecarPrefix + "proxy.command": "/cloud-sql-proxy --port 5432 project:region:instance",
+		LabelSidecarPrefix + "proxy.ports":   "5432, 9090/udp",
+		LabelSidecarPrefix + "envoy.image":   "envoyproxy/envoy:v1.31",
+		LabelSidecarPrefix + "envoy.mounts":  "/etc/envoy, /var/log/app:/logs:ro",
+	}
+	if err := parseKomposeLabels(labels, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+	expected := []kobject.Sidecar{
+		{
+			Name:   "envoy",
+			Image:  "envoyproxy/envoy:v1.31",
+			Mounts: []kobject.SidecarMount{{Path: "/etc/envoy", MountPath: "/etc/envoy"}, {Path: "/var/log/app", MountPath: "/logs", ReadOnly: true}},
+		},
+		{
+			Name:    "proxy",
+			Image:   "gcr.io/cloud-sql-connectors/cloud-sql-proxy:2.11",
+			Command: []string{"/cloud-sql-proxy", "--port", "5432", "project:region:instance"},
+			Ports:   []kobject.Ports{{ContainerPort: 5432, Protocol: "TCP"}, {ContainerPort: 9090, Protocol: "UDP"}},
+		},
+	}
+	if !reflect.DeepEqual(serviceConfig.Sidecars, expected) {
+		t.Errorf("Expected the sidecars %+v, got %+v", expected, serviceConfig.Sidecars)
+	}
+
+	for _, invalid := range []types.Labels{
+		{LabelSidecarPrefix + "proxy.command": "/cloud-sql-proxy"},
+		{LabelSidecarPrefix + "Proxy.image": "proxy"},
+		{LabelSidecarPrefix + "proxy.image": "proxy", LabelSidecarPrefix + "proxy.ports": "http"},
+		{LabelSidecarPrefix + "proxy.image": "proxy", LabelSidecarPrefix + "proxy.mounts": "logs:/logs"},
+		{LabelSidecarPrefix + "proxy.image": "proxy", LabelSidecarPrefix + "proxy.env": "A=B"},
+	} {
+		if err := parseKomposeLabels(invalid, &kobject.ServiceConfig{}); err == nil {
+			t.Errorf("Expected an error for labels %v", invalid)
+		}
+	}
+}
+
+func TestParseInitContainerLabels(t *testing.T) {
+	serviceConfig := kobject.ServiceConfig{}
+	labels := types.Labels{
+		LabelInitContainerImage:                       "busybox",
+		LabelInitContainersPrefix + "migrate.image":   "migrate/migrate:v4",
+		LabelInitContainersPrefix + "migrate.command": "migrate -path /migrations -database $DATABASE_URL up",
+		LabelInitContainersPrefix + "migrate.env":     "DATABASE_URL=postgres://db/app?sslmode=disable, LOG=debug",
+		LabelInitContainersPrefix + "migrate.mounts":  "/migrations:/migrations:ro",
+		LabelInitContainersPrefix + "chown.image":     "busybox",
+	}
+	if err := parseKomposeLabels(labels, &serviceConfig); err != nil {
+		t.Fatalf("parseKomposeLabels(): %v", err)
+	}
+	expected := []kobject.InitContainer{
+		{Name: "chown", Image: "busybox"},
+		{
+			Name:    "migrate",
+			Image:   "migrate/migrate:v4",
+			Command: []string{"migrate", "-path", "/migrations", "-database", "$DATABASE_URL", "up"},
+			Env:     []kobject.EnvVar{{Name: "DATABASE_URL", Value: "postgres://db/app?sslmode=disable"}, {Name: "LOG", Value: "debug"}},
+			Mounts:  []kobject.SidecarMount{{Path: "/migrations", MountPath: "/migrations", ReadOnly: true}},
+		},
+	}
+	if !reflect.DeepEqual(serviceConfig.InitContainers, expected) {
+		t.Errorf("Expected the init containers %+v, got %+v", expected, serviceConfig.InitContainers)
+	}
+	if serviceConfig.Labels[LabelInitContainerImage] != "busybox" {
+		t.Errorf("Expected the label of the single init container to be kept, got %v", serviceConfig.Labels)
+	}
+
+	for _, invalid := range []types.Labels{
+		{LabelInitContainersPrefix + "migrate.command": "migrate up"},
+		{LabelInitContainersPrefix + "Migrate.image": "migrate"},
+		{LabelInitContainersPrefix + "migrate.image": "migrate", LabelInitContainersPrefix + "migrate.env": "DEBUG"},
+		{LabelInitContainersPrefix + "migrate.image": "migrate", LabelInitContainersPrefix + "migrate.ports": "80"},
+	} {
+		if err := parseKomposeLabels(invalid, &kobject.ServiceConfig{}); err == nil {
+			t.Errorf("Expected an error for labels %v", invalid)
+		}
+	}
+}
+
+func TestValidateKomposeLabels(t *testing.T) {
+	testCases := []struct {
+		labels  types.Labels
+		wantErr string
+	}{
+		{types.Labels{LabelServiceType: "NodePort", LabelControllerPaused: "true", "app": "web"}, ""},
+		{types.Labels{"kompose.service.tpye": "nodeport"}, ""},
+		{types.Labels{LabelControllerType: "replicaset"}, `label kompose.controller.type: unknown value "replicaset"`},
+		{types.Labels{LabelImagePullPolicy: "always"}, `label kompose.image-pull-policy: unknown value "always"`},
+		{types.Labels{LabelExposeContainerToHost: "yes"}, `label kompose.controller.port.expose: unknown value "yes", a boolean is expected`},
+		{types.Labels{HealthCheckReadinessHTTPHeaders: "Host"}, `label kompose.service.healthcheck.readiness.http_headers: invalid header "Host", expected Name=value`},
+		{types.Labels{HealthCheckReadinessHTTPScheme: "tcp"}, `label kompose.service.healthcheck.readiness.http_scheme: unknown value "tcp"`},
+		{types.Labels{LabelSidecarPrefix + "proxy.ports": "5432/http"}, `label kompose.sidecar.proxy.ports: invalid protocol "HTTP" of port "5432/http"`},
+		{types.Labels{LabelSidecarPrefix + "proxy.image": "envoyproxy/envoy"}, ""},
+		{types.Labels{LabelDeploymentStrategy: "bluegreen"}, `label kompose.deployment.strategy: unknown value "bluegreen"`},
+		{types.Labels{LabelStatefulSetServiceName: "db_headless"}, `label kompose.statefulset.service-name: invalid name "db_headless"`},
+		{types.Labels{LabelInitContainersPrefix + "migrate.env": "DEBUG"}, `label kompose.init.containers.migrate.env: invalid environment variable "DEBUG", expected NAME=value`},
+		{types.Labels{LabelServiceLoadBalancerIP: "10.0.0.300"}, `label kompose.service.loadbalancerip: invalid value "10.0.0.300", an IP address is expected`},
+		{types.Labels{LabelEnvFromSecret: "DB_PASSWORD=db-credentials/password, API_TOKEN=api/token"}, ""},
+		{types.Labels{LabelEnvFromSecret: "DB_PASSWORD=db-credentials"}, `label kompose.env.from-secret: invalid value "DB_PASSWORD=db-credentials", expected VAR=secret/key`},
+		{types.Labels{LabelServiceAnnotationPrefix + "service.beta.kubernetes.io/aws-load-balancer-type": "nlb"}, ""},
+	}
+
+	for _, testCase := range testCases {
+		err := validateKomposeLabels(testCase.labels)
+		if testCase.wantErr == "" && err != nil {
+			t.Errorf("Expected no error for %v, got %v", testCase.labels, err)
+		}
+		if testCase.wantErr != "" && (err == nil || !strings.Contains(err.Error(), testCase.wantErr)) {
+			t.Errorf("Expected error %q for %v, got %v", testCase.wantErr, testCase.labels, err)
+		}
+	}
+}
+
+func TestClosestKomposeLabel(t *testing.T) {
+	testCases := map[string]string{
+		"kompose.service.tpye":        LabelServiceType,
+		"kompose.servce.type":         LabelServiceType,
+		"kompose.controler.type":      LabelControllerType,
+		"kompose.volume.storage-size": "",
+		"kompose.something.else":      "",
+	}
+
+	for label, want := range testCases {
+		if got := closestKomposeLabel(label); got != want {
+			t.Errorf("Expected suggestion %q for %q, got %q", want, label, got)
+		}
+	}
+}
+
 func Test_parseKomposeLabels(t *testing.T) {
 	service := kobject.ServiceConfig{
 		Name:          "name",
@@ -757,3 +1443,173 @@ func Test_parseKomposeLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadFileSelectedServices(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/compose.yaml"
+	content := `services:
+  web:
+    image: nginx
+    depends_on: [api]
+  api:
+    image: api
+    volumes:
+      - data:/data
+    secrets: [token]
+  worker:
+    image: worker
+    configs: [settings]
+volumes:
+  data: {}
+secrets:
+  token:
+    environment: TOKEN
+configs:
+  settings:
+    content: debug
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	komposeObject, err := new(Compose).LoadFile("shop", []string{file}, "", nil, []string{"web"}, false, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(komposeObject.ServiceConfigs) != 2 {
+		t.Errorf("Expected web and the api it depends on, got %v", komposeObject.ServiceConfigs)
+	}
+	if _, ok := komposeObject.ServiceConfigs["worker"]; ok {
+		t.Errorf("Expected the worker service to be skipped")
+	}
+	if _, ok := komposeObject.Secrets["token"]; !ok || len(komposeObject.Secrets) != 1 {
+		t.Errorf("Expected the secret of api only, got %v", komposeObject.Secrets)
+	}
+
+	if _, err := new(Compose).LoadFile("shop", []string{file}, "", nil, []string{"nope"}, false, nil, nil); err == nil {
+		t.Errorf("Expected an error for an unknown service")
+	}
+}
+
+func TestLoadFileExtends(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(dir+"/common", 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"common/base.yaml": `services:
+  app:
+    image: app:1.0
+    environment:
+      MODE: dev
+      LOG_LEVEL: info
+    env_file: app.env
+    volumes:
+      - ./config:/etc/app
+      - data:/data
+`,
+		"common/app.env": "TOKEN=secret\n",
+		"compose.yaml": `services:
+  web:
+    extends:
+      file: common/base.yaml
+      service: app
+    environment:
+      MODE: production
+    volumes:
+      - ./cache:/var/cache/app
+volumes:
+  data: {}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(dir+"/"+name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	komposeObject, err := new(Compose).LoadFile("shop", []string{dir + "/compose.yaml"}, "", nil, nil, false, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	web := komposeObject.ServiceConfigs["web"]
+	if web.Image != "app:1.0" {
+		t.Errorf("Expected the image of the extended service, got %q", web.Image)
+	}
+	env := map[string]string{}
+	for _, e := range web.Environment {
+		env[e.Name] = e.Value
+	}
+	if env["MODE"] != "production" || env["LOG_LEVEL"] != "info" {
+		t.Errorf("Expected the environment merged with the extended one, got %v", web.Environment)
+	}
+	if len(web.EnvFile) != 1 || web.EnvFile[0] != dir+"/common/app.env" {
+		t.Errorf("Expected the env_file resolved against the extended file, got %v", web.EnvFile)
+	}
+	for _, volume := range []string{dir + "/common/config:/etc/app", "data:/data", dir + "/cache:/var/cache/app"} {
+		if !slices.Contains(web.VolList, volume) {
+			t.Errorf("Expected the volume %s, got %v", volume, web.VolList)
+		}
+	}
+}
+
+func TestRemoteResourceLoader(t *testing.T) {
+	r := remoteResourceLoader{}
+	for file, expected := range map[string]bool{
+		"https://example.com/base.yaml":                       true,
+		"git::https://github.com/example/stack.git//base.yml": true,
+		"common/base.yaml":                                    false,
+	} {
+		if r.Accept(file) != expected {
+			t.Errorf("Expected %s to be accepted: %v", file, expected)
+		}
+	}
+}
+
+func TestLoadFileRemoteExtendsNotAllowed(t *testing.T) {
+	dir := t.TempDir()
+	compose := "services:\n  web:\n    extends:\n      file: https://example.com/base.yaml\n      service: base\n"
+	if err := os.WriteFile(dir+"/compose.yaml", []byte(compose), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := new(Compose).LoadFile("shop", []string{dir + "/compose.yaml"}, "", nil, nil, false, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "--allow-remote-extends") {
+		t.Errorf("Expected the remote extended file to be rejected, got %v", err)
+	}
+}
+
+func TestLoadFileInterpolation(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"compose.yaml": "services:\n  web:\n    image: app:${KOMPOSE_TEST_TAG}\n",
+		".env":         "KOMPOSE_TEST_TAG=dotenv\n",
+		"prod.env":     "KOMPOSE_TEST_TAG=prod\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(dir+"/"+name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := map[string]struct {
+		envFiles []string
+		env      []string
+		expected string
+	}{
+		"dot env":  {nil, nil, "app:dotenv"},
+		"env file": {[]string{dir + "/prod.env"}, nil, "app:prod"},
+		"env":      {[]string{dir + "/prod.env"}, []string{"KOMPOSE_TEST_TAG=cli"}, "app:cli"},
+	}
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			komposeObject, err := new(Compose).LoadFile("shop", []string{dir + "/compose.yaml"}, "", nil, nil, false, test.envFiles, test.env)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if image := komposeObject.ServiceConfigs["web"].Image; image != test.expected {
+				t.Errorf("Expected the image %s, got %s", test.expected, image)
+			}
+		})
+	}
+}
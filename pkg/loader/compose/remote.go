@@ -0,0 +1,79 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compose
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/remote"
+	"github.com/pkg/errors"
+)
+
+// remoteResourceLoader loads the files of the extends of the services given as http(s) URLs or in git
+// repositories, as the remote compose files of the command line. The compose files choose the files they
+// extend, so the remote ones are only fetched when they are allowed, they are rejected otherwise.
+type remoteResourceLoader struct {
+	allowed bool
+}
+
+// Accept tells whether the file is fetched, from an http(s) URL or a git repository
+func (remoteResourceLoader) Accept(file string) bool {
+	return remote.IsRemote(file)
+}
+
+// Load returns the local copy of the file
+func (r remoteResourceLoader) Load(_ context.Context, file string) (string, error) {
+	if !r.allowed {
+		return "", errors.Errorf("the remote file %s isn't loaded, allow the remote extended files with --allow-remote-extends", file)
+	}
+	return remote.Fetch(file)
+}
+
+// Dir returns the directory of the local copy of the file, the relative paths of the file are resolved against it
+func (r remoteResourceLoader) Dir(file string) string {
+	if !r.allowed {
+		return "."
+	}
+	local, err := remote.Fetch(file)
+	if err != nil {
+		return "."
+	}
+	return filepath.Dir(local)
+}
+
+// restrictedResourceLoader rejects the local extended and included files outside of the directory, given
+// as absolute paths or as .. paths leading outside of it. The other files are loaded by compose-go.
+type restrictedResourceLoader struct {
+	dir string
+}
+
+// Accept tells whether the file is local and outside of the directory
+func (r restrictedResourceLoader) Accept(file string) bool {
+	return !remote.IsRemote(file) && !transformer.InDir(r.dir, file)
+}
+
+// Load rejects the file
+func (r restrictedResourceLoader) Load(_ context.Context, file string) (string, error) {
+	return "", errors.Errorf("the file %s is outside of the directory of the compose files", file)
+}
+
+// Dir returns the directory, the file is never loaded
+func (restrictedResourceLoader) Dir(string) string {
+	return "."
+}
@@ -17,16 +17,24 @@ limitations under the License.
 package compose
 
 import (
+	"fmt"
 	"io"
+	"net"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 
+	appsv1 "k8s.io/api/apps/v1"
 	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -34,20 +42,55 @@ const (
 	LabelServiceType = "kompose.service.type"
 	// LabelServiceExternalTrafficPolicy defines the external policy traffic of service to be created
 	LabelServiceExternalTrafficPolicy = "kompose.service.external-traffic-policy"
+	// LabelServiceInternalTrafficPolicy defines whether the traffic from the pods of the cluster is only routed to the pods of their node
+	LabelServiceInternalTrafficPolicy = "kompose.service.internal-traffic-policy"
+	// LabelServiceSessionAffinity defines whether the connections of a client are routed to the same pod
+	LabelServiceSessionAffinity = "kompose.service.session-affinity"
+	// LabelServiceSessionAffinityTimeout defines the seconds the connections of a client stick to the same pod
+	LabelServiceSessionAffinityTimeout = "kompose.service.session-affinity.timeout"
 	// LabelServiceGroup defines the group of services in a single pod
 	LabelServiceGroup = "kompose.service.group"
 	// LabelNodePortPort defines the port value for NodePort service
 	LabelNodePortPort = "kompose.service.nodeport.port"
+	// LabelServiceLoadBalancerIP defines the IP requested for the load balancer of a LoadBalancer service
+	LabelServiceLoadBalancerIP = "kompose.service.loadbalancerip"
+	// LabelServiceLoadBalancerClass defines the load balancer implementation of a LoadBalancer service
+	LabelServiceLoadBalancerClass = "kompose.service.loadbalancer-class"
+	// LabelServiceAnnotationPrefix prefixes the labels added as annotations of the service, without the prefix
+	LabelServiceAnnotationPrefix = "kompose.service.annotation."
 	// LabelServiceExpose defines if the service needs to be made accessible from outside the cluster or not
 	LabelServiceExpose = "kompose.service.expose"
 	// LabelServiceExposeTLSSecret provides the name of the TLS secret to use with the Kubernetes ingress controller
 	LabelServiceExposeTLSSecret = "kompose.service.expose.tls-secret"
 	// LabelServiceExposeIngressClassName provides the name of ingress class to use with the Kubernetes ingress controller
 	LabelServiceExposeIngressClassName = "kompose.service.expose.ingress-class-name"
+	// LabelRouteTLSTermination defines the TLS termination of the OpenShift Route exposing the service
+	LabelRouteTLSTermination = "kompose.service.expose.route.tls-termination"
+	// LabelRouteInsecureEdgeTerminationPolicy defines what the OpenShift Route does with the plain HTTP requests
+	LabelRouteInsecureEdgeTerminationPolicy = "kompose.service.expose.route.insecure-edge-termination-policy"
+	// LabelRouteCertificate, LabelRouteKey and LabelRouteCACertificate are the PEM files of the certificate
+	// served by the OpenShift Route, of its key and of its certificate authority
+	LabelRouteCertificate   = "kompose.service.expose.route.certificate"
+	LabelRouteKey           = "kompose.service.expose.route.key"
+	LabelRouteCACertificate = "kompose.service.expose.route.ca-certificate"
+	// LabelRouteDestinationCACertificate is the PEM file of the certificate authority of the service, trusted
+	// by the OpenShift Route re-encrypting the requests
+	LabelRouteDestinationCACertificate = "kompose.service.expose.route.destination-ca-certificate"
 	// LabelServiceAccountName defines the service account name to provide the credential info of the pod.
 	LabelServiceAccountName = "kompose.serviceaccount-name"
+	// LabelPriorityClass defines the priority class name of the pods
+	LabelPriorityClass = "kompose.pod.priority-class"
+	// LabelRuntimeClass defines the runtime class name of the pods, overriding the runtime of the service
+	LabelRuntimeClass = "kompose.pod.runtime-class"
+	// LabelPriorityClassValue defines the value of the priority class, the PriorityClass is generated when set
+	LabelPriorityClassValue = "kompose.pod.priority-class.value"
 	// LabelControllerType defines the type of controller to be created
 	LabelControllerType = "kompose.controller.type"
+	// LabelControllerPaused defines whether the deployment is created paused
+	LabelControllerPaused = "kompose.controller.paused"
+	// LabelDeploymentStrategy defines whether the pods of the deployment are recreated or replaced
+	// with a rolling update
+	LabelDeploymentStrategy = "kompose.deployment.strategy"
 	// LabelImagePullSecret defines a secret name for kubernetes ImagePullSecrets
 	LabelImagePullSecret = "kompose.image-pull-secret"
 	// LabelImagePullPolicy defines Kubernetes PodSpec imagePullPolicy.
@@ -68,12 +111,42 @@ const (
 	HealthCheckReadinessHTTPGetPath = "kompose.service.healthcheck.readiness.http_get_path"
 	// HealthCheckReadinessHTTPGetPort defines readiness health check HttpGet port
 	HealthCheckReadinessHTTPGetPort = "kompose.service.healthcheck.readiness.http_get_port"
+	// HealthCheckReadinessHTTPHeaders defines readiness health check HttpGet headers, as comma separated Name=value pairs
+	HealthCheckReadinessHTTPHeaders = "kompose.service.healthcheck.readiness.http_headers"
+	// HealthCheckReadinessHTTPScheme defines readiness health check HttpGet scheme, http or https
+	HealthCheckReadinessHTTPScheme = "kompose.service.healthcheck.readiness.http_scheme"
 	// HealthCheckReadinessTCPPort defines readiness health check tcp port
 	HealthCheckReadinessTCPPort = "kompose.service.healthcheck.readiness.tcp_port"
+	// HealthCheckStartupDisable defines startup health check disable
+	HealthCheckStartupDisable = "kompose.service.healthcheck.startup.disable"
+	// HealthCheckStartupTest defines startup health check test
+	HealthCheckStartupTest = "kompose.service.healthcheck.startup.test"
+	// HealthCheckStartupInterval defines startup health check interval
+	HealthCheckStartupInterval = "kompose.service.healthcheck.startup.interval"
+	// HealthCheckStartupTimeout defines startup health check timeout
+	HealthCheckStartupTimeout = "kompose.service.healthcheck.startup.timeout"
+	// HealthCheckStartupRetries defines startup health check retries
+	HealthCheckStartupRetries = "kompose.service.healthcheck.startup.retries"
+	// HealthCheckStartupStartPeriod defines startup health check start period
+	HealthCheckStartupStartPeriod = "kompose.service.healthcheck.startup.start_period"
+	// HealthCheckStartupHTTPGetPath defines startup health check HttpGet path
+	HealthCheckStartupHTTPGetPath = "kompose.service.healthcheck.startup.http_get_path"
+	// HealthCheckStartupHTTPGetPort defines startup health check HttpGet port
+	HealthCheckStartupHTTPGetPort = "kompose.service.healthcheck.startup.http_get_port"
+	// HealthCheckStartupHTTPHeaders defines startup health check HttpGet headers, as comma separated Name=value pairs
+	HealthCheckStartupHTTPHeaders = "kompose.service.healthcheck.startup.http_headers"
+	// HealthCheckStartupHTTPScheme defines startup health check HttpGet scheme, http or https
+	HealthCheckStartupHTTPScheme = "kompose.service.healthcheck.startup.http_scheme"
+	// HealthCheckStartupTCPPort defines startup health check tcp port
+	HealthCheckStartupTCPPort = "kompose.service.healthcheck.startup.tcp_port"
 	// HealthCheckLivenessHTTPGetPath defines liveness health check HttpGet path
 	HealthCheckLivenessHTTPGetPath = "kompose.service.healthcheck.liveness.http_get_path"
 	// HealthCheckLivenessHTTPGetPort defines liveness health check HttpGet port
 	HealthCheckLivenessHTTPGetPort = "kompose.service.healthcheck.liveness.http_get_port"
+	// HealthCheckLivenessHTTPHeaders defines liveness health check HttpGet headers, as comma separated Name=value pairs
+	HealthCheckLivenessHTTPHeaders = "kompose.service.healthcheck.liveness.http_headers"
+	// HealthCheckLivenessHTTPScheme defines liveness health check HttpGet scheme, http or https
+	HealthCheckLivenessHTTPScheme = "kompose.service.healthcheck.liveness.http_scheme"
 	// HealthCheckLivenessTCPPort defines liveness health check tcp port
 	HealthCheckLivenessTCPPort = "kompose.service.healthcheck.liveness.tcp_port"
 	// ServiceTypeHeadless ...
@@ -88,12 +161,30 @@ const (
 	LabelCronJobConcurrencyPolicy = "kompose.cronjob.concurrency_policy"
 	// LabelCronJobBackoffLimit defines the job backoff limit
 	LabelCronJobBackoffLimit = "kompose.cronjob.backoff_limit"
+	// LabelJobPodFailurePolicyFailExitCodes defines the container exit codes that fail the job without retry
+	LabelJobPodFailurePolicyFailExitCodes = "kompose.job.pod_failure_policy.fail_exit_codes"
+	// LabelJobPodFailurePolicyIgnoreDisruptions defines whether pod disruptions count against the job backoff limit
+	LabelJobPodFailurePolicyIgnoreDisruptions = "kompose.job.pod_failure_policy.ignore_disruptions"
+	// LabelJobParallelism defines the maximum number of pods a job runs in parallel
+	LabelJobParallelism = "kompose.job.parallelism"
+	// LabelJobCompletions defines the number of successful pods a job needs to be completed
+	LabelJobCompletions = "kompose.job.completions"
+	// LabelJobTTLSecondsAfterFinished defines how long a finished job is kept before it is deleted
+	LabelJobTTLSecondsAfterFinished = "kompose.job.ttl_seconds_after_finished"
+	// LabelJobActiveDeadlineSeconds defines how long a job may be active before it is terminated
+	LabelJobActiveDeadlineSeconds = "kompose.job.active_deadline_seconds"
 	// LabelInitContainerName defines name resource
 	LabelInitContainerName = "kompose.init.containers.name"
 	// LabelInitContainerImage defines image to pull
 	LabelInitContainerImage = "kompose.init.containers.image"
 	// LabelInitContainerCommand defines commands
 	LabelInitContainerCommand = "kompose.init.containers.command"
+	// LabelInitContainersPrefix prefixes the labels of the init containers of the service, as
+	// kompose.init.containers.<name>.image, command, env and mounts
+	LabelInitContainersPrefix = "kompose.init.containers."
+	// LabelSidecarPrefix prefixes the labels of the sidecar containers added to the pods of the service,
+	// as kompose.sidecar.<name>.image, command, ports and mounts
+	LabelSidecarPrefix = "kompose.sidecar."
 	// LabelHpaMinReplicas defines min pod replicas
 	LabelHpaMinReplicas = "kompose.hpa.replicas.min"
 	// LabelHpaMaxReplicas defines max pod replicas
@@ -106,8 +197,469 @@ const (
 	LabelNameOverride = "kompose.service.name_override"
 	// LabelExposeContainerToHost defines whether to expose container to host or not using hostPort
 	LabelExposeContainerToHost = "kompose.controller.port.expose"
+	// LabelEnableServiceLinks defines whether docker links style service environment variables are injected into the pod
+	LabelEnableServiceLinks = "kompose.enable-service-links"
+	// LabelVolumeType defines the type of kubernetes volume created for the volumes of the service
+	LabelVolumeType = "kompose.volume.type"
+	// LabelVolumeSize defines the size of the persistent volume claims of the service
+	LabelVolumeSize = "kompose.volume.size"
+	// LabelVolumeStorageClassName defines the storage class of the persistent volume claims of the service
+	LabelVolumeStorageClassName = "kompose.volume.storage-class-name"
+	// LabelVolumeConfigMapIgnore defines the globs of the files left out of the ConfigMaps of the directories
+	LabelVolumeConfigMapIgnore = "kompose.volume.configmap-ignore"
+	// LabelVolumeConfigMapRecursive defines whether the files of the subdirectories are included in the ConfigMaps of the directories
+	LabelVolumeConfigMapRecursive = "kompose.volume.configmap-recursive"
+	// LabelVolumeWritablePaths defines the paths mounted as emptyDir volumes when the root filesystem is read only
+	LabelVolumeWritablePaths = "kompose.volume.writable-paths"
+	// LabelVolumeSelector defines the label selecting the persistent volume of the claim of a top level volume
+	LabelVolumeSelector = "kompose.volume.selector"
+	// LabelVolumeStorageClass defines the storage class of the claim of a top level volume
+	LabelVolumeStorageClass = "kompose.volume.storage-class"
+	// LabelVolumeAccessMode defines the access mode of the claim of a top level volume
+	LabelVolumeAccessMode = "kompose.volume.access-mode"
+	// LabelVolumeCSIDriver defines the CSI driver of the inline volume of a top level volume, instead of a claim
+	LabelVolumeCSIDriver = "kompose.volume.csi-driver"
+	// LabelEnvFromSecret defines the environment variables read from the keys of existing secrets, as VAR=secret/key
+	LabelEnvFromSecret = "kompose.env.from-secret"
+	// LabelGPUResource defines the extended resource name the GPUs of the service are requested with
+	LabelGPUResource = "kompose.gpu.resource"
+	// LabelBuildStrategy defines the strategy of the OpenShift BuildConfig, docker or source (S2I)
+	LabelBuildStrategy = "kompose.build.strategy"
+	// LabelBuildBuilderImage defines the builder image of the source (S2I) builds
+	LabelBuildBuilderImage = "kompose.build.builder-image"
+	// LabelBuildWebhooks defines the webhooks triggering the builds of the OpenShift BuildConfig
+	LabelBuildWebhooks = "kompose.build.webhooks"
+	// LabelBuildOutputTag defines the ImageStream tag the built image is pushed to
+	LabelBuildOutputTag = "kompose.build.output-tag"
+	// LabelAntiAffinity defines whether the pods of the service are required or preferred on different nodes
+	LabelAntiAffinity = "kompose.affinity.anti-affinity"
+	// LabelAntiAffinityTopologyKey defines the node label the pods of the service are spread across
+	LabelAntiAffinityTopologyKey = "kompose.affinity.topology-key"
+	// LabelStatefulSetPodManagementPolicy defines whether the pods of the StatefulSet are started one
+	// after the other or in parallel
+	LabelStatefulSetPodManagementPolicy = "kompose.statefulset.pod-management-policy"
+	// LabelStatefulSetUpdateStrategy defines whether the pods of the StatefulSet are replaced on updates
+	// or when they are deleted
+	LabelStatefulSetUpdateStrategy = "kompose.statefulset.update-strategy"
+	// LabelStatefulSetServiceName defines the Service governing the network identity of the pods of the StatefulSet
+	LabelStatefulSetServiceName = "kompose.statefulset.service-name"
+	// LabelStatefulSetPVCRetentionWhenDeleted defines whether the claims of the StatefulSet are deleted with it
+	LabelStatefulSetPVCRetentionWhenDeleted = "kompose.statefulset.pvc-retention.when-deleted"
+	// LabelStatefulSetPVCRetentionWhenScaled defines whether the claims of the pods removed by a scale down are deleted
+	LabelStatefulSetPVCRetentionWhenScaled = "kompose.statefulset.pvc-retention.when-scaled"
 )
 
+// komposeLabels lists all the kompose labels supported on a service, with the
+// validation of their value when it is restricted (nil accepts any value)
+var komposeLabels = map[string]func(value string) error{
+	LabelServiceType:                          oneOf(false, "nodeport", "clusterip", "loadbalancer", "headless", "hostport"),
+	LabelServiceExternalTrafficPolicy:         oneOf(false, "local", "cluster"),
+	LabelServiceInternalTrafficPolicy:         oneOf(false, "local", "cluster"),
+	LabelServiceSessionAffinity:               oneOf(false, "clientip", "none"),
+	LabelServiceSessionAffinityTimeout:        isInt32,
+	LabelServiceGroup:                         nil,
+	LabelNodePortPort:                         nil,
+	LabelServiceLoadBalancerIP:                isIP,
+	LabelServiceLoadBalancerClass:             nil,
+	LabelServiceExpose:                        nil,
+	LabelServiceExposeTLSSecret:               nil,
+	LabelServiceExposeIngressClassName:        nil,
+	LabelRouteTLSTermination:                  oneOf(false, "edge", "passthrough", "reencrypt"),
+	LabelRouteInsecureEdgeTerminationPolicy:   oneOf(false, "none", "allow", "redirect"),
+	LabelRouteCertificate:                     nil,
+	LabelRouteKey:                             nil,
+	LabelRouteCACertificate:                   nil,
+	LabelRouteDestinationCACertificate:        nil,
+	LabelServiceAccountName:                   nil,
+	LabelPriorityClass:                        nil,
+	LabelPriorityClassValue:                   isInt32,
+	LabelRuntimeClass:                         nil,
+	LabelControllerType:                       oneOf(false, "deployment", "daemonset", "statefulset"),
+	LabelControllerPaused:                     isBool,
+	LabelDeploymentStrategy:                   oneOf(false, "recreate", "rollingupdate"),
+	LabelImagePullSecret:                      nil,
+	LabelImagePullPolicy:                      oneOf(true, "Always", "Never", "IfNotPresent"),
+	HealthCheckReadinessDisable:               isBool,
+	HealthCheckReadinessTest:                  nil,
+	HealthCheckReadinessInterval:              nil,
+	HealthCheckReadinessTimeout:               nil,
+	HealthCheckReadinessRetries:               nil,
+	HealthCheckReadinessStartPeriod:           nil,
+	HealthCheckReadinessHTTPGetPath:           nil,
+	HealthCheckReadinessHTTPGetPort:           nil,
+	HealthCheckReadinessHTTPHeaders:           isHTTPHeaders,
+	HealthCheckReadinessHTTPScheme:            oneOf(false, "http", "https"),
+	HealthCheckReadinessTCPPort:               nil,
+	HealthCheckStartupDisable:                 isBool,
+	HealthCheckStartupTest:                    nil,
+	HealthCheckStartupInterval:                nil,
+	HealthCheckStartupTimeout:                 nil,
+	HealthCheckStartupRetries:                 nil,
+	HealthCheckStartupStartPeriod:             nil,
+	HealthCheckStartupHTTPGetPath:             nil,
+	HealthCheckStartupHTTPGetPort:             nil,
+	HealthCheckStartupHTTPHeaders:             isHTTPHeaders,
+	HealthCheckStartupHTTPScheme:              oneOf(false, "http", "https"),
+	HealthCheckStartupTCPPort:                 nil,
+	HealthCheckLivenessHTTPGetPath:            nil,
+	HealthCheckLivenessHTTPGetPort:            nil,
+	HealthCheckLivenessHTTPHeaders:            isHTTPHeaders,
+	HealthCheckLivenessHTTPScheme:             oneOf(false, "http", "https"),
+	HealthCheckLivenessTCPPort:                nil,
+	LabelSecurityContextFsGroup:               nil,
+	LabelContainerVolumeSubpath:               nil,
+	LabelCronJobSchedule:                      nil,
+	LabelCronJobConcurrencyPolicy:             nil,
+	LabelCronJobBackoffLimit:                  nil,
+	LabelJobPodFailurePolicyFailExitCodes:     nil,
+	LabelJobPodFailurePolicyIgnoreDisruptions: isBool,
+	LabelJobParallelism:                       nil,
+	LabelJobCompletions:                       nil,
+	LabelJobTTLSecondsAfterFinished:           nil,
+	LabelJobActiveDeadlineSeconds:             nil,
+	LabelInitContainerName:                    nil,
+	LabelInitContainerImage:                   nil,
+	LabelInitContainerCommand:                 nil,
+	LabelHpaMinReplicas:                       nil,
+	LabelHpaMaxReplicas:                       nil,
+	LabelHpaCPU:                               nil,
+	LabelHpaMemory:                            nil,
+	LabelNameOverride:                         nil,
+	LabelExposeContainerToHost:                isBool,
+	LabelEnableServiceLinks:                   isBool,
+	LabelVolumeType:                           oneOf(true, "configMap", "persistentVolumeClaim", "emptyDir", "hostPath"),
+	LabelVolumeSize:                           nil,
+	LabelVolumeStorageClassName:               nil,
+	LabelVolumeConfigMapIgnore:                nil,
+	LabelVolumeConfigMapRecursive:             isBool,
+	LabelVolumeWritablePaths:                  nil,
+	LabelGPUResource:                          nil,
+	LabelEnvFromSecret:                        isEnvFromSecret,
+	LabelBuildStrategy:                        oneOf(false, "docker", "source"),
+	LabelBuildBuilderImage:                    nil,
+	LabelBuildWebhooks:                        listOf("github", "gitlab", "bitbucket", "generic"),
+	LabelBuildOutputTag:                       nil,
+	LabelAntiAffinity:                         oneOf(false, "preferred", "required", "none"),
+	LabelAntiAffinityTopologyKey:              nil,
+	LabelStatefulSetPodManagementPolicy:       oneOf(false, "orderedready", "parallel"),
+	LabelStatefulSetUpdateStrategy:            oneOf(false, "rollingupdate", "ondelete"),
+	LabelStatefulSetServiceName:               isDNS1035Label,
+	LabelStatefulSetPVCRetentionWhenDeleted:   oneOf(false, "retain", "delete"),
+	LabelStatefulSetPVCRetentionWhenScaled:    oneOf(false, "retain", "delete"),
+}
+
+// sidecarLabels lists the labels of a sidecar, after kompose.sidecar.<name>., with their validation
+var sidecarLabels = map[string]func(value string) error{
+	"image":   nil,
+	"command": nil,
+	"ports":   isSidecarPorts,
+	"mounts":  isSidecarMounts,
+}
+
+// initContainerLabels lists the labels of an init container, after kompose.init.containers.<name>.,
+// with their validation
+var initContainerLabels = map[string]func(value string) error{
+	"image":   nil,
+	"command": nil,
+	"env":     isEnvVars,
+	"mounts":  isSidecarMounts,
+}
+
+// lookupKomposeLabel returns the validation of a kompose label, and whether the label is supported.
+// Besides the labels of komposeLabels, the service annotations, the sidecars and the init containers
+// are set with label prefixes.
+func lookupKomposeLabel(key string) (func(value string) error, bool) {
+	if validate, ok := komposeLabels[key]; ok {
+		return validate, true
+	}
+	if annotation := strings.TrimPrefix(key, LabelServiceAnnotationPrefix); annotation != key && annotation != "" {
+		return nil, true
+	}
+	if sidecar := strings.TrimPrefix(key, LabelSidecarPrefix); sidecar != key {
+		name, field, found := strings.Cut(sidecar, ".")
+		if validate, ok := sidecarLabels[field]; found && ok && len(validation.IsDNS1123Label(name)) == 0 {
+			return validate, true
+		}
+	}
+	if initContainer := strings.TrimPrefix(key, LabelInitContainersPrefix); initContainer != key {
+		name, field, found := strings.Cut(initContainer, ".")
+		if validate, ok := initContainerLabels[field]; found && ok && len(validation.IsDNS1123Label(name)) == 0 {
+			return validate, true
+		}
+	}
+	return nil, false
+}
+
+// oneOf returns a validation accepting only the given values
+func oneOf(caseSensitive bool, values ...string) func(value string) error {
+	return func(value string) error {
+		for _, v := range values {
+			if v == value || (!caseSensitive && strings.EqualFold(v, value)) {
+				return nil
+			}
+		}
+		return errors.Errorf("unknown value %q, supported values are '%s'", value, strings.Join(values, ", "))
+	}
+}
+
+// listOf returns a validation accepting a comma separated list of the given values
+func listOf(values ...string) func(value string) error {
+	validate := oneOf(false, values...)
+	return func(value string) error {
+		for _, item := range strings.Split(value, ",") {
+			if err := validate(strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func isBool(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return errors.Errorf("unknown value %q, a boolean is expected", value)
+	}
+	return nil
+}
+
+func isHTTPHeaders(value string) error {
+	_, err := handleHTTPHeaders(value)
+	return err
+}
+
+func isSidecarPorts(value string) error {
+	_, err := handleSidecarPorts(value)
+	return err
+}
+
+func isSidecarMounts(value string) error {
+	_, err := handleSidecarMounts(value)
+	return err
+}
+
+func isEnvVars(value string) error {
+	_, err := handleEnvVars(value)
+	return err
+}
+
+// isEnvFromSecret validates the comma separated VAR=secret/key list of kompose.env.from-secret
+func isEnvFromSecret(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, ref, ok := strings.Cut(item, "=")
+		secret, key, okRef := strings.Cut(ref, "/")
+		if !ok || !okRef || name == "" || secret == "" || key == "" {
+			return errors.Errorf("invalid value %q, expected VAR=secret/key", item)
+		}
+	}
+	return nil
+}
+
+func isIP(value string) error {
+	if net.ParseIP(value) == nil {
+		return errors.Errorf("invalid value %q, an IP address is expected", value)
+	}
+	return nil
+}
+
+func isDNS1035Label(value string) error {
+	if errs := validation.IsDNS1035Label(value); len(errs) > 0 {
+		return errors.Errorf("invalid name %q: %s", value, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func isInt32(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 32); err != nil {
+		return errors.Errorf("invalid value %q, an integer is expected", value)
+	}
+	return nil
+}
+
+// validateKomposeLabels checks the kompose labels of a service, unknown labels are reported
+// with the closest supported label since they would be silently ignored
+func validateKomposeLabels(labels types.Labels) error {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		if !strings.HasPrefix(key, "kompose.") {
+			continue
+		}
+		validate, ok := lookupKomposeLabel(key)
+		if !ok {
+			if suggestion := closestKomposeLabel(key); suggestion != "" {
+				log.Warnf("Unknown label %q, did you mean %q?", key, suggestion)
+			} else {
+				log.Warnf("Unknown label %q", key)
+			}
+			continue
+		}
+		if validate == nil {
+			continue
+		}
+		if err := validate(labels[key]); err != nil {
+			problems = append(problems, fmt.Sprintf("label %s: %s", key, err))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// KomposeExtension is the service extension the kompose labels can be set in, without their
+// kompose. prefix and optionally nested:
+//
+//	x-kompose:
+//	  controller.type: daemonset
+//	  hpa:
+//	    replicas:
+//	      max: 10
+const KomposeExtension = "x-kompose"
+
+// KubernetesExtension is the service and top level extension holding patches of the generated
+// objects, or raw objects to add to them
+const KubernetesExtension = "x-kubernetes"
+
+// parseKubernetesPatches reads the x-kubernetes extension, a patch or a list of patches
+// identified by the kind of the objects they apply to
+func parseKubernetesPatches(extension interface{}) ([]map[string]interface{}, error) {
+	var items []interface{}
+	switch v := extension.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		items = []interface{}{v}
+	case []interface{}:
+		items = v
+	default:
+		return nil, errors.Errorf("a patch or a list of patches is expected")
+	}
+
+	var patches []map[string]interface{}
+	for i, item := range items {
+		patch, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("patch %d is not a mapping", i+1)
+		}
+		if kind, ok := patch["kind"].(string); !ok || kind == "" {
+			return nil, errors.Errorf("patch %d has no kind", i+1)
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// flattenKomposeExtension converts the x-kompose extension to the kompose labels it sets
+func flattenKomposeExtension(prefix string, value interface{}, labels types.Labels) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if err := flattenKomposeExtension(prefix+"."+key, nested, labels); err != nil {
+				return err
+			}
+		}
+	case string, bool, int, int64, uint64, float64:
+		labels[prefix] = fmt.Sprint(v)
+	case nil:
+		return errors.Errorf("%s has no value", strings.TrimPrefix(prefix, "kompose."))
+	default:
+		return errors.Errorf("%s must be a scalar value, lists are given as comma separated strings", strings.TrimPrefix(prefix, "kompose."))
+	}
+	return nil
+}
+
+// mergeKomposeExtension adds the kompose labels set in the x-kompose extension to the labels of the
+// service. Unlike the labels, an unknown key of the extension is an error.
+func mergeKomposeExtension(labels types.Labels, extension interface{}) (types.Labels, error) {
+	if extension == nil {
+		return labels, nil
+	}
+	if _, ok := extension.(map[string]interface{}); !ok {
+		return nil, errors.Errorf("a mapping of kompose labels is expected")
+	}
+
+	extensionLabels := types.Labels{}
+	if err := flattenKomposeExtension("kompose", extension, extensionLabels); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(extensionLabels))
+	for key := range extensionLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	merged := types.Labels{}
+	for key, value := range labels {
+		merged[key] = value
+	}
+	var problems []string
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, "kompose.")
+		if _, ok := lookupKomposeLabel(key); !ok {
+			if suggestion := closestKomposeLabel(key); suggestion != "" {
+				problems = append(problems, fmt.Sprintf("unknown key %s, did you mean %s?", name, strings.TrimPrefix(suggestion, "kompose.")))
+			} else {
+				problems = append(problems, fmt.Sprintf("unknown key %s", name))
+			}
+			continue
+		}
+		if value, ok := labels[key]; ok && value != extensionLabels[key] {
+			problems = append(problems, fmt.Sprintf("%s is %q while the label %s is %q", name, extensionLabels[key], key, value))
+			continue
+		}
+		merged[key] = extensionLabels[key]
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return merged, nil
+}
+
+// closestKomposeLabel returns the supported label nearest to the given one,
+// or an empty string when none is close enough to be a typo
+func closestKomposeLabel(label string) string {
+	closest := ""
+	minDistance := 4
+	for known := range komposeLabels {
+		distance := levenshtein(label, known)
+		if distance < minDistance || (distance == minDistance && closest != "" && known < closest) {
+			closest = known
+			minDistance = distance
+		}
+	}
+	return closest
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
 // load environment variables from compose file
 func loadEnvVars(envars []string) []kobject.EnvVar {
 	envs := []kobject.EnvVar{}
@@ -162,8 +714,155 @@ func handleServiceType(ServiceType string) (string, error) {
 	case "headless":
 		return ServiceTypeHeadless, nil
 	default:
-		return "", errors.New("Unknown value " + ServiceType + " , supported values are 'nodeport, clusterip, headless or loadbalancer'")
+		return "", errors.New("Unknown value " + ServiceType + " , supported values are 'nodeport, clusterip, headless, loadbalancer or hostport'")
+	}
+}
+
+// handleNodePorts parses the kompose.service.nodeport.port label, the node port of the only port
+// of the service or the node ports of its published ports, as 80:30080,443:30443
+func handleNodePorts(value string) (int32, map[int32]int32, error) {
+	if !strings.Contains(value, ":") {
+		nodePort, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			return 0, nil, errors.Errorf("invalid node port %q", value)
+		}
+		return int32(nodePort), nil, nil
+	}
+
+	nodePorts := map[int32]int32{}
+	for _, mapping := range strings.Split(value, ",") {
+		published, nodePort, _ := strings.Cut(strings.TrimSpace(mapping), ":")
+		p, err := strconv.ParseInt(published, 10, 32)
+		if err != nil {
+			return 0, nil, errors.Errorf("invalid published port %q in %q, expected PUBLISHED:NODEPORT", published, mapping)
+		}
+		n, err := strconv.ParseInt(nodePort, 10, 32)
+		if err != nil {
+			return 0, nil, errors.Errorf("invalid node port %q in %q, expected PUBLISHED:NODEPORT", nodePort, mapping)
+		}
+		nodePorts[int32(p)] = int32(n)
+	}
+	return 0, nodePorts, nil
+}
+
+// handleSidecarPorts parses the ports of a sidecar, comma separated PORT[/PROTOCOL]
+func handleSidecarPorts(value string) ([]kobject.Ports, error) {
+	var ports []kobject.Ports
+	for _, item := range strings.Split(value, ",") {
+		port, protocol, _ := strings.Cut(strings.TrimSpace(item), "/")
+		containerPort, err := strconv.ParseInt(port, 10, 32)
+		if err != nil || containerPort <= 0 || containerPort > 65535 {
+			return nil, errors.Errorf("invalid port %q, expected PORT[/PROTOCOL]", item)
+		}
+		switch protocol = strings.ToUpper(protocol); protocol {
+		case "":
+			protocol = string(api.ProtocolTCP)
+		case string(api.ProtocolTCP), string(api.ProtocolUDP), string(api.ProtocolSCTP):
+		default:
+			return nil, errors.Errorf("invalid protocol %q of port %q, supported values are 'tcp, udp, sctp'", protocol, item)
+		}
+		ports = append(ports, kobject.Ports{ContainerPort: int32(containerPort), Protocol: protocol})
+	}
+	return ports, nil
+}
+
+// handleSidecarMounts parses the mounts of a sidecar or an init container, comma separated
+// PATH[:MOUNT_PATH][:ro] where PATH is where a volume is mounted in the container of the service
+func handleSidecarMounts(value string) ([]kobject.SidecarMount, error) {
+	var mounts []kobject.SidecarMount
+	for _, item := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		mount := kobject.SidecarMount{Path: parts[0], MountPath: parts[0]}
+		if last := parts[len(parts)-1]; len(parts) > 1 && (last == "ro" || last == "rw") {
+			mount.ReadOnly = last == "ro"
+			parts = parts[:len(parts)-1]
+		}
+		if len(parts) == 2 {
+			mount.MountPath = parts[1]
+		}
+		if len(parts) > 2 || !strings.HasPrefix(mount.Path, "/") || !strings.HasPrefix(mount.MountPath, "/") {
+			return nil, errors.Errorf("invalid mount %q, expected PATH[:MOUNT_PATH][:ro]", item)
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
+
+// handlePodManagementPolicy returns the pod management policy of a StatefulSet, orderedready or parallel
+func handlePodManagementPolicy(value string) appsv1.PodManagementPolicyType {
+	if strings.EqualFold(value, "parallel") {
+		return appsv1.ParallelPodManagement
+	}
+	return appsv1.OrderedReadyPodManagement
+}
+
+// handleStatefulSetUpdateStrategy returns the update strategy of a StatefulSet, rollingupdate or ondelete
+func handleStatefulSetUpdateStrategy(value string) appsv1.StatefulSetUpdateStrategyType {
+	if strings.EqualFold(value, "ondelete") {
+		return appsv1.OnDeleteStatefulSetStrategyType
 	}
+	return appsv1.RollingUpdateStatefulSetStrategyType
+}
+
+// handlePVCRetentionPolicy returns the retention policy of the claims of a StatefulSet, retain or delete
+func handlePVCRetentionPolicy(value string) appsv1.PersistentVolumeClaimRetentionPolicyType {
+	if strings.EqualFold(value, "delete") {
+		return appsv1.DeletePersistentVolumeClaimRetentionPolicyType
+	}
+	return appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+}
+
+// validateRouteConfig checks the kompose.service.expose.route labels of a service exposed with expose,
+// the certificates are served by the edge and reencrypt terminations only
+func validateRouteConfig(route kobject.RouteConfig, expose string) error {
+	if expose == "" {
+		return errors.New("the kompose.service.expose.route labels were specified without kompose.service.expose")
+	}
+	switch route.Termination {
+	case "":
+		return errors.Errorf("the kompose.service.expose.route labels require the TLS termination, set %s", LabelRouteTLSTermination)
+	case "passthrough":
+		if route.Certificate != "" || route.Key != "" || route.CACertificate != "" {
+			return errors.New("the passthrough termination serves the certificate of the service, the route certificates can't be set")
+		}
+		if route.InsecureEdgeTerminationPolicy == "allow" {
+			return errors.New("the passthrough termination can't allow the plain HTTP requests, set the insecure edge termination policy to none or redirect")
+		}
+	}
+	if route.DestinationCACertificate != "" && route.Termination != "reencrypt" {
+		return errors.Errorf("%s requires the reencrypt termination", LabelRouteDestinationCACertificate)
+	}
+	if (route.Certificate == "") != (route.Key == "") {
+		return errors.Errorf("%s and %s must be set together", LabelRouteCertificate, LabelRouteKey)
+	}
+	return nil
+}
+
+// handleEnvVars parses the environment variables of an init container, given as comma separated
+// NAME=value pairs
+func handleEnvVars(value string) ([]kobject.EnvVar, error) {
+	var envs []kobject.EnvVar
+	for _, env := range strings.Split(value, ",") {
+		name, envValue, found := strings.Cut(strings.TrimSpace(env), "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, errors.Errorf("invalid environment variable %q, expected NAME=value", env)
+		}
+		envs = append(envs, kobject.EnvVar{Name: strings.TrimSpace(name), Value: strings.TrimSpace(envValue)})
+	}
+	return envs, nil
+}
+
+// handleHTTPHeaders parses the headers of the HTTP probes, given as comma separated Name=value pairs
+func handleHTTPHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, header := range strings.Split(value, ",") {
+		name, headerValue, found := strings.Cut(strings.TrimSpace(header), "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, errors.Errorf("invalid header %q, expected Name=value", header)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+	}
+	return headers, nil
 }
 
 func handleServiceExternalTrafficPolicy(ServiceExternalTrafficPolicyType string) (string, error) {
@@ -177,13 +876,35 @@ func handleServiceExternalTrafficPolicy(ServiceExternalTrafficPolicyType string)
 	}
 }
 
+func handleServiceInternalTrafficPolicy(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "", "cluster":
+		return string(api.ServiceInternalTrafficPolicyCluster), nil
+	case "local":
+		return string(api.ServiceInternalTrafficPolicyLocal), nil
+	default:
+		return "", errors.Errorf("unknown value %q, supported values are 'local, cluster'", value)
+	}
+}
+
+func handleServiceSessionAffinity(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "clientip":
+		return string(api.ServiceAffinityClientIP), nil
+	case "", "none":
+		return string(api.ServiceAffinityNone), nil
+	default:
+		return "", errors.Errorf("unknown value %q, supported values are 'clientip, none'", value)
+	}
+}
+
 func normalizeContainerNames(svcName string) string {
 	return strings.ToLower(svcName)
 }
 
 func normalizeServiceNames(svcName string) string {
 	re := regexp.MustCompile("[._]")
-	return strings.ToLower(re.ReplaceAllString(svcName, "-"))
+	return transformer.TruncateName(strings.ToLower(re.ReplaceAllString(svcName, "-")), transformer.MaxNameLength)
 }
 
 func normalizeVolumes(svcName string) string {
@@ -25,14 +25,15 @@ import (
 
 // Loader interface defines loader that loads files and converts it to kobject representation
 type Loader interface {
-	LoadFile(files []string, profiles []string, noInterpolate bool) (kobject.KomposeObject, error)
+	LoadFile(projectName string, files []string, workingDir string, profiles []string, services []string, noInterpolate bool, envFiles []string, env []string) (kobject.KomposeObject, error)
+	MergedModel(projectName string, files []string, workingDir string, profiles []string, services []string, noInterpolate bool, envFiles []string, env []string) ([]byte, error)
 	///Name() string
 }
 
-// GetLoader returns loader for given format
-func GetLoader(format string) (Loader, error) {
+// GetLoader returns loader for given format, the remote extended files are fetched with --allow-remote-extends
+func GetLoader(format string, opt kobject.ConvertOptions) (Loader, error) {
 	if format != "compose" {
 		return nil, fmt.Errorf("input file format %s is not supported", format)
 	}
-	return new(compose.Compose), nil
+	return &compose.Compose{RemoteResources: opt.AllowRemoteExtends, RestrictPaths: opt.RestrictPaths}, nil
 }
@@ -0,0 +1,476 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reverse converts Kubernetes manifests back to an approximated compose project,
+// to run the workloads locally
+package reverse
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// workload is a controller running the pods of a pod template
+type workload struct {
+	kind     string
+	name     string
+	replicas *int32
+	global   bool
+	template api.PodTemplateSpec
+}
+
+// manifests are the objects read from the manifests, grouped by kind
+type manifests struct {
+	workloads  []workload
+	services   []api.Service
+	claims     []api.PersistentVolumeClaim
+	configMaps map[string]api.ConfigMap
+}
+
+// Convert reads the Kubernetes manifests of the files, and of the YAML and JSON files of the
+// directories, and returns the compose project running their workloads.
+// The conversion is approximated, the fields having no compose equivalent are ignored with a warning.
+func Convert(paths []string) (*types.Project, error) {
+	files, err := manifestFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	m := manifests{configMaps: map[string]api.ConfigMap{}}
+	for _, file := range files {
+		if err := m.read(file); err != nil {
+			return nil, errors.Wrapf(err, "unable to read the manifests of %q", file)
+		}
+	}
+	return m.toProject(), nil
+}
+
+// manifestFiles returns the files and the YAML and JSON files of the directories
+func manifestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			switch filepath.Ext(entry.Name()) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(p, entry.Name()))
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+// read decodes the objects of a file holding one or several YAML documents or JSON objects
+func (m *manifests) read(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var raw runtime.RawExtension
+		if err := decoder.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+			continue
+		}
+		obj, _, err := unstructured.UnstructuredJSONScheme.Decode(raw.Raw, nil, nil)
+		if err != nil {
+			return err
+		}
+		if list, ok := obj.(*unstructured.UnstructuredList); ok {
+			for i := range list.Items {
+				if err := m.add(&list.Items[i]); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := m.add(obj.(*unstructured.Unstructured)); err != nil {
+			return err
+		}
+	}
+}
+
+// add converts the object to its typed kind and keeps the kinds having a compose equivalent
+func (m *manifests) add(u *unstructured.Unstructured) error {
+	convert := func(obj interface{}) error {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+			return errors.Wrapf(err, "invalid %s %q", u.GetKind(), u.GetName())
+		}
+		return nil
+	}
+
+	switch u.GetKind() {
+	case "Deployment":
+		var deployment appsv1.Deployment
+		if err := convert(&deployment); err != nil {
+			return err
+		}
+		m.workloads = append(m.workloads, workload{kind: u.GetKind(), name: deployment.Name, replicas: deployment.Spec.Replicas, template: deployment.Spec.Template})
+	case "StatefulSet":
+		var statefulSet appsv1.StatefulSet
+		if err := convert(&statefulSet); err != nil {
+			return err
+		}
+		m.workloads = append(m.workloads, workload{kind: u.GetKind(), name: statefulSet.Name, replicas: statefulSet.Spec.Replicas, template: statefulSet.Spec.Template})
+	case "DaemonSet":
+		var daemonSet appsv1.DaemonSet
+		if err := convert(&daemonSet); err != nil {
+			return err
+		}
+		m.workloads = append(m.workloads, workload{kind: u.GetKind(), name: daemonSet.Name, global: true, template: daemonSet.Spec.Template})
+	case "Service":
+		var service api.Service
+		if err := convert(&service); err != nil {
+			return err
+		}
+		m.services = append(m.services, service)
+	case "PersistentVolumeClaim":
+		var claim api.PersistentVolumeClaim
+		if err := convert(&claim); err != nil {
+			return err
+		}
+		m.claims = append(m.claims, claim)
+	case "ConfigMap":
+		var configMap api.ConfigMap
+		if err := convert(&configMap); err != nil {
+			return err
+		}
+		m.configMaps[configMap.Name] = configMap
+	default:
+		log.Warnf("Ignoring the %s %q, only the Deployments, StatefulSets, DaemonSets, Services, PersistentVolumeClaims and ConfigMaps are converted", u.GetKind(), u.GetName())
+	}
+	return nil
+}
+
+// toProject creates a compose service for each container of the workloads
+func (m *manifests) toProject() *types.Project {
+	project := &types.Project{
+		Services: types.Services{},
+		Volumes:  types.Volumes{},
+		Configs:  types.Configs{},
+	}
+	for _, claim := range m.claims {
+		project.Volumes[claim.Name] = types.VolumeConfig{}
+	}
+
+	for _, w := range m.workloads {
+		if len(w.template.Spec.InitContainers) > 0 {
+			log.Warnf("Ignoring the init containers of the %s %q", w.kind, w.name)
+		}
+		for _, container := range w.template.Spec.Containers {
+			name := w.name
+			if len(w.template.Spec.Containers) > 1 && container.Name != w.name {
+				name = w.name + "-" + container.Name
+			}
+			service := m.toService(project, w, container)
+			m.addServicePorts(&service, w, container)
+			project.Services[name] = service
+		}
+	}
+	return project
+}
+
+// toService converts a container of a workload to a compose service
+func (m *manifests) toService(project *types.Project, w workload, container api.Container) types.ServiceConfig {
+	service := types.ServiceConfig{
+		Image:       container.Image,
+		Entrypoint:  types.ShellCommand(container.Command),
+		Command:     types.ShellCommand(container.Args),
+		WorkingDir:  container.WorkingDir,
+		Environment: m.environment(w, container),
+		Hostname:    w.template.Spec.Hostname,
+	}
+
+	deploy := &types.DeployConfig{}
+	if w.global {
+		deploy.Mode = "global"
+	} else if w.replicas != nil && *w.replicas != 1 {
+		replicas := int(*w.replicas)
+		deploy.Replicas = &replicas
+	}
+	deploy.Resources.Limits = toResource(container.Resources.Limits)
+	deploy.Resources.Reservations = toResource(container.Resources.Requests)
+	if deploy.Mode != "" || deploy.Replicas != nil || deploy.Resources.Limits != nil || deploy.Resources.Reservations != nil {
+		service.Deploy = deploy
+	}
+
+	probe := container.ReadinessProbe
+	if probe == nil {
+		probe = container.LivenessProbe
+	}
+	if probe != nil {
+		if probe.Exec != nil {
+			service.HealthCheck = toHealthCheck(probe)
+		} else {
+			log.Warnf("Ignoring the probe of the container %q of the %s %q, only the exec probes are converted to healthchecks", container.Name, w.kind, w.name)
+		}
+	}
+
+	for _, port := range container.Ports {
+		expose := strconv.Itoa(int(port.ContainerPort))
+		if port.Protocol == api.ProtocolUDP {
+			expose += "/udp"
+		}
+		service.Expose = append(service.Expose, expose)
+	}
+
+	m.addVolumes(project, &service, w, container)
+	return service
+}
+
+// environment returns the environment of the container, resolving the values of the ConfigMaps
+func (m *manifests) environment(w workload, container api.Container) types.MappingWithEquals {
+	environment := types.MappingWithEquals{}
+	for _, from := range container.EnvFrom {
+		if from.ConfigMapRef == nil {
+			log.Warnf("Ignoring the environment of the container %q of the %s %q read from a Secret", container.Name, w.kind, w.name)
+			continue
+		}
+		configMap, ok := m.configMaps[from.ConfigMapRef.Name]
+		if !ok {
+			log.Warnf("Ignoring the environment of the container %q of the %s %q read from the missing ConfigMap %q", container.Name, w.kind, w.name, from.ConfigMapRef.Name)
+			continue
+		}
+		for key, value := range configMap.Data {
+			value := value
+			environment[from.Prefix+key] = &value
+		}
+	}
+	for _, env := range container.Env {
+		value := env.Value
+		if from := env.ValueFrom; from != nil {
+			configMap, ok := api.ConfigMap{}, false
+			if from.ConfigMapKeyRef != nil {
+				configMap, ok = m.configMaps[from.ConfigMapKeyRef.Name]
+			}
+			if !ok {
+				log.Warnf("Ignoring the variable %q of the container %q of the %s %q, only the values and the ConfigMaps are converted", env.Name, container.Name, w.kind, w.name)
+				continue
+			}
+			value = configMap.Data[from.ConfigMapKeyRef.Key]
+		}
+		environment[env.Name] = &value
+	}
+	if len(environment) == 0 {
+		return nil
+	}
+	return environment
+}
+
+// addVolumes mounts the volumes of the pod mounted by the container
+func (m *manifests) addVolumes(project *types.Project, service *types.ServiceConfig, w workload, container api.Container) {
+	volumes := map[string]api.Volume{}
+	for _, volume := range w.template.Spec.Volumes {
+		volumes[volume.Name] = volume
+	}
+
+	for _, mount := range container.VolumeMounts {
+		volume, ok := volumes[mount.Name]
+		if !ok {
+			continue
+		}
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			claim := volume.PersistentVolumeClaim.ClaimName
+			service.Volumes = append(service.Volumes, types.ServiceVolumeConfig{Type: types.VolumeTypeVolume, Source: claim, Target: mount.MountPath, ReadOnly: mount.ReadOnly})
+			project.Volumes[claim] = types.VolumeConfig{}
+		case volume.HostPath != nil:
+			service.Volumes = append(service.Volumes, types.ServiceVolumeConfig{Type: types.VolumeTypeBind, Source: volume.HostPath.Path, Target: mount.MountPath, ReadOnly: mount.ReadOnly})
+		case volume.EmptyDir != nil && volume.EmptyDir.Medium == api.StorageMediumMemory:
+			service.Volumes = append(service.Volumes, types.ServiceVolumeConfig{Type: types.VolumeTypeTmpfs, Target: mount.MountPath})
+		case volume.EmptyDir != nil:
+			service.Volumes = append(service.Volumes, types.ServiceVolumeConfig{Type: types.VolumeTypeVolume, Target: mount.MountPath})
+		case volume.ConfigMap != nil:
+			m.addConfigs(project, service, w, mount, volume.ConfigMap)
+		default:
+			log.Warnf("Ignoring the volume %q of the %s %q, only the PersistentVolumeClaims, the host paths, the emptyDirs and the ConfigMaps are converted", volume.Name, w.kind, w.name)
+		}
+	}
+}
+
+// addConfigs mounts the keys of a ConfigMap volume as compose configs
+func (m *manifests) addConfigs(project *types.Project, service *types.ServiceConfig, w workload, mount api.VolumeMount, source *api.ConfigMapVolumeSource) {
+	configMap, ok := m.configMaps[source.Name]
+	if !ok {
+		log.Warnf("Ignoring the volume %q of the %s %q, the ConfigMap %q is missing", mount.Name, w.kind, w.name, source.Name)
+		return
+	}
+
+	// the keys are mounted as files named after them, unless the items set their paths
+	paths := map[string]string{}
+	if len(source.Items) > 0 {
+		for _, item := range source.Items {
+			paths[item.Key] = item.Path
+		}
+	} else {
+		for key := range configMap.Data {
+			paths[key] = key
+		}
+	}
+	keys := make([]string, 0, len(paths))
+	for key := range paths {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		target := path.Join(mount.MountPath, paths[key])
+		if mount.SubPath != "" {
+			if mount.SubPath != paths[key] {
+				continue
+			}
+			target = mount.MountPath
+		}
+		name := configMap.Name + "-" + strings.ReplaceAll(key, "/", "-")
+		project.Configs[name] = types.ConfigObjConfig{Content: configMap.Data[key]}
+		service.Configs = append(service.Configs, types.ServiceConfigObjConfig{Source: name, Target: target})
+	}
+}
+
+// addServicePorts publishes the ports of the Services selecting the pods of the workload,
+// and sets the names of the Services as network aliases of the compose service
+func (m *manifests) addServicePorts(service *types.ServiceConfig, w workload, container api.Container) {
+	for _, s := range m.services {
+		if len(s.Spec.Selector) == 0 || !labels.SelectorFromSet(s.Spec.Selector).Matches(labels.Set(w.template.Labels)) {
+			continue
+		}
+
+		published := false
+		for _, port := range s.Spec.Ports {
+			target, ok := containerPort(container, port.TargetPort, port.Port)
+			if !ok {
+				continue
+			}
+			published = true
+			// a headless Service has no port of its own
+			if s.Spec.ClusterIP == api.ClusterIPNone {
+				continue
+			}
+			service.Ports = append(service.Ports, types.ServicePortConfig{
+				Target:    uint32(target),
+				Published: strconv.Itoa(int(port.Port)),
+				Protocol:  strings.ToLower(string(port.Protocol)),
+			})
+		}
+		if !published || s.Name == w.name {
+			continue
+		}
+		if service.Networks == nil {
+			service.Networks = map[string]*types.ServiceNetworkConfig{"default": {}}
+		}
+		service.Networks["default"].Aliases = append(service.Networks["default"].Aliases, s.Name)
+	}
+}
+
+// containerPort resolves the target port of a Service port among the ports of the container
+func containerPort(container api.Container, target intstr.IntOrString, port int32) (int32, bool) {
+	if target.Type == intstr.String {
+		for _, p := range container.Ports {
+			if p.Name == target.StrVal {
+				return p.ContainerPort, true
+			}
+		}
+		return 0, false
+	}
+	number := target.IntVal
+	if number == 0 {
+		number = port
+	}
+	if len(container.Ports) == 0 {
+		return number, true
+	}
+	for _, p := range container.Ports {
+		if p.ContainerPort == number {
+			return number, true
+		}
+	}
+	return 0, false
+}
+
+// toResource converts the CPU and the memory of resource requirements
+func toResource(resources api.ResourceList) *types.Resource {
+	cpu, hasCPU := resources[api.ResourceCPU]
+	memory, hasMemory := resources[api.ResourceMemory]
+	if !hasCPU && !hasMemory {
+		return nil
+	}
+	resource := &types.Resource{}
+	if hasCPU {
+		resource.NanoCPUs = types.NanoCPUs(float32(cpu.MilliValue()) / 1000)
+	}
+	if hasMemory {
+		resource.MemoryBytes = types.UnitBytes(memory.Value())
+	}
+	return resource
+}
+
+// toHealthCheck converts an exec probe
+func toHealthCheck(probe *api.Probe) *types.HealthCheckConfig {
+	seconds := func(s int32) *types.Duration {
+		if s == 0 {
+			return nil
+		}
+		d := types.Duration(time.Duration(s) * time.Second)
+		return &d
+	}
+	healthCheck := &types.HealthCheckConfig{
+		Test:        append(types.HealthCheckTest{"CMD"}, probe.Exec.Command...),
+		Interval:    seconds(probe.PeriodSeconds),
+		Timeout:     seconds(probe.TimeoutSeconds),
+		StartPeriod: seconds(probe.InitialDelaySeconds),
+	}
+	if probe.FailureThreshold != 0 {
+		retries := uint64(probe.FailureThreshold)
+		healthCheck.Retries = &retries
+	}
+	return healthCheck
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reverse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestConvert(t *testing.T) {
+	project, err := Convert([]string{"testdata"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(project.Services) != 1 {
+		t.Fatalf("Expected a single service, got %v", project.Services)
+	}
+	web := project.Services["web"]
+
+	if web.Image != "nginx:1.27" {
+		t.Errorf("Expected the image nginx:1.27, got %q", web.Image)
+	}
+	if web.Deploy == nil || web.Deploy.Replicas == nil || *web.Deploy.Replicas != 2 {
+		t.Errorf("Expected 2 replicas, got %#v", web.Deploy)
+	}
+	if limits := web.Deploy.Resources.Limits; limits == nil || limits.NanoCPUs != 0.5 || limits.MemoryBytes != 256*1024*1024 {
+		t.Errorf("Expected the limits of the container, got %#v", limits)
+	}
+
+	// the Secret variable is dropped, the ConfigMap one is resolved
+	if len(web.Environment) != 2 || *web.Environment["MODE"] != "prod" || *web.Environment["LEVEL"] != "debug" {
+		t.Errorf("Expected MODE and LEVEL, got %v", web.Environment)
+	}
+
+	expectedPorts := []types.ServicePortConfig{{Target: 80, Published: "8080"}}
+	if !reflect.DeepEqual(web.Ports, expectedPorts) {
+		t.Errorf("Expected %v, got %v", expectedPorts, web.Ports)
+	}
+	if web.Networks["default"] == nil || !reflect.DeepEqual(web.Networks["default"].Aliases, []string{"frontend"}) {
+		t.Errorf("Expected the frontend alias, got %v", web.Networks)
+	}
+
+	expectedVolumes := []types.ServiceVolumeConfig{{Type: types.VolumeTypeVolume, Source: "web-data", Target: "/data"}}
+	if !reflect.DeepEqual(web.Volumes, expectedVolumes) {
+		t.Errorf("Expected %v, got %v", expectedVolumes, web.Volumes)
+	}
+	if _, ok := project.Volumes["web-data"]; !ok {
+		t.Errorf("Expected the web-data volume, got %v", project.Volumes)
+	}
+
+	expectedConfigs := []types.ServiceConfigObjConfig{
+		{Source: "settings-default.conf", Target: "/etc/nginx/conf.d/default.conf"},
+		{Source: "settings-level", Target: "/etc/nginx/conf.d/level"},
+	}
+	if !reflect.DeepEqual(web.Configs, expectedConfigs) {
+		t.Errorf("Expected %v, got %v", expectedConfigs, web.Configs)
+	}
+	if project.Configs["settings-level"].Content != "debug" {
+		t.Errorf("Expected the content of the level key, got %v", project.Configs)
+	}
+
+	if web.HealthCheck == nil || !reflect.DeepEqual(web.HealthCheck.Test, types.HealthCheckTest{"CMD", "curl", "-f", "localhost"}) {
+		t.Errorf("Expected the exec probe as healthcheck, got %#v", web.HealthCheck)
+	}
+}
+
+func TestContainerPort(t *testing.T) {
+	container := api.Container{Ports: []api.ContainerPort{{Name: "http", ContainerPort: 8080}}}
+	testCases := []struct {
+		target   intstr.IntOrString
+		port     int32
+		expected int32
+		ok       bool
+	}{
+		{intstr.FromString("http"), 80, 8080, true},
+		{intstr.FromString("grpc"), 80, 0, false},
+		{intstr.FromInt32(8080), 80, 8080, true},
+		{intstr.IntOrString{}, 8080, 8080, true},
+		{intstr.FromInt32(9090), 80, 0, false},
+	}
+	for _, tc := range testCases {
+		port, ok := containerPort(container, tc.target, tc.port)
+		if port != tc.expected || ok != tc.ok {
+			t.Errorf("Expected %d %v for %v, got %d %v", tc.expected, tc.ok, tc.target, port, ok)
+		}
+	}
+}
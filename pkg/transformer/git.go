@@ -0,0 +1,81 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformer
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GetAbsBuildContext returns build context relative to project root dir
+func GetAbsBuildContext(context string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-prefix")
+	cmd.Dir = context
+	var out strings.Builder
+	var stderr strings.Builder
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.New(stderr.String())
+	}
+	//convert output of command to string
+	contextDir := strings.Trim(out.String(), "\n")
+	return contextDir, nil
+}
+
+// HasGitBinary checks if the 'git' binary is available on the system
+func HasGitBinary() bool {
+	_, err := exec.LookPath("git")
+	return err == nil
+}
+
+// GetGitCurrentRemoteURL gets current git remote URI for the current git repo
+func GetGitCurrentRemoteURL(composeFileDir string) (string, error) {
+	cmd := exec.Command("git", "ls-remote", "--get-url")
+	cmd.Dir = composeFileDir
+	var out strings.Builder
+	var stderr strings.Builder
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.New(stderr.String())
+	}
+	url := strings.TrimRight(out.String(), "\n")
+	if !strings.HasSuffix(url, ".git") {
+		url += ".git"
+	}
+	return url, nil
+}
+
+// GetGitCurrentBranch gets current git branch name for the current git repo
+func GetGitCurrentBranch(composeFileDir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = composeFileDir
+	var out strings.Builder
+	var stderr strings.Builder
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.New(stderr.String())
+	}
+	return strings.TrimRight(out.String(), "\n"), nil
+}
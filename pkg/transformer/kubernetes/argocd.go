@@ -0,0 +1,168 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// ArgoCDSyncWaveAnnotation orders the sync of the objects by ArgoCD, lower waves first
+	ArgoCDSyncWaveAnnotation = "argocd.argoproj.io/sync-wave"
+	// argoCDNamespace is the namespace the ArgoCD Applications are created in
+	argoCDNamespace = "argocd"
+	// inClusterServer is the API server of the cluster ArgoCD runs in
+	inClusterServer = "https://kubernetes.default.svc"
+)
+
+// syncWaves returns the sync wave of each service, one more than the highest wave of its depends_on services
+func syncWaves(services map[string]kobject.ServiceConfig) map[string]int {
+	waves := make(map[string]int, len(services))
+	visiting := make(map[string]bool)
+	var wave func(name string) int
+	wave = func(name string) int {
+		if w, ok := waves[name]; ok {
+			return w
+		}
+		// a dependency cycle is broken at the service met twice
+		if visiting[name] {
+			return 0
+		}
+		visiting[name] = true
+		w := 0
+		for dependency := range services[name].DependsOn {
+			if _, ok := services[dependency]; ok {
+				w = max(w, wave(dependency)+1)
+			}
+		}
+		visiting[name] = false
+		waves[name] = w
+		return w
+	}
+	for name := range services {
+		wave(name)
+	}
+	return waves
+}
+
+// ConfigSyncWaves annotates the objects of the services with the ArgoCD sync wave derived from depends_on,
+// when an ArgoCD Application is generated with --argocd-app
+func (k *Kubernetes) ConfigSyncWaves(objects *[]runtime.Object, services map[string]kobject.ServiceConfig, opt kobject.ConvertOptions) error {
+	if opt.ArgoCDApp == "" {
+		return nil
+	}
+	waves := syncWaves(services)
+	for _, obj := range *objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return errors.Wrap(err, "meta.Accessor failed")
+		}
+		wave, ok := waves[accessor.GetLabels()[transformer.Selector]]
+		if !ok || wave == 0 {
+			continue
+		}
+		annotations := accessor.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[ArgoCDSyncWaveAnnotation] = strconv.Itoa(wave)
+		accessor.SetAnnotations(annotations)
+	}
+	return nil
+}
+
+// InitArgoCDApplication initializes the ArgoCD Application syncing the directory or chart the objects are
+// written to. The repository and the path in the repository are detected from the git repository of dir
+// when --argocd-repo-url and --argocd-path aren't given.
+func InitArgoCDApplication(dir string, opt kobject.ConvertOptions) (*unstructured.Unstructured, error) {
+	repoURL, path := opt.ArgoCDRepoURL, opt.ArgoCDPath
+	if repoURL == "" {
+		url, err := transformer.GetGitCurrentRemoteURL(dir)
+		if err != nil {
+			return nil, errors.Wrap(err, "git remote origin repo couldn't be detected, set it with --argocd-repo-url")
+		}
+		repoURL = url
+	}
+	if path == "" {
+		prefix, err := transformer.GetAbsBuildContext(dir)
+		if err != nil {
+			return nil, errors.Wrap(err, "the path in the git repository couldn't be detected, set it with --argocd-path")
+		}
+		path = filepath.ToSlash(filepath.Clean(strings.TrimSuffix(prefix, "/")))
+	}
+	namespace := opt.ArgoCDDestNamespace
+	if namespace == "" {
+		namespace = opt.Namespace
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	source := map[string]interface{}{
+		"repoURL":        repoURL,
+		"path":           path,
+		"targetRevision": "HEAD",
+	}
+	// the objects of the services are in the subdirectories of the directory
+	if opt.OutputLayout == OutputLayoutService && !opt.CreateChart {
+		source["directory"] = map[string]interface{}{"recurse": true}
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata": map[string]interface{}{
+			"name":      opt.ArgoCDApp,
+			"namespace": argoCDNamespace,
+		},
+		"spec": map[string]interface{}{
+			"project": "default",
+			"source":  source,
+			"destination": map[string]interface{}{
+				"server":    inClusterServer,
+				"namespace": namespace,
+			},
+			"syncPolicy": map[string]interface{}{
+				"syncOptions": []interface{}{"CreateNamespace=true"},
+			},
+		},
+	}}, nil
+}
+
+// printArgoCDApplication writes the ArgoCD Application syncing the directory or chart to <app>-application.yaml,
+// next to the directory
+func printArgoCDApplication(dirName string, opt kobject.ConvertOptions) error {
+	application, err := InitArgoCDApplication(dirName, opt)
+	if err != nil {
+		return err
+	}
+	data, err := marshal(application, opt.GenerateJSON, opt.YAMLIndent)
+	if err != nil {
+		return err
+	}
+	_, err = transformer.Print(opt.ArgoCDApp, filepath.Dir(filepath.Clean(dirName)), "application", data, false, opt.GenerateJSON, nil, opt.Provider)
+	return errors.Wrap(err, "transformer.Print failed")
+}
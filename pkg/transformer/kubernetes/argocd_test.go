@@ -0,0 +1,154 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/testutils"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSyncWaves(t *testing.T) {
+	services := map[string]kobject.ServiceConfig{
+		"db":     {Name: "db"},
+		"cache":  {Name: "cache"},
+		"api":    {Name: "api", DependsOn: map[string]string{"db": dependencyConditionHealthy, "cache": dependencyConditionStarted}},
+		"web":    {Name: "web", DependsOn: map[string]string{"api": dependencyConditionStarted, "db": dependencyConditionStarted}},
+		"worker": {Name: "worker", DependsOn: map[string]string{"missing": dependencyConditionStarted}},
+		"a":      {Name: "a", DependsOn: map[string]string{"b": dependencyConditionStarted}},
+		"b":      {Name: "b", DependsOn: map[string]string{"a": dependencyConditionStarted}},
+	}
+	waves := syncWaves(services)
+
+	expected := map[string]int{"db": 0, "cache": 0, "api": 1, "web": 2, "worker": 0}
+	for name, wave := range expected {
+		if waves[name] != wave {
+			t.Errorf("Expected the wave %d for %s, got %d", wave, name, waves[name])
+		}
+	}
+	// a dependency cycle can't be ordered, it is broken to give a wave to its services
+	if _, ok := waves["a"]; !ok {
+		t.Errorf("Expected a wave for the services of the cycle, got %v", waves)
+	}
+}
+
+func TestConfigSyncWaves(t *testing.T) {
+	services := map[string]kobject.ServiceConfig{
+		"db":  {Name: "db"},
+		"web": {Name: "web", DependsOn: map[string]string{"db": dependencyConditionStarted}},
+	}
+	objects := []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "db", Labels: transformer.ConfigLabels("db")}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: transformer.ConfigLabels("web")}},
+		&api.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: transformer.ConfigLabels("web"), Annotations: map[string]string{"a": "b"}}},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":     "HTTPRoute",
+			"metadata": map[string]interface{}{"name": "web", "labels": map[string]interface{}{transformer.Selector: "web"}},
+		}},
+	}
+
+	k := Kubernetes{}
+	if err := k.ConfigSyncWaves(&objects, services, kobject.ConvertOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if annotations := objects[1].(*appsv1.Deployment).Annotations; annotations != nil {
+		t.Errorf("Expected no sync wave without --argocd-app, got %v", annotations)
+	}
+
+	if err := k.ConfigSyncWaves(&objects, services, kobject.ConvertOptions{ArgoCDApp: "app"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if annotations := objects[0].(*appsv1.Deployment).Annotations; annotations != nil {
+		t.Errorf("Expected no sync wave for the first wave, got %v", annotations)
+	}
+	if wave := objects[1].(*appsv1.Deployment).Annotations[ArgoCDSyncWaveAnnotation]; wave != "1" {
+		t.Errorf("Expected the sync wave 1 of the web Deployment, got %q", wave)
+	}
+	expected := map[string]string{"a": "b", ArgoCDSyncWaveAnnotation: "1"}
+	if annotations := objects[2].(*api.Service).Annotations; !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("Expected %v, got %v", expected, annotations)
+	}
+	if wave := objects[3].(*unstructured.Unstructured).GetAnnotations()[ArgoCDSyncWaveAnnotation]; wave != "1" {
+		t.Errorf("Expected the sync wave 1 of the HTTPRoute, got %q", wave)
+	}
+}
+
+func TestInitArgoCDApplication(t *testing.T) {
+	gitDir := testutils.CreateLocalGitDirectory(t)
+	testutils.SetGitRemote(t, gitDir, "origin", "https://git.test.com/somerepo")
+	testutils.CreateSubdir(t, gitDir, "deploy/k8s")
+	defer os.RemoveAll(gitDir)
+
+	testCases := map[string]struct {
+		opt       kobject.ConvertOptions
+		repoURL   string
+		path      string
+		namespace string
+	}{
+		"Detected repository and path": {
+			kobject.ConvertOptions{ArgoCDApp: "shop"},
+			"https://git.test.com/somerepo.git", "deploy/k8s", "default",
+		},
+		"Namespace of the objects": {
+			kobject.ConvertOptions{ArgoCDApp: "shop", Namespace: "shop"},
+			"https://git.test.com/somerepo.git", "deploy/k8s", "shop",
+		},
+		"Given repository, path and namespace": {
+			kobject.ConvertOptions{ArgoCDApp: "shop", ArgoCDRepoURL: "https://example.com/app.git", ArgoCDPath: "chart", ArgoCDDestNamespace: "prod", Namespace: "shop"},
+			"https://example.com/app.git", "chart", "prod",
+		},
+	}
+	for name, test := range testCases {
+		application, err := InitArgoCDApplication(filepath.Join(gitDir, "deploy/k8s"), test.opt)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if application.GetKind() != "Application" || application.GetName() != "shop" || application.GetNamespace() != argoCDNamespace {
+			t.Errorf("%s: expected the shop Application in the %s namespace, got %s %s/%s", name, argoCDNamespace, application.GetKind(), application.GetNamespace(), application.GetName())
+		}
+		repoURL, _, _ := unstructured.NestedString(application.Object, "spec", "source", "repoURL")
+		path, _, _ := unstructured.NestedString(application.Object, "spec", "source", "path")
+		namespace, _, _ := unstructured.NestedString(application.Object, "spec", "destination", "namespace")
+		if repoURL != test.repoURL || path != test.path || namespace != test.namespace {
+			t.Errorf("%s: expected %s %s %s, got %s %s %s", name, test.repoURL, test.path, test.namespace, repoURL, path, namespace)
+		}
+	}
+
+	application, err := InitArgoCDApplication(filepath.Join(gitDir, "deploy/k8s"), kobject.ConvertOptions{ArgoCDApp: "shop", OutputLayout: OutputLayoutService})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recurse, _, _ := unstructured.NestedBool(application.Object, "spec", "source", "directory", "recurse"); !recurse {
+		t.Errorf("Expected the Application to recurse into the directories of the services")
+	}
+
+	dir := testutils.CreateLocalDirectory(t)
+	defer os.RemoveAll(dir)
+	if _, err := InitArgoCDApplication(dir, kobject.ConvertOptions{ArgoCDApp: "shop"}); err == nil {
+		t.Errorf("Expected an error for a directory outside of a git repository")
+	}
+}
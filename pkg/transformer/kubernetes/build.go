@@ -0,0 +1,182 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// KanikoBuild builds the images in the cluster with a Kaniko Job per service
+	KanikoBuild = "kaniko"
+	// KanikoImage is the image of the Kaniko Jobs
+	KanikoImage = "gcr.io/kaniko-project/executor:v1.23.2"
+)
+
+// kanikoGitContext returns the git context of a build context directory as Kaniko reads it,
+// git://<host>/<path>#refs/heads/<branch>, and the path of the directory in the repository
+func kanikoGitContext(buildDir string, opt kobject.ConvertOptions) (context string, subPath string, err error) {
+	repo, branch := opt.BuildRepo, opt.BuildBranch
+	if (repo == "" || branch == "") && !transformer.HasGitBinary() {
+		return "", "", errors.New("Git is not installed! Please install Git to create the Kaniko builds, else supply source repository and branch to use for build using '--build-repo', '--build-branch' options respectively")
+	}
+	if repo == "" {
+		if repo, err = transformer.GetGitCurrentRemoteURL(buildDir); err != nil {
+			return "", "", errors.Wrap(err, "git remote origin repo couldn't be detected")
+		}
+	}
+	if branch == "" {
+		if branch, err = transformer.GetGitCurrentBranch(buildDir); err != nil {
+			return "", "", errors.Wrap(err, "current git branch couldn't be detected")
+		}
+	}
+	if subPath, err = transformer.GetAbsBuildContext(buildDir); err != nil {
+		return "", "", errors.Wrap(err, "the build context isn't in a git repository")
+	}
+
+	// Kaniko clones the repositories over https, the scp-like syntax of ssh is converted
+	for _, scheme := range []string{"https://", "http://", "ssh://", "git://"} {
+		repo = strings.TrimPrefix(repo, scheme)
+	}
+	if user, host, found := strings.Cut(repo, "@"); found && !strings.Contains(user, "/") {
+		repo = strings.Replace(host, ":", "/", 1)
+	}
+	return fmt.Sprintf("git://%s#refs/heads/%s", repo, branch), strings.TrimSuffix(subPath, "/"), nil
+}
+
+// InitKanikoBuild initializes the ConfigMap referencing the build context of the service and the Job
+// building its image with Kaniko. The Job reads the context, the Dockerfile and the destination from
+// the ConfigMap, which can be edited to build another branch.
+func (k *Kubernetes) InitKanikoBuild(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) ([]runtime.Object, error) {
+	if service.Image == "" {
+		return nil, fmt.Errorf("image key required within build parameters in order to build and push service '%s'", name)
+	}
+	context, subPath, err := kanikoGitContext(service.Build, opt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "the Kaniko build of service %q cannot be created", name)
+	}
+	dockerfile := service.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	buildName := name + "-build"
+	cm := &api.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   buildName,
+			Labels: transformer.ConfigLabels(name),
+		},
+		Data: map[string]string{
+			"CONTEXT":          context,
+			"CONTEXT_SUB_PATH": subPath,
+			"DOCKERFILE":       dockerfile,
+			"DESTINATION":      service.Image,
+		},
+	}
+
+	args := []string{
+		"--context=$(CONTEXT)",
+		"--context-sub-path=$(CONTEXT_SUB_PATH)",
+		"--dockerfile=$(DOCKERFILE)",
+		"--destination=$(DESTINATION)",
+	}
+	buildArgs := make([]string, 0, len(service.BuildArgs))
+	for arg, value := range service.BuildArgs {
+		if *value == "\x00" {
+			*value = os.Getenv(arg)
+		}
+		buildArgs = append(buildArgs, fmt.Sprintf("--build-arg=%s=%s", arg, *value))
+	}
+	sort.Strings(buildArgs)
+	args = append(args, buildArgs...)
+	if service.BuildTarget != "" {
+		args = append(args, "--target="+service.BuildTarget)
+	}
+
+	container := api.Container{
+		Name:  "kaniko",
+		Image: KanikoImage,
+		Args:  args,
+		EnvFrom: []api.EnvFromSource{{
+			ConfigMapRef: &api.ConfigMapEnvSource{
+				LocalObjectReference: api.LocalObjectReference{Name: buildName},
+			},
+		}},
+	}
+	podSpec := api.PodSpec{RestartPolicy: api.RestartPolicyNever}
+	if opt.BuildPushSecret != "" {
+		// Kaniko reads the registry credentials from its docker config
+		container.VolumeMounts = []api.VolumeMount{{Name: "docker-config", MountPath: "/kaniko/.docker"}}
+		podSpec.Volumes = []api.Volume{{
+			Name: "docker-config",
+			VolumeSource: api.VolumeSource{
+				Secret: &api.SecretVolumeSource{
+					SecretName: opt.BuildPushSecret,
+					Items:      []api.KeyToPath{{Key: api.DockerConfigJsonKey, Path: "config.json"}},
+				},
+			},
+		}}
+	}
+	podSpec.Containers = []api.Container{container}
+
+	backoffLimit := int32(2)
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   buildName,
+			Labels: transformer.ConfigLabels(name),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			// the pods of the build don't carry the labels of the service, they aren't selected by its Service
+			Template: api.PodTemplateSpec{Spec: podSpec},
+		},
+	}
+	return []runtime.Object{cm, job}, nil
+}
+
+// configKanikoBuild appends the Kaniko build of the service with --build=kaniko, to the objects
+// which aren't updated with the pod template of the service
+func (k *Kubernetes) configKanikoBuild(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions, objects *[]runtime.Object) error {
+	if opt.Build != KanikoBuild || service.Build == "" {
+		return nil
+	}
+	build, err := k.InitKanikoBuild(name, service, opt)
+	if err != nil {
+		return err
+	}
+	*objects = append(*objects, build...)
+	return nil
+}
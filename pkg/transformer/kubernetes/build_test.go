@@ -0,0 +1,142 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/testutils"
+	batchv1 "k8s.io/api/batch/v1"
+	api "k8s.io/api/core/v1"
+)
+
+func TestKanikoGitContext(t *testing.T) {
+	gitDir := testutils.CreateLocalGitDirectory(t)
+	testutils.SetGitRemote(t, gitDir, "origin", "https://git.test.com/somerepo")
+	testutils.CreateGitRemoteBranch(t, gitDir, "newbranch", "origin")
+	testutils.CreateSubdir(t, gitDir, "a/build")
+	defer os.RemoveAll(gitDir)
+
+	testCases := map[string]struct {
+		repo    string
+		branch  string
+		context string
+	}{
+		"Detected remote and branch": {"", "", "git://git.test.com/somerepo.git#refs/heads/newbranch"},
+		"Given https repository":     {"https://example.com/org/app.git", "main", "git://example.com/org/app.git#refs/heads/main"},
+		"Given scp-like repository":  {"git@example.com:org/app.git", "main", "git://example.com/org/app.git#refs/heads/main"},
+		"Given ssh repository":       {"ssh://git@example.com/org/app.git", "main", "git://example.com/org/app.git#refs/heads/main"},
+	}
+	for name, test := range testCases {
+		opt := kobject.ConvertOptions{BuildRepo: test.repo, BuildBranch: test.branch}
+		context, subPath, err := kanikoGitContext(filepath.Join(gitDir, "a/build"), opt)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if context != test.context {
+			t.Errorf("%s: expected the context %q, got %q", name, test.context, context)
+		}
+		if subPath != "a/build" {
+			t.Errorf("%s: expected the sub path a/build, got %q", name, subPath)
+		}
+	}
+
+	dir := testutils.CreateLocalDirectory(t)
+	defer os.RemoveAll(dir)
+	if _, _, err := kanikoGitContext(dir, kobject.ConvertOptions{BuildRepo: "https://example.com/app", BuildBranch: "main"}); err == nil {
+		t.Errorf("Expected an error for a build context outside of a git repository")
+	}
+}
+
+func TestInitKanikoBuild(t *testing.T) {
+	gitDir := testutils.CreateLocalGitDirectory(t)
+	testutils.CreateSubdir(t, gitDir, "web")
+	defer os.RemoveAll(gitDir)
+
+	value := "1.2"
+	service := kobject.ServiceConfig{
+		Name:        "web",
+		Image:       "registry.example.com/web:latest",
+		Build:       filepath.Join(gitDir, "web"),
+		Dockerfile:  "Dockerfile.prod",
+		BuildArgs:   map[string]*string{"VERSION": &value},
+		BuildTarget: "runtime",
+	}
+	opt := kobject.ConvertOptions{Build: KanikoBuild, BuildRepo: "https://example.com/app", BuildBranch: "main", BuildPushSecret: "regcred"}
+
+	k := Kubernetes{}
+	objects, err := k.InitKanikoBuild("web", service, opt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("Expected a ConfigMap and a Job, got %d objects", len(objects))
+	}
+
+	cm, ok := objects[0].(*api.ConfigMap)
+	if !ok {
+		t.Fatalf("Expected a ConfigMap, got %T", objects[0])
+	}
+	expectedData := map[string]string{
+		"CONTEXT":          "git://example.com/app#refs/heads/main",
+		"CONTEXT_SUB_PATH": "web",
+		"DOCKERFILE":       "Dockerfile.prod",
+		"DESTINATION":      "registry.example.com/web:latest",
+	}
+	if cm.Name != "web-build" || !reflect.DeepEqual(cm.Data, expectedData) {
+		t.Errorf("Expected the web-build ConfigMap with %v, got %s with %v", expectedData, cm.Name, cm.Data)
+	}
+
+	job, ok := objects[1].(*batchv1.Job)
+	if !ok {
+		t.Fatalf("Expected a Job, got %T", objects[1])
+	}
+	podSpec := job.Spec.Template.Spec
+	if podSpec.RestartPolicy != api.RestartPolicyNever || len(podSpec.Containers) != 1 {
+		t.Fatalf("Expected a single container never restarted, got %#v", podSpec)
+	}
+	container := podSpec.Containers[0]
+	expectedArgs := []string{
+		"--context=$(CONTEXT)",
+		"--context-sub-path=$(CONTEXT_SUB_PATH)",
+		"--dockerfile=$(DOCKERFILE)",
+		"--destination=$(DESTINATION)",
+		"--build-arg=VERSION=1.2",
+		"--target=runtime",
+	}
+	if container.Image != KanikoImage || !reflect.DeepEqual(container.Args, expectedArgs) {
+		t.Errorf("Expected %s with %v, got %s with %v", KanikoImage, expectedArgs, container.Image, container.Args)
+	}
+	if len(container.EnvFrom) != 1 || container.EnvFrom[0].ConfigMapRef == nil || container.EnvFrom[0].ConfigMapRef.Name != "web-build" {
+		t.Errorf("Expected the variables of the web-build ConfigMap, got %#v", container.EnvFrom)
+	}
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].Secret == nil || podSpec.Volumes[0].Secret.SecretName != "regcred" {
+		t.Errorf("Expected the regcred Secret volume, got %#v", podSpec.Volumes)
+	}
+	if len(container.VolumeMounts) != 1 || container.VolumeMounts[0].MountPath != "/kaniko/.docker" {
+		t.Errorf("Expected the docker config mounted in /kaniko/.docker, got %#v", container.VolumeMounts)
+	}
+
+	service.Image = ""
+	if _, err := k.InitKanikoBuild("web", service, opt); err == nil {
+		t.Errorf("Expected an error for a service without image")
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/loader/compose"
+	"github.com/pkg/errors"
+)
+
+// conflicts maps a conflicting value to the services using it
+type conflicts map[string][]string
+
+func (c conflicts) add(key string, service string) {
+	for _, s := range c[key] {
+		if s == service {
+			return
+		}
+	}
+	c[key] = append(c[key], service)
+}
+
+// problems returns a message for every value used by more than one service
+func (c conflicts) problems(format string) []string {
+	var problems []string
+	for _, key := range c.keys() {
+		if len(c[key]) > 1 {
+			sort.Strings(c[key])
+			problems = append(problems, fmt.Sprintf(format, key, strings.Join(c[key], ", ")))
+		}
+	}
+	return problems
+}
+
+func (c conflicts) keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CheckConflicts validates the whole project before anything is generated, and reports
+// together every container name, resource name, host port and mount path used twice.
+func CheckConflicts(komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) error {
+	containerNames := conflicts{}
+	envConfigMaps := conflicts{}
+	hostPorts := conflicts{}
+	nodePorts := conflicts{}
+	priorityClasses := conflicts{}
+	serviceNames := conflicts{}
+	var problems []string
+
+	for _, name := range SortedKeys(komposeObject.ServiceConfigs) {
+		service := komposeObject.ServiceConfigs[name]
+
+		if service.ContainerName != "" {
+			containerNames.add(GetContainerName(service), name)
+		}
+
+		// the network aliases are the names of additional Services
+		serviceNames.add(service.Name, name)
+		for _, alias := range service.NetworkAliases {
+			serviceNames.add(alias, name)
+		}
+
+		// the names hashed with --env-configmap-hash are unique
+		if !opt.EnvConfigMapHash {
+			for _, envFile := range service.EnvFile {
+				envConfigMaps.add(FormatEnvName(envFile, name), envFile)
+			}
+		}
+
+		for _, port := range service.Port {
+			if service.ExposeContainerToHost && port.HostPort != 0 {
+				hostPorts.add(fmt.Sprintf("%s:%d/%s", port.HostIP, port.HostPort, port.Protocol), name)
+			}
+		}
+		if service.ServiceType == "NodePort" && service.NodePortPort != 0 {
+			nodePorts.add(fmt.Sprint(service.NodePortPort), name)
+		}
+		if service.ServiceType == "NodePort" {
+			for _, nodePort := range service.NodePortPorts {
+				nodePorts.add(fmt.Sprint(nodePort), name)
+			}
+		}
+
+		if className, ok := service.Labels[compose.LabelPriorityClass]; ok {
+			if value, ok := service.Labels[compose.LabelPriorityClassValue]; ok {
+				priorityClasses.add(className, value)
+			}
+		}
+
+		mountPaths := conflicts{}
+		for _, volume := range service.Volumes {
+			if volume.Container != "" {
+				mountPaths.add(volume.Container, volume.MountPath)
+			}
+		}
+		for _, tmpfs := range service.TmpFs {
+			mountPaths.add(strings.Split(tmpfs, ":")[0], "tmpfs "+tmpfs)
+		}
+		for _, config := range service.Configs {
+			if config.Target != "" {
+				mountPaths.add(config.Target, "config "+config.Source)
+			}
+		}
+		for _, problem := range mountPaths.problems("mount path %q is used by %s") {
+			problems = append(problems, fmt.Sprintf("service %q: %s", name, problem))
+		}
+	}
+
+	problems = append(problems, containerNames.problems("container name %q is used by services %s")...)
+	problems = append(problems, envConfigMaps.problems("ConfigMap name %q is generated for env files %s")...)
+	problems = append(problems, hostPorts.problems("host port %q is used by services %s")...)
+	problems = append(problems, nodePorts.problems("node port %s is used by services %s")...)
+	problems = append(problems, serviceNames.problems("Service name %q is used by the services or network aliases of %s")...)
+	problems = append(problems, priorityClasses.problems("priority class %q is defined with values %s")...)
+
+	if len(problems) > 0 {
+		return errors.Errorf("found %d conflicts in the project:\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// CheckGroupPortConflicts reports together the container ports used by several services of a
+// group, the containers of a pod share its network and can't listen on the same port.
+func CheckGroupPortConflicts(groups map[string]kobject.ServiceConfigGroup) error {
+	var problems []string
+	for _, group := range SortedKeys(groups) {
+		ports := conflicts{}
+		for _, service := range groups[group] {
+			for _, port := range ConfigPorts(service) {
+				ports.add(fmt.Sprintf("%d/%s", port.ContainerPort, port.Protocol), service.Name)
+			}
+		}
+		problems = append(problems, ports.problems("container port %s is used by the grouped services %s")...)
+	}
+
+	if len(problems) > 0 {
+		return errors.Errorf("found %d port conflicts in the service groups, the containers of a pod share its network:\n  %s\nchange the container port of all but one of the services, or remove them from the group",
+			len(problems), strings.Join(problems, "\n  "))
+	}
+	return nil
+}
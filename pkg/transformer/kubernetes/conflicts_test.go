@@ -0,0 +1,138 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/loader/compose"
+)
+
+func TestCheckConflicts(t *testing.T) {
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web": {
+				Name:                  "web",
+				ContainerName:         "app",
+				EnvFile:               []string{"config/app.env"},
+				ExposeContainerToHost: true,
+				Port:                  []kobject.Ports{{HostPort: 8080, ContainerPort: 80, Protocol: "TCP"}},
+				Volumes: []kobject.Volumes{
+					{Host: "./data", Container: "/data", MountPath: "./data:/data"},
+				},
+				TmpFs:   []string{"/data"},
+				Configs: []types.ServiceConfigObjConfig{{Source: "web-config", Target: "/etc/web.conf"}},
+			},
+			"api": {
+				Name:                  "api",
+				ContainerName:         "app",
+				EnvFile:               []string{"config/app-env"},
+				ExposeContainerToHost: true,
+				Port:                  []kobject.Ports{{HostPort: 8080, ContainerPort: 8080, Protocol: "TCP"}},
+				ServiceType:           "NodePort",
+				NodePortPort:          30080,
+			},
+			"worker": {
+				Name:         "worker",
+				ServiceType:  "NodePort",
+				NodePortPort: 30080,
+				Port:         []kobject.Ports{{HostPort: 8080, ContainerPort: 8080, Protocol: "TCP"}},
+			},
+		},
+	}
+
+	err := CheckConflicts(komposeObject, kobject.ConvertOptions{})
+	if err == nil {
+		t.Fatalf("expected conflicts to be reported")
+	}
+	for _, expected := range []string{
+		"found 5 conflicts",
+		`service "web": mount path "/data" is used by ./data:/data, tmpfs /data`,
+		`container name "app" is used by services api, web`,
+		`ConfigMap name "config-app-env" is generated for env files config/app-env, config/app.env`,
+		`host port ":8080/TCP" is used by services api, web`,
+		`node port 30080 is used by services api, worker`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected %q in error, got %v", expected, err)
+		}
+	}
+
+	delete(komposeObject.ServiceConfigs, "api")
+	delete(komposeObject.ServiceConfigs, "web")
+	if err := CheckConflicts(komposeObject, kobject.ConvertOptions{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckPriorityClassConflicts(t *testing.T) {
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web":    {Name: "web", Labels: map[string]string{compose.LabelPriorityClass: "high", compose.LabelPriorityClassValue: "1000"}},
+			"api":    {Name: "api", Labels: map[string]string{compose.LabelPriorityClass: "high", compose.LabelPriorityClassValue: "1000"}},
+			"worker": {Name: "worker", Labels: map[string]string{compose.LabelPriorityClass: "high"}},
+		},
+	}
+	if err := CheckConflicts(komposeObject, kobject.ConvertOptions{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	komposeObject.ServiceConfigs["worker"].Labels[compose.LabelPriorityClassValue] = "10"
+	err := CheckConflicts(komposeObject, kobject.ConvertOptions{})
+	if err == nil || !strings.Contains(err.Error(), `priority class "high" is defined with values 10, 1000`) {
+		t.Errorf("expected the priority class values to conflict, got %v", err)
+	}
+}
+
+func TestCheckNetworkAliasConflicts(t *testing.T) {
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web":   {Name: "web", NetworkAliases: []string{"frontend"}},
+			"cache": {Name: "cache", NetworkAliases: []string{"redis"}},
+		},
+	}
+	if err := CheckConflicts(komposeObject, kobject.ConvertOptions{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	komposeObject.ServiceConfigs["frontend"] = kobject.ServiceConfig{Name: "frontend"}
+	err := CheckConflicts(komposeObject, kobject.ConvertOptions{})
+	if err == nil || !strings.Contains(err.Error(), `Service name "frontend" is used by the services or network aliases of frontend, web`) {
+		t.Errorf("expected the network alias to conflict with the service, got %v", err)
+	}
+}
+
+func TestCheckGroupPortConflicts(t *testing.T) {
+	groups := map[string]kobject.ServiceConfigGroup{
+		"front": {
+			{Name: "web", Port: []kobject.Ports{{HostPort: 80, ContainerPort: 8080, Protocol: "TCP"}, {HostPort: 8080, ContainerPort: 8080, Protocol: "TCP"}}},
+			{Name: "metrics", Port: []kobject.Ports{{ContainerPort: 8080, Protocol: "UDP"}, {ContainerPort: 9090, Protocol: "TCP"}}},
+		},
+	}
+	if err := CheckGroupPortConflicts(groups); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	groups["front"] = append(groups["front"], kobject.ServiceConfig{Name: "proxy", Port: []kobject.Ports{{HostPort: 443, ContainerPort: 8080, Protocol: "TCP"}}})
+	err := CheckGroupPortConflicts(groups)
+	if err == nil || !strings.Contains(err.Error(), "container port 8080/TCP is used by the grouped services proxy, web") {
+		t.Errorf("expected the container port to conflict, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	log "github.com/sirupsen/logrus"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// DefaultWaitImage is the image of the init containers waiting for the dependencies of a service
+	DefaultWaitImage = "busybox:1.36"
+
+	dependencyConditionStarted   = "service_started"
+	dependencyConditionHealthy   = "service_healthy"
+	dependencyConditionCompleted = "service_completed_successfully"
+)
+
+// dependencyWaitContainer creates the init container blocking until the Service of the dependency accepts connections.
+// Only the ready pods are behind a Service, so a dependency with a healthcheck is reached once it is healthy.
+func dependencyWaitContainer(dependency string, port int32, image string) api.Container {
+	script := fmt.Sprintf("until nc -z -w 2 %[1]s %[2]d; do echo waiting for %[1]s; sleep 2; done", dependency, port)
+	return api.Container{
+		Name:    "wait-for-" + dependency,
+		Image:   image,
+		Command: []string{"sh", "-c", script},
+	}
+}
+
+// dependencyWaitContainers returns the init containers waiting for the dependencies of the service,
+// skipping the dependencies running in the same pod
+func dependencyWaitContainers(service kobject.ServiceConfig, services map[string]kobject.ServiceConfig, template *api.PodTemplateSpec, opt kobject.ConvertOptions) []api.Container {
+	dependencies := make([]string, 0, len(service.DependsOn))
+	for dependency := range service.DependsOn {
+		dependencies = append(dependencies, dependency)
+	}
+	sort.Strings(dependencies)
+
+	image := opt.WaitImage
+	if image == "" {
+		image = DefaultWaitImage
+	}
+
+	var containers []api.Container
+	for _, name := range dependencies {
+		dependency, ok := services[name]
+		if !ok {
+			continue
+		}
+		switch condition := service.DependsOn[name]; condition {
+		case "", dependencyConditionStarted, dependencyConditionHealthy:
+		case dependencyConditionCompleted:
+			log.Warnf("Service %q can't wait for %q to complete, only running dependencies can be waited for", service.Name, name)
+			continue
+		default:
+			dropped.Warnf("Ignoring the unknown condition %q of the dependency %q of service %q", condition, name, service.Name)
+			continue
+		}
+		if hasContainer(template, GetContainerName(dependency)) {
+			continue
+		}
+		if len(dependency.Port) == 0 {
+			log.Warnf("Service %q can't wait for %q, it has no ports and no Service is generated for it", service.Name, name)
+			continue
+		}
+
+		port := dependency.Port[0].HostPort
+		if port == 0 {
+			port = dependency.Port[0].ContainerPort
+		}
+		containers = append(containers, dependencyWaitContainer(name, port, image))
+	}
+	return containers
+}
+
+// hasContainer tells whether a container or an init container of the pod has the name
+func hasContainer(template *api.PodTemplateSpec, name string) bool {
+	for _, container := range template.Spec.Containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	for _, container := range template.Spec.InitContainers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigDependencyWaits adds init containers to the workloads of the services with depends_on,
+// so that their pods start once the dependencies are reachable like in compose
+func (k *Kubernetes) ConfigDependencyWaits(objects *[]runtime.Object, services map[string]kobject.ServiceConfig, opt kobject.ConvertOptions) error {
+	if !opt.WaitForDependencies {
+		return nil
+	}
+	for _, name := range SortedKeys(services) {
+		service := services[name]
+		if len(service.DependsOn) == 0 {
+			continue
+		}
+		containerName := GetContainerName(service)
+		for _, obj := range *objects {
+			updateTemplate := func(template *api.PodTemplateSpec) error {
+				if !hasContainer(template, containerName) {
+					return nil
+				}
+				waits := dependencyWaitContainers(service, services, template, opt)
+				// the dependencies are waited for before running the other init containers
+				template.Spec.InitContainers = append(waits, template.Spec.InitContainers...)
+				return nil
+			}
+			if err := k.UpdateController(obj, updateTemplate, func(*metav1.ObjectMeta) {}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
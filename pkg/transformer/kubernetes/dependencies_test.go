@@ -0,0 +1,94 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+)
+
+func TestConfigDependencyWaits(t *testing.T) {
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"db":    {Name: "db", Image: "postgres", Port: []kobject.Ports{{ContainerPort: 5432, Protocol: "TCP"}}},
+			"cache": {Name: "cache", Image: "redis", Port: []kobject.Ports{{HostPort: 6380, ContainerPort: 6379, Protocol: "TCP"}}},
+			"batch": {Name: "batch", Image: "batch"},
+			"web": {
+				Name:  "web",
+				Image: "nginx",
+				Port:  []kobject.Ports{{ContainerPort: 80, Protocol: "TCP"}},
+				DependsOn: map[string]string{
+					"db":    dependencyConditionHealthy,
+					"cache": dependencyConditionStarted,
+					"batch": dependencyConditionStarted,
+				},
+			},
+		},
+	}
+
+	for name, test := range map[string]struct {
+		opt      kobject.ConvertOptions
+		expected []api.Container
+	}{
+		"Not enabled": {kobject.ConvertOptions{CreateD: true, Replicas: 1}, nil},
+		"Enabled": {
+			kobject.ConvertOptions{CreateD: true, Replicas: 1, WaitForDependencies: true},
+			[]api.Container{
+				dependencyWaitContainer("cache", 6380, DefaultWaitImage),
+				dependencyWaitContainer("db", 5432, DefaultWaitImage),
+			},
+		},
+		"Custom image": {
+			kobject.ConvertOptions{CreateD: true, Replicas: 1, WaitForDependencies: true, WaitImage: "alpine"},
+			[]api.Container{
+				dependencyWaitContainer("cache", 6380, "alpine"),
+				dependencyWaitContainer("db", 5432, "alpine"),
+			},
+		},
+	} {
+		t.Log("Test case:", name)
+		k := Kubernetes{}
+		objects, err := k.Transform(komposeObject, test.opt)
+		if err != nil {
+			t.Fatalf("k.Transform failed: %v", err)
+		}
+		for _, obj := range objects {
+			deployment, ok := obj.(*appsv1.Deployment)
+			if !ok {
+				continue
+			}
+			initContainers := deployment.Spec.Template.Spec.InitContainers
+			if deployment.Name != "web" {
+				if len(initContainers) != 0 {
+					t.Errorf("Expected no init containers for %s, got %v", deployment.Name, initContainers)
+				}
+				continue
+			}
+			if len(initContainers) != len(test.expected) {
+				t.Fatalf("Expected init containers %v, got %v", test.expected, initContainers)
+			}
+			for i := range test.expected {
+				if initContainers[i].Name != test.expected[i].Name || initContainers[i].Image != test.expected[i].Image || initContainers[i].Command[2] != test.expected[i].Command[2] {
+					t.Errorf("Expected init container %v, got %v", test.expected[i], initContainers[i])
+				}
+			}
+		}
+	}
+}
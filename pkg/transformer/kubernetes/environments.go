@@ -0,0 +1,391 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// EnvironmentsKustomize writes the base objects and a Kustomize overlay for each environment
+	EnvironmentsKustomize = "kustomize"
+	// EnvironmentsHelm writes a chart of the objects of all the environments and a values file for each environment
+	EnvironmentsHelm = "helm"
+)
+
+// EnvironmentObjects are the objects converted for an environment
+type EnvironmentObjects struct {
+	Name    string
+	Objects []runtime.Object
+}
+
+// kustomization is a kustomization.yaml file
+type kustomization struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Resources  []string         `json:"resources,omitempty"`
+	Patches    []kustomizePatch `json:"patches,omitempty"`
+}
+
+// kustomizePatch is a patch of a kustomization, the JSON patches have the target of their operations
+type kustomizePatch struct {
+	Path   string           `json:"path"`
+	Target *kustomizeTarget `json:"target,omitempty"`
+}
+
+// kustomizeTarget selects the object a JSON patch applies to
+type kustomizeTarget struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// patchOperation is an operation of a JSON patch (RFC 6902)
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// PrintEnvironments writes the objects of the base and of the environments to the output directory, as
+// Kustomize overlays of the base objects or as a chart with a values file for each environment
+func PrintEnvironments(base []runtime.Object, environments []EnvironmentObjects, opt kobject.ConvertOptions) error {
+	base = filterKinds(base, opt)
+	for i := range environments {
+		environments[i].Objects = filterKinds(environments[i].Objects, opt)
+	}
+	if opt.EnvironmentsFormat == EnvironmentsHelm {
+		return printHelmEnvironments(base, environments, opt)
+	}
+	return printKustomizeEnvironments(base, environments, opt)
+}
+
+// printKustomizeEnvironments writes the base objects and their kustomization to the base directory, and
+// the overlay of each environment to overlays/NAME: the objects of the environment only, the JSON patches
+// of the objects differing from the base ones and the deletion of the base objects it doesn't have
+func printKustomizeEnvironments(base []runtime.Object, environments []EnvironmentObjects, opt kobject.ConvertOptions) error {
+	dir := getDirName(opt)
+	baseDir := filepath.Join(dir, "base")
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return err
+	}
+
+	baseMaps := map[string]map[string]interface{}{}
+	baseKustomization := kustomization{APIVersion: "kustomize.config.k8s.io/v1beta1", Kind: "Kustomization"}
+	for _, obj := range base {
+		m, err := objectMap(obj)
+		if err != nil {
+			return err
+		}
+		baseMaps[objectKey(obj)] = m
+		file, err := writeObject(baseDir, obj, opt)
+		if err != nil {
+			return err
+		}
+		baseKustomization.Resources = append(baseKustomization.Resources, file)
+	}
+	if err := writeKustomizeFile(baseDir, "kustomization.yaml", baseKustomization, opt); err != nil {
+		return err
+	}
+
+	for _, environment := range environments {
+		overlayDir := filepath.Join(dir, "overlays", environment.Name)
+		if err := os.MkdirAll(overlayDir, 0755); err != nil {
+			return err
+		}
+		overlay := kustomization{APIVersion: "kustomize.config.k8s.io/v1beta1", Kind: "Kustomization", Resources: []string{"../../base"}}
+		seen := map[string]bool{}
+		for _, obj := range environment.Objects {
+			key := objectKey(obj)
+			seen[key] = true
+			baseMap, ok := baseMaps[key]
+			if !ok {
+				file, err := writeObject(overlayDir, obj, opt)
+				if err != nil {
+					return err
+				}
+				overlay.Resources = append(overlay.Resources, file)
+				continue
+			}
+			m, err := objectMap(obj)
+			if err != nil {
+				return err
+			}
+			operations := diffPatch("", baseMap, m)
+			if len(operations) == 0 {
+				continue
+			}
+			file := objectFileName(obj, "patch")
+			if err := writeKustomizeFile(overlayDir, file, operations, opt); err != nil {
+				return err
+			}
+			overlay.Patches = append(overlay.Patches, kustomizePatch{Path: file, Target: patchTarget(obj)})
+		}
+		for _, obj := range base {
+			if seen[objectKey(obj)] {
+				continue
+			}
+			file := objectFileName(obj, "delete")
+			if err := writeKustomizeFile(overlayDir, file, deletePatch(obj), opt); err != nil {
+				return err
+			}
+			overlay.Patches = append(overlay.Patches, kustomizePatch{Path: file})
+		}
+		if err := writeKustomizeFile(overlayDir, "kustomization.yaml", overlay, opt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printHelmEnvironments writes the chart of the base objects and of the objects of the environments only,
+// and a values-NAME.yaml file for each environment, enabling the services and the ingresses it has. The
+// objects of an environment differing from the base ones can't be expressed in the values, they are reported.
+func printHelmEnvironments(base []runtime.Object, environments []EnvironmentObjects, opt kobject.ConvertOptions) error {
+	objects := append([]runtime.Object{}, base...)
+	maps := map[string]map[string]interface{}{}
+	for _, obj := range base {
+		m, err := objectMap(obj)
+		if err != nil {
+			return err
+		}
+		maps[objectKey(obj)] = m
+	}
+	for _, environment := range environments {
+		for _, obj := range environment.Objects {
+			m, err := objectMap(obj)
+			if err != nil {
+				return err
+			}
+			key := objectKey(obj)
+			previous, ok := maps[key]
+			if !ok {
+				maps[key] = m
+				objects = append(objects, obj)
+			} else if !reflect.DeepEqual(previous, m) {
+				log.Warnf("The %s of the environment %s differs from the one of the chart, the values files only enable the services, use --environments-format=%s to keep the differences", key, environment.Name, EnvironmentsKustomize)
+			}
+		}
+	}
+
+	opt.CreateChart = true
+	if err := PrintList(objects, opt); err != nil {
+		return err
+	}
+
+	dir := getDirName(opt)
+	for _, environment := range environments {
+		services := helmServices(environment.Objects)
+		ingress := false
+		for _, obj := range environment.Objects {
+			ingress = ingress || isHelmIngress(obj)
+		}
+		values := helmValues(objects)
+		for name := range values {
+			enabled := services[name]
+			if name == "ingress" {
+				enabled = ingress
+			}
+			values[name] = map[string]interface{}{"enabled": enabled}
+		}
+		data, err := marshalWithIndent(values, opt.YAMLIndent)
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(dir, "values-"+environment.Name+".yaml")
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			return errors.Wrapf(err, "unable to write %s", file)
+		}
+		log.Printf("Helm values file %q created", file)
+	}
+	return nil
+}
+
+// objectKey identifies the object in the environments, by its kind, namespace and name
+func objectKey(obj runtime.Object) string {
+	key := obj.GetObjectKind().GroupVersionKind().Kind
+	if accessor, err := meta.Accessor(obj); err == nil {
+		if accessor.GetNamespace() != "" {
+			key += " " + accessor.GetNamespace() + "/" + accessor.GetName()
+		} else {
+			key += " " + accessor.GetName()
+		}
+	}
+	return key
+}
+
+// objectMap returns the object as the map of its JSON, without its status and its creation timestamp
+func objectMap(obj runtime.Object) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "status")
+	if metadata, ok := m["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+	return m, nil
+}
+
+// objectFileName returns the name of the file of the object, as NAME-KIND[-SUFFIX].yaml
+func objectFileName(obj runtime.Object, suffix string) string {
+	name := ""
+	if accessor, err := meta.Accessor(obj); err == nil {
+		name = accessor.GetName()
+	}
+	trailing := strings.ToLower(obj.GetObjectKind().GroupVersionKind().Kind)
+	if suffix != "" {
+		trailing += "-" + suffix
+	}
+	return transformer.FileName(name, trailing, false)
+}
+
+// writeObject writes the object to its file in the directory and returns the name of the file
+func writeObject(dir string, obj runtime.Object, opt kobject.ConvertOptions) (string, error) {
+	data, err := marshal(obj, false, opt.YAMLIndent)
+	if err != nil {
+		return "", err
+	}
+	file := objectFileName(obj, "")
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, transformer.StripStatus(data), 0644); err != nil {
+		return "", errors.Wrapf(err, "unable to write %s", path)
+	}
+	log.Printf("Kustomize file %q created", path)
+	return file, nil
+}
+
+// writeKustomizeFile writes the value as YAML to the file of the directory, keeping its empty values
+// which are the values of the patches
+func writeKustomizeFile(dir, file string, value interface{}, opt kobject.ConvertOptions) error {
+	j, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var obj interface{}
+	if err := yaml.Unmarshal(j, &obj); err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(opt.YAMLIndent)
+	if err := encoder.Encode(obj); err != nil {
+		return err
+	}
+	data := b.Bytes()
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return errors.Wrapf(err, "unable to write %s", path)
+	}
+	log.Printf("Kustomize file %q created", path)
+	return nil
+}
+
+// patchTarget returns the target of the JSON patch of the object
+func patchTarget(obj runtime.Object) *kustomizeTarget {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	target := &kustomizeTarget{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind}
+	if accessor, err := meta.Accessor(obj); err == nil {
+		target.Name = accessor.GetName()
+		target.Namespace = accessor.GetNamespace()
+	}
+	return target
+}
+
+// deletePatch returns the strategic merge patch deleting the object
+func deletePatch(obj runtime.Object) map[string]interface{} {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	metadata := map[string]interface{}{}
+	if accessor, err := meta.Accessor(obj); err == nil {
+		metadata["name"] = accessor.GetName()
+		if accessor.GetNamespace() != "" {
+			metadata["namespace"] = accessor.GetNamespace()
+		}
+	}
+	return map[string]interface{}{
+		"$patch":     "delete",
+		"apiVersion": gvk.GroupVersion().String(),
+		"kind":       gvk.Kind,
+		"metadata":   metadata,
+	}
+}
+
+// diffPatch returns the JSON patch operations changing the base value into the target one, the maps are
+// compared key by key and the other values, the lists included, are replaced
+func diffPatch(path string, base, target interface{}) []patchOperation {
+	baseMap, baseIsMap := base.(map[string]interface{})
+	targetMap, targetIsMap := target.(map[string]interface{})
+	if !baseIsMap || !targetIsMap {
+		if reflect.DeepEqual(base, target) {
+			return nil
+		}
+		return []patchOperation{{Op: "replace", Path: path, Value: rawValue(target)}}
+	}
+
+	keys := make([]string, 0, len(baseMap)+len(targetMap))
+	for key := range baseMap {
+		keys = append(keys, key)
+	}
+	for key := range targetMap {
+		if _, ok := baseMap[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var operations []patchOperation
+	for _, key := range keys {
+		keyPath := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+		baseValue, inBase := baseMap[key]
+		targetValue, inTarget := targetMap[key]
+		switch {
+		case !inTarget:
+			operations = append(operations, patchOperation{Op: "remove", Path: keyPath})
+		case !inBase:
+			operations = append(operations, patchOperation{Op: "add", Path: keyPath, Value: rawValue(targetValue)})
+		default:
+			operations = append(operations, diffPatch(keyPath, baseValue, targetValue)...)
+		}
+	}
+	return operations
+}
+
+// rawValue returns the JSON of the value of a patch operation
+func rawValue(value interface{}) json.RawMessage {
+	data, _ := json.Marshal(value)
+	return data
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestDiffPatch(t *testing.T) {
+	base := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 1.0,
+			"paused":   true,
+			"ports":    []interface{}{80.0},
+		},
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"app/name": "web"}},
+	}
+	target := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 3.0,
+			"ports":    []interface{}{80.0, 443.0},
+			"strategy": "Recreate",
+		},
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"app/name": "web"}},
+	}
+
+	var got []string
+	for _, operation := range diffPatch("", base, target) {
+		got = append(got, operation.Op+" "+operation.Path+" "+string(operation.Value))
+	}
+	expected := []string{
+		"remove /spec/paused ",
+		"replace /spec/ports [80,443]",
+		"replace /spec/replicas 3",
+		"add /spec/strategy \"Recreate\"",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the operations %q, got %q", expected, got)
+	}
+	if operations := diffPatch("", base, base); len(operations) != 0 {
+		t.Errorf("Expected no operation for equal objects, got %v", operations)
+	}
+}
+
+func TestPrintKustomizeEnvironments(t *testing.T) {
+	deployment := func(name string, replicas int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+	}
+	service := &api.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+	}
+	base := []runtime.Object{deployment("web", 1), service}
+	prod := EnvironmentObjects{Name: "prod", Objects: []runtime.Object{deployment("web", 3), deployment("monitor", 1)}}
+
+	dir := t.TempDir()
+	opt := kobject.ConvertOptions{OutFile: dir, YAMLIndent: 2, EnvironmentsFormat: EnvironmentsKustomize}
+	if err := PrintEnvironments(base, []EnvironmentObjects{prod}, opt); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	read := func(file string) string {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", file, err)
+		}
+		return string(data)
+	}
+	if got := read("base/kustomization.yaml"); !strings.Contains(got, "  - web-deployment.yaml\n  - web-service.yaml\n") {
+		t.Errorf("Expected the base objects in the base kustomization, got:\n%s", got)
+	}
+	read("base/web-deployment.yaml")
+
+	overlay := read("overlays/prod/kustomization.yaml")
+	for _, expected := range []string{"  - ../../base\n  - monitor-deployment.yaml\n", "path: web-deployment-patch.yaml", "path: web-service-delete.yaml"} {
+		if !strings.Contains(overlay, expected) {
+			t.Errorf("Expected %q in the overlay kustomization, got:\n%s", expected, overlay)
+		}
+	}
+	if got := read("overlays/prod/web-deployment-patch.yaml"); !strings.Contains(got, "path: /spec/replicas") || !strings.Contains(got, "value: 3") {
+		t.Errorf("Expected the patch of the replicas, got:\n%s", got)
+	}
+	if got := read("overlays/prod/web-service-delete.yaml"); !strings.Contains(got, "$patch: delete") {
+		t.Errorf("Expected the deletion of the Service, got:\n%s", got)
+	}
+	read("overlays/prod/monitor-deployment.yaml")
+}
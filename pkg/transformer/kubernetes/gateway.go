@@ -0,0 +1,206 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// IngressExposeController exposes the services with Ingress objects
+	IngressExposeController = "ingress"
+	// GatewayAPIExposeController exposes the services with Gateway API HTTPRoute objects
+	GatewayAPIExposeController = "gateway-api"
+
+	// DefaultGatewayName is the name of the Gateway the HTTPRoutes are attached to
+	DefaultGatewayName = "kompose"
+
+	gatewayAPIVersion = "gateway.networking.k8s.io/v1"
+)
+
+// exposedHosts returns the hosts of the kompose.service.expose label, with the paths of each host.
+// The host is empty when the label is "true".
+func exposedHosts(service kobject.ServiceConfig) (hosts []string, paths map[string][]string) {
+	paths = map[string][]string{}
+	for _, value := range regexp.MustCompile("[ ,]*,[ ,]*").Split(service.ExposeService, -1) {
+		host, p := transformer.ParseIngressPath(value)
+		if p == "" {
+			p = "/"
+		}
+		if host == "true" {
+			host = ""
+		}
+		if _, ok := paths[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		paths[host] = append(paths[host], p)
+	}
+	return hosts, paths
+}
+
+// parseGatewayName splits a gateway given as [NAMESPACE/]NAME
+func parseGatewayName(gateway string) (namespace, name string) {
+	if gateway == "" {
+		return "", DefaultGatewayName
+	}
+	if namespace, name, found := strings.Cut(gateway, "/"); found {
+		return namespace, name
+	}
+	return "", gateway
+}
+
+// initHTTPRoutes converts the kompose.service.expose label to HTTPRoutes attached to the gateway,
+// the hosts routing the same paths share a single HTTPRoute
+func (k *Kubernetes) initHTTPRoutes(name string, service kobject.ServiceConfig, port int32, opt kobject.ConvertOptions) []*unstructured.Unstructured {
+	if service.ExposeServiceIngressClassName != "" {
+		dropped.Warnf("Ignoring the ingress class of service %q, the Gateway API is used to expose it", name)
+	}
+	if service.ExposeServiceTLS != "" && opt.GatewayClassName == "" {
+		log.Warnf("The TLS of service %q must be configured on the listeners of the Gateway", name)
+	}
+
+	gatewayNamespace, gatewayName := parseGatewayName(opt.GatewayName)
+	parentRef := map[string]interface{}{"name": gatewayName}
+	if gatewayNamespace != "" {
+		parentRef["namespace"] = gatewayNamespace
+	}
+
+	hosts, paths := exposedHosts(service)
+	var groups [][]string
+	groupOf := map[string]int{}
+	for _, host := range hosts {
+		key := strings.Join(paths[host], ",")
+		if i, ok := groupOf[key]; ok {
+			groups[i] = append(groups[i], host)
+			continue
+		}
+		groupOf[key] = len(groups)
+		groups = append(groups, []string{host})
+	}
+
+	var routes []*unstructured.Unstructured
+	for i, group := range groups {
+		routeName := name
+		if i > 0 {
+			routeName = fmt.Sprintf("%s-%d", name, i+1)
+		}
+
+		var hostnames []interface{}
+		for _, host := range group {
+			if host != "" {
+				hostnames = append(hostnames, host)
+			}
+		}
+		var rules []interface{}
+		for _, p := range paths[group[0]] {
+			rules = append(rules, map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":  "PathPrefix",
+							"value": p,
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": name,
+						"port": int64(port),
+					},
+				},
+			})
+		}
+
+		spec := map[string]interface{}{
+			"parentRefs": []interface{}{parentRef},
+			"rules":      rules,
+		}
+		if len(hostnames) > 0 {
+			spec["hostnames"] = hostnames
+		}
+		route := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": gatewayAPIVersion,
+			"kind":       "HTTPRoute",
+			"metadata":   map[string]interface{}{"name": routeName},
+			"spec":       spec,
+		}}
+		route.SetLabels(transformer.ConfigLabels(name))
+		route.SetAnnotations(transformer.ConfigAnnotations(service))
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// initGateway creates the Gateway the HTTPRoutes are attached to, with an HTTP listener
+// and an HTTPS listener for each host exposed with a TLS secret
+func (k *Kubernetes) initGateway(komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) *unstructured.Unstructured {
+	_, gatewayName := parseGatewayName(opt.GatewayName)
+	listeners := []interface{}{
+		map[string]interface{}{
+			"name":     "http",
+			"protocol": "HTTP",
+			"port":     int64(80),
+		},
+	}
+	for _, name := range SortedKeys(komposeObject.ServiceConfigs) {
+		service := komposeObject.ServiceConfigs[name]
+		if service.ExposeService == "" || service.ExposeServiceTLS == "" {
+			continue
+		}
+		if service.ExposeServiceTLS == "true" {
+			log.Warnf("Service %q is exposed with TLS without a secret, no HTTPS listener is generated for it", name)
+			continue
+		}
+		hosts, _ := exposedHosts(service)
+		for i, host := range hosts {
+			listener := map[string]interface{}{
+				"name":     fmt.Sprintf("https-%s-%d", name, i),
+				"protocol": "HTTPS",
+				"port":     int64(443),
+				"tls": map[string]interface{}{
+					"mode": "Terminate",
+					"certificateRefs": []interface{}{
+						map[string]interface{}{"name": service.ExposeServiceTLS},
+					},
+				},
+			}
+			if host != "" {
+				listener["hostname"] = host
+			}
+			listeners = append(listeners, listener)
+		}
+	}
+
+	gateway := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": gatewayAPIVersion,
+		"kind":       "Gateway",
+		"metadata":   map[string]interface{}{"name": gatewayName},
+		"spec": map[string]interface{}{
+			"gatewayClassName": opt.GatewayClassName,
+			"listeners":        listeners,
+		},
+	}}
+	return gateway
+}
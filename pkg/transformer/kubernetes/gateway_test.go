@@ -0,0 +1,142 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	api "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGatewayAPIExposeController(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:             "web",
+		Image:            "nginx",
+		Port:             []kobject.Ports{{HostPort: 80, ContainerPort: 8080, Protocol: "TCP"}},
+		ExposeService:    "example.com/api,www.example.com/api,admin.example.com",
+		ExposeServiceTLS: "web-tls",
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": service}}
+	opt := kobject.ConvertOptions{ExposeController: GatewayAPIExposeController, GatewayClassName: "istio"}
+
+	k := Kubernetes{Opt: opt}
+	objs, err := k.Transform(komposeObject, opt)
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+
+	routes := map[string]*unstructured.Unstructured{}
+	var gateway *unstructured.Unstructured
+	for _, obj := range objs {
+		if _, ok := obj.(*networkingv1.Ingress); ok {
+			t.Errorf("Expected no ingress with the gateway-api expose controller")
+		}
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			switch u.GetKind() {
+			case "HTTPRoute":
+				routes[u.GetName()] = u
+			case "Gateway":
+				gateway = u
+			}
+		}
+	}
+
+	// the hosts routing the same paths share a route
+	expectedHostnames := map[string][]string{
+		"web":   {"example.com", "www.example.com"},
+		"web-2": {"admin.example.com"},
+	}
+	expectedPaths := map[string]string{"web": "/api", "web-2": "/"}
+	if len(routes) != len(expectedHostnames) {
+		t.Fatalf("Expected %d HTTPRoutes, got %d", len(expectedHostnames), len(routes))
+	}
+	for name, hostnames := range expectedHostnames {
+		route := routes[name]
+		got, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		if !reflect.DeepEqual(got, hostnames) {
+			t.Errorf("Expected hostnames %v for route %s, got %v", hostnames, name, got)
+		}
+		rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+		rule := rules[0].(map[string]interface{})
+		path, _, _ := unstructured.NestedString(rule["matches"].([]interface{})[0].(map[string]interface{}), "path", "value")
+		if path != expectedPaths[name] {
+			t.Errorf("Expected path %s for route %s, got %s", expectedPaths[name], name, path)
+		}
+		backend := rule["backendRefs"].([]interface{})[0].(map[string]interface{})
+		if backend["name"] != "web" || backend["port"] != int64(80) {
+			t.Errorf("Expected the route %s to target the web service port 80, got %v", name, backend)
+		}
+		parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+		if parents[0].(map[string]interface{})["name"] != DefaultGatewayName {
+			t.Errorf("Expected the route %s to be attached to the %s gateway, got %v", name, DefaultGatewayName, parents)
+		}
+	}
+
+	if gateway == nil {
+		t.Fatalf("Expected a Gateway to be generated with --gateway-class")
+	}
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+	if len(listeners) != 4 {
+		t.Errorf("Expected an HTTP listener and 3 HTTPS listeners, got %v", listeners)
+	}
+}
+
+func TestParseGatewayName(t *testing.T) {
+	for gateway, expected := range map[string][2]string{
+		"":               {"", DefaultGatewayName},
+		"public":         {"", "public"},
+		"infra/external": {"infra", "external"},
+	} {
+		namespace, name := parseGatewayName(gateway)
+		if namespace != expected[0] || name != expected[1] {
+			t.Errorf("Expected %v for gateway %q, got %s/%s", expected, gateway, namespace, name)
+		}
+	}
+}
+
+func TestIngressBackendProtocol(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:          "web",
+		Image:         "nginx",
+		Port:          []kobject.Ports{{HostPort: 443, ContainerPort: 8443, Protocol: "TCP", AppProtocol: "https"}},
+		ExposeService: "example.com",
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": service}}
+
+	k := Kubernetes{}
+	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *api.Service:
+			if o.Spec.Ports[0].AppProtocol == nil || *o.Spec.Ports[0].AppProtocol != "https" {
+				t.Errorf("Expected the appProtocol https on the port of the service, got %v", o.Spec.Ports[0].AppProtocol)
+			}
+		case *networkingv1.Ingress:
+			if protocol := o.Annotations[backendProtocolAnnotation]; protocol != "HTTPS" {
+				t.Errorf("Expected the HTTPS backend protocol on the ingress, got %q", protocol)
+			}
+		}
+	}
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	api "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// helmIdentifier matches the names usable as fields of .Values in the templates
+var helmIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// helmServiceValues returns the reference to the values of a service in the templates
+func helmServiceValues(name string) string {
+	if helmIdentifier.MatchString(name) {
+		return ".Values." + name
+	}
+	return fmt.Sprintf("(index .Values %q)", name)
+}
+
+// isHelmIngress tells whether the object exposes a service outside of the cluster, toggled with ingress.enabled
+func isHelmIngress(obj runtime.Object) bool {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	return kind == "Ingress" || kind == "HTTPRoute"
+}
+
+// helmServices returns the compose services of the chart, the services labeling the workloads
+func helmServices(objects []runtime.Object) map[string]bool {
+	k := Kubernetes{}
+	services := map[string]bool{}
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		service := accessor.GetLabels()[transformer.Selector]
+		if service == "" {
+			continue
+		}
+		_ = k.UpdateController(obj, func(*api.PodTemplateSpec) error {
+			services[service] = true
+			return nil
+		}, func(*metav1.ObjectMeta) {})
+	}
+	return services
+}
+
+// helmService returns the compose service the object belongs to, by its label, or by its selector
+// for the Services split by protocol
+func helmService(obj runtime.Object, services map[string]bool) string {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		if service := accessor.GetLabels()[transformer.Selector]; services[service] {
+			return service
+		}
+	}
+	if svc, ok := obj.(*api.Service); ok && services[svc.Spec.Selector[transformer.Selector]] {
+		return svc.Spec.Selector[transformer.Selector]
+	}
+	return ""
+}
+
+// helmCondition returns the condition the template of the object is rendered with, the service of the object
+// must be enabled, and the ingresses enabled for an Ingress or an HTTPRoute. The objects shared by the services,
+// like the volumes or the network policies, are always rendered.
+func helmCondition(obj runtime.Object, services map[string]bool) string {
+	var conditions []string
+	if service := helmService(obj, services); service != "" {
+		conditions = append(conditions, helmServiceValues(service)+".enabled")
+	}
+	if isHelmIngress(obj) {
+		conditions = append(conditions, ".Values.ingress.enabled")
+	}
+	switch len(conditions) {
+	case 0:
+		return ""
+	case 1:
+		return conditions[0]
+	default:
+		return "and " + strings.Join(conditions, " ")
+	}
+}
+
+// helmConditional wraps the template of the object in its condition
+func helmConditional(obj runtime.Object, data []byte, services map[string]bool) []byte {
+	condition := helmCondition(obj, services)
+	if condition == "" {
+		return data
+	}
+	// the status is stripped before the end of the condition is appended
+	data = transformer.StripStatus(data)
+	return []byte(fmt.Sprintf("{{- if %s }}\n%s{{- end }}\n", condition, data))
+}
+
+// helmValues returns the default values of the chart, enabling the services and the ingresses
+func helmValues(objects []runtime.Object) map[string]interface{} {
+	values := map[string]interface{}{}
+	for service := range helmServices(objects) {
+		values[service] = map[string]interface{}{"enabled": true}
+	}
+	for _, obj := range objects {
+		if isHelmIngress(obj) {
+			values["ingress"] = map[string]interface{}{"enabled": true}
+		}
+	}
+	return values
+}
+
+// helmValuesSchema returns the JSON schema of the values, derived from the types of the default values
+func helmValuesSchema(values map[string]interface{}) map[string]interface{} {
+	schema := jsonSchema(values)
+	schema["$schema"] = "https://json-schema.org/draft-07/schema#"
+	return schema
+}
+
+func jsonSchema(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties := make(map[string]interface{}, len(v))
+		for key, property := range v {
+			properties[key] = jsonSchema(property)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	case []interface{}:
+		return map[string]interface{}{"type": "array"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	case int, int32, int64:
+		return map[string]interface{}{"type": "integer"}
+	case float32, float64:
+		return map[string]interface{}{"type": "number"}
+	case string:
+		return map[string]interface{}{"type": "string"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// helmNotes returns the NOTES.txt of the chart, printed by helm install: the addresses of the Services
+// and of the Ingresses or HTTPRoutes of the enabled services
+func helmNotes(objects []runtime.Object) string {
+	services := helmServices(objects)
+	var notes strings.Builder
+	notes.WriteString("{{ .Chart.Name }} is installed in the namespace {{ .Release.Namespace }}.\n")
+
+	// each line is rendered with the condition of its object
+	line := func(obj runtime.Object, text string) {
+		if condition := helmCondition(obj, services); condition != "" {
+			fmt.Fprintf(&notes, "{{- if %s }}\n%s\n{{- end }}\n", condition, text)
+		} else {
+			notes.WriteString(text + "\n")
+		}
+	}
+
+	var svcs []*api.Service
+	var exposed []runtime.Object
+	for _, obj := range objects {
+		if svc, ok := obj.(*api.Service); ok && svc.Spec.ClusterIP != "None" {
+			svcs = append(svcs, svc)
+		}
+		if isHelmIngress(obj) {
+			exposed = append(exposed, obj)
+		}
+	}
+
+	if len(svcs) > 0 {
+		notes.WriteString("\nThe services are reachable in the cluster at:\n")
+	}
+	for _, svc := range svcs {
+		namespace := svc.Namespace
+		if namespace == "" {
+			namespace = "{{ .Release.Namespace }}"
+		}
+		ports := make([]string, 0, len(svc.Spec.Ports))
+		for _, port := range svc.Spec.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = api.ProtocolTCP
+			}
+			ports = append(ports, fmt.Sprintf("%d/%s", port.Port, protocol))
+		}
+		text := fmt.Sprintf("  %s: %s.%s.svc (%s)", svc.Name, svc.Name, namespace, strings.Join(ports, ", "))
+		switch svc.Spec.Type {
+		case api.ServiceTypeLoadBalancer:
+			text += fmt.Sprintf(", external address: kubectl get service %s -n %s", svc.Name, namespace)
+		case api.ServiceTypeNodePort:
+			text += fmt.Sprintf(", node ports: kubectl get service %s -n %s", svc.Name, namespace)
+		}
+		line(svc, text)
+	}
+
+	if len(exposed) > 0 {
+		notes.WriteString("\nThe services are exposed at:\n")
+	}
+	for _, obj := range exposed {
+		for _, url := range exposedURLs(obj) {
+			line(obj, "  "+url)
+		}
+	}
+	return notes.String()
+}
+
+// exposedURLs returns the URLs an Ingress or an HTTPRoute exposes
+func exposedURLs(obj runtime.Object) []string {
+	var urls []string
+	switch t := obj.(type) {
+	case *networkingv1.Ingress:
+		tls := map[string]bool{}
+		for _, entry := range t.Spec.TLS {
+			for _, host := range entry.Hosts {
+				tls[host] = true
+			}
+		}
+		for _, rule := range t.Spec.Rules {
+			scheme := "http"
+			if tls[rule.Host] {
+				scheme = "https"
+			}
+			host := rule.Host
+			if host == "" {
+				host = "<ingress address>"
+			}
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				urls = append(urls, fmt.Sprintf("%s://%s%s", scheme, host, path.Path))
+			}
+		}
+	case *unstructured.Unstructured:
+		hostnames, _, _ := unstructured.NestedStringSlice(t.Object, "spec", "hostnames")
+		for _, host := range hostnames {
+			urls = append(urls, "http://"+host+"/")
+		}
+	}
+	return urls
+}
+
+// packageHelmChart packages the chart of dirName with helm in the destination directory and returns the
+// path of the package
+func packageHelmChart(dirName string, destination string) (string, error) {
+	if _, err := exec.LookPath("helm"); err != nil {
+		return "", errors.New("Helm is not installed! Please install Helm to package the chart")
+	}
+	output, err := exec.Command("helm", "package", dirName, "--destination", destination).CombinedOutput()
+	if err != nil {
+		return "", errors.Errorf("unable to package the chart %q: %s", dirName, strings.TrimSpace(string(output)))
+	}
+	// helm prints the path of the package last: Successfully packaged chart and saved it to: PATH
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if _, path, found := strings.Cut(lines[len(lines)-1], "saved it to:"); found {
+		return strings.TrimSpace(path), nil
+	}
+	// else the destination has the package only
+	packages, err := filepath.Glob(filepath.Join(destination, "*.tgz"))
+	if err != nil || len(packages) != 1 {
+		return "", errors.Errorf("unable to find the package of the chart %q", dirName)
+	}
+	return packages[0], nil
+}
+
+// pushHelmChart packages the chart of dirName with helm and pushes it to the OCI registry ref, with the
+// credentials of helm registry login or docker login. It returns the digest of the pushed chart.
+func pushHelmChart(dirName string, ref string) (string, error) {
+	if !strings.HasPrefix(ref, "oci://") {
+		return "", errors.Errorf("the chart can only be pushed to an OCI registry, as oci://<registry>/<repository>, got %q", ref)
+	}
+	if _, err := exec.LookPath("helm"); err != nil {
+		return "", errors.New("Helm is not installed! Please install Helm to push the chart")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "kompose-chart")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pkg, err := packageHelmChart(dirName, tmpDir)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := exec.Command("helm", "push", pkg, ref).CombinedOutput()
+	if err != nil {
+		return "", errors.Errorf("unable to push the chart %q to %s: %s", dirName, ref, strings.TrimSpace(string(output)))
+	}
+	log.Debugf("helm push output:\n%s", output)
+	for _, line := range strings.Split(string(output), "\n") {
+		if digest, found := strings.CutPrefix(strings.TrimSpace(line), "Digest:"); found {
+			return strings.TrimSpace(digest), nil
+		}
+	}
+	return "", errors.Errorf("no digest in the output of helm push: %s", strings.TrimSpace(string(output)))
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/transformer"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func chartObjects() []runtime.Object {
+	return []runtime.Object{
+		&appsv1.Deployment{TypeMeta: metav1.TypeMeta{Kind: "Deployment"}, ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: transformer.ConfigLabels("web")}},
+		&appsv1.StatefulSet{TypeMeta: metav1.TypeMeta{Kind: "StatefulSet"}, ObjectMeta: metav1.ObjectMeta{Name: "my-db", Labels: transformer.ConfigLabels("my-db")}},
+		&api.Service{
+			TypeMeta:   metav1.TypeMeta{Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web-udp", Labels: transformer.ConfigLabels("web-udp")},
+			Spec:       api.ServiceSpec{Selector: transformer.ConfigLabels("web")},
+		},
+		&networkingv1.Ingress{TypeMeta: metav1.TypeMeta{Kind: "Ingress"}, ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: transformer.ConfigLabels("web")}},
+		&api.PersistentVolumeClaim{TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim"}, ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: transformer.ConfigLabels("data")}},
+	}
+}
+
+func TestHelmConditional(t *testing.T) {
+	objects := chartObjects()
+	services := helmServices(objects)
+	expected := []string{
+		".Values.web.enabled",
+		`(index .Values "my-db").enabled`,
+		".Values.web.enabled",
+		"and .Values.web.enabled .Values.ingress.enabled",
+		"",
+	}
+	for i, obj := range objects {
+		if condition := helmCondition(obj, services); condition != expected[i] {
+			t.Errorf("Expected the condition %q for object %d, got %q", expected[i], i, condition)
+		}
+	}
+
+	data := helmConditional(objects[0], []byte("kind: Deployment\nstatus:\n  replicas: 0\n"), services)
+	if string(data) != "{{- if .Values.web.enabled }}\nkind: Deployment\n{{- end }}\n" {
+		t.Errorf("Expected the template wrapped in its condition without status, got %q", data)
+	}
+	if data := helmConditional(objects[4], []byte("kind: PersistentVolumeClaim\n"), services); string(data) != "kind: PersistentVolumeClaim\n" {
+		t.Errorf("Expected the template of the volume unchanged, got %q", data)
+	}
+}
+
+func TestHelmValues(t *testing.T) {
+	expected := map[string]interface{}{
+		"web":     map[string]interface{}{"enabled": true},
+		"my-db":   map[string]interface{}{"enabled": true},
+		"ingress": map[string]interface{}{"enabled": true},
+	}
+	if values := helmValues(chartObjects()); !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+}
+
+// fakeHelm installs a helm script in the PATH, recording its arguments in the returned file
+func fakeHelm(t *testing.T, script string) string {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	content := "#!/bin/sh\necho \"$@\" >> " + calls + "\n" + script
+	if err := os.WriteFile(filepath.Join(dir, "helm"), []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	return calls
+}
+
+func TestPushHelmChart(t *testing.T) {
+	calls := fakeHelm(t, `case "$1" in
+package) : > "$4/shop-0.0.1.tgz" ;;
+push) echo "Pushed: registry.example.com/charts/shop:0.0.1"; echo "Digest: sha256:1234" ;;
+esac
+`)
+
+	digest, err := pushHelmChart("shop", "oci://registry.example.com/charts")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if digest != "sha256:1234" {
+		t.Errorf("Expected the digest sha256:1234, got %q", digest)
+	}
+
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "package shop --destination ") ||
+		!strings.HasPrefix(lines[1], "push ") || !strings.HasSuffix(lines[1], "/shop-0.0.1.tgz oci://registry.example.com/charts") {
+		t.Errorf("Expected helm package then helm push, got %q", lines)
+	}
+
+	if _, err := pushHelmChart("shop", "registry.example.com/charts"); err == nil {
+		t.Errorf("Expected an error for a repository without the oci:// scheme")
+	}
+}
+
+func TestPushHelmChartFailure(t *testing.T) {
+	fakeHelm(t, `echo "Error: unauthorized"; exit 1
+`)
+	_, err := pushHelmChart("shop", "oci://registry.example.com/charts")
+	if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("Expected the error of helm, got %v", err)
+	}
+}
+
+func TestHelmValuesSchema(t *testing.T) {
+	values := map[string]interface{}{
+		"web":   map[string]interface{}{"enabled": true, "replicas": 2, "image": "nginx"},
+		"ratio": 0.5,
+	}
+	expected := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"ratio": map[string]interface{}{"type": "number"},
+			"web": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"enabled":  map[string]interface{}{"type": "boolean"},
+					"replicas": map[string]interface{}{"type": "integer"},
+					"image":    map[string]interface{}{"type": "string"},
+				},
+			},
+		},
+	}
+	if schema := helmValuesSchema(values); !reflect.DeepEqual(schema, expected) {
+		t.Errorf("Expected %v, got %v", expected, schema)
+	}
+}
+
+func TestHelmNotes(t *testing.T) {
+	objects := chartObjects()
+	objects[2].(*api.Service).Spec.Ports = []api.ServicePort{{Port: 53, Protocol: api.ProtocolUDP}, {Port: 80}}
+	objects[2].(*api.Service).Spec.Type = api.ServiceTypeLoadBalancer
+	objects[3].(*networkingv1.Ingress).Spec = networkingv1.IngressSpec{
+		TLS: []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}}},
+		Rules: []networkingv1.IngressRule{{
+			Host: "shop.example.com",
+			IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{Path: "/"}, {Path: "/api"}},
+			}},
+		}},
+	}
+
+	expected := `{{ .Chart.Name }} is installed in the namespace {{ .Release.Namespace }}.
+
+The services are reachable in the cluster at:
+{{- if .Values.web.enabled }}
+  web-udp: web-udp.{{ .Release.Namespace }}.svc (53/UDP, 80/TCP), external address: kubectl get service web-udp -n {{ .Release.Namespace }}
+{{- end }}
+
+The services are exposed at:
+{{- if and .Values.web.enabled .Values.ingress.enabled }}
+  https://shop.example.com/
+{{- end }}
+{{- if and .Values.web.enabled .Values.ingress.enabled }}
+  https://shop.example.com/api
+{{- end }}
+`
+	if notes := helmNotes(objects); notes != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, notes)
+	}
+}
@@ -18,6 +18,8 @@ package kubernetes
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -37,13 +39,17 @@ import (
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/loader/compose"
 	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	"github.com/kubernetes/kompose/pkg/utils/remote"
 	deployapi "github.com/openshift/api/apps/v1"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
-	hpa "k8s.io/api/autoscaling/v2beta2"
+	hpa "k8s.io/api/autoscaling/v2"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
 	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -85,12 +91,13 @@ type DeploymentMapping struct {
 /**
  * Generate Helm Chart configuration
  */
-func generateHelm(dirName string) error {
+func generateHelm(dirName string, objects []runtime.Object) error {
 	type ChartDetails struct {
 		Name string
 	}
 
-	details := ChartDetails{dirName}
+	// the name of the chart is the name of its directory, helm rejects the names of paths
+	details := ChartDetails{filepath.Base(filepath.Clean(dirName))}
 	manifestDir := dirName + string(os.PathSeparator) + "templates"
 	dir, err := os.Open(dirName)
 
@@ -141,6 +148,33 @@ home:
 		return err
 	}
 
+	/* Create the values.yaml file, the objects of each service and the ingresses can be disabled */
+	values := helmValues(objects)
+	valuesData, err := marshalWithIndent(values, 2)
+	if err != nil {
+		return errors.Wrap(err, "Failed to generate values.yaml")
+	}
+	err = os.WriteFile(dirName+string(os.PathSeparator)+"values.yaml", valuesData, 0644)
+	if err != nil {
+		return err
+	}
+
+	/* Create the values.schema.json file validating the overrides of the values */
+	schema, err := json.MarshalIndent(helmValuesSchema(values), "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "Failed to generate values.schema.json")
+	}
+	err = os.WriteFile(dirName+string(os.PathSeparator)+"values.schema.json", append(schema, '\n'), 0644)
+	if err != nil {
+		return err
+	}
+
+	/* Create the NOTES.txt file printed by helm install, listing the services and their addresses */
+	err = os.WriteFile(manifestDir+string(os.PathSeparator)+"NOTES.txt", []byte(helmNotes(objects)), 0644)
+	if err != nil {
+		return err
+	}
+
 	log.Infof("chart created in %q\n", dirName+string(os.PathSeparator))
 	return nil
 }
@@ -167,12 +201,49 @@ func isDir(name string) (bool, error) {
 	return false, nil
 }
 
+// filterKinds keeps the objects of the --include-kinds kinds, all of them when empty, then drops the
+// objects of the --exclude-kinds kinds. The kinds are matched case-insensitively.
+func filterKinds(objects []runtime.Object, opt kobject.ConvertOptions) []runtime.Object {
+	if len(opt.IncludeKinds) == 0 && len(opt.ExcludeKinds) == 0 {
+		return objects
+	}
+	matches := func(kinds []string, kind string) bool {
+		for _, k := range kinds {
+			if strings.EqualFold(k, kind) {
+				return true
+			}
+		}
+		return false
+	}
+
+	filtered := make([]runtime.Object, 0, len(objects))
+	for _, obj := range objects {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if len(opt.IncludeKinds) > 0 && !matches(opt.IncludeKinds, kind) {
+			log.Debugf("Skipping %s, its kind isn't included", kind)
+			continue
+		}
+		if matches(opt.ExcludeKinds, kind) {
+			log.Debugf("Skipping %s, its kind is excluded", kind)
+			continue
+		}
+		filtered = append(filtered, obj)
+	}
+	return filtered
+}
+
 func getDirName(opt kobject.ConvertOptions) string {
 	dirName := opt.OutFile
 	if dirName == "" {
 		// Let assume all the docker-compose files are in the same directory
 		if opt.CreateChart {
 			filename := opt.InputFiles[0]
+			// the chart of a compose file read from stdin or fetched is created in the current directory
+			if filename == "-" {
+				filename = "compose"
+			} else if remote.IsRemote(filename) {
+				filename = remote.BaseName(filename)
+			}
 			extension := filepath.Ext(filename)
 			dirName = filename[0 : len(filename)-len(extension)]
 		} else {
@@ -182,9 +253,88 @@ func getDirName(opt kobject.ConvertOptions) string {
 	return dirName
 }
 
+const (
+	// OutputLayoutFlat writes the files of all the objects in the output directory
+	OutputLayoutFlat = "flat"
+	// OutputLayoutService writes the files of the objects of each service in a directory named after it
+	OutputLayoutService = "service"
+)
+
+// workloadKinds are the kinds of the objects running the containers of a service
+var workloadKinds = map[string]bool{
+	"Deployment":            true,
+	"DaemonSet":             true,
+	"StatefulSet":           true,
+	"ReplicationController": true,
+	"Job":                   true,
+	"CronJob":               true,
+	"Pod":                   true,
+	"DeploymentConfig":      true,
+}
+
+// ServiceOf returns the service an object was generated for, from its selector label or its name
+// prefixed with the service name, or an empty string. The services are sorted longest first, so that
+// an object is attributed to the most specific service.
+func ServiceOf(services []string, selector, name string) string {
+	for _, value := range []string{selector, name} {
+		for _, service := range services {
+			if value == service || strings.HasPrefix(value, service+"-") {
+				return service
+			}
+		}
+	}
+	return ""
+}
+
+// SortServicesByLength sorts the service names longest first, as expected by ServiceOf
+func SortServicesByLength(services []string) {
+	sort.Slice(services, func(i, j int) bool {
+		if len(services[i]) != len(services[j]) {
+			return len(services[i]) > len(services[j])
+		}
+		return services[i] < services[j]
+	})
+}
+
+// workloadServices returns the services of the workloads, from their selector label, longest first
+func workloadServices(objects []runtime.Object) []string {
+	seen := map[string]bool{}
+	var services []string
+	for _, obj := range objects {
+		if !workloadKinds[obj.GetObjectKind().GroupVersionKind().Kind] {
+			continue
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		if service := accessor.GetLabels()[transformer.Selector]; service != "" && !seen[service] {
+			seen[service] = true
+			services = append(services, service)
+		}
+	}
+	SortServicesByLength(services)
+	return services
+}
+
+// serviceLayoutFile returns the directory and the name of the file of an object with --output-layout=service:
+// the objects of a service are written in its directory, the one named after the service as KIND.yaml and
+// the others as NAME-KIND.yaml, and the objects of no service in the output directory.
+func serviceLayoutFile(dir string, services []string, objectMeta metav1.ObjectMeta) (string, string) {
+	service := ServiceOf(services, objectMeta.Labels[transformer.Selector], objectMeta.Name)
+	if service == "" {
+		return dir, objectMeta.Name
+	}
+	if objectMeta.Name == service {
+		return filepath.Join(dir, service), ""
+	}
+	return filepath.Join(dir, service), objectMeta.Name
+}
+
 // PrintList will take the data converted and decide on the commandline attributes given
 func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 	var f *os.File
+	objects = filterKinds(objects, opt)
 	dirName := getDirName(opt)
 	log.Debugf("Target Dir: %s", dirName)
 
@@ -218,6 +368,23 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 	// if asked to print to stdout or to put in single file
 	// we will create a list
 	if opt.ToStdout || f != nil {
+		if opt.ArgoCDApp != "" {
+			dir := "."
+			if f != nil {
+				dir = filepath.Dir(opt.OutFile)
+			}
+			application, err := InitArgoCDApplication(dir, opt)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, application)
+		}
+		if opt.Merge {
+			log.Warnf("--merge is only supported when writing the objects to a directory, ignoring it")
+		}
+		if opt.OutputLayout == OutputLayoutService {
+			log.Warnf("--output-layout=%s is only supported when writing the objects to a directory, ignoring it", OutputLayoutService)
+		}
 		// convert objects to versioned and add them to list
 		if opt.GenerateJSON {
 			return fmt.Errorf("cannot convert to one file while specifying a json output file or stdout option")
@@ -232,6 +399,11 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 			if err != nil {
 				return fmt.Errorf("error in marshalling the List: %v", err)
 			}
+			if opt.Reproducible {
+				if data, err = reproducibleData(data, opt.GenerateJSON, opt.YAMLIndent); err != nil {
+					return err
+				}
+			}
 			// this part add --- which unifies the file
 			data = []byte(fmt.Sprintf("---\n%s", data))
 			printVal, err := transformer.Print("", dirName, "", data, opt.ToStdout, opt.GenerateJSON, f, opt.Provider)
@@ -251,6 +423,14 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 		}
 
 		var file string
+		var chartServices map[string]bool
+		if opt.CreateChart {
+			chartServices = helmServices(objects)
+		}
+		var layoutServices []string
+		if opt.OutputLayout == OutputLayoutService {
+			layoutServices = workloadServices(objects)
+		}
 		// create a separate file for each provider
 		for _, v := range objects {
 			versionedObject, err := convertToVersion(v)
@@ -261,6 +441,11 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 			if err != nil {
 				return err
 			}
+			if opt.Reproducible {
+				if data, err = reproducibleData(data, opt.GenerateJSON, opt.YAMLIndent); err != nil {
+					return err
+				}
+			}
 
 			var typeMeta metav1.TypeMeta
 			var objectMeta metav1.ObjectMeta
@@ -271,7 +456,8 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 					APIVersion: us.GetAPIVersion(),
 				}
 				objectMeta = metav1.ObjectMeta{
-					Name: us.GetName(),
+					Name:   us.GetName(),
+					Labels: us.GetLabels(),
 				}
 			} else {
 				val := reflect.ValueOf(v).Elem()
@@ -284,7 +470,27 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 				objectMeta = val.FieldByName("ObjectMeta").Interface().(metav1.ObjectMeta)
 			}
 
-			file, err = transformer.Print(objectMeta.Name, finalDirName, strings.ToLower(typeMeta.Kind), data, opt.ToStdout, opt.GenerateJSON, f, opt.Provider)
+			fileDir, fileName := finalDirName, objectMeta.Name
+			if opt.OutputLayout == OutputLayoutService {
+				fileDir, fileName = serviceLayoutFile(finalDirName, layoutServices, objectMeta)
+				if err := os.MkdirAll(fileDir, 0755); err != nil {
+					return err
+				}
+			}
+
+			if opt.Merge {
+				file = filepath.Join(fileDir, transformer.FileName(fileName, strings.ToLower(typeMeta.Kind), opt.GenerateJSON))
+				data, err = mergeWithPrevious(dirName, file, data, opt)
+				if err != nil {
+					return err
+				}
+			}
+
+			if opt.CreateChart {
+				data = helmConditional(v, data, chartServices)
+			}
+
+			file, err = transformer.Print(fileName, fileDir, strings.ToLower(typeMeta.Kind), data, opt.ToStdout, opt.GenerateJSON, f, opt.Provider)
 			if err != nil {
 				return errors.Wrap(err, "transformer.Print failed")
 			}
@@ -293,14 +499,47 @@ func PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
 		}
 	}
 	if opt.CreateChart {
-		err = generateHelm(dirName)
+		err = generateHelm(dirName, objects)
 		if err != nil {
 			return errors.Wrap(err, "generateHelm failed")
 		}
+		if opt.PushChart != "" {
+			digest, err := pushHelmChart(dirName, opt.PushChart)
+			if err != nil {
+				return err
+			}
+			log.Infof("Chart pushed to %s with digest %s", opt.PushChart, digest)
+		}
+	}
+	if opt.Sign && !opt.ToStdout {
+		outFile := ""
+		if f != nil {
+			outFile = opt.OutFile
+		}
+		if err := signOutput(dirName, outFile, files, opt); err != nil {
+			return err
+		}
+	}
+	if opt.ArgoCDApp != "" && !opt.ToStdout && f == nil {
+		return printArgoCDApplication(dirName, opt)
 	}
 	return nil
 }
 
+// MarshalObjects returns the objects as a stream of YAML documents, each starting with ---
+func MarshalObjects(objects []runtime.Object, indent int) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, obj := range objects {
+		data, err := marshal(obj, false, indent)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("---\n")
+		buf.Write(transformer.StripStatus(data))
+	}
+	return buf.Bytes(), nil
+}
+
 // marshal object runtime.Object and return byte array
 func marshal(obj runtime.Object, jsonFormat bool, indent int) (data []byte, err error) {
 	// convert data to yaml or json
@@ -433,14 +672,47 @@ func (k *Kubernetes) initSvcObject(name string, service kobject.ServiceConfig, p
 
 	svc.Spec.Ports = ports
 	svc.Spec.Type = api.ServiceType(service.ServiceType)
+	svc.Spec.LoadBalancerIP = service.LoadBalancerIP
+	if service.LoadBalancerClass != "" {
+		svc.Spec.LoadBalancerClass = &service.LoadBalancerClass
+	}
+	configServiceTraffic(svc, service)
 
 	// Configure annotations
-	annotations := transformer.ConfigAnnotations(service)
-	svc.ObjectMeta.Annotations = annotations
+	svc.ObjectMeta.Annotations = serviceAnnotations(service)
 
 	return svc
 }
 
+// configServiceTraffic sets the traffic policies and the session affinity of the service, the
+// external traffic policy only applies to the services reachable from outside the cluster
+func configServiceTraffic(svc *api.Service, service kobject.ServiceConfig) {
+	if svc.Spec.Type == api.ServiceTypeLoadBalancer || svc.Spec.Type == api.ServiceTypeNodePort {
+		svc.Spec.ExternalTrafficPolicy = api.ServiceExternalTrafficPolicyType(service.ServiceExternalTrafficPolicy)
+	}
+	if service.ServiceInternalTrafficPolicy != "" {
+		policy := api.ServiceInternalTrafficPolicy(service.ServiceInternalTrafficPolicy)
+		svc.Spec.InternalTrafficPolicy = &policy
+	}
+	if service.SessionAffinity != "" {
+		svc.Spec.SessionAffinity = api.ServiceAffinity(service.SessionAffinity)
+	}
+	if service.SessionAffinityTimeout != 0 {
+		timeout := service.SessionAffinityTimeout
+		svc.Spec.SessionAffinityConfig = &api.SessionAffinityConfig{ClientIP: &api.ClientIPConfig{TimeoutSeconds: &timeout}}
+	}
+}
+
+// serviceAnnotations returns the annotations of the Service of the service, with the ones
+// of the kompose.service.annotation.* labels
+func serviceAnnotations(service kobject.ServiceConfig) map[string]string {
+	annotations := transformer.ConfigAnnotations(service)
+	for key, value := range service.ServiceAnnotations {
+		annotations[key] = value
+	}
+	return annotations
+}
+
 // CreateLBService creates a k8s Load Balancer Service
 func (k *Kubernetes) CreateLBService(name string, service kobject.ServiceConfig) []*api.Service {
 	var svcs []*api.Service
@@ -457,11 +729,14 @@ func (k *Kubernetes) CreateLBService(name string, service kobject.ServiceConfig)
 }
 
 // CreateService creates a k8s service
-func (k *Kubernetes) CreateService(name string, service kobject.ServiceConfig) *api.Service {
+func (k *Kubernetes) CreateService(name string, service kobject.ServiceConfig) (*api.Service, error) {
 	svc := k.InitSvc(name, service)
 
 	// Configure the service ports.
-	servicePorts := k.ConfigServicePorts(service)
+	servicePorts, err := k.ConfigServicePorts(service)
+	if err != nil {
+		return nil, err
+	}
 	svc.Spec.Ports = servicePorts
 
 	if service.ServiceType == "Headless" {
@@ -470,12 +745,12 @@ func (k *Kubernetes) CreateService(name string, service kobject.ServiceConfig) *
 	} else {
 		svc.Spec.Type = api.ServiceType(service.ServiceType)
 	}
+	configServiceTraffic(svc, service)
 
 	// Configure annotations
-	annotations := transformer.ConfigAnnotations(service)
-	svc.ObjectMeta.Annotations = annotations
+	svc.ObjectMeta.Annotations = serviceAnnotations(service)
 
-	return svc
+	return svc, nil
 }
 
 // CreateHeadlessService creates a k8s headless service.
@@ -497,12 +772,35 @@ func (k *Kubernetes) CreateHeadlessService(name string, service kobject.ServiceC
 	svc.Spec.ClusterIP = "None"
 
 	// Configure annotations
-	annotations := transformer.ConfigAnnotations(service)
-	svc.ObjectMeta.Annotations = annotations
+	svc.ObjectMeta.Annotations = serviceAnnotations(service)
 
 	return svc
 }
 
+// CreateAliasServices creates a Service per network alias of the service, selecting the same pods
+// with the same ports so that the containers resolving the alias reach them. The aliases of a
+// LoadBalancer or NodePort service are only reachable in the cluster, they are ClusterIP Services.
+func (k *Kubernetes) CreateAliasServices(name string, service kobject.ServiceConfig) ([]*api.Service, error) {
+	var svcs []*api.Service
+	for _, alias := range service.NetworkAliases {
+		aliased := service
+		aliased.Name = alias
+		if aliased.ServiceType != "Headless" {
+			aliased.ServiceType = string(api.ServiceTypeClusterIP)
+		}
+		if k.PortsExist(service) {
+			svc, err := k.CreateService(name, aliased)
+			if err != nil {
+				return nil, err
+			}
+			svcs = append(svcs, svc)
+		} else if service.ServiceType == "Headless" {
+			svcs = append(svcs, k.CreateHeadlessService(name, aliased))
+		}
+	}
+	return svcs, nil
+}
+
 // UpdateKubernetesObjectsMultipleContainers method updates the kubernetes objects with the necessary data
 func (k *Kubernetes) UpdateKubernetesObjectsMultipleContainers(name string, service kobject.ServiceConfig, objects *[]runtime.Object, podSpec PodSpec, opt kobject.ConvertOptions) error {
 	// Configure annotations
@@ -519,6 +817,12 @@ func (k *Kubernetes) UpdateKubernetesObjectsMultipleContainers(name string, serv
 			template.ObjectMeta.Labels = transformer.ConfigLabels(name)
 		}
 		template.Spec = podSpec.Get()
+		if antiAffinity := ConfigPodAntiAffinity(name, service, opt); antiAffinity != nil {
+			if template.Spec.Affinity == nil {
+				template.Spec.Affinity = &api.Affinity{}
+			}
+			template.Spec.Affinity.PodAntiAffinity = antiAffinity
+		}
 		return nil
 	}
 
@@ -533,14 +837,7 @@ func (k *Kubernetes) UpdateKubernetesObjectsMultipleContainers(name string, serv
 		if err != nil {
 			return errors.Wrap(err, "k.UpdateController failed")
 		}
-		if len(service.Volumes) > 0 {
-			switch objType := obj.(type) {
-			case *appsv1.Deployment:
-				objType.Spec.Strategy.Type = appsv1.RecreateDeploymentStrategyType
-			case *deployapi.DeploymentConfig:
-				objType.Spec.Strategy.Type = deployapi.DeploymentStrategyTypeRecreate
-			}
-		}
+		configUpdateStrategy(obj, service, *objects)
 	}
 	return nil
 }
@@ -564,6 +861,10 @@ func (k *Kubernetes) UpdateKubernetesObjects(name string, service kobject.Servic
 		volumes = append(volumes, TmpVolumes...)
 		volumesMount = append(volumesMount, TmpVolumesMount...)
 	}
+	// Configure the writable paths of the read only root filesystem
+	writableVolumesMount, writableVolumes := k.ConfigWritablePaths(name, service, volumesMount)
+	volumes = append(volumes, writableVolumes...)
+	volumesMount = append(volumesMount, writableVolumesMount...)
 
 	if pvc != nil && opt.Controller != StatefulStateController {
 		// Looping on the slice pvc instead of `*objects = append(*objects, pvc...)`
@@ -599,17 +900,35 @@ func (k *Kubernetes) UpdateKubernetesObjects(name string, service kobject.Servic
 		template.Spec.Containers[0].TTY = service.Tty
 		if opt.Controller != StatefulStateController || opt.Volumes == "configMap" {
 			template.Spec.Volumes = append(template.Spec.Volumes, volumes...)
+		} else {
+			// the claims are the volumeClaimTemplates of the StatefulSet
+			for _, volume := range volumes {
+				if volume.PersistentVolumeClaim == nil {
+					template.Spec.Volumes = append(template.Spec.Volumes, volume)
+				}
+			}
 		}
 		template.Spec.Affinity = ConfigAffinity(service)
+		if antiAffinity := ConfigPodAntiAffinity(name, service, opt); antiAffinity != nil {
+			if template.Spec.Affinity == nil {
+				template.Spec.Affinity = &api.Affinity{}
+			}
+			template.Spec.Affinity.PodAntiAffinity = antiAffinity
+		}
 		template.Spec.TopologySpreadConstraints = ConfigTopologySpreadConstraints(service)
 		// Configure the HealthCheck
-		template.Spec.Containers[0].LivenessProbe = configProbe(service.HealthChecks.Liveness)
-		template.Spec.Containers[0].ReadinessProbe = configProbe(service.HealthChecks.Readiness)
+		liveness, readiness, startup, err := configProbes(service)
+		if err != nil {
+			return err
+		}
+		template.Spec.Containers[0].LivenessProbe = liveness
+		template.Spec.Containers[0].ReadinessProbe = readiness
+		template.Spec.Containers[0].StartupProbe = startup
 
 		if service.StopGracePeriod != "" {
 			template.Spec.TerminationGracePeriodSeconds, err = DurationStrToSecondsInt(service.StopGracePeriod)
 			if err != nil {
-				log.Warningf("Failed to parse duration \"%v\" for service \"%v\"", service.StopGracePeriod, name)
+				dropped.Warnf("Ignoring the stop_grace_period %q of service %q, it isn't a duration", service.StopGracePeriod, name)
 			}
 		}
 
@@ -618,15 +937,6 @@ func (k *Kubernetes) UpdateKubernetesObjects(name string, service kobject.Servic
 		// Configure resource reservations
 		podSecurityContext := &api.PodSecurityContext{}
 
-		//set pid namespace mode
-		if service.Pid != "" {
-			if service.Pid == "host" {
-				// podSecurityContext.HostPID = true
-			} else {
-				log.Warningf("Ignoring PID key for service \"%v\". Invalid value \"%v\".", name, service.Pid)
-			}
-		}
-
 		//set supplementalGroups
 		if service.GroupAdd != nil {
 			podSecurityContext.SupplementalGroups = service.GroupAdd
@@ -637,6 +947,8 @@ func (k *Kubernetes) UpdateKubernetesObjects(name string, service kobject.Servic
 			podSecurityContext.FSGroup = &service.FsGroup
 		}
 
+		podSecurityContext.Sysctls = ConfigSysctls(name, service, opt.AllowUnsafeSysctls)
+
 		// Setup security context
 		securityContext := &api.SecurityContext{}
 		if service.Privileged {
@@ -645,25 +957,25 @@ func (k *Kubernetes) UpdateKubernetesObjects(name string, service kobject.Servic
 		if service.User != "" {
 			switch userparts := strings.Split(service.User, ":"); len(userparts) {
 			default:
-				log.Warn("Ignoring ill-formed user directive. Must be in format UID or UID:GID.")
+				dropped.Warnf("Ignoring ill-formed user directive. Must be in format UID or UID:GID.")
 			case 1:
 				uid, err := strconv.ParseInt(userparts[0], 10, 64)
 				if err != nil {
-					log.Warn("Ignoring user directive. User to be specified as a UID (numeric).")
+					dropped.Warnf("Ignoring user directive. User to be specified as a UID (numeric).")
 				} else {
 					securityContext.RunAsUser = &uid
 				}
 			case 2:
 				uid, err := strconv.ParseInt(userparts[0], 10, 64)
 				if err != nil {
-					log.Warn("Ignoring user name in user directive. User to be specified as a UID (numeric).")
+					dropped.Warnf("Ignoring user name in user directive. User to be specified as a UID (numeric).")
 				} else {
 					securityContext.RunAsUser = &uid
 				}
 
 				gid, err := strconv.ParseInt(userparts[1], 10, 64)
 				if err != nil {
-					log.Warn("Ignoring group name in user directive. Group to be specified as a GID (numeric).")
+					dropped.Warnf("Ignoring group name in user directive. Group to be specified as a GID (numeric).")
 				} else {
 					securityContext.RunAsGroup = &gid
 				}
@@ -708,8 +1020,15 @@ func (k *Kubernetes) UpdateKubernetesObjects(name string, service kobject.Servic
 		if err != nil {
 			return err
 		}
+		// a job podFailurePolicy can only be used with restartPolicy Never
+		if service.JobPodFailurePolicy != nil && restart == api.RestartPolicyOnFailure {
+			log.Warnf("Service %q has a job pod failure policy, its restart policy %q is converted to %q", name, restart, api.RestartPolicyNever)
+			restart = api.RestartPolicyNever
+		}
 		template.Spec.RestartPolicy = restart
 
+		template.Spec.EnableServiceLinks = GetEnableServiceLinks(service, opt)
+
 		// Configure hostname/domain_name settings
 		if service.HostName != "" {
 			template.Spec.Hostname = service.HostName
@@ -717,11 +1036,26 @@ func (k *Kubernetes) UpdateKubernetesObjects(name string, service kobject.Servic
 		if service.DomainName != "" {
 			template.Spec.Subdomain = service.DomainName
 		}
+		template.Spec.HostAliases = ConfigHostAliases(name, service)
+		template.Spec.DNSConfig, template.Spec.DNSPolicy = ConfigDNS(service)
+		template.Spec.HostPID, template.Spec.HostIPC, template.Spec.HostNetwork = ConfigHostNamespaces(name, service, opt.AllowHostNamespaces)
+		// keep resolving the cluster services from the host network
+		if template.Spec.HostNetwork && template.Spec.DNSPolicy == "" {
+			template.Spec.DNSPolicy = api.DNSClusterFirstWithHostNet
+		}
 
 		if serviceAccountName, ok := service.Labels[compose.LabelServiceAccountName]; ok {
 			template.Spec.ServiceAccountName = serviceAccountName
 		}
+		if priorityClassName, ok := service.Labels[compose.LabelPriorityClass]; ok {
+			template.Spec.PriorityClassName = priorityClassName
+		}
+		if service.RuntimeClassName != "" {
+			runtimeClassName := service.RuntimeClassName
+			template.Spec.RuntimeClassName = &runtimeClassName
+		}
 		fillInitContainers(template, service)
+		fillSidecars(template, service, opt)
 		return nil
 	}
 
@@ -736,12 +1070,9 @@ func (k *Kubernetes) UpdateKubernetesObjects(name string, service kobject.Servic
 		if err != nil {
 			return errors.Wrap(err, "k.UpdateController failed")
 		}
+		configUpdateStrategy(obj, service, *objects)
 		if len(service.Volumes) > 0 {
 			switch objType := obj.(type) {
-			case *appsv1.Deployment:
-				objType.Spec.Strategy.Type = appsv1.RecreateDeploymentStrategyType
-			case *deployapi.DeploymentConfig:
-				objType.Spec.Strategy.Type = deployapi.DeploymentStrategyTypeRecreate
 			case *appsv1.StatefulSet:
 				// embed all PVCs inside the StatefulSet object
 				if opt.Volumes == "configMap" {
@@ -760,6 +1091,60 @@ func (k *Kubernetes) UpdateKubernetesObjects(name string, service kobject.Servic
 	return nil
 }
 
+// configUpdateStrategy sets the Recreate strategy on the Deployment or the DeploymentConfig of the
+// service when its new pods can't run along the old ones during a rolling update: with the
+// kompose.deployment.strategy label, or when they mount a claim only one node mounts read-write or
+// a host path. The rolling update of update_config wins over the Recreate strategy of the volumes.
+func configUpdateStrategy(obj runtime.Object, service kobject.ServiceConfig, objects []runtime.Object) {
+	recreate := func(spec api.PodSpec, rollingUpdate bool) bool {
+		switch service.DeploymentStrategy {
+		case "recreate":
+			return true
+		case "rollingupdate":
+			return false
+		}
+		return !rollingUpdate && hasExclusiveVolume(spec, objects)
+	}
+
+	switch objType := obj.(type) {
+	case *appsv1.Deployment:
+		if recreate(objType.Spec.Template.Spec, objType.Spec.Strategy.RollingUpdate != nil) {
+			objType.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+		}
+	case *deployapi.DeploymentConfig:
+		if objType.Spec.Template != nil && recreate(objType.Spec.Template.Spec, objType.Spec.Strategy.RollingParams != nil) {
+			objType.Spec.Strategy.Type = deployapi.DeploymentStrategyTypeRecreate
+			objType.Spec.Strategy.RollingParams = nil
+		}
+	}
+}
+
+// hasExclusiveVolume tells whether the pods mount a volume the pods of a rolling update can't share:
+// a host path, or a claim without the ReadWriteMany or ReadOnlyMany access mode. The claims are looked
+// up in the objects, the claims of the other services (volumes_from) are assumed to be exclusive.
+func hasExclusiveVolume(spec api.PodSpec, objects []runtime.Object) bool {
+	for _, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			return true
+		}
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		shared := false
+		for _, obj := range objects {
+			if pvc, ok := obj.(*api.PersistentVolumeClaim); ok && pvc.Name == volume.PersistentVolumeClaim.ClaimName {
+				for _, mode := range pvc.Spec.AccessModes {
+					shared = shared || mode == api.ReadWriteMany || mode == api.ReadOnlyMany
+				}
+			}
+		}
+		if !shared {
+			return true
+		}
+	}
+	return false
+}
+
 // getServiceVolumesID create a unique id for the service's volume mounts
 func getServiceVolumesID(service kobject.ServiceConfig) string {
 	id := ""
@@ -789,7 +1174,8 @@ func getServiceGroupID(service kobject.ServiceConfig, mode string) string {
 //     create one for this group.
 //  2. If service containers restart policy and no workload argument provide and it's restart policy looks like a pod, then
 //     this service should generate a pod. If group mode specified, it should be grouped and ignore the restart policy.
-//  3. If group mode specified, port conflict between services in one group will be ignored, and multiple service should be created.
+//  3. If group mode specified, the services of a group can't use the same container port (see CheckGroupPortConflicts),
+//     and a Service is created per service of the group.
 //  4. If `volume` group mode specified, we don't have an appropriate name for this combined service, use the first one for now.
 //     A warn/info message should be printed to let the user know.
 func KomposeObjectToServiceConfigGroupMapping(komposeObject *kobject.KomposeObject, opt kobject.ConvertOptions) map[string]kobject.ServiceConfigGroup {
@@ -812,49 +1198,73 @@ func KomposeObjectToServiceConfigGroupMapping(komposeObject *kobject.KomposeObje
 
 // TranslatePodResource config pod resources
 func TranslatePodResource(service *kobject.ServiceConfig, template *api.PodTemplateSpec) {
-	// Configure the resource limits
-	if service.MemLimit != 0 || service.CPULimit != 0 || service.DeployLabels["kompose.ephemeral-storage.limit"] != "" {
-		resourceLimit := api.ResourceList{}
+	if limits := resourceLimits(*service); limits != nil {
+		template.Spec.Containers[0].Resources.Limits = limits
+	}
+	if requests := resourceRequests(*service); requests != nil {
+		template.Spec.Containers[0].Resources.Requests = requests
+	}
+}
 
-		if service.MemLimit != 0 {
-			resourceLimit[api.ResourceMemory] = *resource.NewQuantity(int64(service.MemLimit), "RandomStringForFormat")
-		}
+// resourceLimits returns the resource limits of the container of the service, nil without limits
+func resourceLimits(service kobject.ServiceConfig) api.ResourceList {
+	if service.MemLimit == 0 && service.CPULimit == 0 && service.GPUs == 0 && service.DeployLabels["kompose.ephemeral-storage.limit"] == "" {
+		return nil
+	}
+	resourceLimit := api.ResourceList{}
 
-		if service.CPULimit != 0 {
-			resourceLimit[api.ResourceCPU] = *resource.NewMilliQuantity(service.CPULimit, resource.DecimalSI)
-		}
+	if service.MemLimit != 0 {
+		resourceLimit[api.ResourceMemory] = *resource.NewQuantity(int64(service.MemLimit), "RandomStringForFormat")
+	}
 
-		// Check for ephemeral-storage in deploy labels
-		if val, ok := service.DeployLabels["kompose.ephemeral-storage.limit"]; ok {
-			if quantity, err := resource.ParseQuantity(val); err == nil {
-				resourceLimit[api.ResourceEphemeralStorage] = quantity
-			}
+	if service.CPULimit != 0 {
+		resourceLimit[api.ResourceCPU] = *resource.NewMilliQuantity(service.CPULimit, resource.DecimalSI)
+	}
+
+	// Check for ephemeral-storage in deploy labels
+	if val, ok := service.DeployLabels["kompose.ephemeral-storage.limit"]; ok {
+		if quantity, err := resource.ParseQuantity(val); err == nil {
+			resourceLimit[api.ResourceEphemeralStorage] = quantity
 		}
+	}
 
-		template.Spec.Containers[0].Resources.Limits = resourceLimit
+	// extended resources can't be overcommitted, GPUs are only set as limits
+	if service.GPUs != 0 {
+		resourceLimit[gpuResourceName(service)] = *resource.NewQuantity(service.GPUs, resource.DecimalSI)
 	}
+	return resourceLimit
+}
 
-	// Configure the resource requests
-	if service.MemReservation != 0 || service.CPUReservation != 0 || service.DeployLabels["kompose.ephemeral-storage.request"] != "" {
-		resourceRequests := api.ResourceList{}
+// resourceRequests returns the resource requests of the container of the service, nil without requests
+func resourceRequests(service kobject.ServiceConfig) api.ResourceList {
+	if service.MemReservation == 0 && service.CPUReservation == 0 && service.DeployLabels["kompose.ephemeral-storage.request"] == "" {
+		return nil
+	}
+	resourceRequests := api.ResourceList{}
 
-		if service.MemReservation != 0 {
-			resourceRequests[api.ResourceMemory] = *resource.NewQuantity(int64(service.MemReservation), "RandomStringForFormat")
-		}
+	if service.MemReservation != 0 {
+		resourceRequests[api.ResourceMemory] = *resource.NewQuantity(int64(service.MemReservation), "RandomStringForFormat")
+	}
 
-		if service.CPUReservation != 0 {
-			resourceRequests[api.ResourceCPU] = *resource.NewMilliQuantity(service.CPUReservation, resource.DecimalSI)
-		}
+	if service.CPUReservation != 0 {
+		resourceRequests[api.ResourceCPU] = *resource.NewMilliQuantity(service.CPUReservation, resource.DecimalSI)
+	}
 
-		// Check for ephemeral-storage in deploy labels
-		if val, ok := service.DeployLabels["kompose.ephemeral-storage.request"]; ok {
-			if quantity, err := resource.ParseQuantity(val); err == nil {
-				resourceRequests[api.ResourceEphemeralStorage] = quantity
-			}
+	// Check for ephemeral-storage in deploy labels
+	if val, ok := service.DeployLabels["kompose.ephemeral-storage.request"]; ok {
+		if quantity, err := resource.ParseQuantity(val); err == nil {
+			resourceRequests[api.ResourceEphemeralStorage] = quantity
 		}
+	}
+	return resourceRequests
+}
 
-		template.Spec.Containers[0].Resources.Requests = resourceRequests
+// gpuResourceName returns the extended resource the GPUs of the service are requested with
+func gpuResourceName(service kobject.ServiceConfig) api.ResourceName {
+	if service.GPUResource != "" {
+		return api.ResourceName(service.GPUResource)
 	}
+	return DefaultGPUResource
 }
 
 // GetImagePullPolicy get image pull settings
@@ -873,6 +1283,19 @@ func GetImagePullPolicy(name, policy string) (api.PullPolicy, error) {
 	return "", nil
 }
 
+// GetEnableServiceLinks returns the enableServiceLinks value of the pod spec,
+// the kompose.enable-service-links label wins over --disable-service-links
+func GetEnableServiceLinks(service kobject.ServiceConfig, opt kobject.ConvertOptions) *bool {
+	if service.EnableServiceLinks != nil {
+		return service.EnableServiceLinks
+	}
+	if opt.DisableServiceLinks {
+		enableServiceLinks := false
+		return &enableServiceLinks
+	}
+	return nil
+}
+
 // GetRestartPolicy ...
 func GetRestartPolicy(name, restart string) (api.RestartPolicy, error) {
 	switch restart {
@@ -887,22 +1310,81 @@ func GetRestartPolicy(name, restart string) (api.RestartPolicy, error) {
 	}
 }
 
-// SortServicesFirst - the objects that we get can be in any order this keeps services first
-// according to best practice kubernetes services should be created first
-// http://kubernetes.io/docs/user-guide/config-best-practices/
-func (k *Kubernetes) SortServicesFirst(objs *[]runtime.Object) {
-	var svc, others, ret []runtime.Object
+// objectOrder is the order the objects are written and applied in: the namespace, the accounts and the
+// priority classes, the configuration, the storage, the Services, then the workloads using all of them,
+// and last the objects scaling or routing to the workloads
+var objectOrder = []string{
+	"Namespace",
+	"PriorityClass",
+	"ServiceAccount",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"ConfigMap",
+	"Secret",
+	"SealedSecret",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"Service",
+	"ImageStream",
+	"BuildConfig",
+	"Deployment",
+	"DaemonSet",
+	"StatefulSet",
+	"ReplicationController",
+	"DeploymentConfig",
+	"Job",
+	"CronJob",
+	"Pod",
+	"HorizontalPodAutoscaler",
+	"PodDisruptionBudget",
+	"NetworkPolicy",
+	"Gateway",
+	"HTTPRoute",
+	"Ingress",
+	"IngressRoute",
+	"Route",
+}
 
-	for _, obj := range *objs {
-		if obj.GetObjectKind().GroupVersionKind().Kind == "Service" {
-			svc = append(svc, obj)
-		} else {
-			others = append(others, obj)
+// SortObjects sorts the objects by kind in the order of objectOrder, the other kinds last in the
+// alphabetical order, then by namespace and name, so that the output doesn't change between runs and
+// the objects are applied after the ones they use.
+// http://kubernetes.io/docs/user-guide/config-best-practices/
+func (k *Kubernetes) SortObjects(objs *[]runtime.Object) {
+	rank := make(map[string]int, len(objectOrder))
+	for i, kind := range objectOrder {
+		rank[kind] = i
+	}
+	kindRank := func(kind string) int {
+		if r, ok := rank[kind]; ok {
+			return r
+		}
+		return len(objectOrder)
+	}
+	key := func(obj runtime.Object) (string, string) {
+		if accessor, err := meta.Accessor(obj); err == nil {
+			return accessor.GetNamespace(), accessor.GetName()
 		}
+		return "", ""
 	}
-	ret = append(ret, svc...)
-	ret = append(ret, others...)
-	*objs = ret
+
+	sort.SliceStable(*objs, func(i, j int) bool {
+		a, b := (*objs)[i], (*objs)[j]
+		kindA, kindB := a.GetObjectKind().GroupVersionKind().Kind, b.GetObjectKind().GroupVersionKind().Kind
+		if rankA, rankB := kindRank(kindA), kindRank(kindB); rankA != rankB {
+			return rankA < rankB
+		}
+		if kindA != kindB {
+			return kindA < kindB
+		}
+		namespaceA, nameA := key(a)
+		namespaceB, nameB := key(b)
+		if namespaceA != namespaceB {
+			return namespaceA < namespaceB
+		}
+		return nameA < nameB
+	})
 }
 
 // RemoveDupObjects remove objects that are dups...eg. configmaps from env.
@@ -978,28 +1460,89 @@ func GetContentFromFile(file string) (string, error) {
 
 // FormatEnvName format env name
 func FormatEnvName(name string, serviceName string) string {
-	envName := strings.Trim(name, "./")
+	return transformer.TruncateName(getUsableNameEnvFile(name, serviceName), transformer.MaxNameLength)
+}
 
-	// replace all non-alphanumerical characters with dashes to have a unique envName (env filename could be used multiple times)
-	envName = regexp.MustCompile(`[^a-zA-Z0-9]`).ReplaceAllString(envName, "-")
-	envName = getUsableNameEnvFile(envName, serviceName)
-	return envName
+// EnvConfigMapName returns the name of the ConfigMap of an env file of a service. With --env-configmap-hash,
+// the name is suffixed with a hash of the env file path and of its data, so that the names stay unique
+// after the truncation and change with the content of the file.
+func EnvConfigMapName(envFile string, serviceName string, data map[string]string, opt kobject.ConvertOptions) string {
+	if !opt.EnvConfigMapHash {
+		return FormatEnvName(envFile, serviceName)
+	}
+	suffix := "-" + envFileHash(envFile, data)
+	envName := getUsableNameEnvFile(envFile, serviceName)
+	// leave room for the -N suffix of the parts of an oversized env file
+	if max := 63 - len(suffix) - 3; len(envName) > max {
+		envName = envName[0:max]
+	}
+	return strings.TrimRight(envName, "-") + suffix
+}
+
+// envFileHash returns the first 10 hexadecimal characters of the SHA-256 of the env file path and of its sorted data
+func envFileHash(envFile string, data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	h.Write([]byte(envFile))
+	for _, key := range keys {
+		h.Write([]byte{0})
+		h.Write([]byte(key + "=" + data[key]))
+	}
+	return hex.EncodeToString(h.Sum(nil))[0:10]
 }
 
 // getUsableNameEnvFile checks and adjusts the environment file name to make it usable.
-// If the first character of envName is a hyphen "-", it is concatenated with nameService.
-// If the length of envName is greater than 63, it is truncated to 63 characters.
-// Returns the adjusted environment file name.
-func getUsableNameEnvFile(envName string, serviceName string) string {
+// All non-alphanumerical characters are replaced with dashes, and if the first character
+// of envName is a hyphen "-", it is concatenated with nameService.
+// Returns the adjusted environment file name, before any truncation.
+func getUsableNameEnvFile(name string, serviceName string) string {
+	envName := strings.Trim(name, "./")
+
+	// replace all non-alphanumerical characters with dashes to have a unique envName (env filename could be used multiple times)
+	envName = regexp.MustCompile(`[^a-zA-Z0-9]`).ReplaceAllString(envName, "-")
 	if string(envName[0]) == "-" { // -env-local....
 		envName = fmt.Sprintf("%s%s", serviceName, envName)
 	}
-	if len(envName) > 63 {
-		envName = envName[0:63]
-	}
 	return envName
 }
 
+// GetRenames returns the resources whose name differs from the one used in the compose file,
+// either because the service name was sanitized or because an env_file name was truncated.
+// An error is returned when two env files end up with the same ConfigMap name. The ConfigMaps
+// named after the hash of their env file with --env-configmap-hash are unique and not reported.
+func GetRenames(komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) ([]kobject.Rename, error) {
+	var renames []kobject.Rename
+	envNames := make(map[string]string)
+	for _, name := range SortedKeys(komposeObject.ServiceConfigs) {
+		service := komposeObject.ServiceConfigs[name]
+		if service.OriginalName != "" {
+			renames = append(renames, kobject.Rename{Kind: "Service", Original: service.OriginalName, Name: name})
+		}
+		if opt.EnvConfigMapHash {
+			continue
+		}
+		for _, envFile := range service.EnvFile {
+			envName := FormatEnvName(envFile, name)
+			if other, ok := envNames[envName]; ok {
+				if other != envFile {
+					return nil, fmt.Errorf("env files %q and %q are both converted to ConfigMap %q", other, envFile, envName)
+				}
+				continue
+			}
+			envNames[envName] = envFile
+			if envName != getUsableNameEnvFile(envFile, name) {
+				renames = append(renames, kobject.Rename{Kind: "ConfigMap", Original: envFile, Name: envName})
+			}
+		}
+	}
+	return renames, nil
+}
+
 // FormatFileName format file name
 func FormatFileName(name string) string {
 	// Split the filepath name so that we use the
@@ -1023,7 +1566,7 @@ func GetContainerName(service kobject.ServiceConfig) string {
 	if len(service.ContainerName) > 0 {
 		name = service.ContainerName
 	}
-	return FormatContainerName(name)
+	return transformer.TruncateName(FormatContainerName(name), transformer.MaxNameLength)
 }
 
 // FormatResourceName generate a valid k8s resource name
@@ -1071,21 +1614,98 @@ func reformatSecretConfigUnderscoreWithDash(secretConfig types.ServiceSecretConf
 // fillInitContainers looks for an initContainer resources and its passed as labels
 // if there is no image, it does not fill the initContainer
 // https://kubernetes.io/docs/concepts/workloads/pods/init-containers/
+// The init containers of the kompose.init.containers.<name>.* labels run after it, in the order of their names.
 func fillInitContainers(template *api.PodTemplateSpec, service kobject.ServiceConfig) {
-	resourceImage, exist := service.Labels[compose.LabelInitContainerImage]
-	if !exist || resourceImage == "" {
-		return
+	if resourceImage := service.Labels[compose.LabelInitContainerImage]; resourceImage != "" {
+		resourceName, exist := service.Labels[compose.LabelInitContainerName]
+		if !exist || resourceName == "" {
+			resourceName = "init-service"
+		}
+
+		template.Spec.InitContainers = append(template.Spec.InitContainers, api.Container{
+			Name:    resourceName,
+			Command: parseContainerCommandsFromStr(service.Labels[compose.LabelInitContainerCommand]),
+			Image:   resourceImage,
+		})
 	}
-	resourceName, exist := service.Labels[compose.LabelInitContainerName]
-	if !exist || resourceName == "" {
-		resourceName = "init-service"
+
+	for _, initContainer := range service.InitContainers {
+		if hasContainer(template, initContainer.Name) {
+			dropped.Warnf("Ignoring the init container %q of service %q, a container of the pod has the same name", initContainer.Name, service.Name)
+			continue
+		}
+		container := api.Container{
+			Name:         initContainer.Name,
+			Image:        initContainer.Image,
+			Command:      initContainer.Command,
+			VolumeMounts: sharedVolumeMounts(template, service, initContainer.Name, initContainer.Mounts),
+		}
+		for _, env := range initContainer.Env {
+			container.Env = append(container.Env, api.EnvVar{Name: env.Name, Value: env.Value})
+		}
+		template.Spec.InitContainers = append(template.Spec.InitContainers, container)
 	}
+}
 
-	template.Spec.InitContainers = append(template.Spec.InitContainers, api.Container{
-		Name:    resourceName,
-		Command: parseContainerCommandsFromStr(service.Labels[compose.LabelInitContainerCommand]),
-		Image:   resourceImage,
-	})
+// fillSidecars adds the sidecars of the service to the pod. Their mounts share the volumes mounted
+// in the container of the service, the first container of the pod.
+func fillSidecars(template *api.PodTemplateSpec, service kobject.ServiceConfig, opt kobject.ConvertOptions) {
+	for _, sidecar := range service.Sidecars {
+		if hasContainer(template, sidecar.Name) {
+			dropped.Warnf("Ignoring the sidecar %q of service %q, a container of the pod has the same name", sidecar.Name, service.Name)
+			continue
+		}
+		container := api.Container{
+			Name:    sidecar.Name,
+			Image:   sidecar.Image,
+			Command: sidecar.Command,
+		}
+		for _, port := range sidecar.Ports {
+			container.Ports = append(container.Ports, api.ContainerPort{ContainerPort: port.ContainerPort, Protocol: api.Protocol(port.Protocol)})
+		}
+		container.VolumeMounts = sharedVolumeMounts(template, service, sidecar.Name, sidecar.Mounts)
+		addSidecar(&template.Spec, container, opt)
+	}
+}
+
+// sharedVolumeMounts returns the mounts of the volumes of the container of the service, the first
+// container of the pod, shared with another container of the pod
+func sharedVolumeMounts(template *api.PodTemplateSpec, service kobject.ServiceConfig, containerName string, mounts []kobject.SidecarMount) []api.VolumeMount {
+	var volumeMounts []api.VolumeMount
+	for _, mount := range mounts {
+		volumeMount, ok := findVolumeMount(template.Spec.Containers[0].VolumeMounts, mount.Path)
+		if !ok {
+			dropped.Warnf("Ignoring the mount %s of the container %q of service %q, no volume is mounted there", mount.Path, containerName, service.Name)
+			continue
+		}
+		volumeMount.MountPath = mount.MountPath
+		volumeMount.ReadOnly = volumeMount.ReadOnly || mount.ReadOnly
+		volumeMounts = append(volumeMounts, volumeMount)
+	}
+	return volumeMounts
+}
+
+// addSidecar appends the sidecar to the containers of the pod, or with --native-sidecars to its init
+// containers as a native sidecar, restarted until the containers stop: it starts before them and
+// stops after them
+func addSidecar(spec *api.PodSpec, sidecar api.Container, opt kobject.ConvertOptions) {
+	if !opt.NativeSidecars {
+		spec.Containers = append(spec.Containers, sidecar)
+		return
+	}
+	always := api.ContainerRestartPolicyAlways
+	sidecar.RestartPolicy = &always
+	spec.InitContainers = append(spec.InitContainers, sidecar)
+}
+
+// findVolumeMount returns the volume mount of the mount path
+func findVolumeMount(volumeMounts []api.VolumeMount, mountPath string) (api.VolumeMount, bool) {
+	for _, volumeMount := range volumeMounts {
+		if volumeMount.MountPath == mountPath {
+			return volumeMount, true
+		}
+	}
+	return api.VolumeMount{}, false
 }
 
 // parseContainerCommandsFromStr parses a string containing comma-separated commands
@@ -1129,7 +1749,7 @@ func searchHPAValues(labels map[string]string) bool {
 // the number of replicas will be managed by the HPA
 func createHPAResources(name string, service *kobject.ServiceConfig) hpa.HorizontalPodAutoscaler {
 	valuesHpa := getResourceHpaValues(service)
-	service.Replicas = 0
+	service.Replicas = nil
 	metrics := getHpaMetricSpec(valuesHpa)
 	scalerSpecs := hpa.HorizontalPodAutoscaler{
 		TypeMeta: metav1.TypeMeta{
@@ -1154,6 +1774,20 @@ func createHPAResources(name string, service *kobject.ServiceConfig) hpa.Horizon
 	return scalerSpecs
 }
 
+// hpaV2beta2 converts the HorizontalPodAutoscaler to autoscaling/v2beta2, which has the fields of autoscaling/v2
+func hpaV2beta2(autoscaler hpa.HorizontalPodAutoscaler) (*hpav2beta2.HorizontalPodAutoscaler, error) {
+	data, err := json.Marshal(autoscaler)
+	if err != nil {
+		return nil, errors.Wrap(err, "json.Marshal failed")
+	}
+	legacy := &hpav2beta2.HorizontalPodAutoscaler{}
+	if err := json.Unmarshal(data, legacy); err != nil {
+		return nil, errors.Wrap(err, "json.Unmarshal failed")
+	}
+	legacy.APIVersion = "autoscaling/v2beta2"
+	return legacy, nil
+}
+
 // getResourceHpaValues retrieves the min/max replicas and CPU/memory utilization values
 // control if maxReplicas is less than minReplicas
 func getResourceHpaValues(service *kobject.ServiceConfig) HpaValues {
@@ -1294,14 +1928,14 @@ func checkIsEmptyDir(filePath string) (bool, error) {
 // ReadWriteOnce RWO can be mounted in read/write mode to exactly 1 host
 // https://kubernetes.io/docs/concepts/storage/persistent-volumes/#access-modes
 func setVolumeAccessMode(mode string, volumeAccesMode []api.PersistentVolumeAccessMode) []api.PersistentVolumeAccessMode {
-	switch mode {
-	case "ro", "rox":
+	switch strings.ToLower(mode) {
+	case "ro", "rox", "readonlymany":
 		volumeAccesMode = []api.PersistentVolumeAccessMode{api.ReadOnlyMany}
-	case "rwx":
+	case "rwx", "readwritemany":
 		volumeAccesMode = []api.PersistentVolumeAccessMode{api.ReadWriteMany}
-	case "rwop":
+	case "rwop", "readwriteoncepod":
 		volumeAccesMode = []api.PersistentVolumeAccessMode{api.ReadWriteOncePod}
-	case "rwo":
+	case "rwo", "readwriteonce":
 		volumeAccesMode = []api.PersistentVolumeAccessMode{api.ReadWriteOnce}
 	default:
 		volumeAccesMode = []api.PersistentVolumeAccessMode{api.ReadWriteOnce}
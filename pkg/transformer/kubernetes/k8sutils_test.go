@@ -21,20 +21,24 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/loader/compose"
 	"github.com/kubernetes/kompose/pkg/testutils"
+	"github.com/kubernetes/kompose/pkg/transformer"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
-	hpa "k8s.io/api/autoscaling/v2beta2"
+	hpa "k8s.io/api/autoscaling/v2"
 	api "k8s.io/api/core/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -74,7 +78,10 @@ func TestCreateService(t *testing.T) {
 	}
 
 	// Test the creation of the service
-	svc := k.CreateService("foo", service)
+	svc, err := k.CreateService("foo", service)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	if svc.Spec.Ports[0].Port != 123 {
 		t.Errorf("Expected port 123 upon conversion, actual %d", svc.Spec.Ports[0].Port)
@@ -640,6 +647,38 @@ func TestServiceWithHealthCheck(t *testing.T) {
 	}
 }
 
+func TestConfigProbeHTTPHeadersAndScheme(t *testing.T) {
+	probe, err := configProbe(kobject.HealthCheck{
+		HTTPPath:    "/health",
+		HTTPPort:    8443,
+		HTTPScheme:  "HTTPS",
+		HTTPHeaders: map[string]string{"X-Probe": "liveness", "Host": "example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if probe == nil || probe.HTTPGet == nil {
+		t.Fatalf("Expected an HTTP probe, got %+v", probe)
+	}
+	if probe.HTTPGet.Scheme != api.URISchemeHTTPS {
+		t.Errorf("Expected the HTTPS scheme, got %q", probe.HTTPGet.Scheme)
+	}
+	expected := []api.HTTPHeader{{Name: "Host", Value: "example.com"}, {Name: "X-Probe", Value: "liveness"}}
+	if !reflect.DeepEqual(probe.HTTPGet.HTTPHeaders, expected) {
+		t.Errorf("Expected the headers %v sorted by name, got %v", expected, probe.HTTPGet.HTTPHeaders)
+	}
+}
+
+func TestConfigProbeWithoutHandler(t *testing.T) {
+	if _, err := configProbe(kobject.HealthCheck{Retries: 3, Interval: 10}); err == nil {
+		t.Errorf("Expected an error for a health check without command, HTTP or TCP handler")
+	}
+	service := kobject.ServiceConfig{Name: "web", HealthChecks: kobject.HealthChecks{Startup: kobject.HealthCheck{Retries: 3}}}
+	if err := new(PodSpec).Append(AddContainer(service, kobject.ConvertOptions{})).Err(); err == nil {
+		t.Errorf("Expected the container to fail on the startup probe without handler")
+	}
+}
+
 // TestServiceWithoutPort this tests if Headless Service is created for services without Port.
 func TestServiceWithoutPort(t *testing.T) {
 	service := kobject.ServiceConfig{
@@ -692,6 +731,51 @@ func TestRecreateStrategyWithVolumesPresent(t *testing.T) {
 	}
 }
 
+func TestDeploymentStrategy(t *testing.T) {
+	claim := kobject.Volumes{SvcName: "app", MountPath: "/tmp/volume", PVCName: "app-claim0"}
+	shared := claim
+	shared.AccessMode = "rwx"
+
+	testCases := map[string]struct {
+		strategy string
+		volumes  []kobject.Volumes
+		opt      kobject.ConvertOptions
+		expected appsv1.DeploymentStrategyType
+	}{
+		"Recreate with a ReadWriteOnce claim":       {"", []kobject.Volumes{claim}, kobject.ConvertOptions{}, appsv1.RecreateDeploymentStrategyType},
+		"Rolling update with a ReadWriteMany claim": {"", []kobject.Volumes{shared}, kobject.ConvertOptions{}, ""},
+		"Rolling update with emptyDir volumes":      {"", []kobject.Volumes{claim}, kobject.ConvertOptions{Volumes: "emptyDir"}, ""},
+		"Recreate with a host path":                 {"", []kobject.Volumes{{SvcName: "app", MountPath: "/tmp/volume", PVCName: "app-claim0", Host: "/data"}}, kobject.ConvertOptions{Volumes: "hostPath", InputFiles: []string{"compose.yaml"}}, appsv1.RecreateDeploymentStrategyType},
+		"Recreate with the label":                   {"recreate", nil, kobject.ConvertOptions{}, appsv1.RecreateDeploymentStrategyType},
+		"Rolling update with the label":             {"rollingupdate", []kobject.Volumes{claim}, kobject.ConvertOptions{}, ""},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			service := kobject.ServiceConfig{
+				ContainerName:      "name",
+				Image:              "image",
+				Volumes:            test.volumes,
+				DeploymentStrategy: test.strategy,
+			}
+			komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"app": service}}
+			test.opt.CreateD = true
+			test.opt.Replicas = 1
+			k := Kubernetes{Opt: test.opt}
+
+			objects, err := k.Transform(komposeObject, test.opt)
+			if err != nil {
+				t.Fatalf("k.Transform failed: %v", err)
+			}
+			for _, obj := range objects {
+				if deployment, ok := obj.(*appsv1.Deployment); ok && deployment.Spec.Strategy.Type != test.expected {
+					t.Errorf("Expected the strategy %q, got %q", test.expected, deployment.Spec.Strategy.Type)
+				}
+			}
+		})
+	}
+}
+
 func TestSortedKeys(t *testing.T) {
 	service := kobject.ServiceConfig{
 		ContainerName: "name",
@@ -712,6 +796,39 @@ func TestSortedKeys(t *testing.T) {
 	}
 }
 
+func Test_filterKinds(t *testing.T) {
+	objects := []runtime.Object{
+		&api.Service{TypeMeta: metav1.TypeMeta{Kind: "Service"}},
+		&appsv1.Deployment{TypeMeta: metav1.TypeMeta{Kind: "Deployment"}},
+		&api.PersistentVolumeClaim{TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim"}},
+	}
+	kinds := func(objects []runtime.Object) []string {
+		var kinds []string
+		for _, obj := range objects {
+			kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+		return kinds
+	}
+
+	tests := []struct {
+		name string
+		opt  kobject.ConvertOptions
+		want []string
+	}{
+		{"no filter", kobject.ConvertOptions{}, []string{"Service", "Deployment", "PersistentVolumeClaim"}},
+		{"include", kobject.ConvertOptions{IncludeKinds: []string{"deployment", "Service"}}, []string{"Service", "Deployment"}},
+		{"exclude", kobject.ConvertOptions{ExcludeKinds: []string{"persistentvolumeclaim"}}, []string{"Service", "Deployment"}},
+		{"include and exclude", kobject.ConvertOptions{IncludeKinds: []string{"Service", "Deployment"}, ExcludeKinds: []string{"Service"}}, []string{"Deployment"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kinds(filterKinds(objects, tt.opt)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterKinds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // test conversion from duration string to seconds *int64
 func TestDurationStrToSecondsInt(t *testing.T) {
 	testCases := map[string]struct {
@@ -885,11 +1002,11 @@ func TestFormatEnvName(t *testing.T) {
 			want: "random",
 		},
 		{
-			name: "check that everything after $ is removed",
+			name: "check that a long name is truncated with a hash of the whole name",
 			args: args{
 				name: "abcdefghijklnmopqrstuvxyzabcdefghijklmnopqrstuvwxyzabcdejghijkl$Hereisadditional",
 			},
-			want: "abcdefghijklnmopqrstuvxyzabcdefghijklmnopqrstuvwxyzabcdejghijkl",
+			want: "abcdefghijklnmopqrstuvxyzabcdefghijklmnopqrstuvwxyzabc-26c61097",
 		},
 		{
 			name: "check that not begins with -",
@@ -909,6 +1026,74 @@ func TestFormatEnvName(t *testing.T) {
 	}
 }
 
+func TestEnvConfigMapName(t *testing.T) {
+	data := map[string]string{"FOO": "bar"}
+	if got := EnvConfigMapName("web.env", "web", data, kobject.ConvertOptions{}); got != "web-env" {
+		t.Errorf("Expected the env file name without --env-configmap-hash, got %q", got)
+	}
+
+	opt := kobject.ConvertOptions{EnvConfigMapHash: true}
+	name := EnvConfigMapName("web.env", "web", data, opt)
+	if !regexp.MustCompile(`^web-env-[0-9a-f]{10}$`).MatchString(name) {
+		t.Errorf("Expected the env file name suffixed with a hash, got %q", name)
+	}
+	if other := EnvConfigMapName("web.env", "db", data, opt); other != name {
+		t.Errorf("Expected the same name for the same env file and data, got %q and %q", name, other)
+	}
+	if other := EnvConfigMapName("web.env", "web", map[string]string{"FOO": "baz"}, opt); other == name {
+		t.Errorf("Expected the name to change with the data, got %q", other)
+	}
+	if other := EnvConfigMapName("web-env", "web", data, opt); other == name {
+		t.Errorf("Expected different env files to get different names, got %q", other)
+	}
+
+	longDir := "config/" + strings.Repeat("a", 60)
+	one := EnvConfigMapName(longDir+"/one.env", "web", data, opt)
+	two := EnvConfigMapName(longDir+"/two.env", "web", data, opt)
+	if one == two || len(one) > 60 || len(two) > 60 {
+		t.Errorf("Expected unique names leaving room for the parts suffix, got %q and %q", one, two)
+	}
+}
+
+func TestGetRenames(t *testing.T) {
+	longEnvFile := "config/" + strings.Repeat("a", 60) + "/one.env"
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web-app": {Name: "web_app", OriginalName: "web_app", EnvFile: []string{longEnvFile, "web.env"}},
+			"db":      {Name: "db", EnvFile: []string{"web.env"}},
+		},
+	}
+	renames, err := GetRenames(komposeObject, kobject.ConvertOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []kobject.Rename{
+		{Kind: "Service", Original: "web_app", Name: "web-app"},
+		{Kind: "ConfigMap", Original: longEnvFile, Name: FormatEnvName(longEnvFile, "web-app")},
+	}
+	if !reflect.DeepEqual(renames, expected) {
+		t.Errorf("expected renames %v, got %v", expected, renames)
+	}
+
+	// the truncated names are suffixed with a hash of the whole name and don't collide
+	komposeObject.ServiceConfigs["db"] = kobject.ServiceConfig{Name: "db", EnvFile: []string{"config/" + strings.Repeat("a", 60) + "/two.env"}}
+	if _, err := GetRenames(komposeObject, kobject.ConvertOptions{}); err != nil {
+		t.Errorf("unexpected error for env files truncated with a hash: %v", err)
+	}
+
+	komposeObject.ServiceConfigs["db"] = kobject.ServiceConfig{Name: "db", EnvFile: []string{"web-env"}}
+	if _, err := GetRenames(komposeObject, kobject.ConvertOptions{}); err == nil {
+		t.Errorf("expected an error for env files converted to the same ConfigMap name")
+	}
+	renames, err = GetRenames(komposeObject, kobject.ConvertOptions{EnvConfigMapHash: true})
+	if err != nil {
+		t.Fatalf("unexpected error with hashed ConfigMap names: %v", err)
+	}
+	if len(renames) != 1 || renames[0].Kind != "Service" {
+		t.Errorf("expected only the service rename with hashed ConfigMap names, got %v", renames)
+	}
+}
+
 // Test empty interfaces removal
 func TestRemoveEmptyInterfaces(t *testing.T) {
 	type Obj = map[string]interface{}
@@ -977,6 +1162,106 @@ func Test_parseContainerCommandsFromStr(t *testing.T) {
 	}
 }
 
+func Test_fillSidecars(t *testing.T) {
+	template := &api.PodTemplateSpec{Spec: api.PodSpec{Containers: []api.Container{{
+		Name:         "web",
+		VolumeMounts: []api.VolumeMount{{Name: "web-claim0", MountPath: "/var/log/nginx"}},
+	}}}}
+	service := kobject.ServiceConfig{
+		Name: "web",
+		Sidecars: []kobject.Sidecar{
+			{
+				Name:    "shipper",
+				Image:   "fluent/fluent-bit",
+				Command: []string{"fluent-bit", "-c", "/etc/fluent-bit.conf"},
+				Ports:   []kobject.Ports{{ContainerPort: 2020, Protocol: "TCP"}},
+				Mounts: []kobject.SidecarMount{
+					{Path: "/var/log/nginx", MountPath: "/logs", ReadOnly: true},
+					{Path: "/missing", MountPath: "/missing"},
+				},
+			},
+			{Name: "web", Image: "nginx"},
+		},
+	}
+
+	fillSidecars(template, service, kobject.ConvertOptions{})
+
+	expected := []api.Container{
+		template.Spec.Containers[0],
+		{
+			Name:         "shipper",
+			Image:        "fluent/fluent-bit",
+			Command:      []string{"fluent-bit", "-c", "/etc/fluent-bit.conf"},
+			Ports:        []api.ContainerPort{{ContainerPort: 2020, Protocol: api.ProtocolTCP}},
+			VolumeMounts: []api.VolumeMount{{Name: "web-claim0", MountPath: "/logs", ReadOnly: true}},
+		},
+	}
+	if !reflect.DeepEqual(template.Spec.Containers, expected) {
+		t.Errorf("Expected the containers %+v, got %+v", expected, template.Spec.Containers)
+	}
+}
+
+func TestNativeSidecars(t *testing.T) {
+	template := &api.PodTemplateSpec{Spec: api.PodSpec{
+		Containers:     []api.Container{{Name: "web"}},
+		InitContainers: []api.Container{{Name: "migrate", Image: "migrate"}},
+	}}
+	service := kobject.ServiceConfig{
+		Name:     "web",
+		Sidecars: []kobject.Sidecar{{Name: "proxy", Image: "envoyproxy/envoy"}, {Name: "migrate", Image: "other"}},
+	}
+
+	fillSidecars(template, service, kobject.ConvertOptions{NativeSidecars: true})
+
+	if len(template.Spec.Containers) != 1 {
+		t.Errorf("Expected the sidecars to not be containers, got %+v", template.Spec.Containers)
+	}
+	initContainers := template.Spec.InitContainers
+	if len(initContainers) != 2 || initContainers[0].RestartPolicy != nil || initContainers[1].Name != "proxy" {
+		t.Fatalf("Expected the proxy sidecar after the init containers, got %+v", initContainers)
+	}
+	if policy := initContainers[1].RestartPolicy; policy == nil || *policy != api.ContainerRestartPolicyAlways {
+		t.Errorf("Expected the sidecar to always restart, got %v", policy)
+	}
+}
+
+func TestFillNamedInitContainers(t *testing.T) {
+	template := &api.PodTemplateSpec{Spec: api.PodSpec{Containers: []api.Container{{
+		Name:         "web",
+		VolumeMounts: []api.VolumeMount{{Name: "web-claim0", MountPath: "/migrations"}},
+	}}}}
+	service := kobject.ServiceConfig{
+		Name:   "web",
+		Labels: map[string]string{compose.LabelInitContainerImage: "busybox"},
+		InitContainers: []kobject.InitContainer{
+			{
+				Name:    "migrate",
+				Image:   "migrate/migrate:v4",
+				Command: []string{"migrate", "up"},
+				Env:     []kobject.EnvVar{{Name: "LOG", Value: "debug"}},
+				Mounts:  []kobject.SidecarMount{{Path: "/migrations", MountPath: "/sql", ReadOnly: true}},
+			},
+			{Name: "web", Image: "nginx"},
+		},
+	}
+
+	fillInitContainers(template, service)
+
+	expected := []api.Container{
+		{Name: "init-service", Image: "busybox", Command: []string{}},
+		{
+			Name:         "migrate",
+			Image:        "migrate/migrate:v4",
+			Command:      []string{"migrate", "up"},
+			Env:          []api.EnvVar{{Name: "LOG", Value: "debug"}},
+			VolumeMounts: []api.VolumeMount{{Name: "web-claim0", MountPath: "/sql", ReadOnly: true}},
+		},
+	}
+	if !reflect.DeepEqual(template.Spec.InitContainers, expected) {
+		t.Errorf("Expected the init containers %+v, got %+v", expected, template.Spec.InitContainers)
+	}
+}
+
 func Test_fillInitContainers(t *testing.T) {
 	type args struct {
 		template *api.PodTemplateSpec
@@ -4010,3 +4295,119 @@ func TestKubernetes_fixNetworkModeToService(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslatePodResourceGPUs(t *testing.T) {
+	testCases := map[string]struct {
+		service  kobject.ServiceConfig
+		resource api.ResourceName
+	}{
+		"Default resource": {kobject.ServiceConfig{GPUs: 2}, DefaultGPUResource},
+		"Custom resource":  {kobject.ServiceConfig{GPUs: 2, GPUResource: "amd.com/gpu"}, "amd.com/gpu"},
+	}
+
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		template := &api.PodTemplateSpec{Spec: api.PodSpec{Containers: []api.Container{{}}}}
+		TranslatePodResource(&test.service, template)
+		limit := template.Spec.Containers[0].Resources.Limits[test.resource]
+		if limit.Value() != 2 {
+			t.Errorf("Expected a limit of 2 %s, got %v", test.resource, template.Spec.Containers[0].Resources.Limits)
+		}
+		if len(template.Spec.Containers[0].Resources.Requests) != 0 {
+			t.Errorf("Expected no requests, got %v", template.Spec.Containers[0].Resources.Requests)
+		}
+	}
+}
+
+func TestPrintListServiceLayout(t *testing.T) {
+	labels := func(name string) map[string]string { return map[string]string{transformer.Selector: name} }
+	objects := []runtime.Object{
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: labels("web")},
+		},
+		&api.Service{
+			TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: labels("web")},
+		},
+		&api.PersistentVolumeClaim{
+			TypeMeta:   metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web-claim0", Labels: labels("web-claim0")},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "web-worker", Labels: labels("web-worker")},
+		},
+		&api.Namespace{
+			TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "shop"},
+		},
+	}
+
+	dir := t.TempDir()
+	opt := kobject.ConvertOptions{OutFile: dir, OutputLayout: OutputLayoutService, YAMLIndent: 2}
+	if err := PrintList(objects, opt); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, file := range []string{
+		"web/deployment.yaml",
+		"web/service.yaml",
+		"web/web-claim0-persistentvolumeclaim.yaml",
+		"web-worker/deployment.yaml",
+		"shop-namespace.yaml",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("Expected %s to be written: %v", file, err)
+		}
+	}
+}
+
+func TestSortObjects(t *testing.T) {
+	object := func(kind, name string) runtime.Object {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": name},
+		}}
+	}
+	objects := []runtime.Object{
+		object("Ingress", "web"),
+		object("Deployment", "web"),
+		object("Service", "web"),
+		object("Application", "shop"),
+		object("HorizontalPodAutoscaler", "web"),
+		object("Deployment", "db"),
+		object("PersistentVolumeClaim", "db-data"),
+		object("Service", "db"),
+		object("ConfigMap", "web-env"),
+		object("Secret", "db-password"),
+		object("ServiceAccount", "web"),
+		object("Namespace", "shop"),
+		object("Middleware", "web"),
+	}
+
+	k := Kubernetes{}
+	k.SortObjects(&objects)
+	var got []string
+	for _, obj := range objects {
+		got = append(got, obj.GetObjectKind().GroupVersionKind().Kind+"/"+obj.(*unstructured.Unstructured).GetName())
+	}
+	expected := []string{
+		"Namespace/shop",
+		"ServiceAccount/web",
+		"ConfigMap/web-env",
+		"Secret/db-password",
+		"PersistentVolumeClaim/db-data",
+		"Service/db",
+		"Service/web",
+		"Deployment/db",
+		"Deployment/web",
+		"HorizontalPodAutoscaler/web",
+		"Ingress/web",
+		"Application/shop",
+		"Middleware/web",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the objects in the order\n%v\ngot\n%v", expected, got)
+	}
+}
@@ -17,8 +17,8 @@ limitations under the License.
 package kubernetes
 
 import (
-	"encoding/base64"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path"
@@ -28,12 +28,16 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/go-units"
 	"github.com/fatih/structs"
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/loader/compose"
 	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	"github.com/kubernetes/kompose/pkg/validate"
 	"github.com/mattn/go-shellwords"
 	deployapi "github.com/openshift/api/apps/v1"
 	buildapi "github.com/openshift/api/build/v1"
@@ -45,6 +49,8 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	api "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -57,12 +63,42 @@ type Kubernetes struct {
 	Opt kobject.ConvertOptions
 }
 
+func init() {
+	transformer.Register("kubernetes", func(opt kobject.ConvertOptions) transformer.Transformer {
+		return &Kubernetes{Opt: opt}
+	})
+}
+
 // PVCRequestSize (Persistent Volume Claim) has default size
 const PVCRequestSize = "100Mi"
 
+// ConfigMapDataLimit is the maximum size of the data stored in a single ConfigMap,
+// it leaves room for the metadata below the 1MiB limit of the API server
+const ConfigMapDataLimit = 1000 * 1024
+
+// KomposeIgnoreFile lists the globs of the files left out of the ConfigMap of a directory, one per line
+const KomposeIgnoreFile = ".komposeignore"
+
+// DefaultGPUResource is the extended resource the GPUs are requested with, unless set with the kompose.gpu.resource label
+const DefaultGPUResource = "nvidia.com/gpu"
+
+// downwardAPIFields are the placeholders of the environment values read from the fields of the pod with the downward API
+var downwardAPIFields = map[string]string{
+	"{{.PodName}}":            "metadata.name",
+	"{{.PodNamespace}}":       "metadata.namespace",
+	"{{.PodUID}}":             "metadata.uid",
+	"{{.PodIP}}":              "status.podIP",
+	"{{.HostIP}}":             "status.hostIP",
+	"{{.NodeName}}":           "spec.nodeName",
+	"{{.ServiceAccountName}}": "spec.serviceAccountName",
+}
+
 // ValidVolumeSet has the different types of valid volumes
 var ValidVolumeSet = map[string]struct{}{"emptyDir": {}, "hostPath": {}, "configMap": {}, "persistentVolumeClaim": {}}
 
+// validAccessModes are the access modes of the kompose.volume.access-mode label, in lower case
+var validAccessModes = map[string]struct{}{"rwo": {}, "rox": {}, "rwx": {}, "rwop": {}, "readwriteonce": {}, "readonlymany": {}, "readwritemany": {}, "readwriteoncepod": {}}
+
 const (
 	// DeploymentController is controller type for Deployment
 	DeploymentController = "deployment"
@@ -161,7 +197,9 @@ func (k *Kubernetes) InitPodSpecWithConfigMap(name string, image string, service
 		if value.Mode != nil {
 			tmpMode := int32(*value.Mode)
 			volSource.DefaultMode = &tmpMode
+			volSource.Items[0].Mode = &tmpMode
 		}
+		warnFileOwner("config", value.Source, name, value.UID, value.GID, service.FsGroup)
 
 		cmVol := api.Volume{
 			Name:         cmVolName,
@@ -221,19 +259,19 @@ func (k *Kubernetes) InitSvc(name string, service kobject.ServiceConfig) *api.Se
 
 // InitConfigMapForEnvWithLookup initializes a ConfigMap object from an env_file with variable interpolation support
 // using the provided lookup function to resolve variable references like ${VAR} or ${VAR:-default}
-func (k *Kubernetes) InitConfigMapForEnvWithLookup(name string, opt kobject.ConvertOptions, envFile string, lookup func(key string) (string, bool)) *api.ConfigMap {
-	workDir, err := transformer.GetComposeFileDir(opt.InputFiles)
+func (k *Kubernetes) InitConfigMapForEnvWithLookup(name string, opt kobject.ConvertOptions, envFile string, lookup func(key string) (string, bool)) (*api.ConfigMap, error) {
+	workDir, err := transformer.GetWorkingDir(opt)
 	if err != nil {
-		log.Fatalf("Unable to get compose file directory: %s", err)
+		return nil, errors.Wrap(err, "Unable to get compose file directory")
 	}
 	envs, err := LoadEnvFiles(filepath.Join(workDir, envFile), lookup)
 	if err != nil {
-		log.Fatalf("Unable to retrieve env file: %s", err)
+		return nil, errors.Wrap(err, "Unable to retrieve env file")
 	}
 
 	// Remove root pathing
 	// replace all other slashes / periods
-	envName := FormatEnvName(envFile, name)
+	envName := EnvConfigMapName(envFile, name, envs, opt)
 
 	// In order to differentiate files, we append to the name and remove '.env' if applicable from the file name
 	configMap := &api.ConfigMap{
@@ -247,24 +285,27 @@ func (k *Kubernetes) InitConfigMapForEnvWithLookup(name string, opt kobject.Conv
 		},
 		Data: envs,
 	}
+	if opt.WithKomposeAnnotation && envName != getUsableNameEnvFile(envFile, name) {
+		configMap.Annotations = map[string]string{transformer.OriginalNameAnnotation: envFile}
+	}
 
-	return configMap
+	return configMap, nil
 }
 
 // InitConfigMapForEnv initializes a ConfigMap object
-func (k *Kubernetes) InitConfigMapForEnv(name string, opt kobject.ConvertOptions, envFile string) *api.ConfigMap {
-	workDir, err := transformer.GetComposeFileDir(opt.InputFiles)
+func (k *Kubernetes) InitConfigMapForEnv(name string, opt kobject.ConvertOptions, envFile string) (*api.ConfigMap, error) {
+	workDir, err := transformer.GetWorkingDir(opt)
 	if err != nil {
-		log.Fatalf("Unable to get compose file directory: %s", err)
+		return nil, errors.Wrap(err, "Unable to get compose file directory")
 	}
 	envs, err := GetEnvsFromFile(filepath.Join(workDir, envFile))
 	if err != nil {
-		log.Fatalf("Unable to retrieve env file: %s", err)
+		return nil, errors.Wrap(err, "Unable to retrieve env file")
 	}
 
 	// Remove root pathing
 	// replace all other slashes / periods
-	envName := FormatEnvName(envFile, name)
+	envName := EnvConfigMapName(envFile, name, envs, opt)
 
 	// In order to differentiate files, we append to the name and remove '.env' if applicable from the file name
 	configMap := &api.ConfigMap{
@@ -278,14 +319,18 @@ func (k *Kubernetes) InitConfigMapForEnv(name string, opt kobject.ConvertOptions
 		},
 		Data: envs,
 	}
+	if opt.WithKomposeAnnotation && envName != getUsableNameEnvFile(envFile, name) {
+		configMap.Annotations = map[string]string{transformer.OriginalNameAnnotation: envFile}
+	}
 
-	return configMap
+	return configMap, nil
 }
 
-// IntiConfigMapFromFileOrDir will create a configmap from dir or file
+// IntiConfigMapFromFileOrDir will create a configmap from dir or file, with the items mounting the files of the
+// subdirectories back at their path
 // usage:
 //  1. volume
-func (k *Kubernetes) IntiConfigMapFromFileOrDir(name, cmName, filePath string, service kobject.ServiceConfig) (*api.ConfigMap, error) {
+func (k *Kubernetes) IntiConfigMapFromFileOrDir(name, cmName, filePath string, service kobject.ServiceConfig) (*api.ConfigMap, []api.KeyToPath, error) {
 	configMap := &api.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "ConfigMap",
@@ -296,42 +341,147 @@ func (k *Kubernetes) IntiConfigMapFromFileOrDir(name, cmName, filePath string, s
 			Labels: transformer.ConfigLabels(name),
 		},
 	}
-	dataMap := make(map[string]string)
+	var items []api.KeyToPath
 
 	fi, err := os.Stat(filePath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	switch mode := fi.Mode(); {
 	case mode.IsDir():
-		files, err := os.ReadDir(filePath)
+		var dataMap map[string]string
+		dataMap, items, err = readConfigMapDir(filePath, service)
 		if err != nil {
-			return nil, err
-		}
-
-		for _, file := range files {
-			if !file.IsDir() {
-				log.Debugf("Read file to ConfigMap: %s", file.Name())
-				data, err := GetContentFromFile(filePath + "/" + file.Name())
-				if err != nil {
-					return nil, err
-				}
-				dataMap[file.Name()] = data
-			}
+			return nil, nil, err
 		}
 		initConfigMapData(configMap, dataMap)
 
 	case mode.IsRegular():
 		// do file stuff
-		configMap = k.InitConfigMapFromFile(name, service, filePath)
+		configMap, err = k.InitConfigMapFromFile(name, service, filePath)
+		if err != nil {
+			return nil, nil, err
+		}
 		configMap.Name = cmName
 		configMap.Annotations = map[string]string{
 			"use-subpath": "true",
 		}
 	}
 
-	return configMap, nil
+	return configMap, items, nil
+}
+
+// configMapIgnorePatterns returns the globs of the files left out of the ConfigMap of a directory, from the
+// kompose.volume.configmap-ignore label and the .komposeignore file of the directory
+func configMapIgnorePatterns(dir string, service kobject.ServiceConfig) ([]string, error) {
+	var patterns []string
+	if value, ok := service.Labels[compose.LabelVolumeConfigMapIgnore]; ok {
+		patterns = append(patterns, strings.Split(value, ",")...)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, KomposeIgnoreFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "unable to read %s", KomposeIgnoreFile)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			patterns = append(patterns, line)
+		}
+	}
+
+	var globs []string
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %q to ignore the files of %s", pattern, dir)
+		}
+		globs = append(globs, pattern)
+	}
+	return globs, nil
+}
+
+// ignoredFile checks if the path of a file, relative to the directory of the ConfigMap, or its name matches one of the globs
+func ignoredFile(rel string, globs []string) bool {
+	for _, glob := range globs {
+		if match, _ := filepath.Match(glob, rel); match {
+			return true
+		}
+		if match, _ := filepath.Match(glob, path.Base(rel)); match {
+			return true
+		}
+	}
+	return false
+}
+
+// readConfigMapDir reads the files of a directory converted to a ConfigMap, except the ignored ones. The files of the
+// subdirectories are only read with the kompose.volume.configmap-recursive label, they are stored under their path with
+// the slashes replaced by underscores, and the returned items mount them back at their path.
+func readConfigMapDir(dir string, service kobject.ServiceConfig) (map[string]string, []api.KeyToPath, error) {
+	globs, err := configMapIgnorePatterns(dir, service)
+	if err != nil {
+		return nil, nil, err
+	}
+	recursive := false
+	if value, ok := service.Labels[compose.LabelVolumeConfigMapRecursive]; ok {
+		if recursive, err = strconv.ParseBool(value); err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid value of label %s in service %s", compose.LabelVolumeConfigMapRecursive, service.Name)
+		}
+	}
+
+	data := map[string]string{}
+	var items []api.KeyToPath
+	nested := false
+	err = filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if file == dir {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if !recursive || ignoredFile(rel, globs) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == KomposeIgnoreFile {
+			return nil
+		}
+		if ignoredFile(rel, globs) {
+			log.Debugf("Ignore file %s of ConfigMap", rel)
+			return nil
+		}
+
+		key := strings.ReplaceAll(rel, "/", "_")
+		if _, ok := data[key]; ok {
+			return errors.Errorf("file %s of %s has the same ConfigMap key %s as another file", rel, dir, key)
+		}
+		log.Debugf("Read file to ConfigMap: %s", rel)
+		content, err := GetContentFromFile(file)
+		if err != nil {
+			return err
+		}
+		data[key] = content
+		items = append(items, api.KeyToPath{Key: key, Path: rel})
+		nested = nested || key != rel
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if !nested {
+		// the keys are the paths of the files, no need to list them
+		items = nil
+	}
+	return data, items, nil
 }
 
 // useSubPathMount check if a configmap should be mounted as subpath
@@ -346,17 +496,70 @@ func useSubPathMount(cm *api.ConfigMap) bool {
 	return true
 }
 
+// splitConfigMap splits a ConfigMap whose data exceeds ConfigMapDataLimit into several ConfigMaps
+// named after it with a numeric suffix. Keys are sorted so that the split is stable.
+func splitConfigMap(cm *api.ConfigMap) ([]*api.ConfigMap, error) {
+	sizes := make(map[string]int)
+	total := 0
+	for key, value := range cm.Data {
+		sizes[key] = len(key) + len(value)
+		total += sizes[key]
+	}
+	for key, value := range cm.BinaryData {
+		sizes[key] = len(key) + len(value)
+		total += sizes[key]
+	}
+	if total <= ConfigMapDataLimit {
+		return []*api.ConfigMap{cm}, nil
+	}
+
+	keys := make([]string, 0, len(sizes))
+	for key := range sizes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []*api.ConfigMap
+	var part *api.ConfigMap
+	partSize := 0
+	for _, key := range keys {
+		if sizes[key] > ConfigMapDataLimit {
+			return nil, errors.Errorf("%q is too large to be stored in ConfigMap %q (%d bytes, limit is %d)", key, cm.Name, sizes[key], ConfigMapDataLimit)
+		}
+		if part == nil || partSize+sizes[key] > ConfigMapDataLimit {
+			part = &api.ConfigMap{
+				TypeMeta:   cm.TypeMeta,
+				ObjectMeta: *cm.ObjectMeta.DeepCopy(),
+				Data:       map[string]string{},
+				BinaryData: map[string][]byte{},
+			}
+			part.Name = fmt.Sprintf("%s-%d", cm.Name, len(parts))
+			parts = append(parts, part)
+			partSize = 0
+		}
+		if value, ok := cm.Data[key]; ok {
+			part.Data[key] = value
+		} else {
+			part.BinaryData[key] = cm.BinaryData[key]
+		}
+		partSize += sizes[key]
+	}
+	log.Warnf("ConfigMap %q is larger than %d bytes and has been split into %d ConfigMaps", cm.Name, ConfigMapDataLimit, len(parts))
+	return parts, nil
+}
+
+// initConfigMapData stores the text files in the data of the ConfigMap and the other files, which are not
+// valid UTF-8 or hold control characters, unchanged in its binaryData. The serializer encodes them in base64.
 func initConfigMapData(configMap *api.ConfigMap, data map[string]string) {
 	stringData := map[string]string{}
 	binData := map[string][]byte{}
 
 	for k, v := range data {
 		lfText := strings.Replace(v, "\r\n", "\n", -1)
-		isText := util.IsText([]byte(lfText))
-		if isText {
+		if utf8.ValidString(v) && util.IsText([]byte(lfText)) {
 			stringData[k] = lfText
 		} else {
-			binData[k] = []byte(base64.StdEncoding.EncodeToString([]byte(v)))
+			binData[k] = []byte(v)
 		}
 	}
 
@@ -383,10 +586,13 @@ func (k *Kubernetes) InitConfigMapFromContent(name string, service kobject.Servi
 }
 
 // InitConfigMapFromFile initializes a ConfigMap object
-func (k *Kubernetes) InitConfigMapFromFile(name string, service kobject.ServiceConfig, fileName string) *api.ConfigMap {
+func (k *Kubernetes) InitConfigMapFromFile(name string, service kobject.ServiceConfig, fileName string) (*api.ConfigMap, error) {
 	content, err := GetContentFromFile(fileName)
 	if err != nil {
-		log.Fatalf("Unable to retrieve file: %s", err)
+		return nil, errors.Wrap(err, "Unable to retrieve file")
+	}
+	if len(content) > ConfigMapDataLimit {
+		log.Warnf("File %s is larger than the %d bytes of a ConfigMap, it will be rejected by the cluster", fileName, ConfigMapDataLimit)
 	}
 
 	configMapName := ""
@@ -408,7 +614,7 @@ func (k *Kubernetes) InitConfigMapFromFile(name string, service kobject.ServiceC
 
 	data := map[string]string{filepath.Base(fileName): content}
 	initConfigMapData(configMap, data)
-	return configMap
+	return configMap, nil
 }
 
 // InitD initializes Kubernetes Deployment object
@@ -433,6 +639,7 @@ func (k *Kubernetes) InitD(name string, service kobject.ServiceConfig, replicas
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &rp,
+			Paused:   service.Paused,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: transformer.ConfigLabels(name),
 			},
@@ -463,12 +670,19 @@ func (k *Kubernetes) InitD(name string, service kobject.ServiceConfig, replicas
 		}
 		log.Debugf("Set deployment '%s' rolling update: MaxSurge: %s, MaxUnavailable: %s", name, ms, mu)
 	}
+	dc.Spec.MinReadySeconds = service.GetKubernetesMinReadySeconds()
 
 	return dc
 }
 
 // InitDS initializes Kubernetes DaemonSet object
 func (k *Kubernetes) InitDS(name string, service kobject.ServiceConfig) *appsv1.DaemonSet {
+	var podSpec api.PodSpec
+	if len(service.Configs) > 0 {
+		podSpec = k.InitPodSpecWithConfigMap(name, service.Image, service)
+	} else {
+		podSpec = k.InitPodSpec(name, service.Image, service.ImagePullSecret)
+	}
 	ds := &appsv1.DaemonSet{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "DaemonSet",
@@ -483,7 +697,11 @@ func (k *Kubernetes) InitDS(name string, service kobject.ServiceConfig) *appsv1.
 				MatchLabels: transformer.ConfigLabels(name),
 			},
 			Template: api.PodTemplateSpec{
-				Spec: k.InitPodSpec(name, service.Image, service.ImagePullSecret),
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      transformer.ConfigLabels(name),
+					Annotations: transformer.ConfigAnnotations(service),
+				},
+				Spec: podSpec,
 			},
 		},
 	}
@@ -516,9 +734,40 @@ func (k *Kubernetes) InitSS(name string, service kobject.ServiceConfig, replicas
 			Selector: &metav1.LabelSelector{
 				MatchLabels: transformer.ConfigLabels(name),
 			},
-			ServiceName: service.Name,
+			ServiceName:     service.Name,
+			MinReadySeconds: service.GetKubernetesMinReadySeconds(),
 		},
 	}
+	if update := service.GetKubernetesStatefulSetUpdateStrategy(); update != nil {
+		ds.Spec.UpdateStrategy = *update
+	}
+
+	statefulSet := service.StatefulSet
+	ds.Spec.PodManagementPolicy = statefulSet.PodManagementPolicy
+	if statefulSet.ServiceName != "" {
+		ds.Spec.ServiceName = statefulSet.ServiceName
+	}
+	switch statefulSet.UpdateStrategy {
+	case appsv1.OnDeleteStatefulSetStrategyType:
+		// the pods are replaced when they are deleted, the update_config doesn't apply
+		ds.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	case appsv1.RollingUpdateStatefulSetStrategyType:
+		ds.Spec.UpdateStrategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
+	}
+	if statefulSet.PVCRetentionWhenDeleted != "" || statefulSet.PVCRetentionWhenScaled != "" {
+		// the claims are retained by default
+		policy := &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+			WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+			WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		}
+		if statefulSet.PVCRetentionWhenDeleted != "" {
+			policy.WhenDeleted = statefulSet.PVCRetentionWhenDeleted
+		}
+		if statefulSet.PVCRetentionWhenScaled != "" {
+			policy.WhenScaled = statefulSet.PVCRetentionWhenScaled
+		}
+		ds.Spec.PersistentVolumeClaimRetentionPolicy = policy
+	}
 	return ds
 }
 
@@ -537,19 +786,79 @@ func (k *Kubernetes) InitCJ(name string, service kobject.ServiceConfig, schedule
 			Schedule:          schedule,
 			ConcurrencyPolicy: concurrencyPolicy,
 			JobTemplate: batchv1.JobTemplateSpec{
-				Spec: batchv1.JobSpec{
-					BackoffLimit: backoffLimit,
-					Template: api.PodTemplateSpec{
-						Spec: k.InitPodSpec(name, service.Image, service.ImagePullSecret),
-					},
-				},
+				Spec: k.initJobSpec(name, service, backoffLimit),
 			},
 		},
 	}
 	return cj
 }
 
-func (k *Kubernetes) initIngress(name string, service kobject.ServiceConfig, port int32) *networkingv1.Ingress {
+// InitJ initializes Kubernetes Job object
+func (k *Kubernetes) InitJ(name string, service kobject.ServiceConfig, backoffLimit *int32) *batchv1.Job {
+	j := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: transformer.ConfigAllLabels(name, &service),
+		},
+		Spec: k.initJobSpec(name, service, backoffLimit),
+	}
+	return j
+}
+
+func (k *Kubernetes) initJobSpec(name string, service kobject.ServiceConfig, backoffLimit *int32) batchv1.JobSpec {
+	return batchv1.JobSpec{
+		Parallelism:             service.JobParallelism,
+		Completions:             service.JobCompletions,
+		ActiveDeadlineSeconds:   service.JobActiveDeadlineSeconds,
+		TTLSecondsAfterFinished: service.JobTTLSecondsAfterFinish,
+		BackoffLimit:            backoffLimit,
+		PodFailurePolicy:        service.JobPodFailurePolicy,
+		Template: api.PodTemplateSpec{
+			Spec: k.InitPodSpec(name, service.Image, service.ImagePullSecret),
+		},
+	}
+}
+
+// NeedsJob returns true when a service with restart "no" or "on-failure" has to be
+// converted to a Job instead of a bare Pod
+func NeedsJob(service kobject.ServiceConfig) bool {
+	return service.RestartMaxAttempts != nil ||
+		service.JobPodFailurePolicy != nil ||
+		service.JobParallelism != nil ||
+		service.JobCompletions != nil ||
+		service.JobTTLSecondsAfterFinish != nil ||
+		service.JobActiveDeadlineSeconds != nil
+}
+
+// GetJobBackoffLimit returns the backoff limit of a job created from the given service,
+// an explicit kompose.cronjob.backoff_limit wins over the restart: on-failure:N retry budget
+func GetJobBackoffLimit(service kobject.ServiceConfig) *int32 {
+	if service.CronJobBackoffLimit != nil {
+		return service.CronJobBackoffLimit
+	}
+	if service.RestartMaxAttempts != nil {
+		return service.RestartMaxAttempts
+	}
+	// a failed pod must not be retried with restart: "no"
+	if service.Restart == "no" && service.JobPodFailurePolicy != nil {
+		backoffLimit := int32(0)
+		return &backoffLimit
+	}
+	return nil
+}
+
+// backendProtocolAnnotation sets the protocol ingress-nginx uses to reach the backends of the Ingresses
+const backendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+
+// backendProtocols are the values of the backend protocol annotation, by application protocol
+var backendProtocols = map[string]string{"https": "HTTPS", "grpc": "GRPC", "grpcs": "GRPCS"}
+
+func (k *Kubernetes) initIngress(name string, service kobject.ServiceConfig, servicePort api.ServicePort) *networkingv1.Ingress {
+	port := servicePort.Port
 	hosts := regexp.MustCompile("[ ,]*,[ ,]*").Split(service.ExposeService, -1)
 
 	ingress := &networkingv1.Ingress{
@@ -619,6 +928,15 @@ func (k *Kubernetes) initIngress(name string, service kobject.ServiceConfig, por
 		ingress.Spec.IngressClassName = &service.ExposeServiceIngressClassName
 	}
 
+	// the backends serving https or grpc are reached with their protocol
+	if servicePort.AppProtocol != nil {
+		if protocol, ok := backendProtocols[strings.ToLower(*servicePort.AppProtocol)]; ok {
+			if _, ok := ingress.Annotations[backendProtocolAnnotation]; !ok {
+				ingress.Annotations[backendProtocolAnnotation] = protocol
+			}
+		}
+	}
+
 	return ingress
 }
 
@@ -629,8 +947,7 @@ func (k *Kubernetes) CreateSecrets(komposeObject kobject.KomposeObject) ([]*api.
 		if config.File != "" {
 			dataString, err := GetContentFromFile(config.File)
 			if err != nil {
-				log.Fatal("unable to read secret from file: ", config.File)
-				return nil, err
+				return nil, errors.Wrapf(err, "unable to read secret from file %s", config.File)
 			}
 			data := []byte(dataString)
 			resourceName := FormatResourceName(name)
@@ -648,7 +965,7 @@ func (k *Kubernetes) CreateSecrets(komposeObject kobject.KomposeObject) ([]*api.
 			}
 			objects = append(objects, secret)
 		} else {
-			log.Warnf("External secrets %s is not currently supported - ignoring", name)
+			dropped.Warnf("External secrets %s is not currently supported - ignoring", name)
 		}
 	}
 	return objects, nil
@@ -718,6 +1035,14 @@ func ConfigPorts(service kobject.ServiceConfig) []api.ContainerPort {
 	return ports
 }
 
+// appProtocol returns the application protocol of the port, nil when it isn't set
+func appProtocol(port kobject.Ports) *string {
+	if port.AppProtocol == "" {
+		return nil
+	}
+	return &port.AppProtocol
+}
+
 // ConfigLBServicePorts method configure the ports of the k8s Load Balancer Service
 func (k *Kubernetes) ConfigLBServicePorts(service kobject.ServiceConfig) ([]api.ServicePort, []api.ServicePort) {
 	var tcpPorts []api.ServicePort
@@ -731,9 +1056,10 @@ func (k *Kubernetes) ConfigLBServicePorts(service kobject.ServiceConfig) ([]api.
 		targetPort.StrVal = strconv.Itoa(int(port.ContainerPort))
 
 		servicePort := api.ServicePort{
-			Name:       strconv.Itoa(int(port.HostPort)),
-			Port:       port.HostPort,
-			TargetPort: targetPort,
+			Name:        strconv.Itoa(int(port.HostPort)),
+			Port:        port.HostPort,
+			TargetPort:  targetPort,
+			AppProtocol: appProtocol(port),
 		}
 
 		if protocol := api.Protocol(port.Protocol); protocol == api.ProtocolTCP {
@@ -748,7 +1074,7 @@ func (k *Kubernetes) ConfigLBServicePorts(service kobject.ServiceConfig) ([]api.
 }
 
 // ConfigServicePorts configure the container service ports.
-func (k *Kubernetes) ConfigServicePorts(service kobject.ServiceConfig) []api.ServicePort {
+func (k *Kubernetes) ConfigServicePorts(service kobject.ServiceConfig) ([]api.ServicePort, error) {
 	servicePorts := []api.ServicePort{}
 	seenPorts := make(map[int]struct{}, len(service.Port))
 
@@ -767,19 +1093,24 @@ func (k *Kubernetes) ConfigServicePorts(service kobject.ServiceConfig) []api.Ser
 		if _, ok := seenPorts[int(port.HostPort)]; ok {
 			// https://github.com/kubernetes/kubernetes/issues/2995
 			if service.ServiceType == string(api.ServiceTypeLoadBalancer) {
-				log.Fatalf("Service %s of type LoadBalancer cannot use TCP and UDP for the same port", name)
+				return nil, errors.Errorf("Service %s of type LoadBalancer cannot use TCP and UDP for the same port", name)
 			}
 			name = fmt.Sprintf("%s-%s", name, strings.ToLower(port.Protocol))
 		}
 
 		servicePort = api.ServicePort{
-			Name:       name,
-			Port:       port.HostPort,
-			TargetPort: targetPort,
+			Name:        name,
+			Port:        port.HostPort,
+			TargetPort:  targetPort,
+			AppProtocol: appProtocol(port),
 		}
 
-		if service.ServiceType == string(api.ServiceTypeNodePort) && service.NodePortPort != 0 {
-			servicePort.NodePort = service.NodePortPort
+		if service.ServiceType == string(api.ServiceTypeNodePort) {
+			if nodePort, ok := service.NodePortPorts[port.HostPort]; ok {
+				servicePort.NodePort = nodePort
+			} else if service.NodePortPort != 0 {
+				servicePort.NodePort = service.NodePortPort
+			}
 		}
 
 		// If the default is already TCP, no need to include protocol.
@@ -790,7 +1121,7 @@ func (k *Kubernetes) ConfigServicePorts(service kobject.ServiceConfig) []api.Ser
 		servicePorts = append(servicePorts, servicePort)
 		seenPorts[int(port.HostPort)] = struct{}{}
 	}
-	return servicePorts
+	return servicePorts, nil
 }
 
 // ConfigCapabilities configure POSIX capabilities that can be added or removed to a container
@@ -809,7 +1140,129 @@ func ConfigCapabilities(service kobject.ServiceConfig) *api.Capabilities {
 	}
 }
 
+// safeSysctls are the sysctls the kubelets allow by default
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced":              true,
+	"net.ipv4.ip_local_port_range":        true,
+	"net.ipv4.ip_local_reserved_ports":    true,
+	"net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.ping_group_range":           true,
+	"net.ipv4.tcp_fin_timeout":            true,
+	"net.ipv4.tcp_keepalive_intvl":        true,
+	"net.ipv4.tcp_keepalive_probes":       true,
+	"net.ipv4.tcp_keepalive_time":         true,
+	"net.ipv4.tcp_syncookies":             true,
+}
+
+// isNamespacedSysctl returns whether the sysctl can be set for a single pod
+func isNamespacedSysctl(name string) bool {
+	if name == "kernel.sem" || strings.HasPrefix(name, "kernel.shm") || strings.HasPrefix(name, "kernel.msg") {
+		return true
+	}
+	return strings.HasPrefix(name, "fs.mqueue.") || strings.HasPrefix(name, "net.")
+}
+
+// ConfigSysctls configures the sysctls of the pod, the unsafe ones are kept only when allowed
+func ConfigSysctls(name string, service kobject.ServiceConfig, allowUnsafe bool) []api.Sysctl {
+	names := make([]string, 0, len(service.Sysctls))
+	for sysctl := range service.Sysctls {
+		names = append(names, sysctl)
+	}
+	sort.Strings(names)
+
+	var sysctls []api.Sysctl
+	for _, sysctl := range names {
+		switch {
+		case !isNamespacedSysctl(sysctl):
+			dropped.Warnf("Ignoring sysctl %q of service %q, it isn't namespaced and can't be set for a pod", sysctl, name)
+			continue
+		case safeSysctls[sysctl]:
+		case allowUnsafe:
+			log.Warnf("The unsafe sysctl %q of service %q must be allowed on the kubelets with --allowed-unsafe-sysctls", sysctl, name)
+		default:
+			dropped.Warnf("Ignoring the unsafe sysctl %q of service %q, use --allow-unsafe-sysctls to keep it", sysctl, name)
+			continue
+		}
+		sysctls = append(sysctls, api.Sysctl{Name: sysctl, Value: service.Sysctls[sysctl]})
+	}
+	return sysctls
+}
+
+// ConfigHostAliases converts the extra_hosts of the service to host aliases, with the hostnames grouped by IP
+func ConfigHostAliases(name string, service kobject.ServiceConfig) []api.HostAlias {
+	hostnames := map[string][]string{}
+	var ips []string
+	for hostname, addresses := range service.ExtraHosts {
+		for _, ip := range addresses {
+			if ip == "host-gateway" {
+				dropped.Warnf("Ignoring extra host %q of service %q, the host gateway has no equivalent in Kubernetes", hostname, name)
+				continue
+			}
+			if _, ok := hostnames[ip]; !ok {
+				ips = append(ips, ip)
+			}
+			hostnames[ip] = append(hostnames[ip], hostname)
+		}
+	}
+	sort.Strings(ips)
+
+	var hostAliases []api.HostAlias
+	for _, ip := range ips {
+		sort.Strings(hostnames[ip])
+		hostAliases = append(hostAliases, api.HostAlias{IP: ip, Hostnames: hostnames[ip]})
+	}
+	return hostAliases
+}
+
+// ConfigDNS converts the dns, dns_search and dns_opt keys of the service to the DNS configuration of the pod.
+// The cluster DNS is replaced when nameservers are set, like the dns key replaces the embedded DNS server.
+func ConfigDNS(service kobject.ServiceConfig) (*api.PodDNSConfig, api.DNSPolicy) {
+	if len(service.DNS) == 0 && len(service.DNSSearch) == 0 && len(service.DNSOpts) == 0 {
+		return nil, ""
+	}
+
+	dnsConfig := &api.PodDNSConfig{
+		Nameservers: service.DNS,
+		Searches:    service.DNSSearch,
+	}
+	for _, opt := range service.DNSOpts {
+		// the options are given like in resolv.conf, as name[:value]
+		option := api.PodDNSConfigOption{Name: opt}
+		if name, value, found := strings.Cut(opt, ":"); found {
+			option = api.PodDNSConfigOption{Name: name, Value: &value}
+		}
+		dnsConfig.Options = append(dnsConfig.Options, option)
+	}
+
+	if len(service.DNS) > 0 {
+		return dnsConfig, api.DNSNone
+	}
+	return dnsConfig, ""
+}
+
+// ConfigHostNamespaces returns whether the pod shares the PID, IPC and network namespaces of the host,
+// they are only shared when allowed since the pod is then no longer isolated from the node
+func ConfigHostNamespaces(name string, service kobject.ServiceConfig, allow bool) (hostPID, hostIPC, hostNetwork bool) {
+	isHost := func(key, value string) bool {
+		switch {
+		case value == "":
+			return false
+		case value != "host":
+			if key != "network_mode" {
+				dropped.Warnf("Ignoring %s of service %q, only the host %s namespace can be shared", key, name, key)
+			}
+			return false
+		case !allow:
+			dropped.Warnf("Ignoring %s: host of service %q, use --allow-host-namespaces to share the host namespace", key, name)
+			return false
+		}
+		return true
+	}
+	return isHost("pid", service.Pid), isHost("ipc", service.Ipc), isHost("network_mode", service.NetworkMode)
+}
+
 // ConfigTmpfs configure the tmpfs.
+// The size option of the tmpfs, as in /run:size=64m, limits the size of its volume.
 func (k *Kubernetes) ConfigTmpfs(name string, service kobject.ServiceConfig) ([]api.VolumeMount, []api.Volume) {
 	//initializing volumemounts and volumes
 	volumeMounts := []api.VolumeMount{}
@@ -817,8 +1270,8 @@ func (k *Kubernetes) ConfigTmpfs(name string, service kobject.ServiceConfig) ([]
 
 	for index, volume := range service.TmpFs {
 		//naming volumes if multiple tmpfs are provided
-		volumeName := fmt.Sprintf("%s-tmpfs%d", name, index)
-		volume = strings.Split(volume, ":")[0]
+		volumeName := transformer.TruncateName(fmt.Sprintf("%s-tmpfs%d", name, index), transformer.MaxNameLength)
+		volume, options, _ := strings.Cut(volume, ":")
 		// create a new volume mount object and append to list
 		volMount := api.VolumeMount{
 			Name:      volumeName,
@@ -828,6 +1281,18 @@ func (k *Kubernetes) ConfigTmpfs(name string, service kobject.ServiceConfig) ([]
 
 		//create tmpfs specific empty volumes
 		volSource := k.ConfigEmptyVolumeSource("tmpfs")
+		for _, option := range strings.Split(options, ",") {
+			size, ok := strings.CutPrefix(option, "size=")
+			if !ok {
+				continue
+			}
+			bytes, err := units.RAMInBytes(size)
+			if err != nil {
+				dropped.Warnf("Ignoring the size %q of the tmpfs %s of service %s: %v", size, volume, name, err)
+				continue
+			}
+			volSource.EmptyDir.SizeLimit = resource.NewQuantity(bytes, resource.BinarySI)
+		}
 
 		// create a new volume object using the volsource and add to list
 		vol := api.Volume{
@@ -839,6 +1304,52 @@ func (k *Kubernetes) ConfigTmpfs(name string, service kobject.ServiceConfig) ([]
 	return volumeMounts, volumes
 }
 
+// defaultWritablePaths are the paths commonly written by the containers, whatever their image
+var defaultWritablePaths = []string{"/tmp", "/var/run"}
+
+// ConfigWritablePaths configures the emptyDir volumes of the paths written by the containers whose root
+// filesystem is read only, the default ones and the ones of the kompose.volume.writable-paths label,
+// unless they are already mounted.
+func (k *Kubernetes) ConfigWritablePaths(name string, service kobject.ServiceConfig, mounts []api.VolumeMount) ([]api.VolumeMount, []api.Volume) {
+	var volumeMounts []api.VolumeMount
+	var volumes []api.Volume
+	if !service.ReadOnly {
+		return volumeMounts, volumes
+	}
+
+	paths := append([]string{}, defaultWritablePaths...)
+	if value, ok := service.Labels[compose.LabelVolumeWritablePaths]; ok {
+		for _, path := range strings.Split(value, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	mounted := map[string]bool{}
+	for _, mount := range mounts {
+		mounted[filepath.Clean(mount.MountPath)] = true
+	}
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if mounted[path] {
+			continue
+		}
+		mounted[path] = true
+
+		volumeName := transformer.TruncateName(fmt.Sprintf("%s-writable%d", name, len(volumes)), transformer.MaxNameLength)
+		volumeMounts = append(volumeMounts, api.VolumeMount{
+			Name:      volumeName,
+			MountPath: path,
+		})
+		volumes = append(volumes, api.Volume{
+			Name:         volumeName,
+			VolumeSource: *k.ConfigEmptyVolumeSource("volume"),
+		})
+	}
+	return volumeMounts, volumes
+}
+
 // ConfigSecretVolumes config volumes from secret.
 // Link: https://docs.docker.com/compose/compose-file/#secrets
 // In kubernetes' Secret resource, it has a data structure like a map[string]bytes, every key will act like the file name
@@ -851,12 +1362,7 @@ func (k *Kubernetes) ConfigSecretVolumes(name string, service kobject.ServiceCon
 	if len(service.Secrets) > 0 {
 		for _, secretConfig := range service.Secrets {
 			secretConfig := reformatSecretConfigUnderscoreWithDash(secretConfig)
-			if secretConfig.UID != "" {
-				log.Warnf("Ignore pid in secrets for service: %s", name)
-			}
-			if secretConfig.GID != "" {
-				log.Warnf("Ignore gid in secrets for service: %s", name)
-			}
+			warnFileOwner("secret", secretConfig.Source, name, secretConfig.UID, secretConfig.GID, service.FsGroup)
 
 			var secretItemPath, secretMountPath, secretSubPath string
 			if k.Opt.SecretsAsFiles {
@@ -878,6 +1384,7 @@ func (k *Kubernetes) ConfigSecretVolumes(name string, service kobject.ServiceCon
 			if secretConfig.Mode != nil {
 				mode := cast.ToInt32(*secretConfig.Mode)
 				volSource.Secret.DefaultMode = &mode
+				volSource.Secret.Items[0].Mode = &mode
 			}
 
 			vol := api.Volume{
@@ -897,6 +1404,17 @@ func (k *Kubernetes) ConfigSecretVolumes(name string, service kobject.ServiceCon
 	return volumeMounts, volumes
 }
 
+// warnFileOwner warns about the uid and gid of a secret or config mount, Kubernetes cannot set the owner of
+// the projected files. The group is only honored when it matches the fsGroup of the pod.
+func warnFileOwner(kind string, source string, name string, uid string, gid string, fsGroup int64) {
+	if uid != "" {
+		dropped.Warnf("Ignoring uid %s of %s %s in service %s, the owner of the mounted files cannot be set in Kubernetes", uid, kind, source, name)
+	}
+	if gid != "" && gid != strconv.FormatInt(fsGroup, 10) {
+		dropped.Warnf("Ignoring gid %s of %s %s in service %s, set the label %s to change the group of the mounted files", gid, kind, source, name, compose.LabelSecurityContextFsGroup)
+	}
+}
+
 func (k *Kubernetes) getSecretPaths(secretConfig types.ServiceSecretConfig) (secretItemPath, secretMountPath, secretSubPath string) {
 	// Default secretConfig.Target to secretConfig.Source, just in case user was using short secret syntax or
 	// otherwise did not define a specific target
@@ -989,7 +1507,7 @@ func (k *Kubernetes) ConfigVolumes(name string, service kobject.ServiceConfig) (
 	}
 
 	// Override volume type if specified in service labels.
-	if vt, ok := service.Labels["kompose.volume.type"]; ok {
+	if vt, ok := service.Labels[compose.LabelVolumeType]; ok {
 		if _, okk := ValidVolumeSet[vt]; !okk {
 			return nil, nil, nil, nil, fmt.Errorf("invalid volume type %s specified in label 'kompose.volume.type' in service %s", vt, service.Name)
 		}
@@ -1037,6 +1555,8 @@ func (k *Kubernetes) ConfigVolumes(name string, service kobject.ServiceConfig) (
 		} else {
 			volumeName = volume.VolumeName
 		}
+		// the volume name is the name of its claim or ConfigMap too
+		volumeName = transformer.TruncateName(volumeName, transformer.MaxNameLength)
 		volMount := api.VolumeMount{
 			Name:      volumeName,
 			ReadOnly:  readonly,
@@ -1057,16 +1577,29 @@ func (k *Kubernetes) ConfigVolumes(name string, service kobject.ServiceConfig) (
 			volsource = source
 		} else if useConfigMap {
 			log.Debugf("Use configmap volume")
-			cm, err := k.IntiConfigMapFromFileOrDir(name, volumeName, volume.Host, service)
+			cm, items, err := k.IntiConfigMapFromFileOrDir(name, volumeName, volume.Host, service)
 			if err != nil {
 				return nil, nil, nil, nil, err
 			}
-			cms = append(cms, cm)
-			volsource = k.ConfigConfigMapVolumeSource(volumeName, volume.Container, cm)
+			parts, err := splitConfigMap(cm)
+			if err != nil {
+				return nil, nil, nil, nil, err
+			}
+			cms = append(cms, parts...)
+			if len(parts) > 1 {
+				volsource = k.ConfigProjectedConfigMapVolumeSource(parts, items)
+			} else {
+				volsource = k.ConfigConfigMapVolumeSource(volumeName, volume.Container, cm)
+				if items != nil {
+					volsource.ConfigMap.Items = items
+				}
+			}
 
 			if useSubPathMount(cm) {
 				volMount.SubPath = volsource.ConfigMap.Items[0].Path
 			}
+		} else if source := k.ConfigDriverVolumeSource(volume, readonly); source != nil {
+			volsource = source
 		} else {
 			volsource = k.ConfigPVCVolumeSource(volumeName, readonly)
 			if volume.VFrom == "" {
@@ -1075,19 +1608,29 @@ func (k *Kubernetes) ConfigVolumes(name string, service kobject.ServiceConfig) (
 				if k.Opt.PVCRequestSize != "" {
 					defaultSize = k.Opt.PVCRequestSize
 				}
+				for key, value := range service.Labels {
+					if key == compose.LabelVolumeSize {
+						defaultSize = value
+					} else if key == compose.LabelVolumeStorageClassName {
+						storageClassName = value
+					}
+				}
+				// the labels of the top level volume win over the ones of the service
 				if len(volume.PVCSize) > 0 {
 					defaultSize = volume.PVCSize
-				} else {
-					for key, value := range service.Labels {
-						if key == "kompose.volume.size" {
-							defaultSize = value
-						} else if key == "kompose.volume.storage-class-name" {
-							storageClassName = value
-						}
+				}
+				if len(volume.StorageClass) > 0 {
+					storageClassName = volume.StorageClass
+				}
+				mode := volume.Mode
+				if len(volume.AccessMode) > 0 {
+					if _, ok := validAccessModes[strings.ToLower(volume.AccessMode)]; !ok {
+						return nil, nil, nil, nil, fmt.Errorf("invalid access mode %s specified in label '%s' of volume %s", volume.AccessMode, compose.LabelVolumeAccessMode, volume.VolumeName)
 					}
+					mode = volume.AccessMode
 				}
 
-				createdPVC, err := k.CreatePVC(volumeName, volume.Mode, defaultSize, volume.SelectorValue, storageClassName)
+				createdPVC, err := k.CreatePVC(volumeName, mode, defaultSize, volume.SelectorValue, storageClassName)
 
 				if err != nil {
 					return nil, nil, nil, nil, errors.Wrap(err, "k.CreatePVC failed")
@@ -1109,7 +1652,7 @@ func (k *Kubernetes) ConfigVolumes(name string, service kobject.ServiceConfig) (
 		volumes = append(volumes, vol)
 
 		if len(volume.Host) > 0 && (!useHostPath && !useConfigMap) {
-			log.Warningf("Volume mount on the host %q isn't supported - ignoring path on the host", volume.Host)
+			dropped.Warnf("Volume mount on the host %q isn't supported - ignoring path on the host", volume.Host)
 		}
 	}
 
@@ -1158,9 +1701,31 @@ func (k *Kubernetes) ConfigConfigMapVolumeSource(cmName string, targetPath strin
 	}
 }
 
+// ConfigProjectedConfigMapVolumeSource config a projected volume source merging several configmaps,
+// the items are split between the configmaps holding their key
+func (k *Kubernetes) ConfigProjectedConfigMapVolumeSource(cms []*api.ConfigMap, items []api.KeyToPath) *api.VolumeSource {
+	s := api.ProjectedVolumeSource{}
+	for _, cm := range cms {
+		projection := &api.ConfigMapProjection{
+			LocalObjectReference: api.LocalObjectReference{Name: cm.Name},
+		}
+		for _, item := range items {
+			_, inData := cm.Data[item.Key]
+			_, inBinaryData := cm.BinaryData[item.Key]
+			if inData || inBinaryData {
+				projection.Items = append(projection.Items, item)
+			}
+		}
+		s.Sources = append(s.Sources, api.VolumeProjection{ConfigMap: projection})
+	}
+	return &api.VolumeSource{
+		Projected: &s,
+	}
+}
+
 // ConfigHostPathVolumeSource is a helper function to create a HostPath api.VolumeSource
 func (k *Kubernetes) ConfigHostPathVolumeSource(path string) (*api.VolumeSource, error) {
-	dir, err := transformer.GetComposeFileDir(k.Opt.InputFiles)
+	dir, err := transformer.GetWorkingDir(k.Opt)
 	if err != nil {
 		return nil, err
 	}
@@ -1184,6 +1749,48 @@ func (k *Kubernetes) ConfigPVCVolumeSource(name string, readonly bool) *api.Volu
 	}
 }
 
+// ConfigDriverVolumeSource configures the inline volume of the volume created by a driver, instead of
+// a PVC which would never be bound: a CSI volume with the kompose.volume.csi-driver label, with the
+// driver_opts as attributes, or an NFS volume with the nfs driver_opts of the local driver.
+// It returns nil for the other volumes.
+func (k *Kubernetes) ConfigDriverVolumeSource(volume kobject.Volumes, readonly bool) *api.VolumeSource {
+	if volume.CSIDriver != "" {
+		return &api.VolumeSource{
+			CSI: &api.CSIVolumeSource{
+				Driver:           volume.CSIDriver,
+				ReadOnly:         &readonly,
+				VolumeAttributes: volume.DriverOpts,
+			},
+		}
+	}
+
+	switch volume.DriverOpts["type"] {
+	case "nfs", "nfs4":
+	default:
+		return nil
+	}
+	// the server is the addr option, or the host of the device as server:/path
+	server, path, _ := strings.Cut(volume.DriverOpts["device"], ":")
+	for _, option := range strings.Split(volume.DriverOpts["o"], ",") {
+		if addr, ok := strings.CutPrefix(option, "addr="); ok {
+			server = addr
+		} else if option == "ro" {
+			readonly = true
+		}
+	}
+	if server == "" || path == "" {
+		dropped.Warnf("Ignoring the nfs driver_opts of volume %s, the server and the device (as :/path) are required", volume.VolumeName)
+		return nil
+	}
+	return &api.VolumeSource{
+		NFS: &api.NFSVolumeSource{
+			Server:   server,
+			Path:     path,
+			ReadOnly: readonly,
+		},
+	}
+}
+
 // ConfigEnvs configures the environment variables.
 func ConfigEnvs(service kobject.ServiceConfig, opt kobject.ConvertOptions) ([]api.EnvVar, []api.EnvFromSource, error) {
 	envs := transformer.EnvSort{}
@@ -1195,36 +1802,62 @@ func ConfigEnvs(service kobject.ServiceConfig, opt kobject.ConvertOptions) ([]ap
 	if len(service.EnvFile) > 0 {
 		// Load each env_file
 		for _, file := range service.EnvFile {
-			envName := FormatEnvName(file, service.Name)
-
-			envsFrom = append(envsFrom, api.EnvFromSource{
-				ConfigMapRef: &api.ConfigMapEnvSource{
-					LocalObjectReference: api.LocalObjectReference{
-						Name: envName,
-					},
-				},
-			})
-
 			// Load environment variables from file
-			workDir, err := transformer.GetComposeFileDir(opt.InputFiles)
+			workDir, err := transformer.GetWorkingDir(opt)
 			if err != nil {
-				log.Fatalf("Unable to get compose file directory: %s", err)
+				return envs, envsFrom, errors.Wrap(err, "Unable to get compose file directory")
 			}
-			envLoad, err := GetEnvsFromFile(filepath.Join(workDir, file))
+
+			// an oversized env_file is split in several ConfigMaps, reference all of them
+			data, err := LoadEnvFiles(filepath.Join(workDir, file), envLookup(service))
 			if err != nil {
 				return envs, envsFrom, errors.Wrap(err, "Unable to read env_file")
 			}
-
+			envName := EnvConfigMapName(file, service.Name, data, opt)
+			parts, err := splitConfigMap(&api.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: envName}, Data: data})
+			if err != nil {
+				return envs, envsFrom, err
+			}
+			for _, part := range parts {
+				envsFrom = append(envsFrom, api.EnvFromSource{
+					ConfigMapRef: &api.ConfigMapEnvSource{
+						LocalObjectReference: api.LocalObjectReference{
+							Name: part.Name,
+						},
+					},
+				})
+			}
 			// Mark environment variable source to env file
-			for k := range envLoad {
+			for k := range data {
 				keysFromEnvFile[k] = true
 			}
 		}
 	}
 
+	secretEnvs := map[string]*api.SecretKeySelector{}
+	if value, ok := service.Labels[compose.LabelEnvFromSecret]; ok {
+		var err error
+		if secretEnvs, err = parseEnvFromSecret(value); err != nil {
+			return envs, envsFrom, errors.Wrapf(err, "invalid label %s in service %s", compose.LabelEnvFromSecret, service.Name)
+		}
+	}
+	for name, selector := range secretEnvs {
+		envs = append(envs, api.EnvVar{
+			Name:      name,
+			ValueFrom: &api.EnvVarSource{SecretKeyRef: selector},
+		})
+	}
+
 	// Load up the environment variables
 	for _, v := range service.Environment {
-		if !keysFromEnvFile[v.Name] {
+		if !keysFromEnvFile[v.Name] && secretEnvs[v.Name] == nil {
+			if fieldPath, ok := downwardAPIFields[strings.TrimSpace(v.Value)]; ok {
+				envs = append(envs, api.EnvVar{
+					Name:      v.Name,
+					ValueFrom: &api.EnvVarSource{FieldRef: &api.ObjectFieldSelector{FieldPath: fieldPath}},
+				})
+				continue
+			}
 			if strings.Contains(v.Value, "run/secrets") {
 				v.Value = FormatResourceName(v.Value)
 			}
@@ -1242,6 +1875,28 @@ func ConfigEnvs(service kobject.ServiceConfig, opt kobject.ConvertOptions) ([]ap
 	return envs, envsFrom, nil
 }
 
+// parseEnvFromSecret parses the comma separated VAR=secret/key list of the kompose.env.from-secret label
+// into the keys of the existing secrets the variables are read from
+func parseEnvFromSecret(value string) (map[string]*api.SecretKeySelector, error) {
+	selectors := map[string]*api.SecretKeySelector{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, ref, ok := strings.Cut(item, "=")
+		secret, key, okRef := strings.Cut(ref, "/")
+		if !ok || !okRef || name == "" || secret == "" || key == "" {
+			return nil, errors.Errorf("%q is not in the VAR=secret/key format", item)
+		}
+		selectors[name] = &api.SecretKeySelector{
+			LocalObjectReference: api.LocalObjectReference{Name: secret},
+			Key:                  key,
+		}
+	}
+	return selectors, nil
+}
+
 // ConfigAffinity configures the Affinity.
 func ConfigAffinity(service kobject.ServiceConfig) *api.Affinity {
 	var affinity *api.Affinity
@@ -1265,6 +1920,39 @@ func ConfigAffinity(service kobject.ServiceConfig) *api.Affinity {
 	return affinity
 }
 
+// ConfigPodAntiAffinity configures the PodAntiAffinity keeping the pods of the workload name on different
+// nodes, or on different values of the topology key, with the anti-affinity of the service or of --anti-affinity.
+func ConfigPodAntiAffinity(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) *api.PodAntiAffinity {
+	antiAffinity := service.AntiAffinity
+	if antiAffinity == "" {
+		antiAffinity = opt.AntiAffinity
+	}
+	topologyKey := service.AntiAffinityTopologyKey
+	if topologyKey == "" {
+		topologyKey = "kubernetes.io/hostname"
+	}
+
+	term := api.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: transformer.ConfigLabels(name),
+		},
+		TopologyKey: topologyKey,
+	}
+	switch antiAffinity {
+	case "required":
+		return &api.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []api.PodAffinityTerm{term},
+		}
+	case "preferred":
+		return &api.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []api.WeightedPodAffinityTerm{
+				{Weight: 100, PodAffinityTerm: term},
+			},
+		}
+	}
+	return nil
+}
+
 // ConfigTopologySpreadConstraints configures the TopologySpreadConstraints.
 func ConfigTopologySpreadConstraints(service kobject.ServiceConfig) []api.TopologySpreadConstraint {
 	preferencesLen := len(service.Placement.Preferences)
@@ -1298,11 +1986,17 @@ func configConstrains(constrains map[string]string, operator api.NodeSelectorOpe
 	if constraintsLen == 0 {
 		return rs
 	}
-	for k, v := range constrains {
+	// the requirements are sorted by key, so that the output doesn't change between runs
+	keys := make([]string, 0, constraintsLen)
+	for k := range constrains {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
 		r := api.NodeSelectorRequirement{
 			Key:      k,
 			Operator: operator,
-			Values:   []string{v},
+			Values:   []string{constrains[k]},
 		}
 		rs = append(rs, r)
 	}
@@ -1310,14 +2004,14 @@ func configConstrains(constrains map[string]string, operator api.NodeSelectorOpe
 }
 
 // CreateWorkloadAndConfigMapObjects generates a Kubernetes artifact for each input type service
-func (k *Kubernetes) CreateWorkloadAndConfigMapObjects(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) []runtime.Object {
+func (k *Kubernetes) CreateWorkloadAndConfigMapObjects(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) ([]runtime.Object, error) {
 	var objects []runtime.Object
 	var replica int
 
-	if opt.IsReplicaSetFlag || service.Replicas == 0 {
+	if opt.IsReplicaSetFlag || service.Replicas == nil {
 		replica = opt.Replicas
 	} else {
-		replica = service.Replicas
+		replica = *service.Replicas
 	}
 
 	// Check to see if Compose v3 Deploy.Mode has been set to "global"
@@ -1326,7 +2020,7 @@ func (k *Kubernetes) CreateWorkloadAndConfigMapObjects(name string, service kobj
 		if opt.Controller == "" {
 			opt.CreateD = false
 			opt.CreateDS = true
-		} else if opt.Controller != "daemonset" {
+		} else if opt.Controller != DaemonSetController {
 			log.Warnf("Global deploy mode service is best converted to daemonset, now it convert to %s", opt.Controller)
 		}
 	}
@@ -1339,11 +2033,14 @@ func (k *Kubernetes) CreateWorkloadAndConfigMapObjects(name string, service kobj
 		if opt.Controller != "" {
 			log.Warnf("Use label %s type %s for service %s, ignore %s flags", compose.LabelControllerType, val, name, opt.Controller)
 		}
-		opt.Controller = val
+		opt.Controller = strings.ToLower(val)
 	}
 
 	if len(service.Configs) > 0 {
-		objects = k.createConfigMapFromComposeConfig(name, service, objects)
+		var err error
+		if objects, err = k.createConfigMapFromComposeConfig(name, service, objects); err != nil {
+			return nil, err
+		}
 	}
 
 	if opt.CreateD || opt.Controller == DeploymentController {
@@ -1357,13 +2054,19 @@ func (k *Kubernetes) CreateWorkloadAndConfigMapObjects(name string, service kobj
 	if opt.Controller == StatefulStateController {
 		objects = append(objects, k.InitSS(name, service, replica))
 	}
+	if opt.Controller != StatefulStateController && service.StatefulSet != (kobject.StatefulSetConfig{}) {
+		dropped.Warnf("Ignoring the kompose.statefulset labels of service %q, it isn't converted to a StatefulSet", name)
+	}
 
-	envConfigMaps := k.PargeEnvFiletoConfigMaps(name, service, opt)
+	envConfigMaps, err := k.PargeEnvFiletoConfigMaps(name, service, opt)
+	if err != nil {
+		return nil, err
+	}
 	objects = append(objects, envConfigMaps...)
-	return objects
+	return objects, nil
 }
 
-func (k *Kubernetes) createConfigMapFromComposeConfig(name string, service kobject.ServiceConfig, objects []runtime.Object) []runtime.Object {
+func (k *Kubernetes) createConfigMapFromComposeConfig(name string, service kobject.ServiceConfig, objects []runtime.Object) ([]runtime.Object, error) {
 	for _, config := range service.Configs {
 		currentConfigName := config.Source
 		currentConfigObj := service.ConfigsMetaData[currentConfigName]
@@ -1375,7 +2078,10 @@ func (k *Kubernetes) createConfigMapFromComposeConfig(name string, service kobje
 		}
 		if currentConfigObj.File != "" {
 			currentFileName := currentConfigObj.File
-			configMap := k.InitConfigMapFromFile(name, service, currentFileName)
+			configMap, err := k.InitConfigMapFromFile(name, service, currentFileName)
+			if err != nil {
+				return nil, err
+			}
 			objects = append(objects, configMap)
 		} else if currentConfigObj.Content != "" {
 			content := currentConfigObj.Content
@@ -1383,12 +2089,12 @@ func (k *Kubernetes) createConfigMapFromComposeConfig(name string, service kobje
 			objects = append(objects, configMap)
 		} else if currentConfigObj.Environment != "" {
 			// TODO: Add support for environment variables in configmaps
-			log.Warnf("Environment variables in configmaps are not supported yet")
+			dropped.Warnf("Environment variables in configmaps are not supported yet")
 		} else {
 			log.Warnf("Configmap %s is empty", currentConfigName)
 		}
 	}
-	return objects
+	return objects, nil
 }
 
 // InitPod initializes Kubernetes Pod object
@@ -1408,9 +2114,43 @@ func (k *Kubernetes) InitPod(name string, service kobject.ServiceConfig) *api.Po
 	return &pod
 }
 
-// CreateNetworkPolicy initializes Network policy
-func (k *Kubernetes) CreateNetworkPolicy(networkName string) (*networkingv1.NetworkPolicy, error) {
+// CreateNetworkPolicy initializes Network policy, the pods of the network can reach each other and the cluster DNS.
+// Internal networks have no access to the outside.
+func (k *Kubernetes) CreateNetworkPolicy(networkName string, internal bool) (*networkingv1.NetworkPolicy, error) {
 	str := "true"
+	peers := []networkingv1.NetworkPolicyPeer{{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"io.kompose.network/" + networkName: str},
+		},
+	}}
+	udp, tcp := api.ProtocolUDP, api.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+	egress := []networkingv1.NetworkPolicyEgressRule{
+		{To: peers},
+		{
+			To: []networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"},
+				},
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"k8s-app": "kube-dns"},
+				},
+			}},
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		},
+	}
+	if !internal {
+		egress = append(egress, networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{
+				{IPBlock: &networkingv1.IPBlock{CIDR: "0.0.0.0/0"}},
+				{IPBlock: &networkingv1.IPBlock{CIDR: "::/0"}},
+			},
+		})
+	}
+
 	np := &networkingv1.NetworkPolicy{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "NetworkPolicy",
@@ -1425,19 +2165,38 @@ func (k *Kubernetes) CreateNetworkPolicy(networkName string) (*networkingv1.Netw
 				MatchLabels: map[string]string{"io.kompose.network/" + networkName: str},
 			},
 			Ingress: []networkingv1.NetworkPolicyIngressRule{{
-				From: []networkingv1.NetworkPolicyPeer{{
-					PodSelector: &metav1.LabelSelector{
-						MatchLabels: map[string]string{"io.kompose.network/" + networkName: str},
-					},
-				}},
+				From: peers,
 			}},
+			Egress:      egress,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
 		},
 	}
 
 	return np, nil
 }
 
-func buildServiceImage(opt kobject.ConvertOptions, service kobject.ServiceConfig, name string) error {
+// CreateDefaultDenyNetworkPolicy initializes the Network policy denying all the traffic of the pods of a namespace,
+// only the traffic allowed by the policies of the networks is then accepted
+func (k *Kubernetes) CreateDefaultDenyNetworkPolicy(namespace string) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default-deny",
+			Namespace: namespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+}
+
+// buildServiceImage builds and pushes the image of the service with --build=local, the image of the service
+// is updated with the reference of the pushed image
+func buildServiceImage(opt kobject.ConvertOptions, service *kobject.ServiceConfig, name string) error {
 	// Must build the images before conversion (got to add service.Image in case 'image' key isn't provided
 	// Check that --build is set to true
 	// Check to see if there is an InputFile (required!) before we build the container
@@ -1477,36 +2236,57 @@ func buildServiceImage(opt kobject.ConvertOptions, service kobject.ServiceConfig
 		log.Infof("Build key detected. Attempting to build image '%s'", service.Image)
 
 		// Build the image!
-		err := transformer.BuildDockerImage(service, name)
+		err := transformer.BuildDockerImage(*service, name)
 		if err != nil {
 			return errors.Wrapf(err, "Unable to build Docker image for service %v", name)
 		}
 
 		// Push the built image to the repo!
-		err = transformer.PushDockerImageWithOpt(service, name, opt)
+		image, err := transformer.PushDockerImageWithOpt(*service, name, opt)
 		if err != nil {
 			return errors.Wrapf(err, "Unable to push Docker image for service %v", name)
 		}
+		if image != service.Image {
+			log.Infof("Service %q uses the pushed image '%s'", name, image)
+			service.Image = image
+		}
 	}
 	return nil
 }
 
-func (k *Kubernetes) configKubeServiceAndIngressForService(service kobject.ServiceConfig, name string, objects *[]runtime.Object) {
+func (k *Kubernetes) configKubeServiceAndIngressForService(service kobject.ServiceConfig, name string, opt kobject.ConvertOptions, objects *[]runtime.Object) error {
+	if service.ServiceType != "LoadBalancer" && (service.LoadBalancerIP != "" || service.LoadBalancerClass != "") {
+		dropped.Warnf("Ignoring the load balancer IP and class of service %q, it isn't of type LoadBalancer", name)
+	}
 	if k.PortsExist(service) {
 		if service.ServiceType == "LoadBalancer" {
 			svcs := k.CreateLBService(name, service)
 			for _, svc := range svcs {
-				svc.Spec.ExternalTrafficPolicy = api.ServiceExternalTrafficPolicyType(service.ServiceExternalTrafficPolicy)
 				*objects = append(*objects, svc)
 			}
 			if len(svcs) > 1 {
 				log.Warningf("Create multiple service to avoid using mixed protocol in the same service when it's loadbalancer type")
 			}
+			for _, route := range k.initTraefikIngressRoutes(name, service, svcs[0]) {
+				*objects = append(*objects, route)
+			}
 		} else {
-			svc := k.CreateService(name, service)
+			svc, err := k.CreateService(name, service)
+			if err != nil {
+				return err
+			}
 			*objects = append(*objects, svc)
 			if service.ExposeService != "" {
-				*objects = append(*objects, k.initIngress(name, service, svc.Spec.Ports[0].Port))
+				if opt.ExposeController == GatewayAPIExposeController {
+					for _, route := range k.initHTTPRoutes(name, service, svc.Spec.Ports[0].Port, opt) {
+						*objects = append(*objects, route)
+					}
+				} else {
+					*objects = append(*objects, k.initIngress(name, service, svc.Spec.Ports[0]))
+				}
+			}
+			for _, route := range k.initTraefikIngressRoutes(name, service, svc) {
+				*objects = append(*objects, route)
 			}
 			if service.ServiceExternalTrafficPolicy != "" && svc.Spec.Type != api.ServiceTypeNodePort {
 				log.Warningf("External Traffic Policy is ignored for the service %v of type %v", name, service.ServiceType)
@@ -1523,13 +2303,21 @@ func (k *Kubernetes) configKubeServiceAndIngressForService(service kobject.Servi
 			log.Warnf("Service %q won't be created because 'ports' is not specified", service.Name)
 		}
 	}
+	aliases, err := k.CreateAliasServices(name, service)
+	if err != nil {
+		return err
+	}
+	for _, svc := range aliases {
+		*objects = append(*objects, svc)
+	}
+	return nil
 }
 
-func (k *Kubernetes) configNetworkPolicyForService(service kobject.ServiceConfig, name string, objects *[]runtime.Object) error {
+func (k *Kubernetes) configNetworkPolicyForService(service kobject.ServiceConfig, name string, internalNetworks map[string]bool, objects *[]runtime.Object) error {
 	if len(service.Network) > 0 {
 		for _, net := range service.Network {
 			log.Infof("Network %s is detected at Source, shall be converted to equivalent NetworkPolicy at Destination", net)
-			np, err := k.CreateNetworkPolicy(net)
+			np, err := k.CreateNetworkPolicy(net, internalNetworks[net])
 
 			if err != nil {
 				return errors.Wrapf(err, "Unable to create Network Policy for network %v for service %v", net, name)
@@ -1547,16 +2335,14 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 	var allobjects []runtime.Object
 
 	if komposeObject.Secrets != nil {
-		secrets, err := k.CreateSecrets(komposeObject)
+		secrets, err := k.SecretObjects(komposeObject, opt)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Unable to create Secret resource")
 		}
-		for _, item := range secrets {
-			allobjects = append(allobjects, item)
-		}
+		allobjects = append(allobjects, secrets...)
 	}
 
-	if komposeObject.Namespace != "" {
+	if komposeObject.Namespace != "" && opt.CreateNamespace {
 		ns := transformer.CreateNamespace(komposeObject.Namespace)
 		allobjects = append(allobjects, ns)
 	}
@@ -1564,6 +2350,9 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 	if opt.ServiceGroupMode != "" {
 		log.Debugf("Service group mode is: %s", opt.ServiceGroupMode)
 		komposeObjectToServiceConfigGroupMapping := KomposeObjectToServiceConfigGroupMapping(&komposeObject, opt)
+		if err := CheckGroupPortConflicts(komposeObjectToServiceConfigGroupMapping); err != nil {
+			return nil, err
+		}
 		sortedGroupMappingKeys := SortedKeys(komposeObjectToServiceConfigGroupMapping)
 		for _, group := range sortedGroupMappingKeys {
 			groupMapping := komposeObjectToServiceConfigGroupMapping[group]
@@ -1587,30 +2376,31 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 			}
 
 			// added a container
-			// ports conflict check between services
-			portsUses := map[string]bool{}
-
 			for _, service := range groupMapping {
-				// first do ports check
-				ports := ConfigPorts(service)
-				for _, port := range ports {
-					key := string(port.ContainerPort) + string(port.Protocol)
-					if portsUses[key] {
-						return nil, fmt.Errorf("detect ports conflict when group services, service: %s, port: %d", service.Name, port.ContainerPort)
-					}
-					portsUses[key] = true
-				}
-
 				log.Infof("Group Service %s to [%s]", service.Name, groupName)
+				if len(service.Sidecars) > 0 {
+					dropped.Warnf("Ignoring the sidecars of service %q, the services of a group are already containers of the same pod", service.Name)
+				}
 				service.WithKomposeAnnotation = opt.WithKomposeAnnotation
-				podSpec.Append(AddContainer(service, opt))
+				if err := buildServiceImage(opt, &service, service.Name); err != nil {
+					return nil, err
+				}
+				if err := podSpec.Append(AddContainer(service, opt)).Err(); err != nil {
+					return nil, err
+				}
 
-				if err := buildServiceImage(opt, service, service.Name); err != nil {
+				if err := k.configKanikoBuild(service.Name, service, opt, &allobjects); err != nil {
 					return nil, err
 				}
 				// override..
-				objects = append(objects, k.CreateWorkloadAndConfigMapObjects(groupName, service, opt)...)
-				k.configKubeServiceAndIngressForService(service, groupName, &objects)
+				workloads, err := k.CreateWorkloadAndConfigMapObjects(groupName, service, opt)
+				if err != nil {
+					return nil, err
+				}
+				objects = append(objects, workloads...)
+				if err := k.configKubeServiceAndIngressForService(service, groupName, opt, &objects); err != nil {
+					return nil, err
+				}
 
 				// Configure the container volumes.
 				volumesMount, volumes, pvc, cms, err := k.ConfigVolumes(groupName, service)
@@ -1623,6 +2413,10 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 					volumes = append(volumes, TmpVolumes...)
 					volumesMount = append(volumesMount, TmpVolumesMount...)
 				}
+				// Configure the writable paths of the read only root filesystem
+				writableVolumesMount, writableVolumes := k.ConfigWritablePaths(groupName, service, volumesMount)
+				volumes = append(volumes, writableVolumes...)
+				volumesMount = append(volumesMount, writableVolumesMount...)
 				podSpec.Append(
 					SetVolumeMounts(volumesMount),
 					SetVolumes(volumes),
@@ -1644,35 +2438,56 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 					ImagePullPolicy(groupName, service),
 					RestartPolicy(groupName, service),
 					SecurityContext(groupName, service),
+					Sysctls(groupName, service, opt),
 					HostName(service),
+					HostAliases(groupName, service),
+					DNSConfig(service),
+					HostNamespaces(groupName, service, opt),
 					DomainName(service),
 					ResourcesLimits(service),
 					ResourcesRequests(service),
 					TerminationGracePeriodSeconds(groupName, service),
 					TopologySpreadConstraints(service),
+					RuntimeClassName(service),
+					EnableServiceLinks(service, opt),
 				)
 
 				if serviceAccountName, ok := service.Labels[compose.LabelServiceAccountName]; ok {
 					podSpec.Append(ServiceAccountName(serviceAccountName))
 				}
+				if priorityClassName, ok := service.Labels[compose.LabelPriorityClass]; ok {
+					podSpec.Append(PriorityClassName(priorityClassName))
+				}
+				if err := podSpec.Err(); err != nil {
+					return nil, err
+				}
 
 				err = k.UpdateKubernetesObjectsMultipleContainers(groupName, service, &objects, podSpec, opt)
 				if err != nil {
 					return nil, errors.Wrap(err, "Error transforming Kubernetes objects")
 				}
+				if err = k.ConfigLoggingSidecar(service.Name, service, opt, &objects); err != nil {
+					return nil, err
+				}
 
 				if opt.GenerateNetworkPolicies {
-					if err = k.configNetworkPolicyForService(service, service.Name, &objects); err != nil {
+					if err = k.configNetworkPolicyForService(service, service.Name, komposeObject.InternalNetworks, &objects); err != nil {
 						return nil, err
 					}
 				}
+				if err = k.ConfigPriorityClass(service, &objects); err != nil {
+					return nil, err
+				}
 			}
 
 			allobjects = append(allobjects, objects...)
 		}
 	}
 	sortedKeys := SortedKeys(komposeObject.ServiceConfigs)
-	for _, name := range sortedKeys {
+	// the images are built and pushed one at a time, before transforming the services in parallel
+	services := make([]kobject.ServiceConfig, len(sortedKeys))
+	serviceObjects := make([][]runtime.Object, len(sortedKeys))
+	for i, name := range sortedKeys {
 		service := komposeObject.ServiceConfigs[name]
 
 		// if service belongs to a group, we already processed it
@@ -1680,51 +2495,53 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 			continue
 		}
 
-		var objects []runtime.Object
-
 		service.WithKomposeAnnotation = opt.WithKomposeAnnotation
 
-		if err := buildServiceImage(opt, service, name); err != nil {
+		if err := buildServiceImage(opt, &service, name); err != nil {
 			return nil, err
 		}
-
-		// Generate pod or cronjob and configmap objects
-		if (service.Restart == "no" || service.Restart == "on-failure") && !opt.IsPodController() {
-			if service.CronJobSchedule != "" {
-				log.Infof("Create kubernetes pod instead of pod controller due to restart policy: %s", service.Restart)
-				cronJob := k.InitCJ(name, service, service.CronJobSchedule, service.CronJobConcurrencyPolicy, service.CronJobBackoffLimit)
-				objects = append(objects, cronJob)
-			} else {
-				pod := k.InitPod(name, service)
-				objects = append(objects, pod)
-			}
-			envConfigMaps := k.PargeEnvFiletoConfigMaps(name, service, opt)
-			objects = append(objects, envConfigMaps...)
-		} else {
-			objects = k.CreateWorkloadAndConfigMapObjects(name, service, opt)
-		}
-		if opt.Controller == StatefulStateController {
-			service.ServiceType = "Headless"
+		if err := k.configKanikoBuild(name, service, opt, &serviceObjects[i]); err != nil {
+			return nil, err
 		}
-		k.configKubeServiceAndIngressForService(service, name, &objects)
-		err := k.UpdateKubernetesObjects(name, service, opt, &objects)
-		if err != nil {
-			return nil, errors.Wrap(err, "Error transforming Kubernetes objects")
+		services[i] = service
+	}
+
+	// the objects of the services are kept in the order of their names
+	err := forEachParallel(len(sortedKeys), func(i int) error {
+		if komposeObject.ServiceConfigs[sortedKeys[i]].InGroup {
+			return nil
 		}
-		if opt.GenerateNetworkPolicies {
-			if err := k.configNetworkPolicyForService(service, name, &objects); err != nil {
-				return nil, err
+		objects, err := k.transformService(sortedKeys[i], services[i], komposeObject.InternalNetworks, opt)
+		serviceObjects[i] = append(serviceObjects[i], objects...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, objects := range serviceObjects {
+		allobjects = append(allobjects, objects...)
+	}
+	// the traffic the network policies don't allow is denied once for the namespace, the other
+	// namespaces of --namespace-by get their own
+	if opt.GenerateNetworkPolicies {
+		allobjects = append(allobjects, k.CreateDefaultDenyNetworkPolicy(""))
+	}
+
+	if opt.ExposeController == GatewayAPIExposeController && opt.GatewayClassName != "" {
+		for _, obj := range allobjects {
+			if obj.GetObjectKind().GroupVersionKind().Kind == "HTTPRoute" {
+				allobjects = append(allobjects, k.initGateway(komposeObject, opt))
+				break
 			}
 		}
-		err = k.configHorizontalPodScaler(name, service, opt, &objects)
-		if err != nil {
-			return nil, errors.Wrap(err, "Error creating Kubernetes HPA")
-		}
-		allobjects = append(allobjects, objects...)
 	}
 
-	// sort all object so Services are first
-	k.SortServicesFirst(&allobjects)
+	if err := k.ApplyKubernetesPatches(&allobjects, komposeObject); err != nil {
+		return nil, err
+	}
+
+	// sort the objects in the order they are applied in
+	k.SortObjects(&allobjects)
 	k.RemoveDupObjects(&allobjects)
 
 	// Only append namespaces if --namespace has been passed in
@@ -1732,10 +2549,75 @@ func (k *Kubernetes) Transform(komposeObject kobject.KomposeObject, opt kobject.
 		transformer.AssignNamespaceToObjects(&allobjects, komposeObject.Namespace)
 	}
 	// k.FixWorkloadVersion(&allobjects)
+	if err := k.ConfigDependencyWaits(&allobjects, komposeObject.ServiceConfigs, opt); err != nil {
+		return nil, err
+	}
+	if err := k.ConfigSyncWaves(&allobjects, komposeObject.ServiceConfigs, opt); err != nil {
+		return nil, err
+	}
 	k.fixNetworkModeToService(&allobjects, komposeObject.ServiceConfigs)
+	if opt.NamespaceBy != "" {
+		k.SplitNamespaces(&allobjects, komposeObject, opt)
+	}
 	return allobjects, nil
 }
 
+// transformService creates the objects of a service which doesn't belong to a group
+func (k *Kubernetes) transformService(name string, service kobject.ServiceConfig, internalNetworks map[string]bool, opt kobject.ConvertOptions) ([]runtime.Object, error) {
+	var objects []runtime.Object
+
+	// Generate pod or cronjob and configmap objects
+	if (service.Restart == "no" || service.Restart == "on-failure") && !opt.IsPodController() {
+		if service.CronJobSchedule != "" {
+			log.Infof("Create kubernetes pod instead of pod controller due to restart policy: %s", service.Restart)
+			cronJob := k.InitCJ(name, service, service.CronJobSchedule, service.CronJobConcurrencyPolicy, GetJobBackoffLimit(service))
+			objects = append(objects, cronJob)
+		} else if NeedsJob(service) {
+			job := k.InitJ(name, service, GetJobBackoffLimit(service))
+			objects = append(objects, job)
+		} else {
+			pod := k.InitPod(name, service)
+			objects = append(objects, pod)
+		}
+		envConfigMaps, err := k.PargeEnvFiletoConfigMaps(name, service, opt)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, envConfigMaps...)
+	} else {
+		var err error
+		if objects, err = k.CreateWorkloadAndConfigMapObjects(name, service, opt); err != nil {
+			return nil, err
+		}
+	}
+	if opt.Controller == StatefulStateController {
+		service.ServiceType = "Headless"
+	}
+	if err := k.configKubeServiceAndIngressForService(service, name, opt, &objects); err != nil {
+		return nil, err
+	}
+	err := k.UpdateKubernetesObjects(name, service, opt, &objects)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error transforming Kubernetes objects")
+	}
+	if err := k.ConfigLoggingSidecar(name, service, opt, &objects); err != nil {
+		return nil, err
+	}
+	if opt.GenerateNetworkPolicies {
+		if err := k.configNetworkPolicyForService(service, name, internalNetworks, &objects); err != nil {
+			return nil, err
+		}
+	}
+	err = k.configHorizontalPodScaler(name, service, opt, &objects)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating Kubernetes HPA")
+	}
+	if err := k.ConfigPriorityClass(service, &objects); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
 // UpdateController updates the given object with the given pod template update function and ObjectMeta update function
 func (k *Kubernetes) UpdateController(obj runtime.Object, updateTemplate func(*api.PodTemplateSpec) error, updateMeta func(meta *metav1.ObjectMeta)) (err error) {
 	switch t := obj.(type) {
@@ -1763,6 +2645,12 @@ func (k *Kubernetes) UpdateController(obj runtime.Object, updateTemplate func(*a
 			return errors.Wrap(err, "updateTemplate failed")
 		}
 		updateMeta(&t.ObjectMeta)
+	case *batchv1.Job:
+		err = updateTemplate(&t.Spec.Template)
+		if err != nil {
+			return errors.Wrap(err, "updateTemplate failed")
+		}
+		updateMeta(&t.ObjectMeta)
 	case *deployapi.DeploymentConfig:
 		err = updateTemplate(t.Spec.Template)
 		if err != nil {
@@ -1786,6 +2674,43 @@ func (k *Kubernetes) UpdateController(obj runtime.Object, updateTemplate func(*a
 	return nil
 }
 
+// AddLabelsAndAnnotations sets the labels and annotations given on the command line on the
+// metadata of every object, and the pod ones on the pod templates only
+func AddLabelsAndAnnotations(objects []runtime.Object, opt kobject.ConvertOptions) error {
+	k := Kubernetes{Opt: opt}
+	for _, obj := range objects {
+		if accessor, err := meta.Accessor(obj); err == nil {
+			accessor.SetLabels(mergeStringMaps(accessor.GetLabels(), opt.AddLabels))
+			accessor.SetAnnotations(mergeStringMaps(accessor.GetAnnotations(), opt.AddAnnotations))
+		}
+		if len(opt.AddPodLabels) == 0 && len(opt.AddPodAnnotations) == 0 {
+			continue
+		}
+		err := k.UpdateController(obj, func(template *api.PodTemplateSpec) error {
+			template.Labels = mergeStringMaps(template.Labels, opt.AddPodLabels)
+			template.Annotations = mergeStringMaps(template.Annotations, opt.AddPodAnnotations)
+			return nil
+		}, func(*metav1.ObjectMeta) {})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mergeStringMaps(m map[string]string, values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return m
+	}
+	if m == nil {
+		m = make(map[string]string, len(values))
+	}
+	for key, value := range values {
+		m[key] = value
+	}
+	return m
+}
+
 // configHorizontalPodScaler create Hpa resource also append to the objects
 // first checks if the service labels contain any HPA labels using the searchHPAValues
 func (k *Kubernetes) configHorizontalPodScaler(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions, objects *[]runtime.Object) (err error) {
@@ -1794,23 +2719,93 @@ func (k *Kubernetes) configHorizontalPodScaler(name string, service kobject.Serv
 		return nil
 	}
 
+	// a daemonset runs one pod per node, its replicas can not be scaled
+	for _, obj := range *objects {
+		if ds, ok := obj.(*appsv1.DaemonSet); ok && ds.Name == name {
+			dropped.Warnf("Ignoring the autoscaling labels of service %q, it is converted to a daemonset", name)
+			return nil
+		}
+	}
+
 	hpa := createHPAResources(name, &service)
+	hpa.Labels = transformer.ConfigLabels(name)
+
+	// the replicas of the scaled deployment are left to the HorizontalPodAutoscaler, setting them
+	// would scale the deployment back at every apply
+	for _, obj := range *objects {
+		if d, ok := obj.(*appsv1.Deployment); ok && d.Name == name {
+			d.Spec.Replicas = nil
+		}
+	}
+
+	// autoscaling/v2 is served from Kubernetes 1.23, the older clusters serve autoscaling/v2beta2
+	if version, err := validate.ParseVersion(opt.KubernetesVersion); err == nil && version.Less(validate.Version{Major: 1, Minor: 23}) {
+		legacy, err := hpaV2beta2(hpa)
+		if err != nil {
+			return errors.Wrapf(err, "unable to convert the HorizontalPodAutoscaler of service %q to autoscaling/v2beta2", name)
+		}
+		*objects = append(*objects, legacy)
+		return nil
+	}
 	*objects = append(*objects, &hpa)
 	return nil
 }
 
-func (k *Kubernetes) PargeEnvFiletoConfigMaps(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) []runtime.Object {
+// ConfigPriorityClass generates the PriorityClass of the pods of the service when its value is given
+func (k *Kubernetes) ConfigPriorityClass(service kobject.ServiceConfig, objects *[]runtime.Object) error {
+	value, ok := service.Labels[compose.LabelPriorityClassValue]
+	if !ok {
+		return nil
+	}
+	name, ok := service.Labels[compose.LabelPriorityClass]
+	if !ok {
+		dropped.Warnf("Ignoring label %s of service %q, the priority class is set with %s", compose.LabelPriorityClassValue, service.Name, compose.LabelPriorityClass)
+		return nil
+	}
+	priority, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return errors.Wrapf(err, "invalid priority class value %q of service %q", value, service.Name)
+	}
+
+	*objects = append(*objects, &schedulingv1.PriorityClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PriorityClass",
+			APIVersion: "scheduling.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Value: int32(priority),
+	})
+	return nil
+}
+
+func (k *Kubernetes) PargeEnvFiletoConfigMaps(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) ([]runtime.Object, error) {
+	configMaps := make([]runtime.Object, 0)
+	for _, envFile := range service.EnvFile {
+		configMap, err := k.InitConfigMapForEnvWithLookup(name, opt, envFile, envLookup(service))
+		if err != nil {
+			return nil, err
+		}
+		parts, err := splitConfigMap(configMap)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to create ConfigMap for env file %s", envFile)
+		}
+		for _, part := range parts {
+			configMaps = append(configMaps, part)
+		}
+	}
+	return configMaps, nil
+}
+
+// envLookup returns the lookup used to interpolate the env files of a service
+func envLookup(service kobject.ServiceConfig) func(key string) (string, bool) {
 	envs := make(map[string]string)
 	for _, env := range service.Environment {
 		envs[env.Name] = env.Value
 	}
-	configMaps := make([]runtime.Object, 0)
-	for _, envFile := range service.EnvFile {
-		configMap := k.InitConfigMapForEnvWithLookup(name, opt, envFile, func(key string) (string, bool) {
-			v, ok := envs[key]
-			return v, ok
-		})
-		configMaps = append(configMaps, configMap)
+	return func(key string) (string, bool) {
+		v, ok := envs[key]
+		return v, ok
 	}
-	return configMaps
 }
@@ -22,8 +22,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/compose-spec/compose-go/v2/types"
 
@@ -32,17 +34,25 @@ import (
 	"github.com/kubernetes/kompose/pkg/transformer"
 	deployapi "github.com/openshift/api/apps/v1"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	appsv1 "k8s.io/api/apps/v1"
+	hpa "k8s.io/api/autoscaling/v2"
+	hpav2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
 	api "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func newServiceConfig() kobject.ServiceConfig {
+	replicas := 2
 	return kobject.ServiceConfig{
 		Name:            "app",
 		ContainerName:   "name",
@@ -66,7 +76,7 @@ func newServiceConfig() kobject.ServiceConfig {
 		Stdin:           true,
 		Tty:             true,
 		TmpFs:           []string{"/tmp"},
-		Replicas:        2,
+		Replicas:        &replicas,
 		Volumes:         []kobject.Volumes{{SvcName: "app", MountPath: "/tmp/volume", PVCName: "app-claim0"}},
 		GroupAdd:        []int64{1003, 1005},
 		Configs:         []types.ServiceConfigObjConfig{{Source: "config", Target: "/etc/world"}},
@@ -323,6 +333,33 @@ func TestKomposeConvertIngress(t *testing.T) {
 	}
 }
 
+func TestKomposeConvertZeroReplicas(t *testing.T) {
+	replicas := 0
+	service := newServiceConfig()
+	service.Replicas = &replicas
+	service.Paused = true
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"app": service}}
+
+	k := Kubernetes{}
+	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+
+	for _, obj := range objs {
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			if *d.Spec.Replicas != 0 {
+				t.Errorf("Expected 0 replicas, got %d", *d.Spec.Replicas)
+			}
+			if !d.Spec.Paused {
+				t.Errorf("Expected deployment to be paused")
+			}
+			return
+		}
+	}
+	t.Errorf("Expected a deployment to be generated")
+}
+
 func TestKomposeConvert(t *testing.T) {
 	replicas := 3
 	testCases := map[string]struct {
@@ -384,8 +421,8 @@ func TestKomposeConvert(t *testing.T) {
 							t.Errorf("Expected %d replicas, got %d", replicas, d.Spec.Replicas)
 						}
 					} else {
-						if (int)(*d.Spec.Replicas) != newServiceConfig().Replicas {
-							t.Errorf("Expected %d replicas, got %d", newServiceConfig().Replicas, d.Spec.Replicas)
+						if (int)(*d.Spec.Replicas) != *newServiceConfig().Replicas {
+							t.Errorf("Expected %d replicas, got %d", *newServiceConfig().Replicas, d.Spec.Replicas)
 						}
 					}
 					foundD = true
@@ -415,8 +452,8 @@ func TestKomposeConvert(t *testing.T) {
 									t.Errorf("Expected %d replicas, got %d", replicas, d.Spec.Replicas)
 								}
 							} else {
-								if (int)(*d.Spec.Replicas) != newServiceConfig().Replicas {
-									t.Errorf("Expected %d replicas, got %d", newServiceConfig().Replicas, d.Spec.Replicas)
+								if (int)(*d.Spec.Replicas) != *newServiceConfig().Replicas {
+									t.Errorf("Expected %d replicas, got %d", *newServiceConfig().Replicas, d.Spec.Replicas)
 								}
 							}
 							foundD = true
@@ -476,8 +513,8 @@ func TestKomposeConvert(t *testing.T) {
 							t.Errorf("Expected %d replicas, got %d", replicas, ss.Spec.Replicas)
 						}
 					} else {
-						if (int)(*ss.Spec.Replicas) != newServiceConfig().Replicas {
-							t.Errorf("Expected %d replicas, got %d", newServiceConfig().Replicas, ss.Spec.Replicas)
+						if (int)(*ss.Spec.Replicas) != *newServiceConfig().Replicas {
+							t.Errorf("Expected %d replicas, got %d", *newServiceConfig().Replicas, ss.Spec.Replicas)
 						}
 					}
 					foundSS = true
@@ -507,8 +544,8 @@ func TestKomposeConvert(t *testing.T) {
 									t.Errorf("Expected %d replicas, got %d", replicas, d.Spec.Replicas)
 								}
 							} else {
-								if (int)(*d.Spec.Replicas) != newServiceConfig().Replicas {
-									t.Errorf("Expected %d replicas, got %d", newServiceConfig().Replicas, d.Spec.Replicas)
+								if (int)(*d.Spec.Replicas) != *newServiceConfig().Replicas {
+									t.Errorf("Expected %d replicas, got %d", *newServiceConfig().Replicas, d.Spec.Replicas)
 								}
 							}
 							foundSS = true
@@ -619,6 +656,137 @@ func TestRestartOnFailure(t *testing.T) {
 	}
 }
 
+func TestRestartOnFailureMaxAttempts(t *testing.T) {
+	maxAttempts := int32(3)
+	podFailurePolicy := &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{
+			{
+				Action: batchv1.PodFailurePolicyActionFailJob,
+				OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+					Operator: batchv1.PodFailurePolicyOnExitCodesOpIn,
+					Values:   []int32{42},
+				},
+			},
+		},
+	}
+
+	testCases := map[string]struct {
+		service       kobject.ServiceConfig
+		backoffLimit  int32
+		restartPolicy api.RestartPolicy
+	}{
+		"on-failure:3 creates a Job": {
+			kobject.ServiceConfig{Image: "foobar", Restart: "on-failure", RestartMaxAttempts: &maxAttempts},
+			3, api.RestartPolicyOnFailure,
+		},
+		"pod failure policy forces restartPolicy Never": {
+			kobject.ServiceConfig{Image: "foobar", Restart: "on-failure", RestartMaxAttempts: &maxAttempts, JobPodFailurePolicy: podFailurePolicy},
+			3, api.RestartPolicyNever,
+		},
+		"pod failure policy with restart no does not retry": {
+			kobject.ServiceConfig{Image: "foobar", Restart: "no", JobPodFailurePolicy: podFailurePolicy},
+			0, api.RestartPolicyNever,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		k := Kubernetes{}
+		komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"app": test.service}}
+		objs, err := k.Transform(komposeObject, kobject.ConvertOptions{})
+		if err != nil {
+			t.Fatalf("k.Transform failed: %v", err)
+		}
+		if len(objs) != 1 {
+			t.Fatalf("Expected only one job, got %d objects", len(objs))
+		}
+
+		job, ok := objs[0].(*batchv1.Job)
+		if !ok {
+			t.Fatalf("Expected 'job' object, got %T", objs[0])
+		}
+		if job.Spec.BackoffLimit == nil || *job.Spec.BackoffLimit != test.backoffLimit {
+			t.Errorf("Expected backoffLimit %d, got %v", test.backoffLimit, job.Spec.BackoffLimit)
+		}
+		if job.Spec.Template.Spec.RestartPolicy != test.restartPolicy {
+			t.Errorf("Expected restartPolicy as %s, got %s", test.restartPolicy, job.Spec.Template.Spec.RestartPolicy)
+		}
+		if !reflect.DeepEqual(job.Spec.PodFailurePolicy, test.service.JobPodFailurePolicy) {
+			t.Errorf("Expected podFailurePolicy %v, got %v", test.service.JobPodFailurePolicy, job.Spec.PodFailurePolicy)
+		}
+	}
+}
+
+func TestJobExecutionLabels(t *testing.T) {
+	parallelism, completions, ttl := int32(2), int32(5), int32(3600)
+	activeDeadlineSeconds := int64(600)
+	service := kobject.ServiceConfig{
+		Image:                    "foobar",
+		Restart:                  "no",
+		JobParallelism:           &parallelism,
+		JobCompletions:           &completions,
+		JobTTLSecondsAfterFinish: &ttl,
+		JobActiveDeadlineSeconds: &activeDeadlineSeconds,
+	}
+
+	k := Kubernetes{}
+	objs, err := k.Transform(kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"app": service}}, kobject.ConvertOptions{})
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+
+	job, ok := objs[0].(*batchv1.Job)
+	if !ok {
+		t.Fatalf("Expected 'job' object, got %T", objs[0])
+	}
+	if *job.Spec.Parallelism != parallelism || *job.Spec.Completions != completions {
+		t.Errorf("Expected parallelism %d and completions %d, got %d and %d", parallelism, completions, *job.Spec.Parallelism, *job.Spec.Completions)
+	}
+	if *job.Spec.TTLSecondsAfterFinished != ttl || *job.Spec.ActiveDeadlineSeconds != activeDeadlineSeconds {
+		t.Errorf("Expected ttlSecondsAfterFinished %d and activeDeadlineSeconds %d, got %d and %d", ttl, activeDeadlineSeconds, *job.Spec.TTLSecondsAfterFinished, *job.Spec.ActiveDeadlineSeconds)
+	}
+	if job.Spec.BackoffLimit != nil {
+		t.Errorf("Expected default backoffLimit, got %d", *job.Spec.BackoffLimit)
+	}
+}
+
+func TestCronJobBackoffLimitFromMaxAttempts(t *testing.T) {
+	maxAttempts := int32(3)
+	cronBackoffLimit := int32(1)
+
+	testCases := map[string]struct {
+		backoffLimit *int32
+		expected     int32
+	}{
+		"on-failure:3 is used as backoffLimit":           {nil, 3},
+		"kompose.cronjob.backoff_limit takes precedence": {&cronBackoffLimit, 1},
+	}
+
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		service := kobject.ServiceConfig{
+			Image:               "foobar",
+			Restart:             "on-failure",
+			RestartMaxAttempts:  &maxAttempts,
+			CronJobSchedule:     "* * * * *",
+			CronJobBackoffLimit: test.backoffLimit,
+		}
+		k := Kubernetes{}
+		objs, err := k.Transform(kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"app": service}}, kobject.ConvertOptions{})
+		if err != nil {
+			t.Fatalf("k.Transform failed: %v", err)
+		}
+
+		cronJob, ok := objs[0].(*batchv1.CronJob)
+		if !ok {
+			t.Fatalf("Expected 'cronjob' object, got %T", objs[0])
+		}
+		if backoffLimit := cronJob.Spec.JobTemplate.Spec.BackoffLimit; backoffLimit == nil || *backoffLimit != test.expected {
+			t.Errorf("Expected backoffLimit %d, got %v", test.expected, backoffLimit)
+		}
+	}
+}
+
 func TestInitPodSpec(t *testing.T) {
 	name := "foo"
 	k := Kubernetes{}
@@ -638,6 +806,60 @@ func TestConfigTmpfs(t *testing.T) {
 	}
 }
 
+func TestConfigTmpfsSize(t *testing.T) {
+	k := Kubernetes{}
+	service := kobject.ServiceConfig{TmpFs: []string{"/run:rw,size=64m,mode=1777", "/scratch:size=67108864", "/cache", "/bad:size=lots"}}
+	volumeMounts, volumes := k.ConfigTmpfs("foo", service)
+
+	expectedPaths := []string{"/run", "/scratch", "/cache", "/bad"}
+	expectedSizes := []string{"64Mi", "64Mi", "", ""}
+	for i, volume := range volumes {
+		if volumeMounts[i].MountPath != expectedPaths[i] {
+			t.Errorf("Expected the mount path %s, got %s", expectedPaths[i], volumeMounts[i].MountPath)
+		}
+		size := ""
+		if volume.EmptyDir.SizeLimit != nil {
+			size = volume.EmptyDir.SizeLimit.String()
+		}
+		if size != expectedSizes[i] || volume.EmptyDir.Medium != api.StorageMediumMemory {
+			t.Errorf("Expected a memory volume of size %q for %s, got %+v", expectedSizes[i], expectedPaths[i], volume.EmptyDir)
+		}
+	}
+}
+
+func TestConfigWritablePaths(t *testing.T) {
+	testCases := map[string]struct {
+		readOnly bool
+		labels   map[string]string
+		mounts   []api.VolumeMount
+		expected []string
+	}{
+		"Not read only": {false, nil, nil, nil},
+		"Default paths": {true, nil, nil, []string{"/tmp", "/var/run"}},
+		"Label paths":   {true, map[string]string{compose.LabelVolumeWritablePaths: "/var/cache/nginx, /var/log/nginx/"}, nil, []string{"/tmp", "/var/run", "/var/cache/nginx", "/var/log/nginx"}},
+		"Mounted paths": {true, nil, []api.VolumeMount{{Name: "foo-tmpfs0", MountPath: "/tmp/"}}, []string{"/var/run"}},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			k := Kubernetes{}
+			service := kobject.ServiceConfig{Name: "foo", ReadOnly: test.readOnly, Labels: test.labels}
+			volumeMounts, volumes := k.ConfigWritablePaths("foo", service, test.mounts)
+
+			var paths []string
+			for i, mount := range volumeMounts {
+				paths = append(paths, mount.MountPath)
+				if volumes[i].Name != mount.Name || volumes[i].EmptyDir == nil || volumes[i].EmptyDir.Medium != "" {
+					t.Errorf("Expected an emptyDir volume %s, got %+v", mount.Name, volumes[i])
+				}
+			}
+			if !reflect.DeepEqual(paths, test.expected) {
+				t.Errorf("Expected the writable paths %v, got %v", test.expected, paths)
+			}
+		})
+	}
+}
+
 func TestConfigCapabilities(t *testing.T) {
 	testCases := map[string]struct {
 		service kobject.ServiceConfig
@@ -826,6 +1048,42 @@ func TestMultipleContainersInPod(t *testing.T) {
 	}
 }
 
+func TestResourcesOnMultipleContainers(t *testing.T) {
+	web := kobject.ServiceConfig{Name: "web", Image: "nginx", MemLimit: 64 * 1024 * 1024, CPUReservation: 250, Privileged: true}
+	worker := kobject.ServiceConfig{Name: "worker", Image: "worker", CPULimit: 500}
+
+	podSpec := PodSpec{}
+	for _, service := range []kobject.ServiceConfig{web, worker} {
+		podSpec.Append(
+			AddContainer(service, kobject.ConvertOptions{}),
+			ResourcesLimits(service),
+			ResourcesRequests(service),
+			SecurityContext(service.Name, service),
+		)
+	}
+
+	containers := podSpec.Get().Containers
+	if len(containers) != 2 {
+		t.Fatalf("Expected 2 containers, got %d", len(containers))
+	}
+	webContainer, workerContainer := containers[0], containers[1]
+	if limits := webContainer.Resources.Limits; len(limits) != 1 || limits.Memory().Value() != 64*1024*1024 {
+		t.Errorf("Expected the memory limit of web, got %v", limits)
+	}
+	if requests := webContainer.Resources.Requests; len(requests) != 1 || requests.Cpu().MilliValue() != 250 {
+		t.Errorf("Expected the CPU request of web, got %v", requests)
+	}
+	if webContainer.SecurityContext == nil || !*webContainer.SecurityContext.Privileged {
+		t.Errorf("Expected the web container to be privileged, got %v", webContainer.SecurityContext)
+	}
+	if limits := workerContainer.Resources.Limits; len(limits) != 1 || limits.Cpu().MilliValue() != 500 {
+		t.Errorf("Expected the CPU limit of worker, got %v", limits)
+	}
+	if workerContainer.Resources.Requests != nil || workerContainer.SecurityContext != nil {
+		t.Errorf("Expected the worker container to keep its own settings, got %v and %v", workerContainer.Resources.Requests, workerContainer.SecurityContext)
+	}
+}
+
 func TestServiceAccountNameOnMultipleContainers(t *testing.T) {
 	groupName := "pod_group"
 	serviceAccountName := "my-service"
@@ -892,6 +1150,14 @@ func TestHealthCheckOnMultipleContainers(t *testing.T) {
 		}
 	}
 
+	createProbe := func(TCPPort int32) *api.Probe {
+		probe, err := configProbe(createHealthCheck(TCPPort))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return probe
+	}
+
 	createConfig := func(name string, livenessTCPPort, readinessTCPPort int32) kobject.ServiceConfig {
 		config := newSimpleServiceConfig()
 		config.Labels = map[string]string{compose.LabelServiceGroup: groupName}
@@ -917,12 +1183,12 @@ func TestHealthCheckOnMultipleContainers(t *testing.T) {
 			kobject.ConvertOptions{ServiceGroupMode: "label", CreateD: true},
 			map[string]api.Container{
 				"app1": {
-					LivenessProbe:  configProbe(createHealthCheck(8081)),
-					ReadinessProbe: configProbe(createHealthCheck(9091)),
+					LivenessProbe:  createProbe(8081),
+					ReadinessProbe: createProbe(9091),
 				},
 				"app2": {
-					LivenessProbe:  configProbe(createHealthCheck(8082)),
-					ReadinessProbe: configProbe(createHealthCheck(9092)),
+					LivenessProbe:  createProbe(8082),
+					ReadinessProbe: createProbe(9092),
 				},
 			},
 		},
@@ -973,6 +1239,151 @@ func TestCreatePVC(t *testing.T) {
 	}
 }
 
+func TestConfigVolumesClaimLabels(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:   "db",
+		Labels: map[string]string{compose.LabelVolumeSize: "5Gi", compose.LabelVolumeStorageClassName: "standard"},
+		Volumes: []kobject.Volumes{
+			{SvcName: "db", VolumeName: "data", MountPath: "/data", Container: "/data", PVCName: "data", PVCSize: "20Gi", StorageClass: "fast", AccessMode: "ReadWriteOncePod"},
+			{SvcName: "db", VolumeName: "logs", MountPath: "/logs", Container: "/logs", PVCName: "logs"},
+		},
+	}
+	k := Kubernetes{}
+	_, _, pvcs, _, err := k.ConfigVolumes("db", service)
+	if err != nil {
+		t.Fatalf("k.ConfigVolumes failed: %v", err)
+	}
+
+	expected := map[string]struct {
+		size         string
+		storageClass string
+		accessMode   api.PersistentVolumeAccessMode
+	}{
+		"data": {"20Gi", "fast", api.ReadWriteOncePod},
+		"logs": {"5Gi", "standard", api.ReadWriteOnce},
+	}
+	if len(pvcs) != len(expected) {
+		t.Fatalf("Expected %d claims, got %d", len(expected), len(pvcs))
+	}
+	for _, pvc := range pvcs {
+		want := expected[pvc.Name]
+		size := pvc.Spec.Resources.Requests[api.ResourceStorage]
+		if size.String() != want.size || *pvc.Spec.StorageClassName != want.storageClass || pvc.Spec.AccessModes[0] != want.accessMode {
+			t.Errorf("Expected the claim %s of %s, %s and %s, got %s, %s and %v", pvc.Name, want.size, want.storageClass, want.accessMode, size.String(), *pvc.Spec.StorageClassName, pvc.Spec.AccessModes)
+		}
+	}
+
+	service.Volumes[0].AccessMode = "ReadSometimes"
+	if _, _, _, _, err := k.ConfigVolumes("db", service); err == nil {
+		t.Errorf("Expected an error for an invalid access mode")
+	}
+}
+
+func TestConfigDriverVolumeSource(t *testing.T) {
+	readOnly := true
+	testCases := map[string]struct {
+		volume   kobject.Volumes
+		readonly bool
+		expected *api.VolumeSource
+	}{
+		"No driver": {kobject.Volumes{VolumeName: "data"}, false, nil},
+		"NFS": {kobject.Volumes{VolumeName: "data", DriverOpts: map[string]string{"type": "nfs", "o": "addr=10.0.0.1,nfsvers=4,ro", "device": ":/exports/data"}}, false,
+			&api.VolumeSource{NFS: &api.NFSVolumeSource{Server: "10.0.0.1", Path: "/exports/data", ReadOnly: true}}},
+		"NFS server in device": {kobject.Volumes{VolumeName: "data", DriverOpts: map[string]string{"type": "nfs4", "device": "nfs.example.com:/exports"}}, false,
+			&api.VolumeSource{NFS: &api.NFSVolumeSource{Server: "nfs.example.com", Path: "/exports"}}},
+		"NFS without server": {kobject.Volumes{VolumeName: "data", DriverOpts: map[string]string{"type": "nfs", "device": ":/exports"}}, false, nil},
+		"Bind":               {kobject.Volumes{VolumeName: "data", DriverOpts: map[string]string{"type": "none", "o": "bind", "device": "/srv/data"}}, false, nil},
+		"CSI": {kobject.Volumes{VolumeName: "data", CSIDriver: "smb.csi.k8s.io", DriverOpts: map[string]string{"source": "//smb.example.com/share"}}, true,
+			&api.VolumeSource{CSI: &api.CSIVolumeSource{Driver: "smb.csi.k8s.io", ReadOnly: &readOnly, VolumeAttributes: map[string]string{"source": "//smb.example.com/share"}}}},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			k := Kubernetes{}
+			if result := k.ConfigDriverVolumeSource(test.volume, test.readonly); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestStatefulSetVolumes(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:  "db",
+		Image: "postgres",
+		TmpFs: []string{"/run"},
+		Volumes: []kobject.Volumes{
+			{SvcName: "db", VolumeName: "data", MountPath: "/data", Container: "/data", PVCName: "data"},
+			{SvcName: "db", VolumeName: "shared", MountPath: "/shared", Container: "/shared", PVCName: "shared",
+				DriverOpts: map[string]string{"type": "nfs", "o": "addr=10.0.0.1", "device": ":/exports/shared"}},
+		},
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"db": service}}
+	opt := kobject.ConvertOptions{Controller: StatefulStateController}
+
+	k := Kubernetes{Opt: opt}
+	objs, err := k.Transform(komposeObject, opt)
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+	for _, obj := range objs {
+		if _, ok := obj.(*api.PersistentVolumeClaim); ok {
+			t.Errorf("Expected the claims to be volumeClaimTemplates")
+		}
+		if ss, ok := obj.(*appsv1.StatefulSet); ok {
+			var volumes, templates []string
+			for _, volume := range ss.Spec.Template.Spec.Volumes {
+				volumes = append(volumes, volume.Name)
+			}
+			for _, template := range ss.Spec.VolumeClaimTemplates {
+				templates = append(templates, template.Name)
+			}
+			if !reflect.DeepEqual(volumes, []string{"shared", "db-tmpfs0"}) {
+				t.Errorf("Expected the volumes shared and db-tmpfs0, got %v", volumes)
+			}
+			if !reflect.DeepEqual(templates, []string{"data"}) {
+				t.Errorf("Expected the volumeClaimTemplate data, got %v", templates)
+			}
+		}
+	}
+}
+
+func TestStatefulSetSettings(t *testing.T) {
+	one := uint64(1)
+	service := kobject.ServiceConfig{
+		Name:               "db",
+		Image:              "postgres",
+		DeployUpdateConfig: types.UpdateConfig{Parallelism: &one},
+		StatefulSet: kobject.StatefulSetConfig{
+			PodManagementPolicy:    appsv1.ParallelPodManagement,
+			UpdateStrategy:         appsv1.OnDeleteStatefulSetStrategyType,
+			ServiceName:            "db-headless",
+			PVCRetentionWhenScaled: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+		},
+	}
+	k := Kubernetes{}
+	ss := k.InitSS("db", service, 3)
+
+	if ss.Spec.PodManagementPolicy != appsv1.ParallelPodManagement || ss.Spec.ServiceName != "db-headless" {
+		t.Errorf("Expected the Parallel pod management of the db-headless service, got %q and %q", ss.Spec.PodManagementPolicy, ss.Spec.ServiceName)
+	}
+	if expected := (appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}); !reflect.DeepEqual(ss.Spec.UpdateStrategy, expected) {
+		t.Errorf("Expected the update strategy %+v, got %+v", expected, ss.Spec.UpdateStrategy)
+	}
+	expected := &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+	}
+	if !reflect.DeepEqual(ss.Spec.PersistentVolumeClaimRetentionPolicy, expected) {
+		t.Errorf("Expected the retention policy %+v, got %+v", expected, ss.Spec.PersistentVolumeClaimRetentionPolicy)
+	}
+
+	ss = k.InitSS("db", kobject.ServiceConfig{Name: "db", Image: "postgres"}, 1)
+	if ss.Spec.PodManagementPolicy != "" || ss.Spec.ServiceName != "db" || ss.Spec.PersistentVolumeClaimRetentionPolicy != nil {
+		t.Errorf("Expected the defaults of the StatefulSet, got %+v", ss.Spec)
+	}
+}
+
 func TestCreateHostPortAndProtocol(t *testing.T) {
 	groupName := "pod_group"
 	komposeObject := kobject.KomposeObject{
@@ -1035,6 +1446,179 @@ func TestServiceExternalTrafficPolicy(t *testing.T) {
 	}
 }
 
+func TestCreateAliasServices(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:           "db",
+		Image:          "postgres",
+		ServiceType:    string(api.ServiceTypeNodePort),
+		NodePortPort:   30432,
+		Port:           []kobject.Ports{{HostPort: 5432, ContainerPort: 5432, Protocol: "TCP"}},
+		NetworkAliases: []string{"database", "postgres"},
+	}
+	k := Kubernetes{}
+	svcs, err := k.CreateAliasServices("db", service)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(svcs) != 2 {
+		t.Fatalf("Expected a Service per alias, got %d", len(svcs))
+	}
+	for i, alias := range service.NetworkAliases {
+		svc := svcs[i]
+		if svc.Name != alias || svc.Spec.Selector["io.kompose.service"] != "db" {
+			t.Errorf("Expected the Service %s selecting the pods of db, got %s selecting %v", alias, svc.Name, svc.Spec.Selector)
+		}
+		if svc.Spec.Type != api.ServiceTypeClusterIP || len(svc.Spec.Ports) != 1 || svc.Spec.Ports[0].Port != 5432 || svc.Spec.Ports[0].NodePort != 0 {
+			t.Errorf("Expected a ClusterIP Service with the port 5432, got %s with %+v", svc.Spec.Type, svc.Spec.Ports)
+		}
+	}
+
+	service.Port = nil
+	service.ServiceType = "Headless"
+	if svcs, err := k.CreateAliasServices("db", service); err != nil || len(svcs) != 2 || svcs[0].Spec.ClusterIP != api.ClusterIPNone {
+		t.Errorf("Expected headless aliases of the headless service, got %+v", svcs)
+	}
+}
+
+func TestServiceTrafficPolicies(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:                         "app",
+		Image:                        "nginx",
+		ServiceType:                  string(api.ServiceTypeNodePort),
+		Port:                         []kobject.Ports{{HostPort: 80, ContainerPort: 8080, Protocol: "TCP"}},
+		ServiceExternalTrafficPolicy: string(api.ServiceExternalTrafficPolicyTypeLocal),
+		ServiceInternalTrafficPolicy: string(api.ServiceInternalTrafficPolicyLocal),
+		SessionAffinity:              string(api.ServiceAffinityClientIP),
+		SessionAffinityTimeout:       3600,
+	}
+	k := Kubernetes{}
+	svc, err := k.CreateService("app", service)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if svc.Spec.ExternalTrafficPolicy != api.ServiceExternalTrafficPolicyTypeLocal {
+		t.Errorf("Expected the Local external traffic policy on the NodePort service, got %q", svc.Spec.ExternalTrafficPolicy)
+	}
+	if svc.Spec.InternalTrafficPolicy == nil || *svc.Spec.InternalTrafficPolicy != api.ServiceInternalTrafficPolicyLocal {
+		t.Errorf("Expected the Local internal traffic policy, got %v", svc.Spec.InternalTrafficPolicy)
+	}
+	if svc.Spec.SessionAffinity != api.ServiceAffinityClientIP {
+		t.Errorf("Expected the ClientIP session affinity, got %q", svc.Spec.SessionAffinity)
+	}
+	if config := svc.Spec.SessionAffinityConfig; config == nil || config.ClientIP == nil || *config.ClientIP.TimeoutSeconds != 3600 {
+		t.Errorf("Expected a session affinity of 3600 seconds, got %+v", config)
+	}
+
+	service.ServiceType = string(api.ServiceTypeClusterIP)
+	if svc, err := k.CreateService("app", service); err != nil || svc.Spec.ExternalTrafficPolicy != "" {
+		t.Errorf("Expected no external traffic policy on the ClusterIP service, got %q", svc.Spec.ExternalTrafficPolicy)
+	}
+}
+
+func TestLoadBalancerSettings(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:               "app",
+		Image:              "nginx",
+		ServiceType:        string(api.ServiceTypeLoadBalancer),
+		Port:               []kobject.Ports{{HostPort: 80, ContainerPort: 8080, Protocol: "TCP"}},
+		LoadBalancerIP:     "203.0.113.10",
+		LoadBalancerClass:  "service.k8s.aws/nlb",
+		ServiceAnnotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-scheme": "internal"},
+	}
+	k := Kubernetes{}
+	svcs := k.CreateLBService("app", service)
+	if len(svcs) != 1 {
+		t.Fatalf("Expected a single load balancer service, got %d", len(svcs))
+	}
+	svc := svcs[0]
+	if svc.Spec.LoadBalancerIP != "203.0.113.10" {
+		t.Errorf("Expected the load balancer IP 203.0.113.10, got %q", svc.Spec.LoadBalancerIP)
+	}
+	if svc.Spec.LoadBalancerClass == nil || *svc.Spec.LoadBalancerClass != "service.k8s.aws/nlb" {
+		t.Errorf("Expected the load balancer class service.k8s.aws/nlb, got %v", svc.Spec.LoadBalancerClass)
+	}
+	if svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-scheme"] != "internal" {
+		t.Errorf("Expected the annotation of the label, got %v", svc.Annotations)
+	}
+}
+
+func TestServiceNodePorts(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:          "app",
+		Image:         "nginx",
+		ServiceType:   string(api.ServiceTypeNodePort),
+		Port:          []kobject.Ports{{HostPort: 80, ContainerPort: 8080, Protocol: "TCP"}, {ContainerPort: 443, Protocol: "TCP"}, {HostPort: 9090, ContainerPort: 9090, Protocol: "TCP"}},
+		NodePortPorts: map[int32]int32{80: 30080, 443: 30443},
+	}
+	k := Kubernetes{}
+	ports, err := k.ConfigServicePorts(service)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[int32]int32{80: 30080, 443: 30443, 9090: 0}
+	if len(ports) != len(expected) {
+		t.Fatalf("Expected %d service ports, got %+v", len(expected), ports)
+	}
+	for _, port := range ports {
+		if port.NodePort != expected[port.Port] {
+			t.Errorf("Expected the node port %d for port %d, got %d", expected[port.Port], port.Port, port.NodePort)
+		}
+	}
+}
+
+func TestLoadBalancerMixedProtocolPort(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:        "dns",
+		Image:       "coredns/coredns",
+		ServiceType: string(api.ServiceTypeLoadBalancer),
+		Port:        []kobject.Ports{{HostPort: 53, ContainerPort: 53, Protocol: "TCP"}, {HostPort: 53, ContainerPort: 53, Protocol: "UDP"}},
+	}
+	k := Kubernetes{}
+	if _, err := k.ConfigServicePorts(service); err == nil || !strings.Contains(err.Error(), "cannot use TCP and UDP for the same port") {
+		t.Errorf("Expected an error for the TCP and UDP port of the LoadBalancer, got %v", err)
+	}
+}
+
+func TestEnableServiceLinks(t *testing.T) {
+	enabled := true
+	serviceWithLabel := newServiceConfig()
+	serviceWithLabel.EnableServiceLinks = &enabled
+	serviceInGroup := newSimpleServiceConfig()
+	serviceInGroup.Labels = map[string]string{compose.LabelServiceGroup: "group"}
+
+	testCases := map[string]struct {
+		service  kobject.ServiceConfig
+		opt      kobject.ConvertOptions
+		expected *bool
+	}{
+		"default":                 {newServiceConfig(), kobject.ConvertOptions{CreateD: true}, nil},
+		"--disable-service-links": {newServiceConfig(), kobject.ConvertOptions{CreateD: true, DisableServiceLinks: true}, new(bool)},
+		"label overrides flag":    {serviceWithLabel, kobject.ConvertOptions{CreateD: true, DisableServiceLinks: true}, &enabled},
+		"service group mode":      {serviceInGroup, kobject.ConvertOptions{CreateD: true, ServiceGroupMode: "label", DisableServiceLinks: true}, new(bool)},
+	}
+
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		k := Kubernetes{}
+		objs, err := k.Transform(kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"app": test.service}}, test.opt)
+		if err != nil {
+			t.Fatalf("k.Transform failed: %v", err)
+		}
+		found := false
+		for _, obj := range objs {
+			if d, ok := obj.(*appsv1.Deployment); ok {
+				found = true
+				if !reflect.DeepEqual(d.Spec.Template.Spec.EnableServiceLinks, test.expected) {
+					t.Errorf("Expected enableServiceLinks %v, got %v", test.expected, d.Spec.Template.Spec.EnableServiceLinks)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Expected a deployment to be generated")
+		}
+	}
+}
+
 func TestVolumeMountSubPath(t *testing.T) {
 	groupName := "pod_group"
 	expectedSubPathValue := "test-subpath"
@@ -1069,58 +1653,297 @@ func TestNetworkPoliciesGeneration(t *testing.T) {
 	for _, obj := range objs {
 		if np, ok := obj.(*networkingv1.NetworkPolicy); ok {
 			matchLabelsLength := len(np.Spec.PodSelector.MatchLabels)
-			if matchLabelsLength == 0 {
+			if np.Name == "default-deny" {
+				// the default deny policy selects every pod of the namespace
+				if matchLabelsLength != 0 {
+					t.Errorf("Expected the default deny Network Policy to select every pod, got %v", np.Spec.PodSelector.MatchLabels)
+				}
+			} else if matchLabelsLength == 0 {
 				t.Errorf("Expected length of Network Policy PodSelector to be greater than 0, got %v", matchLabelsLength)
 			}
 		}
 	}
 }
 
-func TestServiceGroupModeImagePullSecrets(t *testing.T) {
-	groupName := "pod_group"
+func TestNetworkPoliciesEgress(t *testing.T) {
 	serviceConfig := newServiceConfig()
+	serviceConfig.Network = []string{"frontend", "backend"}
 	komposeObject := kobject.KomposeObject{
-		ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+		ServiceConfigs:   map[string]kobject.ServiceConfig{"app": serviceConfig},
+		InternalNetworks: map[string]bool{"backend": true},
 	}
 	k := Kubernetes{}
-	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{ServiceGroupMode: groupName, GenerateNetworkPolicies: true})
+	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{GenerateNetworkPolicies: true})
 	if err != nil {
-		t.Error(errors.Wrap(err, "k.Transform failed"))
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
 	}
-	expectedSecretsLen := len(serviceConfig.ImagePullSecret)
+
+	policies := map[string]*networkingv1.NetworkPolicy{}
 	for _, obj := range objs {
-		if deployment, ok := obj.(*appsv1.Deployment); ok {
-			secretsLen := len(deployment.Spec.Template.Spec.ImagePullSecrets)
-			if secretsLen != expectedSecretsLen {
-				t.Errorf("Expected length of Deployment ImagePullSecrets to be equal to %v, got %v", expectedSecretsLen, secretsLen)
+		if np, ok := obj.(*networkingv1.NetworkPolicy); ok {
+			if _, ok := policies[np.Name]; ok {
+				t.Errorf("Expected a single network policy %s", np.Name)
 			}
+			policies[np.Name] = np
+		}
+	}
+	if len(policies) != 3 {
+		t.Errorf("Expected the policies of the 2 networks and the default deny one, got %v", policies)
+	}
+	for _, name := range []string{"frontend", "backend", "default-deny"} {
+		np, ok := policies[name]
+		if !ok {
+			t.Fatalf("Expected network policy %s, got %v", name, policies)
+		}
+		if len(np.Spec.PolicyTypes) != 2 {
+			t.Errorf("Expected network policy %s to restrict ingress and egress, got %v", name, np.Spec.PolicyTypes)
 		}
 	}
+	if rules := policies["default-deny"].Spec; len(rules.Ingress) != 0 || len(rules.Egress) != 0 || len(rules.PodSelector.MatchLabels) != 0 {
+		t.Errorf("Expected the default deny network policy to select every pod without rules, got %v", rules)
+	}
+
+	// network peers and DNS, and the outside for networks which are not internal
+	if egress := policies["frontend"].Spec.Egress; len(egress) != 3 || egress[2].To[0].IPBlock == nil {
+		t.Errorf("Expected the frontend network policy to allow egress to the outside, got %v", egress)
+	}
+	egress := policies["backend"].Spec.Egress
+	if len(egress) != 2 {
+		t.Fatalf("Expected the internal backend network policy to allow egress to the network and DNS only, got %v", egress)
+	}
+	if egress[1].Ports[0].Port.IntValue() != 53 || egress[1].To[0].PodSelector.MatchLabels["k8s-app"] != "kube-dns" {
+		t.Errorf("Expected egress to the cluster DNS, got %v", egress[1])
+	}
 }
 
-func TestNamespaceGeneration(t *testing.T) {
-	ns := "app"
+func TestPriorityClass(t *testing.T) {
+	web := newServiceConfig()
+	web.Labels = map[string]string{compose.LabelPriorityClass: "high", compose.LabelPriorityClassValue: "100000"}
+	worker := newServiceConfig()
+	worker.Name = "worker"
+	worker.Labels = map[string]string{compose.LabelPriorityClass: "high"}
 	komposeObject := kobject.KomposeObject{
-		ServiceConfigs: map[string]kobject.ServiceConfig{"app": newServiceConfig()},
-		Namespace:      ns,
+		ServiceConfigs: map[string]kobject.ServiceConfig{"web": web, "worker": worker},
 	}
 	k := Kubernetes{}
 	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{})
+	if err != nil {
+		t.Fatal(errors.Wrap(err, "k.Transform failed"))
+	}
+
+	priorityClasses := 0
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			if o.Spec.Template.Spec.PriorityClassName != "high" {
+				t.Errorf("Expected priority class high for deployment %s, got %q", o.Name, o.Spec.Template.Spec.PriorityClassName)
+			}
+		case *schedulingv1.PriorityClass:
+			priorityClasses++
+			if o.Name != "high" || o.Value != 100000 {
+				t.Errorf("Expected priority class high with value 100000, got %s with value %d", o.Name, o.Value)
+			}
+		}
+	}
+	if priorityClasses != 1 {
+		t.Errorf("Expected 1 PriorityClass, got %d", priorityClasses)
+	}
+}
+
+func TestDaemonSetController(t *testing.T) {
+	serviceConfig := newServiceConfig()
+	serviceConfig.DeployMode = "global"
+	serviceConfig.Annotations = map[string]string{"team": "logging"}
+
+	testCases := map[string]struct {
+		labels map[string]string
+		opt    kobject.ConvertOptions
+	}{
+		"Global deploy mode": {nil, kobject.ConvertOptions{CreateD: true}},
+		"Controller flag":    {nil, kobject.ConvertOptions{Controller: DaemonSetController}},
+		"Controller label":   {map[string]string{compose.LabelControllerType: "DaemonSet"}, kobject.ConvertOptions{Controller: DeploymentController}},
+	}
+
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		service := serviceConfig
+		service.Labels = map[string]string{compose.LabelHpaMaxReplicas: "5"}
+		for key, value := range test.labels {
+			service.Labels[key] = value
+		}
+		k := Kubernetes{}
+		objs, err := k.Transform(kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"app": service}}, test.opt)
+		if err != nil {
+			t.Fatal(errors.Wrap(err, "k.Transform failed"))
+		}
+		daemonSets := 0
+		for _, obj := range objs {
+			switch o := obj.(type) {
+			case *appsv1.DaemonSet:
+				daemonSets++
+				if o.Spec.Template.Annotations["team"] != "logging" {
+					t.Errorf("Expected the service annotations on the pod template, got %v", o.Spec.Template.Annotations)
+				}
+			case *appsv1.Deployment:
+				t.Errorf("Expected no deployment, got %s", o.Name)
+			case *hpa.HorizontalPodAutoscaler:
+				t.Errorf("Expected no horizontal pod autoscaler for a daemonset, got %s", o.Name)
+			}
+		}
+		if daemonSets != 1 {
+			t.Errorf("Expected 1 daemonset, got %d", daemonSets)
+		}
+	}
+}
+
+func TestHorizontalPodAutoscalerReplicas(t *testing.T) {
+	replicas := 3
+	service := kobject.ServiceConfig{
+		Name:     "web",
+		Image:    "nginx",
+		Replicas: &replicas,
+		Labels:   map[string]string{compose.LabelHpaMinReplicas: "2", compose.LabelHpaMaxReplicas: "5"},
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": service}}
+	opt := kobject.ConvertOptions{CreateD: true, Replicas: 1}
+	k := Kubernetes{Opt: opt}
+	objs, err := k.Transform(komposeObject, opt)
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+	deployments := 0
+	for _, obj := range objs {
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			deployments++
+			if d.Spec.Replicas != nil {
+				t.Errorf("Expected the replicas of the scaled deployment to be omitted, got %d", *d.Spec.Replicas)
+			}
+		}
+	}
+	if deployments != 1 {
+		t.Errorf("Expected 1 deployment, got %d", deployments)
+	}
+}
+
+func TestHorizontalPodAutoscalerVersion(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:   "web",
+		Image:  "nginx",
+		Labels: map[string]string{compose.LabelHpaMaxReplicas: "5", compose.LabelHpaCPU: "60"},
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": service}}
+
+	for version, expected := range map[string]string{"": "autoscaling/v2", "1.31": "autoscaling/v2", "1.22": "autoscaling/v2beta2"} {
+		opt := kobject.ConvertOptions{CreateD: true, Replicas: 1, KubernetesVersion: version}
+		k := Kubernetes{Opt: opt}
+		objs, err := k.Transform(komposeObject, opt)
+		if err != nil {
+			t.Fatalf("k.Transform failed: %v", err)
+		}
+		found := false
+		for _, obj := range objs {
+			switch o := obj.(type) {
+			case *hpa.HorizontalPodAutoscaler:
+				found = o.APIVersion == expected && o.Spec.MaxReplicas == 5
+			case *hpav2beta2.HorizontalPodAutoscaler:
+				found = o.APIVersion == expected && o.Spec.MaxReplicas == 5 && len(o.Spec.Metrics) == 2
+			}
+		}
+		if !found {
+			t.Errorf("Expected a %s HorizontalPodAutoscaler for Kubernetes %q, got %v", expected, version, objs)
+		}
+	}
+}
+
+func TestServiceGroupModeImagePullSecrets(t *testing.T) {
+	groupName := "pod_group"
+	serviceConfig := newServiceConfig()
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+	}
+	k := Kubernetes{}
+	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{ServiceGroupMode: groupName, GenerateNetworkPolicies: true})
 	if err != nil {
 		t.Error(errors.Wrap(err, "k.Transform failed"))
 	}
+	expectedSecretsLen := len(serviceConfig.ImagePullSecret)
 	for _, obj := range objs {
-		if namespace, ok := obj.(*api.Namespace); ok {
-			if strings.ToLower(ns) != strings.ToLower(namespace.ObjectMeta.Name) {
-				t.Errorf("Expected namespace name %v, got %v", ns, namespace.ObjectMeta.Name)
+		if deployment, ok := obj.(*appsv1.Deployment); ok {
+			secretsLen := len(deployment.Spec.Template.Spec.ImagePullSecrets)
+			if secretsLen != expectedSecretsLen {
+				t.Errorf("Expected length of Deployment ImagePullSecrets to be equal to %v, got %v", expectedSecretsLen, secretsLen)
 			}
 		}
+	}
+}
+
+func TestServiceGroupModeEnvError(t *testing.T) {
+	serviceConfig := newServiceConfig()
+	serviceConfig.Labels = map[string]string{compose.LabelServiceGroup: "pod_group"}
+	serviceConfig.EnvFile = []string{"missing.env"}
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"app": serviceConfig},
+	}
+	k := Kubernetes{}
+	opt := kobject.ConvertOptions{ServiceGroupMode: "label", CreateD: true, InputFiles: []string{filepath.Join(t.TempDir(), "compose.yaml")}}
+	if _, err := k.Transform(komposeObject, opt); err == nil {
+		t.Errorf("Expected an error for the missing env file of a grouped service")
+	}
+}
+
+func TestNamespaceGeneration(t *testing.T) {
+	ns := "app"
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"app": newServiceConfig()},
+		Namespace:      ns,
+	}
+	k := Kubernetes{}
+	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true})
+	if err != nil {
+		t.Error(errors.Wrap(err, "k.Transform failed"))
+	}
+	deployments := 0
+	for _, obj := range objs {
+		// the Namespace manifest is only generated with --create-namespace
+		if namespace, ok := obj.(*api.Namespace); ok {
+			t.Errorf("Expected no namespace without --create-namespace, got %v", namespace.ObjectMeta.Name)
+		}
 		if dep, ok := obj.(*appsv1.Deployment); ok {
+			deployments++
 			if dep.ObjectMeta.Namespace != ns {
 				t.Errorf("Expected deployment namespace %v, got %v", ns, dep.ObjectMeta.Namespace)
 			}
 		}
 	}
+	if deployments != 1 {
+		t.Errorf("Expected 1 deployment, got %d", deployments)
+	}
+}
+
+func TestCreateNamespace(t *testing.T) {
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"app": newServiceConfig()},
+		Namespace:      "app",
+	}
+	for _, createNamespace := range []bool{true, false} {
+		k := Kubernetes{}
+		objs, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateNamespace: createNamespace})
+		if err != nil {
+			t.Fatal(errors.Wrap(err, "k.Transform failed"))
+		}
+		namespaces := 0
+		for _, obj := range objs {
+			if namespace, ok := obj.(*api.Namespace); ok {
+				namespaces++
+				if namespace.Namespace != "" {
+					t.Errorf("Expected the cluster scoped Namespace to have no namespace, got %q", namespace.Namespace)
+				}
+			}
+		}
+		if expected := map[bool]int{true: 1, false: 0}[createNamespace]; namespaces != expected {
+			t.Errorf("Expected %d Namespace with CreateNamespace %v, got %d", expected, createNamespace, namespaces)
+		}
+	}
 }
 
 // Test namespace generation with namespace being blank / ""
@@ -1325,7 +2148,10 @@ UNDEFINED_VAR=${MISSING_VAR:-default_value}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			k := Kubernetes{}
-			cms := k.PargeEnvFiletoConfigMaps(tc.service.Name, tc.service, tc.opt)
+			cms, err := k.PargeEnvFiletoConfigMaps(tc.service.Name, tc.service, tc.opt)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 			if len(cms) != tc.want {
 				t.Errorf("Expected %d ConfigMaps, got %d", tc.want, len(cms))
 			}
@@ -1333,3 +2159,600 @@ UNDEFINED_VAR=${MISSING_VAR:-default_value}
 		})
 	}
 }
+
+func TestSplitOversizedConfigMaps(t *testing.T) {
+	composeDir := t.TempDir()
+	dir := filepath.Join(composeDir, "data")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create data dir: %v", err)
+	}
+	for _, file := range []string{"a.txt", "b.txt", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(strings.Repeat("x", 400*1024)), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+	envFile := filepath.Join(composeDir, "big.env")
+	envContent := fmt.Sprintf("FIRST=%s\nSECOND=%s\n", strings.Repeat("y", 600*1024), strings.Repeat("z", 600*1024))
+	if err := os.WriteFile(envFile, []byte(envContent), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+
+	service := kobject.ServiceConfig{
+		Name:    "web",
+		Image:   "nginx",
+		EnvFile: []string{"big.env"},
+		Volumes: []kobject.Volumes{{Host: dir, Container: "/data", MountPath: dir + ":/data", PVCName: "web-claim0"}},
+	}
+	opt := kobject.ConvertOptions{InputFiles: []string{filepath.Join(composeDir, "compose.yaml")}}
+	k := Kubernetes{Opt: opt}
+
+	_, volumes, _, cms, err := k.ConfigVolumes("web", service)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cms) != 2 || cms[0].Name != "web-cm0-0" || cms[1].Name != "web-cm0-1" {
+		t.Fatalf("Expected the directory to be split in 2 ConfigMaps, got %d", len(cms))
+	}
+	if len(cms[0].Data) != 2 || len(cms[1].Data) != 1 {
+		t.Errorf("Expected 2 and 1 files in the ConfigMaps, got %d and %d", len(cms[0].Data), len(cms[1].Data))
+	}
+	if volumes[0].Projected == nil || len(volumes[0].Projected.Sources) != 2 {
+		t.Errorf("Expected a projected volume over the 2 ConfigMaps, got %+v", volumes[0].VolumeSource)
+	}
+
+	envConfigMaps, err := k.PargeEnvFiletoConfigMaps("web", service, opt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, envsFrom, err := ConfigEnvs(service, opt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(envConfigMaps) != 2 || len(envsFrom) != 2 {
+		t.Fatalf("Expected the env file to be split in 2 ConfigMaps, got %d ConfigMaps and %d references", len(envConfigMaps), len(envsFrom))
+	}
+	for i, envFrom := range envsFrom {
+		if name := envConfigMaps[i].(*api.ConfigMap).Name; envFrom.ConfigMapRef.Name != name {
+			t.Errorf("Expected envFrom to reference %q, got %q", name, envFrom.ConfigMapRef.Name)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "d.txt"), []byte(strings.Repeat("x", 2*1024*1024)), 0644); err != nil {
+		t.Fatalf("Failed to write d.txt: %v", err)
+	}
+	if _, _, _, _, err := k.ConfigVolumes("web", service); err == nil {
+		t.Errorf("Expected an error for a file larger than a ConfigMap")
+	}
+
+	if err := os.WriteFile(envFile, []byte("HUGE="+strings.Repeat("y", 2*1024*1024)+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write env file: %v", err)
+	}
+	if _, err := k.PargeEnvFiletoConfigMaps("web", service, opt); err == nil {
+		t.Errorf("Expected an error for an env value larger than a ConfigMap")
+	}
+}
+
+func TestConfigMapBinaryData(t *testing.T) {
+	dir := t.TempDir()
+	binary := []byte{0x1f, 0x8b, 0x08, 0x00, 0xff, 0xfe}
+	if err := os.WriteFile(filepath.Join(dir, "app.conf"), []byte("key=value\r\n"), 0644); err != nil {
+		t.Fatalf("Failed to write app.conf: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data.gz"), binary, 0644); err != nil {
+		t.Fatalf("Failed to write data.gz: %v", err)
+	}
+
+	k := Kubernetes{}
+	cm, _, err := k.IntiConfigMapFromFileOrDir("web", "web-cm0", dir, kobject.ServiceConfig{Name: "web"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cm.Data["app.conf"] != "key=value\n" {
+		t.Errorf("Expected the text file in data, got %q", cm.Data["app.conf"])
+	}
+	if _, ok := cm.Data["data.gz"]; ok {
+		t.Errorf("Expected the binary file not to be in data")
+	}
+	if !reflect.DeepEqual(cm.BinaryData["data.gz"], binary) {
+		t.Errorf("Expected the binary file unchanged in binaryData, got %v", cm.BinaryData["data.gz"])
+	}
+}
+
+func TestReadConfigMapDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"app.conf":          "app",
+		"server.key":        "secret",
+		".komposeignore":    "# keys\n*.key\n",
+		".git/config":       "git",
+		"conf.d/extra.conf": "extra",
+		"conf.d/tls/a.key":  "secret",
+	}
+	for file, content := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755); err != nil {
+			t.Fatalf("Failed to create the directory of %s: %v", file, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+
+	testCases := map[string]struct {
+		labels        map[string]string
+		expectedKeys  []string
+		expectedItems []api.KeyToPath
+	}{
+		"Top level files": {nil, []string{"app.conf"}, nil},
+		"Recursive": {
+			map[string]string{compose.LabelVolumeConfigMapRecursive: "true", compose.LabelVolumeConfigMapIgnore: ".git, *.md"},
+			[]string{"app.conf", "conf.d_extra.conf"},
+			[]api.KeyToPath{{Key: "app.conf", Path: "app.conf"}, {Key: "conf.d_extra.conf", Path: "conf.d/extra.conf"}},
+		},
+		"Ignored directory": {
+			map[string]string{compose.LabelVolumeConfigMapRecursive: "true", compose.LabelVolumeConfigMapIgnore: ".git,conf.d"},
+			[]string{"app.conf"},
+			nil,
+		},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, items, err := readConfigMapDir(dir, kobject.ServiceConfig{Name: "web", Labels: test.labels})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			keys := make([]string, 0, len(data))
+			for key := range data {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, test.expectedKeys) {
+				t.Errorf("Expected the keys %v, got %v", test.expectedKeys, keys)
+			}
+			if !reflect.DeepEqual(items, test.expectedItems) {
+				t.Errorf("Expected the items %+v, got %+v", test.expectedItems, items)
+			}
+		})
+	}
+
+	if _, _, err := readConfigMapDir(dir, kobject.ServiceConfig{Name: "web", Labels: map[string]string{compose.LabelVolumeConfigMapIgnore: "[a-"}}); err == nil {
+		t.Errorf("Expected an error for an invalid pattern")
+	}
+}
+
+func TestAddLabelsAndAnnotations(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"io.kompose.service": "web"}},
+	}
+	service := &api.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	opt := kobject.ConvertOptions{
+		AddLabels:         map[string]string{"team": "frontend"},
+		AddAnnotations:    map[string]string{"owner": "frontend@example.com"},
+		AddPodLabels:      map[string]string{"cost-center": "42"},
+		AddPodAnnotations: map[string]string{"sidecar.istio.io/inject": "true"},
+	}
+
+	if err := AddLabelsAndAnnotations([]runtime.Object{deployment, service}, opt); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedLabels := map[string]string{"io.kompose.service": "web", "team": "frontend"}
+	if !reflect.DeepEqual(deployment.Labels, expectedLabels) {
+		t.Errorf("Expected deployment labels %v, got %v", expectedLabels, deployment.Labels)
+	}
+	if service.Labels["team"] != "frontend" || service.Annotations["owner"] != "frontend@example.com" {
+		t.Errorf("Expected the label and annotation on the service, got %v and %v", service.Labels, service.Annotations)
+	}
+	if deployment.Spec.Template.Labels["cost-center"] != "42" || deployment.Spec.Template.Annotations["sidecar.istio.io/inject"] != "true" {
+		t.Errorf("Expected the pod label and annotation on the pod template, got %v and %v", deployment.Spec.Template.Labels, deployment.Spec.Template.Annotations)
+	}
+	if _, ok := deployment.Labels["cost-center"]; ok {
+		t.Errorf("Expected the pod label on the pod template only")
+	}
+	if _, ok := deployment.Spec.Template.Labels["team"]; ok {
+		t.Errorf("Expected the label on the object metadata only")
+	}
+}
+
+func TestConfigSysctls(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:  "web",
+		Image: "nginx",
+		Sysctls: map[string]string{
+			"net.ipv4.tcp_syncookies": "1",
+			"net.core.somaxconn":      "1024",
+			"vm.max_map_count":        "262144",
+		},
+	}
+	safe := []api.Sysctl{{Name: "net.ipv4.tcp_syncookies", Value: "1"}}
+	unsafe := []api.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}, {Name: "net.ipv4.tcp_syncookies", Value: "1"}}
+
+	for name, test := range map[string]struct {
+		allowUnsafe bool
+		expected    []api.Sysctl
+	}{
+		"Safe sysctls only":  {false, safe},
+		"Unsafe sysctls too": {true, unsafe},
+	} {
+		t.Log("Test case:", name)
+		opt := kobject.ConvertOptions{CreateD: true, Replicas: 1, AllowUnsafeSysctls: test.allowUnsafe}
+		k := Kubernetes{}
+		objects, err := k.Transform(kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": service}}, opt)
+		if err != nil {
+			t.Fatalf("k.Transform failed: %v", err)
+		}
+		found := false
+		for _, obj := range objects {
+			if deployment, ok := obj.(*appsv1.Deployment); ok {
+				found = true
+				securityContext := deployment.Spec.Template.Spec.SecurityContext
+				if securityContext == nil || !reflect.DeepEqual(securityContext.Sysctls, test.expected) {
+					t.Errorf("Expected sysctls %v, got %v", test.expected, securityContext)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Expected a deployment to be generated")
+		}
+	}
+}
+
+func TestConfigHostAliases(t *testing.T) {
+	service := kobject.ServiceConfig{
+		ExtraHosts: types.HostsList{
+			"db.local":     {"10.0.0.2"},
+			"api.local":    {"10.0.0.1"},
+			"web.local":    {"10.0.0.1"},
+			"docker.local": {"host-gateway"},
+		},
+	}
+	expected := []api.HostAlias{
+		{IP: "10.0.0.1", Hostnames: []string{"api.local", "web.local"}},
+		{IP: "10.0.0.2", Hostnames: []string{"db.local"}},
+	}
+	if hostAliases := ConfigHostAliases("web", service); !reflect.DeepEqual(hostAliases, expected) {
+		t.Errorf("Expected host aliases %v, got %v", expected, hostAliases)
+	}
+
+	// the containers of a group share the host aliases of the pod
+	podSpec := PodSpec{}
+	podSpec.Append(
+		HostAliases("web", service),
+		HostAliases("cache", kobject.ServiceConfig{ExtraHosts: types.HostsList{"cache.local": {"10.0.0.1"}, "web.local": {"10.0.0.1"}}}),
+	)
+	expected[0].Hostnames = append(expected[0].Hostnames, "cache.local")
+	if !reflect.DeepEqual(podSpec.HostAliases, expected) {
+		t.Errorf("Expected merged host aliases %v, got %v", expected, podSpec.HostAliases)
+	}
+}
+
+func TestConfigDNS(t *testing.T) {
+	ndots := "2"
+	testCases := map[string]struct {
+		service   kobject.ServiceConfig
+		dnsConfig *api.PodDNSConfig
+		dnsPolicy api.DNSPolicy
+	}{
+		"No dns": {kobject.ServiceConfig{}, nil, ""},
+		"Nameservers replace the cluster DNS": {
+			kobject.ServiceConfig{DNS: []string{"1.1.1.1"}, DNSOpts: []string{"ndots:2", "use-vc"}},
+			&api.PodDNSConfig{Nameservers: []string{"1.1.1.1"}, Options: []api.PodDNSConfigOption{{Name: "ndots", Value: &ndots}, {Name: "use-vc"}}},
+			api.DNSNone,
+		},
+		"Search domains keep the cluster DNS": {
+			kobject.ServiceConfig{DNSSearch: []string{"example.com"}},
+			&api.PodDNSConfig{Searches: []string{"example.com"}},
+			"",
+		},
+	}
+
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		dnsConfig, dnsPolicy := ConfigDNS(test.service)
+		if !reflect.DeepEqual(dnsConfig, test.dnsConfig) || dnsPolicy != test.dnsPolicy {
+			t.Errorf("Expected %v and policy %q, got %v and policy %q", test.dnsConfig, test.dnsPolicy, dnsConfig, dnsPolicy)
+		}
+	}
+}
+
+func TestHostNamespaces(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:        "agent",
+		Image:       "node-exporter",
+		Pid:         "host",
+		Ipc:         "host",
+		NetworkMode: "host",
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"agent": service}}
+
+	for name, allow := range map[string]bool{"Not allowed": false, "Allowed": true} {
+		t.Log("Test case:", name)
+		opt := kobject.ConvertOptions{CreateD: true, Replicas: 1, AllowHostNamespaces: allow}
+		k := Kubernetes{}
+		objects, err := k.Transform(komposeObject, opt)
+		if err != nil {
+			t.Fatalf("k.Transform failed: %v", err)
+		}
+		found := false
+		for _, obj := range objects {
+			if deployment, ok := obj.(*appsv1.Deployment); ok {
+				found = true
+				podSpec := deployment.Spec.Template.Spec
+				if podSpec.HostPID != allow || podSpec.HostIPC != allow || podSpec.HostNetwork != allow {
+					t.Errorf("Expected the host namespaces to be shared: %v, got hostPID %v, hostIPC %v, hostNetwork %v", allow, podSpec.HostPID, podSpec.HostIPC, podSpec.HostNetwork)
+				}
+				if allow && podSpec.DNSPolicy != api.DNSClusterFirstWithHostNet {
+					t.Errorf("Expected the %s DNS policy, got %q", api.DNSClusterFirstWithHostNet, podSpec.DNSPolicy)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Expected a deployment to be generated")
+		}
+	}
+}
+
+func TestUpdateConfig(t *testing.T) {
+	two := uint64(2)
+	zero := uint64(0)
+	testCases := map[string]struct {
+		updateConfig        types.UpdateConfig
+		controller          string
+		expectedStrategy    appsv1.DeploymentStrategyType
+		expectedSurge       string
+		expectedUnavailable string
+		expectedSSStrategy  *appsv1.StatefulSetUpdateStrategy
+		expectedMinReady    int32
+	}{
+		"Recreate with the volumes by default": {types.UpdateConfig{}, "", appsv1.RecreateDeploymentStrategyType, "<nil>", "<nil>", nil, 0},
+		"Stop first by default":                {types.UpdateConfig{Parallelism: &two}, "", appsv1.RollingUpdateDeploymentStrategyType, "0", "2", nil, 0},
+		"Start first with a delay": {types.UpdateConfig{Parallelism: &two, Order: "start-first", Delay: types.Duration(10 * time.Second)},
+			"", appsv1.RollingUpdateDeploymentStrategyType, "2", "0", nil, 10},
+		"All the pods at once": {types.UpdateConfig{Parallelism: &zero, Order: "stop-first"}, "", appsv1.RollingUpdateDeploymentStrategyType, "0", "100%", nil, 0},
+		"StatefulSet": {types.UpdateConfig{Parallelism: &two, Delay: types.Duration(5 * time.Second)}, StatefulStateController, "", "", "",
+			&appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType, RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{MaxUnavailable: &intstr.IntOrString{IntVal: 2}}}, 5},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			komposeObject := newKomposeObject()
+			service := komposeObject.ServiceConfigs["app"]
+			service.DeployUpdateConfig = test.updateConfig
+			komposeObject.ServiceConfigs["app"] = service
+			opt := kobject.ConvertOptions{CreateD: test.controller == "", Controller: test.controller}
+
+			k := Kubernetes{}
+			objs, err := k.Transform(komposeObject, opt)
+			if err != nil {
+				t.Fatalf("k.Transform failed: %v", err)
+			}
+			for _, obj := range objs {
+				switch o := obj.(type) {
+				case *appsv1.Deployment:
+					if o.Spec.Strategy.Type != test.expectedStrategy {
+						t.Errorf("Expected the strategy %s, got %s", test.expectedStrategy, o.Spec.Strategy.Type)
+					}
+					var surge, unavailable *intstr.IntOrString
+					if o.Spec.Strategy.RollingUpdate != nil {
+						surge, unavailable = o.Spec.Strategy.RollingUpdate.MaxSurge, o.Spec.Strategy.RollingUpdate.MaxUnavailable
+					}
+					if surge.String() != test.expectedSurge || unavailable.String() != test.expectedUnavailable {
+						t.Errorf("Expected maxSurge %s and maxUnavailable %s, got %s and %s", test.expectedSurge, test.expectedUnavailable, surge, unavailable)
+					}
+					if o.Spec.MinReadySeconds != test.expectedMinReady {
+						t.Errorf("Expected minReadySeconds %d, got %d", test.expectedMinReady, o.Spec.MinReadySeconds)
+					}
+				case *appsv1.StatefulSet:
+					if !reflect.DeepEqual(&o.Spec.UpdateStrategy, test.expectedSSStrategy) {
+						t.Errorf("Expected the update strategy %+v, got %+v", test.expectedSSStrategy, o.Spec.UpdateStrategy)
+					}
+					if o.Spec.MinReadySeconds != test.expectedMinReady {
+						t.Errorf("Expected minReadySeconds %d, got %d", test.expectedMinReady, o.Spec.MinReadySeconds)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestConfigPodAntiAffinity(t *testing.T) {
+	term := api.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: transformer.ConfigLabels("app")},
+		TopologyKey:   "kubernetes.io/hostname",
+	}
+	zoneTerm := term
+	zoneTerm.TopologyKey = "topology.kubernetes.io/zone"
+
+	testCases := map[string]struct {
+		antiAffinity string
+		topologyKey  string
+		flag         string
+		expected     *api.PodAntiAffinity
+	}{
+		"None":      {"", "", "", nil},
+		"Preferred": {"preferred", "", "", &api.PodAntiAffinity{PreferredDuringSchedulingIgnoredDuringExecution: []api.WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: term}}}},
+		"Required across the zones": {"required", "topology.kubernetes.io/zone", "", &api.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []api.PodAffinityTerm{zoneTerm}}},
+		"Flag":                 {"", "", "required", &api.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []api.PodAffinityTerm{term}}},
+		"Label overrides flag": {"none", "", "required", nil},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			komposeObject := newKomposeObject()
+			service := komposeObject.ServiceConfigs["app"]
+			service.AntiAffinity = test.antiAffinity
+			service.AntiAffinityTopologyKey = test.topologyKey
+			komposeObject.ServiceConfigs["app"] = service
+			opt := kobject.ConvertOptions{CreateD: true, AntiAffinity: test.flag}
+
+			k := Kubernetes{}
+			objs, err := k.Transform(komposeObject, opt)
+			if err != nil {
+				t.Fatalf("k.Transform failed: %v", err)
+			}
+			for _, obj := range objs {
+				if d, ok := obj.(*appsv1.Deployment); ok {
+					var antiAffinity *api.PodAntiAffinity
+					if d.Spec.Template.Spec.Affinity != nil {
+						antiAffinity = d.Spec.Template.Spec.Affinity.PodAntiAffinity
+					}
+					if !reflect.DeepEqual(antiAffinity, test.expected) {
+						t.Errorf("Expected the pod anti-affinity %+v, got %+v", test.expected, antiAffinity)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRuntimeClassName(t *testing.T) {
+	komposeObject := newKomposeObject()
+	service := komposeObject.ServiceConfigs["app"]
+	service.RuntimeClassName = "gvisor"
+	komposeObject.ServiceConfigs["app"] = service
+
+	k := Kubernetes{}
+	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true})
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+	for _, obj := range objs {
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			runtimeClassName := d.Spec.Template.Spec.RuntimeClassName
+			if runtimeClassName == nil || *runtimeClassName != "gvisor" {
+				t.Errorf("Expected the runtime class gvisor, got %v", runtimeClassName)
+			}
+		}
+	}
+}
+
+func TestConfigMapVolumeMode(t *testing.T) {
+	service := newServiceConfig()
+	// the mode is decoded as in a compose file, 288 is 0440
+	var config types.ServiceConfigObjConfig
+	if err := json.Unmarshal([]byte(`{"source": "config", "target": "/etc/world", "mode": 288}`), &config); err != nil {
+		t.Fatalf("Unable to decode the config: %v", err)
+	}
+	service.Configs = []types.ServiceConfigObjConfig{config}
+
+	k := Kubernetes{}
+	podSpec := k.InitPodSpecWithConfigMap("app", "image", service)
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].ConfigMap == nil {
+		t.Fatalf("Expected a configmap volume, got %+v", podSpec.Volumes)
+	}
+	source := podSpec.Volumes[0].ConfigMap
+	if source.DefaultMode == nil || *source.DefaultMode != 0440 {
+		t.Errorf("Expected the default mode 0440, got %v", source.DefaultMode)
+	}
+	if len(source.Items) != 1 || source.Items[0].Mode == nil || *source.Items[0].Mode != 0440 {
+		t.Errorf("Expected the item mode 0440, got %+v", source.Items)
+	}
+}
+
+func TestSecretVolumeMode(t *testing.T) {
+	service := newServiceConfig()
+	var secret types.ServiceSecretConfig
+	if err := json.Unmarshal([]byte(`{"source": "db_password", "mode": 256}`), &secret); err != nil {
+		t.Fatalf("Unable to decode the secret: %v", err)
+	}
+	service.Secrets = []types.ServiceSecretConfig{secret}
+
+	k := Kubernetes{}
+	_, volumes := k.ConfigSecretVolumes("app", service)
+	if len(volumes) != 1 || volumes[0].Secret == nil {
+		t.Fatalf("Expected a secret volume, got %+v", volumes)
+	}
+	source := volumes[0].Secret
+	if source.DefaultMode == nil || *source.DefaultMode != 0400 {
+		t.Errorf("Expected the default mode 0400, got %v", source.DefaultMode)
+	}
+	if len(source.Items) != 1 || source.Items[0].Mode == nil || *source.Items[0].Mode != 0400 {
+		t.Errorf("Expected the item mode 0400, got %+v", source.Items)
+	}
+}
+
+func TestWarnFileOwner(t *testing.T) {
+	testCases := map[string]struct {
+		uid      string
+		gid      string
+		fsGroup  int64
+		warnings int
+	}{
+		"No owner":          {"", "", 0, 0},
+		"Uid":               {"1000", "", 0, 1},
+		"Gid of the pod":    {"", "1001", 1001, 0},
+		"Gid not the pod's": {"", "2000", 1001, 1},
+		"Uid and gid":       {"1000", "2000", 0, 2},
+	}
+
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+			warnFileOwner("secret", "db-password", "app", test.uid, test.gid, test.fsGroup)
+			warnings := 0
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == logrus.WarnLevel {
+					warnings++
+				}
+			}
+			if warnings != test.warnings {
+				t.Errorf("Expected %d warnings, got %d: %+v", test.warnings, warnings, hook.AllEntries())
+			}
+		})
+	}
+}
+
+func TestEnvFromSecret(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:        "web",
+		Environment: []kobject.EnvVar{{Name: "DB_HOST", Value: "db"}, {Name: "DB_PASSWORD", Value: "secret"}},
+		Labels:      map[string]string{compose.LabelEnvFromSecret: "DB_PASSWORD=db-credentials/password, API_TOKEN=api/token"},
+	}
+	envs, _, err := ConfigEnvs(service, kobject.ConvertOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []api.EnvVar{
+		{Name: "API_TOKEN", ValueFrom: &api.EnvVarSource{SecretKeyRef: &api.SecretKeySelector{LocalObjectReference: api.LocalObjectReference{Name: "api"}, Key: "token"}}},
+		{Name: "DB_HOST", Value: "db"},
+		{Name: "DB_PASSWORD", ValueFrom: &api.EnvVarSource{SecretKeyRef: &api.SecretKeySelector{LocalObjectReference: api.LocalObjectReference{Name: "db-credentials"}, Key: "password"}}},
+	}
+	if !reflect.DeepEqual(envs, expected) {
+		t.Errorf("Expected the environment %+v, got %+v", expected, envs)
+	}
+
+	service.Labels[compose.LabelEnvFromSecret] = "DB_PASSWORD=db-credentials"
+	if _, _, err := ConfigEnvs(service, kobject.ConvertOptions{}); err == nil {
+		t.Errorf("Expected an error for a reference without key")
+	}
+}
+
+func TestDownwardAPIEnvs(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name: "web",
+		Environment: []kobject.EnvVar{
+			{Name: "POD_NAME", Value: "{{.PodName}}"},
+			{Name: "POD_IP", Value: "{{.PodIP}}"},
+			{Name: "NODE", Value: "{{.NodeName}}"},
+			{Name: "GREETING", Value: "hello {{.PodName}}"},
+		},
+	}
+	envs, _, err := ConfigEnvs(service, kobject.ConvertOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fieldRef := func(fieldPath string) *api.EnvVarSource {
+		return &api.EnvVarSource{FieldRef: &api.ObjectFieldSelector{FieldPath: fieldPath}}
+	}
+	expected := []api.EnvVar{
+		{Name: "GREETING", Value: "hello {{.PodName}}"},
+		{Name: "NODE", ValueFrom: fieldRef("spec.nodeName")},
+		{Name: "POD_IP", ValueFrom: fieldRef("status.podIP")},
+		{Name: "POD_NAME", ValueFrom: fieldRef("metadata.name")},
+	}
+	if !reflect.DeepEqual(envs, expected) {
+		t.Errorf("Expected the environment %+v, got %+v", expected, envs)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/docker"
+	deployapi "github.com/openshift/api/apps/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// The recommended labels of Kubernetes, set with --standard-labels
+const (
+	LabelName      = "app.kubernetes.io/name"
+	LabelInstance  = "app.kubernetes.io/instance"
+	LabelVersion   = "app.kubernetes.io/version"
+	LabelComponent = "app.kubernetes.io/component"
+	LabelPartOf    = "app.kubernetes.io/part-of"
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+)
+
+// standardLabels returns the recommended labels of the objects of the service: the name of the application
+// is the name of its image, the component is the compose service and the instance is the compose project.
+// The selectors only use the instance and the component labels, the other ones may change.
+func standardLabels(service kobject.ServiceConfig, project string) (labels map[string]string, selector map[string]string) {
+	name := service.Name
+	version := ""
+	if image, err := docker.ParseImage(service.Image, ""); err == nil && service.Image != "" {
+		name = image.ShortName[strings.LastIndex(image.ShortName, "/")+1:]
+		// the digests aren't versions
+		if !strings.Contains(service.Image, "@") {
+			version = image.Tag
+		}
+	}
+
+	selector = map[string]string{
+		LabelInstance:  project,
+		LabelComponent: service.Name,
+	}
+	labels = map[string]string{
+		LabelName:      labelValue(name),
+		LabelInstance:  project,
+		LabelComponent: service.Name,
+		LabelPartOf:    project,
+		LabelManagedBy: "kompose",
+	}
+	if version = labelValue(version); version != "" {
+		labels[LabelVersion] = version
+	}
+	return labels, selector
+}
+
+// labelValue truncates the value to a valid label value, it is empty when the value isn't valid
+func labelValue(value string) string {
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	value = strings.TrimRight(value, "-_.")
+	if len(validation.IsValidLabelValue(value)) > 0 {
+		return ""
+	}
+	return value
+}
+
+// AddStandardLabels sets the recommended app.kubernetes.io labels on the objects, their pod templates and
+// their selectors. The objects of a compose service, labeled with its io.kompose.service label, get all of
+// them, the other objects only the project and kompose labels.
+func AddStandardLabels(objects []runtime.Object, komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) error {
+	project := labelValue(komposeObject.ProjectName)
+	if project == "" {
+		project = "kompose"
+	}
+	labels := map[string]map[string]string{}
+	selectors := map[string]map[string]string{}
+	for _, service := range komposeObject.ServiceConfigs {
+		labels[service.Name], selectors[service.Name] = standardLabels(service, project)
+	}
+	common := map[string]string{
+		LabelPartOf:    project,
+		LabelManagedBy: "kompose",
+	}
+
+	// the labels of the service of the io.kompose.service label of the map
+	serviceLabels := func(m map[string]string, from map[string]map[string]string) map[string]string {
+		if m == nil {
+			return nil
+		}
+		return from[m[transformer.Selector]]
+	}
+
+	k := Kubernetes{Opt: opt}
+	for _, obj := range objects {
+		if accessor, err := meta.Accessor(obj); err == nil {
+			values := serviceLabels(accessor.GetLabels(), labels)
+			if values == nil {
+				values = common
+			}
+			accessor.SetLabels(mergeStringMaps(accessor.GetLabels(), values))
+		}
+
+		err := k.UpdateController(obj, func(template *api.PodTemplateSpec) error {
+			template.Labels = mergeStringMaps(template.Labels, serviceLabels(template.Labels, labels))
+			return nil
+		}, func(*metav1.ObjectMeta) {})
+		if err != nil {
+			return err
+		}
+
+		// the selectors select the pods of the same service, whose templates got the labels above
+		switch t := obj.(type) {
+		case *appsv1.Deployment:
+			addSelectorLabels(t.Spec.Selector, selectors)
+		case *appsv1.StatefulSet:
+			addSelectorLabels(t.Spec.Selector, selectors)
+		case *appsv1.DaemonSet:
+			addSelectorLabels(t.Spec.Selector, selectors)
+		case *api.ReplicationController:
+			t.Spec.Selector = mergeStringMaps(t.Spec.Selector, serviceLabels(t.Spec.Selector, selectors))
+		case *deployapi.DeploymentConfig:
+			t.Spec.Selector = mergeStringMaps(t.Spec.Selector, serviceLabels(t.Spec.Selector, selectors))
+		case *api.Service:
+			t.Spec.Selector = mergeStringMaps(t.Spec.Selector, serviceLabels(t.Spec.Selector, selectors))
+		}
+	}
+	return nil
+}
+
+// addSelectorLabels adds the selector labels of the service selected by the label selector
+func addSelectorLabels(selector *metav1.LabelSelector, selectors map[string]map[string]string) {
+	if selector == nil || selector.MatchLabels == nil {
+		return
+	}
+	selector.MatchLabels = mergeStringMaps(selector.MatchLabels, selectors[selector.MatchLabels[transformer.Selector]])
+}
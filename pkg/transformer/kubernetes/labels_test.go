@@ -0,0 +1,122 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+)
+
+func TestAddStandardLabels(t *testing.T) {
+	web := kobject.ServiceConfig{
+		Name:  "web",
+		Image: "registry.example.com/team/nginx:1.27-alpine",
+		Port:  []kobject.Ports{{HostPort: 80, ContainerPort: 8080, Protocol: "TCP"}},
+	}
+	db := kobject.ServiceConfig{
+		Name:  "db",
+		Image: "postgres@sha256:4a54a5f7a3c1ed1d0a5a2b2d1e5f6a2c0d9c8b7a6f5e4d3c2b1a0f9e8d7c6b5a",
+		Port:  []kobject.Ports{{HostPort: 5432, ContainerPort: 5432, Protocol: "TCP"}},
+	}
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"web": web, "db": db},
+		ProjectName:    "shop",
+	}
+	opt := kobject.ConvertOptions{CreateD: true, Replicas: 1}
+
+	k := Kubernetes{Opt: opt}
+	objects, err := k.Transform(komposeObject, opt)
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+	if err := AddStandardLabels(objects, komposeObject, opt); err != nil {
+		t.Fatalf("AddStandardLabels failed: %v", err)
+	}
+
+	expectedLabels := map[string]map[string]string{
+		"web": {
+			"io.kompose.service": "web",
+			LabelName:            "nginx",
+			LabelInstance:        "shop",
+			LabelVersion:         "1.27-alpine",
+			LabelComponent:       "web",
+			LabelPartOf:          "shop",
+			LabelManagedBy:       "kompose",
+		},
+		// the digests aren't versions
+		"db": {
+			"io.kompose.service": "db",
+			LabelName:            "postgres",
+			LabelInstance:        "shop",
+			LabelComponent:       "db",
+			LabelPartOf:          "shop",
+			LabelManagedBy:       "kompose",
+		},
+	}
+	expectedSelectors := map[string]map[string]string{
+		"web": {"io.kompose.service": "web", LabelInstance: "shop", LabelComponent: "web"},
+		"db":  {"io.kompose.service": "db", LabelInstance: "shop", LabelComponent: "db"},
+	}
+
+	deployments, services := 0, 0
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			deployments++
+			if !reflect.DeepEqual(o.Labels, expectedLabels[o.Name]) {
+				t.Errorf("Expected labels %v for deployment %s, got %v", expectedLabels[o.Name], o.Name, o.Labels)
+			}
+			if !reflect.DeepEqual(o.Spec.Template.Labels, expectedLabels[o.Name]) {
+				t.Errorf("Expected template labels %v for deployment %s, got %v", expectedLabels[o.Name], o.Name, o.Spec.Template.Labels)
+			}
+			if !reflect.DeepEqual(o.Spec.Selector.MatchLabels, expectedSelectors[o.Name]) {
+				t.Errorf("Expected selector %v for deployment %s, got %v", expectedSelectors[o.Name], o.Name, o.Spec.Selector.MatchLabels)
+			}
+		case *api.Service:
+			services++
+			if !reflect.DeepEqual(o.Labels, expectedLabels[o.Name]) {
+				t.Errorf("Expected labels %v for service %s, got %v", expectedLabels[o.Name], o.Name, o.Labels)
+			}
+			if !reflect.DeepEqual(o.Spec.Selector, expectedSelectors[o.Name]) {
+				t.Errorf("Expected selector %v for service %s, got %v", expectedSelectors[o.Name], o.Name, o.Spec.Selector)
+			}
+		}
+	}
+	if deployments != 2 || services != 2 {
+		t.Errorf("Expected 2 deployments and 2 services, got %d and %d", deployments, services)
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	long := "1.0.0-aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	testCases := map[string]string{
+		"1.27-alpine": "1.27-alpine",
+		"latest":      "latest",
+		"":            "",
+		"bad/value":   "",
+		long:          long[:63],
+	}
+	for value, expected := range testCases {
+		if got := labelValue(value); got != expected {
+			t.Errorf("Expected %q for the label value %q, got %q", expected, value, got)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	"github.com/pkg/errors"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// LoggingSidecarImage is the image of the fluent-bit sidecars shipping the logs with --logging-sidecar
+	LoggingSidecarImage = "fluent/fluent-bit:3.1"
+
+	loggingSidecarName = "fluent-bit"
+	loggingConfigDir   = "/fluent-bit/config"
+	loggingConfigFile  = "fluent-bit.conf"
+)
+
+// loggingOutput returns the fluent-bit output shipping the logs as the logging driver of the service,
+// fluentd and gelf are supported
+func loggingOutput(service kobject.ServiceConfig) (string, error) {
+	options := service.LoggingOptions
+	var output []string
+	switch service.LoggingDriver {
+	case "fluentd":
+		address := options["fluentd-address"]
+		if address == "" {
+			address = "localhost:24224"
+		}
+		address = strings.TrimPrefix(address, "tcp://")
+		if strings.Contains(address, "://") {
+			return "", errors.Errorf("unsupported fluentd-address %s, only the tcp addresses are supported", address)
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid fluentd-address %s", address)
+		}
+		output = []string{"Name forward", "Host " + host, "Port " + port}
+		if tag := options["tag"]; tag != "" {
+			output = append(output, "Tag "+tag)
+		}
+	case "gelf":
+		address, err := url.Parse(options["gelf-address"])
+		if err != nil || address.Hostname() == "" || (address.Scheme != "udp" && address.Scheme != "tcp") {
+			return "", errors.Errorf("invalid gelf-address %q, expected udp://host:port or tcp://host:port", options["gelf-address"])
+		}
+		port := address.Port()
+		if port == "" {
+			port = "12201"
+		}
+		output = []string{"Name gelf", "Host " + address.Hostname(), "Port " + port, "Mode " + address.Scheme, "Gelf_Short_Message_Key log"}
+	default:
+		return "", errors.Errorf("unsupported logging driver %s, only fluentd and gelf are shipped by the sidecar", service.LoggingDriver)
+	}
+
+	var ignored []string
+	for option := range options {
+		if option != "fluentd-address" && option != "gelf-address" && option != "tag" {
+			ignored = append(ignored, option)
+		}
+	}
+	if len(ignored) > 0 {
+		sort.Strings(ignored)
+		dropped.Warnf("Ignoring the logging options %s of service %q", strings.Join(ignored, ", "), service.Name)
+	}
+	return "    Match *\n    " + strings.Join(output, "\n    ") + "\n", nil
+}
+
+// loggingConfig returns the configuration of fluent-bit, tailing the log files of the container the node
+// writes the standard output of the container to
+func loggingConfig(containerName string, output string) string {
+	return fmt.Sprintf(`[SERVICE]
+    Flush 1
+    Log_Level info
+
+[INPUT]
+    Name tail
+    Path /var/log/containers/${POD_NAME}_${POD_NAMESPACE}_%s-*.log
+    multiline.parser cri, docker
+    Tag %s
+    Refresh_Interval 5
+
+[OUTPUT]
+%s`, containerName, containerName, output)
+}
+
+// loggingSidecar returns the fluent-bit sidecar reading its configuration in the ConfigMap volume
+func loggingSidecar(name string, volume string) api.Container {
+	fieldEnv := func(name, path string) api.EnvVar {
+		return api.EnvVar{Name: name, ValueFrom: &api.EnvVarSource{FieldRef: &api.ObjectFieldSelector{FieldPath: path}}}
+	}
+	return api.Container{
+		Name:    name,
+		Image:   LoggingSidecarImage,
+		Command: []string{"/fluent-bit/bin/fluent-bit", "-c", loggingConfigDir + "/" + loggingConfigFile},
+		Env:     []api.EnvVar{fieldEnv("POD_NAME", "metadata.name"), fieldEnv("POD_NAMESPACE", "metadata.namespace")},
+		VolumeMounts: []api.VolumeMount{
+			{Name: volume, MountPath: loggingConfigDir, ReadOnly: true},
+			{Name: volume + "-logs", MountPath: "/var/log", ReadOnly: true},
+		},
+	}
+}
+
+// ConfigLoggingSidecar ships the logs of the services with a fluentd or gelf logging driver with a fluent-bit
+// sidecar, configured by a ConfigMap, which tails the log files of the container on the node
+func (k *Kubernetes) ConfigLoggingSidecar(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions, objects *[]runtime.Object) error {
+	if !opt.LoggingSidecar || service.LoggingDriver == "" {
+		return nil
+	}
+	output, err := loggingOutput(service)
+	if err != nil {
+		dropped.Warnf("Ignoring the logging driver of service %q: %s", name, err)
+		return nil
+	}
+
+	containerName := GetContainerName(service)
+	sidecarName := containerName + "-" + loggingSidecarName
+	volume := name + "-logging"
+	configMap := &api.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   volume,
+			Labels: transformer.ConfigLabels(name),
+		},
+		Data: map[string]string{loggingConfigFile: loggingConfig(containerName, output)},
+	}
+
+	added := false
+	for _, obj := range *objects {
+		updateTemplate := func(template *api.PodTemplateSpec) error {
+			if !hasContainer(template, containerName) || hasContainer(template, sidecarName) {
+				return nil
+			}
+			addSidecar(&template.Spec, loggingSidecar(sidecarName, volume), opt)
+			template.Spec.Volumes = append(template.Spec.Volumes,
+				api.Volume{Name: volume, VolumeSource: api.VolumeSource{ConfigMap: &api.ConfigMapVolumeSource{
+					LocalObjectReference: api.LocalObjectReference{Name: volume},
+				}}},
+				api.Volume{Name: volume + "-logs", VolumeSource: api.VolumeSource{HostPath: &api.HostPathVolumeSource{Path: "/var/log"}}},
+			)
+			added = true
+			return nil
+		}
+		if err := k.UpdateController(obj, updateTemplate, func(*metav1.ObjectMeta) {}); err != nil {
+			return err
+		}
+	}
+	if added {
+		*objects = append(*objects, configMap)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+)
+
+func TestLoggingSidecar(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:           "web",
+		Image:          "nginx",
+		LoggingDriver:  "fluentd",
+		LoggingOptions: map[string]string{"fluentd-address": "fluentd.logging:24224", "tag": "web"},
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": service}}
+	opt := kobject.ConvertOptions{CreateD: true, Replicas: 1, LoggingSidecar: true}
+
+	k := Kubernetes{Opt: opt}
+	objs, err := k.Transform(komposeObject, opt)
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+
+	var deployment *appsv1.Deployment
+	var configMap *api.ConfigMap
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			deployment = o
+		case *api.ConfigMap:
+			if o.Name == "web-logging" {
+				configMap = o
+			}
+		}
+	}
+	if deployment == nil || configMap == nil {
+		t.Fatalf("Expected a deployment and the web-logging ConfigMap, got %v", objs)
+	}
+
+	containers := deployment.Spec.Template.Spec.Containers
+	if len(containers) != 2 || containers[1].Name != "web-fluent-bit" || containers[1].Image != LoggingSidecarImage {
+		t.Fatalf("Expected the fluent-bit sidecar, got %+v", containers)
+	}
+	if len(deployment.Spec.Template.Spec.Volumes) != 2 {
+		t.Errorf("Expected the volumes of the configuration and of the logs, got %+v", deployment.Spec.Template.Spec.Volumes)
+	}
+
+	config := configMap.Data[loggingConfigFile]
+	for _, expected := range []string{
+		"Path /var/log/containers/${POD_NAME}_${POD_NAMESPACE}_web-*.log",
+		"Name forward",
+		"Host fluentd.logging",
+		"Port 24224",
+		"Tag web",
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("Expected the configuration to contain %q, got:\n%s", expected, config)
+		}
+	}
+}
+
+func TestLoggingOutput(t *testing.T) {
+	output, err := loggingOutput(kobject.ServiceConfig{LoggingDriver: "gelf", LoggingOptions: map[string]string{"gelf-address": "udp://graylog:12201"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{"Name gelf", "Host graylog", "Port 12201", "Mode udp"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected the gelf output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	for _, service := range []kobject.ServiceConfig{
+		{LoggingDriver: "syslog"},
+		{LoggingDriver: "gelf"},
+		{LoggingDriver: "fluentd", LoggingOptions: map[string]string{"fluentd-address": "unix:///var/run/fluentd.sock"}},
+	} {
+		if _, err := loggingOutput(service); err == nil {
+			t.Errorf("Expected an error for the logging driver %s %v", service.LoggingDriver, service.LoggingOptions)
+		}
+	}
+}
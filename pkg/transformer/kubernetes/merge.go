@@ -0,0 +1,235 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// MergeBaseDir is the directory of the output directory where the generated manifests
+// are kept, they are the common ancestor of the next three-way merge
+const MergeBaseDir = ".kompose"
+
+// mergeWithPrevious merges the generated manifest with the file of the previous conversion,
+// keeping the manual edits made to it since it was generated.
+// The generated manifest is then stored to be used as the base of the next merge.
+func mergeWithPrevious(dirName, file string, generated []byte, opt kobject.ConvertOptions) ([]byte, error) {
+	rel, err := filepath.Rel(dirName, file)
+	if err != nil {
+		return nil, err
+	}
+	basePath := filepath.Join(dirName, MergeBaseDir, rel)
+
+	merged, err := threeWayMerge(basePath, file, generated, opt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to merge %q", file)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(basePath), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(basePath, generated, 0644); err != nil {
+		return nil, errors.Wrapf(err, "failed to save %q", basePath)
+	}
+	return merged, nil
+}
+
+func threeWayMerge(basePath, file string, generated []byte, opt kobject.ConvertOptions) ([]byte, error) {
+	onDisk, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return generated, nil
+	} else if err != nil {
+		return nil, err
+	}
+	previous, err := os.ReadFile(basePath)
+	if os.IsNotExist(err) {
+		log.Warnf("No previously generated version of %q, it is overwritten", file)
+		return generated, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var base, current, disk interface{}
+	for _, doc := range []struct {
+		data []byte
+		out  *interface{}
+	}{{previous, &base}, {generated, &current}, {onDisk, &disk}} {
+		if err := yaml.Unmarshal(doc.data, doc.out); err != nil {
+			return nil, err
+		}
+	}
+
+	// keep the files as they are written when only one side changed
+	if reflect.DeepEqual(disk, base) {
+		return generated, nil
+	}
+	if reflect.DeepEqual(current, base) || reflect.DeepEqual(current, disk) {
+		log.Infof("Keeping the manual edits of %q", file)
+		return onDisk, nil
+	}
+
+	log.Infof("Merging the manual edits of %q", file)
+	merged := mergeValues(filepath.Base(file), base, current, disk)
+	if opt.GenerateJSON {
+		return json.MarshalIndent(merged, "", "  ")
+	}
+	return marshalWithIndent(merged, opt.YAMLIndent)
+}
+
+// mergeValues merges the changes made to base by the generator (current) and by hand (disk),
+// the manual edit wins when both changed the same value
+func mergeValues(path string, base, current, disk interface{}) interface{} {
+	if reflect.DeepEqual(disk, base) {
+		return current
+	}
+	if reflect.DeepEqual(current, base) || reflect.DeepEqual(current, disk) {
+		return disk
+	}
+
+	baseMap, _ := base.(map[string]interface{})
+	currentMap, currentIsMap := current.(map[string]interface{})
+	diskMap, diskIsMap := disk.(map[string]interface{})
+	if currentIsMap && diskIsMap {
+		return mergeMaps(path, baseMap, currentMap, diskMap)
+	}
+
+	baseList, _ := base.([]interface{})
+	currentList, currentIsList := current.([]interface{})
+	diskList, diskIsList := disk.([]interface{})
+	if currentIsList && diskIsList && isNamedList(baseList) && isNamedList(currentList) && isNamedList(diskList) {
+		return mergeNamedLists(path, baseList, currentList, diskList)
+	}
+
+	log.Warnf("Conflict on %s, keeping the manual edit", path)
+	return disk
+}
+
+func mergeMaps(path string, base, current, disk map[string]interface{}) map[string]interface{} {
+	keys := map[string]bool{}
+	for _, m := range []map[string]interface{}{base, current, disk} {
+		for key := range m {
+			keys[key] = true
+		}
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	merged := map[string]interface{}{}
+	for _, key := range sortedKeys {
+		if value, ok := mergeEntry(path+"."+key, base, current, disk, key); ok {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// mergeEntry merges a key of a map, it returns false when the key is removed
+func mergeEntry(path string, base, current, disk map[string]interface{}, key string) (interface{}, bool) {
+	b, inBase := base[key]
+	c, inCurrent := current[key]
+	d, inDisk := disk[key]
+
+	switch {
+	case inCurrent && inDisk:
+		return mergeValues(path, b, c, d), true
+	case inCurrent:
+		// added by the generator, or removed by hand
+		if !inBase {
+			return c, true
+		}
+		if !reflect.DeepEqual(b, c) {
+			log.Warnf("Conflict on %s, keeping the manual removal", path)
+		}
+		return nil, false
+	case inDisk:
+		// added by hand, or removed by the generator
+		if !inBase {
+			return d, true
+		}
+		if !reflect.DeepEqual(b, d) {
+			log.Warnf("Conflict on %s, keeping the manual edit", path)
+			return d, true
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+// isNamedList checks if all the items of a list are objects with a name, like containers or ports
+func isNamedList(list []interface{}) bool {
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := m["name"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeNamedLists(path string, base, current, disk []interface{}) []interface{} {
+	index := func(list []interface{}) map[string]map[string]interface{} {
+		items := map[string]map[string]interface{}{}
+		for _, item := range list {
+			m := item.(map[string]interface{})
+			items[m["name"].(string)] = m
+		}
+		return items
+	}
+	baseItems, currentItems, diskItems := index(base), index(current), index(disk)
+	wrap := func(items map[string]map[string]interface{}) map[string]interface{} {
+		m := map[string]interface{}{}
+		for name, item := range items {
+			m[name] = item
+		}
+		return m
+	}
+	baseMap, currentMap, diskMap := wrap(baseItems), wrap(currentItems), wrap(diskItems)
+
+	var merged []interface{}
+	for _, item := range current {
+		name := item.(map[string]interface{})["name"].(string)
+		if value, ok := mergeEntry(fmt.Sprintf("%s[%s]", path, name), baseMap, currentMap, diskMap, name); ok {
+			merged = append(merged, value)
+		}
+	}
+	for _, item := range disk {
+		name := item.(map[string]interface{})["name"].(string)
+		if _, ok := currentItems[name]; ok {
+			continue
+		}
+		if value, ok := mergeEntry(fmt.Sprintf("%s[%s]", path, name), baseMap, currentMap, diskMap, name); ok {
+			merged = append(merged, value)
+		}
+	}
+	return merged
+}
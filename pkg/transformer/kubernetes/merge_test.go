@@ -0,0 +1,147 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMergeWithPrevious(t *testing.T) {
+	previous := `kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.25
+        - name: sidecar
+          image: busybox
+`
+	onDisk := `kind: Deployment
+metadata:
+  name: web
+  annotations:
+    team: frontend
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.25
+          resources:
+            limits:
+              memory: 512Mi
+        - name: sidecar
+          image: busybox
+        - name: proxy
+          image: envoy
+`
+	generated := `kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.27
+`
+	expected := `kind: Deployment
+metadata:
+  name: web
+  annotations:
+    team: frontend
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.27
+          resources:
+            limits:
+              memory: 512Mi
+        - name: proxy
+          image: envoy
+`
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "web-deployment.yaml")
+	basePath := filepath.Join(dir, MergeBaseDir, "web-deployment.yaml")
+	if err := os.MkdirAll(filepath.Dir(basePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(basePath, []byte(previous), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(onDisk), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := mergeWithPrevious(dir, file, []byte(generated), kobject.ConvertOptions{YAMLIndent: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var got, want interface{}
+	if err := yaml.Unmarshal(merged, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal([]byte(expected), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected merged manifest:\n%s\ngot:\n%s", expected, merged)
+	}
+
+	base, err := os.ReadFile(basePath)
+	if err != nil || string(base) != generated {
+		t.Errorf("Expected the generated manifest to be saved as the next base, got %q (%v)", base, err)
+	}
+}
+
+func TestMergeWithPreviousWithoutEdits(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "web-service.yaml")
+	generated := []byte("kind: Service\nmetadata:\n  name: web\n")
+
+	// first conversion, nothing to merge with
+	merged, err := mergeWithPrevious(dir, file, generated, kobject.ConvertOptions{})
+	if err != nil || string(merged) != string(generated) {
+		t.Fatalf("Expected the generated manifest, got %q (%v)", merged, err)
+	}
+	if err := os.WriteFile(file, merged, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// file not edited, the new generated manifest is used as is
+	regenerated := []byte("kind: Service\nmetadata:\n  name: web\n  labels:\n    app: web\n")
+	merged, err = mergeWithPrevious(dir, file, regenerated, kobject.ConvertOptions{})
+	if err != nil || string(merged) != string(regenerated) {
+		t.Errorf("Expected the regenerated manifest, got %q (%v)", merged, err)
+	}
+}
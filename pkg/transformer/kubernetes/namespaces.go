@@ -0,0 +1,321 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/loader/compose"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	log "github.com/sirupsen/logrus"
+	api "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// NamespaceByGroup places the services in the namespace named after their kompose.service.group label
+	NamespaceByGroup = "group"
+	// NamespaceByNetwork places the services in the namespace named after their compose network
+	NamespaceByNetwork = "network"
+)
+
+// namespaceName returns the name of a group or a network usable as a namespace
+func namespaceName(name string) string {
+	name = strings.Trim(strings.NewReplacer(".", "-", "_", "-").Replace(strings.ToLower(name)), "-")
+	return transformer.TruncateName(name, transformer.MaxNameLength)
+}
+
+// ServiceNamespaces returns the namespace of each service, named after its kompose.service.group label or
+// after its network. The services without group, or only on the default network, are left out: they stay
+// in the namespace of the project.
+func ServiceNamespaces(services map[string]kobject.ServiceConfig, by string) map[string]string {
+	namespaces := map[string]string{}
+	for _, name := range SortedKeys(services) {
+		service := services[name]
+		var namespace string
+		switch by {
+		case NamespaceByGroup:
+			namespace = service.Labels[compose.LabelServiceGroup]
+		case NamespaceByNetwork:
+			var networks []string
+			for _, network := range service.Network {
+				if network != "default" && !strings.HasSuffix(network, "-default") {
+					networks = append(networks, network)
+				}
+			}
+			sort.Strings(networks)
+			if len(networks) > 1 {
+				log.Warnf("Service %q is on the networks %s, it is placed in the namespace of %s", name, strings.Join(networks, ", "), networks[0])
+			}
+			if len(networks) > 0 {
+				namespace = networks[0]
+			}
+		}
+		if namespace = namespaceName(namespace); namespace != "" {
+			namespaces[name] = namespace
+		}
+	}
+	return namespaces
+}
+
+// SplitNamespaces places the objects of the services in the namespaces of ServiceNamespaces and adds the
+// Namespace objects with --create-namespace. The host names of the Services of the other namespaces are qualified with their
+// namespace in the environment of the containers, and the ConfigMaps and the Secrets mounted across the
+// namespaces are copied into the namespace of their workload.
+func (k *Kubernetes) SplitNamespaces(objects *[]runtime.Object, komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) {
+	namespaces := ServiceNamespaces(komposeObject.ServiceConfigs, opt.NamespaceBy)
+	if len(namespaces) == 0 {
+		return
+	}
+	if opt.NamespaceBy == NamespaceByGroup {
+		// the workloads of a group are named after it
+		for _, namespace := range namespaces {
+			namespaces[namespace] = namespace
+		}
+	}
+	services := make([]string, 0, len(namespaces))
+	for service := range namespaces {
+		services = append(services, service)
+	}
+	SortServicesByLength(services)
+
+	existing := map[string]bool{}
+	for _, obj := range *objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if kind == "Namespace" {
+			existing[accessor.GetName()] = true
+			continue
+		}
+		if transformer.IsClusterScoped(kind) {
+			continue
+		}
+		// the default deny network policy of the project stays in its namespace
+		if np, ok := obj.(*networkingv1.NetworkPolicy); ok && len(np.Spec.PodSelector.MatchLabels) == 0 {
+			continue
+		}
+		service := ServiceOf(services, accessor.GetLabels()[transformer.Selector], accessor.GetName())
+		if namespace, ok := namespaces[service]; ok {
+			accessor.SetNamespace(namespace)
+		}
+	}
+
+	var created, denied []string
+	// the default deny network policy of the project is already in its namespace
+	deniedSet := map[string]bool{komposeObject.Namespace: true}
+	for _, namespace := range namespaces {
+		if !existing[namespace] {
+			existing[namespace] = true
+			created = append(created, namespace)
+		}
+		if opt.GenerateNetworkPolicies && !deniedSet[namespace] {
+			deniedSet[namespace] = true
+			denied = append(denied, namespace)
+		}
+	}
+	sort.Strings(created)
+	for _, namespace := range created {
+		if opt.CreateNamespace {
+			*objects = append(*objects, transformer.CreateNamespace(namespace))
+		}
+	}
+	sort.Strings(denied)
+	for _, namespace := range denied {
+		*objects = append(*objects, k.CreateDefaultDenyNetworkPolicy(namespace))
+	}
+
+	k.copyCrossNamespaceReferences(objects)
+	k.qualifyServiceHosts(*objects, komposeObject.Namespace)
+	k.SortObjects(objects)
+}
+
+// qualifyServiceHosts qualifies the host names of the Services of the other namespaces in the environment
+// of the containers and in their env ConfigMaps, as NAME.NAMESPACE.svc, and in the init containers waiting
+// for the dependencies
+func (k *Kubernetes) qualifyServiceHosts(objects []runtime.Object, defaultNamespace string) {
+	if defaultNamespace == "" {
+		defaultNamespace = "default"
+	}
+	hosts := map[string]string{}
+	configMaps := map[string]*api.ConfigMap{}
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *api.Service:
+			hosts[o.Name] = o.Namespace
+			if o.Namespace == "" {
+				hosts[o.Name] = defaultNamespace
+			}
+		case *api.ConfigMap:
+			configMaps[o.Namespace+"/"+o.Name] = o
+		}
+	}
+
+	qualify := func(value, namespace string) string {
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		for host, hostNamespace := range hosts {
+			if hostNamespace == namespace || !strings.Contains(value, host) {
+				continue
+			}
+			pattern := regexp.MustCompile(`(^|[^A-Za-z0-9.-])` + regexp.QuoteMeta(host) + `([^A-Za-z0-9.-]|$)`)
+			value = pattern.ReplaceAllString(value, fmt.Sprintf("${1}%s.%s.svc${2}", host, hostNamespace))
+		}
+		return value
+	}
+
+	qualified := map[*api.ConfigMap]bool{}
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		namespace := accessor.GetNamespace()
+		qualifyContainer := func(container *api.Container) {
+			for i := range container.Env {
+				container.Env[i].Value = qualify(container.Env[i].Value, namespace)
+			}
+			for _, envFrom := range container.EnvFrom {
+				if envFrom.ConfigMapRef == nil {
+					continue
+				}
+				configMap, ok := configMaps[namespace+"/"+envFrom.ConfigMapRef.Name]
+				if !ok || qualified[configMap] {
+					continue
+				}
+				qualified[configMap] = true
+				for key, value := range configMap.Data {
+					configMap.Data[key] = qualify(value, namespace)
+				}
+			}
+			if strings.HasPrefix(container.Name, "wait-for-") {
+				for i := range container.Command {
+					container.Command[i] = qualify(container.Command[i], namespace)
+				}
+			}
+		}
+		_ = k.UpdateController(obj, func(template *api.PodTemplateSpec) error {
+			for i := range template.Spec.InitContainers {
+				qualifyContainer(&template.Spec.InitContainers[i])
+			}
+			for i := range template.Spec.Containers {
+				qualifyContainer(&template.Spec.Containers[i])
+			}
+			return nil
+		}, func(*metav1.ObjectMeta) {})
+	}
+}
+
+// copyCrossNamespaceReferences copies the ConfigMaps and the Secrets referenced by the workloads of another
+// namespace into it. The claims can't be shared across the namespaces, they are reported.
+func (k *Kubernetes) copyCrossNamespaceReferences(objects *[]runtime.Object) {
+	byName := map[string][]runtime.Object{}
+	present := map[string]bool{}
+	for _, obj := range *objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		key := obj.GetObjectKind().GroupVersionKind().Kind + "/" + accessor.GetName()
+		byName[key] = append(byName[key], obj)
+		present[key+"/"+accessor.GetNamespace()] = true
+	}
+
+	var copies []runtime.Object
+	for _, obj := range *objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		namespace := accessor.GetNamespace()
+		_ = k.UpdateController(obj, func(template *api.PodTemplateSpec) error {
+			for _, ref := range podReferences(template.Spec) {
+				if present[ref+"/"+namespace] || len(byName[ref]) == 0 {
+					continue
+				}
+				if strings.HasPrefix(ref, "PersistentVolumeClaim/") {
+					log.Warnf("The %s of the namespace %s is mounted by %s in the namespace %s, a claim can't be shared across the namespaces", ref, namespaceOf(byName[ref][0]), accessor.GetName(), namespace)
+					continue
+				}
+				copied := byName[ref][0].DeepCopyObject()
+				if copiedAccessor, err := meta.Accessor(copied); err == nil {
+					copiedAccessor.SetNamespace(namespace)
+				}
+				present[ref+"/"+namespace] = true
+				copies = append(copies, copied)
+			}
+			return nil
+		}, func(*metav1.ObjectMeta) {})
+	}
+	*objects = append(*objects, copies...)
+}
+
+// podReferences returns the ConfigMaps, the Secrets and the claims referenced by the pod spec, as KIND/NAME
+func podReferences(spec api.PodSpec) []string {
+	var refs []string
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.ConfigMap != nil:
+			refs = append(refs, "ConfigMap/"+volume.ConfigMap.Name)
+		case volume.Secret != nil:
+			refs = append(refs, "Secret/"+volume.Secret.SecretName)
+		case volume.PersistentVolumeClaim != nil:
+			refs = append(refs, "PersistentVolumeClaim/"+volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	containers := append(append([]api.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				refs = append(refs, "ConfigMap/"+envFrom.ConfigMapRef.Name)
+			}
+			if envFrom.SecretRef != nil {
+				refs = append(refs, "Secret/"+envFrom.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				refs = append(refs, "ConfigMap/"+env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				refs = append(refs, "Secret/"+env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	return refs
+}
+
+// namespaceOf returns the namespace of the object
+func namespaceOf(obj runtime.Object) string {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		return accessor.GetNamespace()
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestServiceNamespaces(t *testing.T) {
+	services := map[string]kobject.ServiceConfig{
+		"web":    {Labels: map[string]string{"kompose.service.group": "Front_End"}, Network: []string{"shop-frontend", "shop-default"}},
+		"api":    {Labels: map[string]string{"kompose.service.group": "back"}, Network: []string{"shop-frontend", "shop-backend"}},
+		"db":     {Network: []string{"shop-backend"}},
+		"worker": {Network: []string{"shop-default"}},
+	}
+
+	byGroup := ServiceNamespaces(services, NamespaceByGroup)
+	if expected := map[string]string{"web": "front-end", "api": "back"}; !reflect.DeepEqual(byGroup, expected) {
+		t.Errorf("Expected the namespaces %v by group, got %v", expected, byGroup)
+	}
+	byNetwork := ServiceNamespaces(services, NamespaceByNetwork)
+	if expected := map[string]string{"web": "shop-frontend", "api": "shop-backend", "db": "shop-backend"}; !reflect.DeepEqual(byNetwork, expected) {
+		t.Errorf("Expected the namespaces %v by network, got %v", expected, byNetwork)
+	}
+}
+
+func TestSplitNamespaces(t *testing.T) {
+	objectMeta := func(name, service string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{transformer.Selector: service}}
+	}
+	deployment := func(name string, container api.Container) *appsv1.Deployment {
+		container.Name = name
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: objectMeta(name, name),
+			Spec: appsv1.DeploymentSpec{Template: api.PodTemplateSpec{Spec: api.PodSpec{
+				Containers: []api.Container{container},
+				Volumes:    []api.Volume{{Name: "token", VolumeSource: api.VolumeSource{Secret: &api.SecretVolumeSource{SecretName: "token"}}}},
+			}}},
+		}
+	}
+	service := func(name string) *api.Service {
+		return &api.Service{TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"}, ObjectMeta: objectMeta(name, name)}
+	}
+	web := deployment("web", api.Container{
+		Env:     []api.EnvVar{{Name: "API_URL", Value: "http://api:8080"}, {Name: "SITE", Value: "api.example.com"}},
+		EnvFrom: []api.EnvFromSource{{ConfigMapRef: &api.ConfigMapEnvSource{LocalObjectReference: api.LocalObjectReference{Name: "web-env"}}}},
+	})
+	configMap := &api.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: objectMeta("web-env", "web"),
+		Data:       map[string]string{"DATABASE_URL": "postgres://db:5432/shop"},
+	}
+	secret := &api.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "shop"},
+	}
+	objects := []runtime.Object{web, configMap, service("api"), deployment("api", api.Container{}), service("db"), secret}
+
+	komposeObject := kobject.KomposeObject{
+		Namespace: "shop",
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web": {Labels: map[string]string{"kompose.service.group": "front"}},
+			"api": {Labels: map[string]string{"kompose.service.group": "back"}},
+			"db":  {},
+		},
+	}
+	k := Kubernetes{}
+	k.SplitNamespaces(&objects, komposeObject, kobject.ConvertOptions{NamespaceBy: NamespaceByGroup, CreateNamespace: true})
+
+	var got []string
+	for _, obj := range objects {
+		accessor, _ := meta.Accessor(obj)
+		got = append(got, obj.GetObjectKind().GroupVersionKind().Kind+" "+accessor.GetNamespace()+"/"+accessor.GetName())
+	}
+	expected := []string{
+		"Namespace /back",
+		"Namespace /front",
+		"ConfigMap front/web-env",
+		"Secret back/token",
+		"Secret front/token",
+		"Secret shop/token",
+		"Service back/api",
+		"Service shop/db",
+		"Deployment back/api",
+		"Deployment front/web",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the objects %v, got %v", expected, got)
+	}
+
+	env := web.Spec.Template.Spec.Containers[0].Env
+	if env[0].Value != "http://api.back.svc:8080" || env[1].Value != "api.example.com" {
+		t.Errorf("Expected the host of api to be qualified with its namespace, got %v", env)
+	}
+	if value := configMap.Data["DATABASE_URL"]; value != "postgres://db.shop.svc:5432/shop" {
+		t.Errorf("Expected the host of db to be qualified in the env ConfigMap, got %q", value)
+	}
+}
+
+func TestSplitNamespacesDefaultDeny(t *testing.T) {
+	k := Kubernetes{}
+	deployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: map[string]string{transformer.Selector: name}},
+		}
+	}
+	objects := []runtime.Object{deployment("web"), deployment("default"), k.CreateDefaultDenyNetworkPolicy("shop")}
+	komposeObject := kobject.KomposeObject{
+		Namespace: "shop",
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web":     {Labels: map[string]string{"kompose.service.group": "front"}},
+			"default": {Labels: map[string]string{"kompose.service.group": "back"}},
+		},
+	}
+	k.SplitNamespaces(&objects, komposeObject, kobject.ConvertOptions{NamespaceBy: NamespaceByGroup, GenerateNetworkPolicies: true})
+
+	var got []string
+	for _, obj := range objects {
+		if np, ok := obj.(*networkingv1.NetworkPolicy); ok {
+			got = append(got, np.Namespace+"/"+np.Name)
+		}
+	}
+	expected := []string{"back/default-deny", "front/default-deny", "shop/default-deny"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected a default deny network policy per namespace %v, got %v", expected, got)
+	}
+}
+
+func TestSplitNamespacesWithoutCreateNamespace(t *testing.T) {
+	objects := []runtime.Object{&appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{transformer.Selector: "web"}},
+	}}
+	komposeObject := kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{"web": {Labels: map[string]string{"kompose.service.group": "front"}}},
+	}
+	k := Kubernetes{}
+	k.SplitNamespaces(&objects, komposeObject, kobject.ConvertOptions{NamespaceBy: NamespaceByGroup})
+
+	if len(objects) != 1 {
+		t.Fatalf("Expected no Namespace without --create-namespace, got %v", objects)
+	}
+	if namespace := objects[0].(*appsv1.Deployment).Namespace; namespace != "front" {
+		t.Errorf("Expected the deployment in the namespace front, got %q", namespace)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	goruntime "runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// transformWorkers is the number of services transformed at a time
+var transformWorkers = goruntime.GOMAXPROCS(0)
+
+// forEachParallel calls fn with the indexes from 0 to n-1, with transformWorkers calls at a time,
+// and returns the error of the lowest index, so that the same error is reported on each run. A panic
+// of fn is returned as its error, it would otherwise end the process, the conversion API included.
+func forEachParallel(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < transformWorkers && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = callRecovered(fn, i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// callRecovered calls fn with i, and returns its panic as an error
+func callRecovered(fn func(i int) error, i int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("unexpected error: %v", r)
+		}
+	}()
+	return fn(i)
+}
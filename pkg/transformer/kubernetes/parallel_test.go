@@ -0,0 +1,89 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestForEachParallel(t *testing.T) {
+	var calls [100]int32
+	err := forEachParallel(len(calls), func(i int) error {
+		atomic.AddInt32(&calls[i], 1)
+		if i%10 == 7 {
+			return fmt.Errorf("error %d", i)
+		}
+		return nil
+	})
+	for i, count := range calls {
+		if count != 1 {
+			t.Errorf("Expected index %d to be called once, got %d calls", i, count)
+		}
+	}
+	if err == nil || err.Error() != "error 7" {
+		t.Errorf("Expected the error of the lowest index, got %v", err)
+	}
+}
+
+func TestForEachParallelPanic(t *testing.T) {
+	err := forEachParallel(10, func(i int) error {
+		if i == 3 {
+			var service *kobject.ServiceConfig
+			return fmt.Errorf("service %s", service.Name)
+		}
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "unexpected error") {
+		t.Errorf("Expected the panic to be returned as an error, got %v", err)
+	}
+}
+
+func TestTransformKeepsServiceOrder(t *testing.T) {
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{}}
+	for i := 0; i < 50; i++ {
+		service := newSimpleServiceConfig()
+		service.Name = fmt.Sprintf("service-%02d", i)
+		komposeObject.ServiceConfigs[service.Name] = service
+	}
+
+	k := Kubernetes{}
+	objects, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, obj := range objects {
+		if deployment, ok := obj.(*appsv1.Deployment); ok {
+			names = append(names, deployment.Name)
+		}
+	}
+	if len(names) != 50 {
+		t.Fatalf("Expected 50 deployments, got %d", len(names))
+	}
+	for i, name := range names {
+		if expected := fmt.Sprintf("service-%02d", i); name != expected {
+			t.Errorf("Expected deployment %d to be %s, got %s", i, expected, name)
+		}
+	}
+}
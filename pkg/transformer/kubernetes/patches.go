@@ -0,0 +1,212 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+// patchMergeKeys are the keys identifying the items of the lists merged item by item, like a
+// strategic merge patch does. The other lists of a patch replace the generated ones.
+var patchMergeKeys = map[string][]string{
+	"containers":          {"name"},
+	"initContainers":      {"name"},
+	"ephemeralContainers": {"name"},
+	"volumes":             {"name"},
+	"env":                 {"name"},
+	"volumeMounts":        {"mountPath"},
+	"imagePullSecrets":    {"name"},
+	"hostAliases":         {"ip"},
+	"tolerations":         {"key"},
+	// the container ports and the service ports
+	"ports": {"containerPort", "port"},
+}
+
+// mergePatch merges the patch into the object like a JSON merge patch, a null value removes the
+// field, except that the lists of patchMergeKeys are merged item by item
+func mergePatch(object, patch map[string]interface{}) map[string]interface{} {
+	if object == nil {
+		object = map[string]interface{}{}
+	}
+	for key, value := range patch {
+		switch v := value.(type) {
+		case nil:
+			delete(object, key)
+		case map[string]interface{}:
+			original, _ := object[key].(map[string]interface{})
+			object[key] = mergePatch(original, v)
+		case []interface{}:
+			original, _ := object[key].([]interface{})
+			if keys, ok := patchMergeKeys[key]; ok {
+				object[key] = mergeList(original, v, keys)
+			} else {
+				object[key] = v
+			}
+		default:
+			object[key] = v
+		}
+	}
+	return object
+}
+
+// mergeList merges the items of the patch into the items of the original list having the same
+// merge key, the other items are appended
+func mergeList(original, patch []interface{}, keys []string) []interface{} {
+	mergeKey := func(item interface{}) interface{} {
+		if m, ok := item.(map[string]interface{}); ok {
+			for _, key := range keys {
+				if value, ok := m[key]; ok {
+					return value
+				}
+			}
+		}
+		return nil
+	}
+
+	merged := append([]interface{}{}, original...)
+	for _, item := range patch {
+		patchItem, ok := item.(map[string]interface{})
+		key := mergeKey(item)
+		if !ok || key == nil {
+			merged = append(merged, item)
+			continue
+		}
+		found := false
+		for i, originalItem := range merged {
+			if reflect.DeepEqual(mergeKey(originalItem), key) {
+				original, _ := originalItem.(map[string]interface{})
+				merged[i] = mergePatch(original, patchItem)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, patchItem)
+		}
+	}
+	return merged
+}
+
+// normalizePatch converts the values of a patch decoded from the compose file to the ones of
+// the unstructured objects, integers as int64
+func normalizePatch(patch map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	var normalized map[string]interface{}
+	if err := utiljson.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// applyPatch merges the patch into the object, the object is replaced when it is typed
+func applyPatch(obj runtime.Object, patch map[string]interface{}) (runtime.Object, error) {
+	// the kind and the name identify the patched object, they are kept
+	patch = mergePatch(map[string]interface{}{}, patch)
+	delete(patch, "kind")
+	delete(patch, "apiVersion")
+	if metadata, ok := patch["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "name")
+	}
+
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.Object = mergePatch(u.Object, patch)
+		return u, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	content = mergePatch(content, patch)
+	patched := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(content, patched, true); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// applyPatches merges the patches into the objects of their kind, and of their name when it is set.
+// A patch with an apiVersion matching no object is added as a new object.
+func applyPatches(objects *[]runtime.Object, patches []map[string]interface{}, defaultName string) error {
+	for _, raw := range patches {
+		patch, err := normalizePatch(raw)
+		if err != nil {
+			return err
+		}
+		kind, _ := patch["kind"].(string)
+		name, _, _ := unstructured.NestedString(patch, "metadata", "name")
+		if name == "" {
+			name = defaultName
+		}
+
+		matched := false
+		for i, obj := range *objects {
+			if obj.GetObjectKind().GroupVersionKind().Kind != kind {
+				continue
+			}
+			if accessor, err := meta.Accessor(obj); err != nil || (name != "" && accessor.GetName() != name) {
+				continue
+			}
+			patched, err := applyPatch(obj, patch)
+			if err != nil {
+				return errors.Wrapf(err, "failed to patch %s %q", kind, name)
+			}
+			(*objects)[i] = patched
+			matched = true
+		}
+		if matched {
+			continue
+		}
+
+		if _, ok := patch["apiVersion"]; !ok {
+			return errors.Errorf("the %s patch matches no generated object, set its apiVersion to add it as a new object", kind)
+		}
+		if name == "" {
+			return errors.Errorf("the %s object has no name", kind)
+		}
+		object := &unstructured.Unstructured{Object: patch}
+		object.SetName(name)
+		*objects = append(*objects, object)
+	}
+	return nil
+}
+
+// ApplyKubernetesPatches merges the x-kubernetes patches into the generated objects, the patches of a
+// service apply to the objects named after the service unless they set another name
+func (k *Kubernetes) ApplyKubernetesPatches(objects *[]runtime.Object, komposeObject kobject.KomposeObject) error {
+	for _, name := range SortedKeys(komposeObject.ServiceConfigs) {
+		service := komposeObject.ServiceConfigs[name]
+		if err := applyPatches(objects, service.KubernetesPatches, name); err != nil {
+			return errors.Wrapf(err, "invalid x-kubernetes of service %q", name)
+		}
+	}
+	if err := applyPatches(objects, komposeObject.KubernetesPatches, ""); err != nil {
+		return errors.Wrap(err, "invalid top level x-kubernetes")
+	}
+	return nil
+}
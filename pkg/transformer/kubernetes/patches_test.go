@@ -0,0 +1,136 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMergePatch(t *testing.T) {
+	object := map[string]interface{}{
+		"replicas": int64(1),
+		"paused":   true,
+		"containers": []interface{}{
+			map[string]interface{}{"name": "web", "image": "nginx"},
+		},
+		"args": []interface{}{"a", "b"},
+	}
+	patch := map[string]interface{}{
+		"replicas": int64(3),
+		"paused":   nil,
+		"containers": []interface{}{
+			map[string]interface{}{"name": "web", "image": "nginx:1.27"},
+			map[string]interface{}{"name": "sidecar", "image": "envoy"},
+		},
+		"args": []interface{}{"c"},
+	}
+	expected := map[string]interface{}{
+		"replicas": int64(3),
+		"containers": []interface{}{
+			map[string]interface{}{"name": "web", "image": "nginx:1.27"},
+			map[string]interface{}{"name": "sidecar", "image": "envoy"},
+		},
+		"args": []interface{}{"c"},
+	}
+	if merged := mergePatch(object, patch); !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected %v, got %v", expected, merged)
+	}
+}
+
+func TestApplyKubernetesPatches(t *testing.T) {
+	newKomposeObject := func(servicePatches, patches []map[string]interface{}) kobject.KomposeObject {
+		return kobject.KomposeObject{
+			ServiceConfigs: map[string]kobject.ServiceConfig{
+				"web": {Name: "web", Image: "nginx", Port: []kobject.Ports{{ContainerPort: 80, Protocol: "TCP"}}, KubernetesPatches: servicePatches},
+			},
+			KubernetesPatches: patches,
+		}
+	}
+	opt := kobject.ConvertOptions{CreateD: true, Replicas: 1}
+
+	komposeObject := newKomposeObject(
+		[]map[string]interface{}{{
+			"kind": "Deployment",
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{"runtimeClassName": "gvisor"},
+				},
+			},
+		}},
+		[]map[string]interface{}{{
+			"apiVersion": "policy/v1",
+			"kind":       "PodDisruptionBudget",
+			"metadata":   map[string]interface{}{"name": "web"},
+			"spec":       map[string]interface{}{"minAvailable": 1},
+		}},
+	)
+	k := Kubernetes{}
+	objects, err := k.Transform(komposeObject, opt)
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+	var deployment *appsv1.Deployment
+	var pdb *unstructured.Unstructured
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			deployment = o
+		case *unstructured.Unstructured:
+			pdb = o
+		}
+	}
+	if deployment == nil || deployment.Spec.Template.Spec.RuntimeClassName == nil || *deployment.Spec.Template.Spec.RuntimeClassName != "gvisor" {
+		t.Errorf("Expected the deployment to be patched with the gvisor runtime class, got %v", deployment)
+	}
+	if deployment != nil && deployment.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort != 80 {
+		t.Errorf("Expected the generated fields to be kept, got %v", deployment.Spec.Template.Spec.Containers[0])
+	}
+	if pdb == nil || pdb.GetKind() != "PodDisruptionBudget" || pdb.GetName() != "web" {
+		t.Fatalf("Expected the PodDisruptionBudget to be added, got %v", pdb)
+	}
+	if minAvailable, _, _ := unstructured.NestedInt64(pdb.Object, "spec", "minAvailable"); minAvailable != 1 {
+		t.Errorf("Expected minAvailable 1, got %v", pdb.Object)
+	}
+
+	for name, patches := range map[string][]map[string]interface{}{
+		"Unknown field":  {{"kind": "Deployment", "spec": map[string]interface{}{"replica": 2}}},
+		"Unmatched kind": {{"kind": "StatefulSet", "spec": map[string]interface{}{"replicas": 2}}},
+	} {
+		if _, err := k.Transform(newKomposeObject(patches, nil), opt); err == nil {
+			t.Errorf("%s: expected the patch to fail", name)
+		}
+	}
+}
+
+func TestApplyPatchKeepsType(t *testing.T) {
+	service := &api.Service{}
+	service.Kind = "Service"
+	service.Name = "web"
+	patched, err := applyPatch(service, map[string]interface{}{"spec": map[string]interface{}{"type": "NodePort"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := patched.(*api.Service); !ok || s.Spec.Type != api.ServiceTypeNodePort || s.Name != "web" {
+		t.Errorf("Expected a NodePort service named web, got %v", patched)
+	}
+}
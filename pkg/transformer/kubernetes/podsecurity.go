@@ -0,0 +1,142 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	log "github.com/sirupsen/logrus"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// PodSecurityRestricted is the restricted Pod Security Standard the workloads comply with, set with --pod-security
+const PodSecurityRestricted = "restricted"
+
+// restrictedCapabilities are the only capabilities the containers may add with the restricted profile
+var restrictedCapabilities = map[api.Capability]bool{"NET_BIND_SERVICE": true}
+
+// ApplyPodSecurity makes the pod templates of the objects comply with the restricted Pod Security Standard:
+// the containers run as non-root users, with the RuntimeDefault seccomp profile, without any capability
+// and without privilege escalation. The compose settings conflicting with it are removed with a warning.
+func ApplyPodSecurity(objects []runtime.Object, opt kobject.ConvertOptions) error {
+	if opt.PodSecurity != PodSecurityRestricted {
+		return nil
+	}
+
+	k := Kubernetes{Opt: opt}
+	for _, obj := range objects {
+		name := ""
+		if accessor, err := meta.Accessor(obj); err == nil {
+			name = fmt.Sprintf("%s %q", obj.GetObjectKind().GroupVersionKind().Kind, accessor.GetName())
+		}
+
+		restrict := func(template *api.PodTemplateSpec) error {
+			restrictPodSpec(name, &template.Spec)
+			return nil
+		}
+		if rc, ok := obj.(*api.ReplicationController); ok && rc.Spec.Template != nil {
+			_ = restrict(rc.Spec.Template)
+			continue
+		}
+		if err := k.UpdateController(obj, restrict, func(*metav1.ObjectMeta) {}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restrictPodSpec applies the restricted profile to the pod spec of the object name
+func restrictPodSpec(name string, podSpec *api.PodSpec) {
+	if podSpec.HostNetwork || podSpec.HostPID || podSpec.HostIPC {
+		log.Warnf("Removing the host namespaces of %s, they aren't allowed by the restricted pod security", name)
+		podSpec.HostNetwork, podSpec.HostPID, podSpec.HostIPC = false, false, false
+		if podSpec.DNSPolicy == api.DNSClusterFirstWithHostNet {
+			podSpec.DNSPolicy = ""
+		}
+	}
+	for _, volume := range podSpec.Volumes {
+		if volume.HostPath != nil {
+			log.Warnf("The hostPath volume %q of %s isn't allowed by the restricted pod security, the pods will be rejected", volume.Name, name)
+		}
+	}
+
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &api.PodSecurityContext{}
+	}
+	security := podSpec.SecurityContext
+	if security.RunAsUser != nil && *security.RunAsUser == 0 {
+		log.Warnf("Removing the root user of %s, it isn't allowed by the restricted pod security", name)
+		security.RunAsUser = nil
+	}
+	runAsNonRoot := true
+	security.RunAsNonRoot = &runAsNonRoot
+	if security.SeccompProfile == nil {
+		security.SeccompProfile = &api.SeccompProfile{Type: api.SeccompProfileTypeRuntimeDefault}
+	}
+
+	for i := range podSpec.InitContainers {
+		restrictContainer(name, &podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		restrictContainer(name, &podSpec.Containers[i])
+	}
+}
+
+// restrictContainer applies the restricted profile to a container of the object name
+func restrictContainer(name string, container *api.Container) {
+	for _, port := range container.Ports {
+		if port.HostPort != 0 {
+			log.Warnf("The host port %d of the container %q of %s isn't allowed by the restricted pod security, the pods will be rejected", port.HostPort, container.Name, name)
+		}
+	}
+
+	if container.SecurityContext == nil {
+		container.SecurityContext = &api.SecurityContext{}
+	}
+	security := container.SecurityContext
+	if security.Privileged != nil && *security.Privileged {
+		log.Warnf("Removing the privileged mode of the container %q of %s, it isn't allowed by the restricted pod security", container.Name, name)
+	}
+	security.Privileged = nil
+	if security.RunAsUser != nil && *security.RunAsUser == 0 {
+		log.Warnf("Removing the root user of the container %q of %s, it isn't allowed by the restricted pod security", container.Name, name)
+		security.RunAsUser = nil
+	}
+	allowPrivilegeEscalation := false
+	security.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+
+	capabilities := &api.Capabilities{Drop: []api.Capability{"ALL"}}
+	if security.Capabilities != nil {
+		var removed []string
+		for _, capability := range security.Capabilities.Add {
+			if restrictedCapabilities[capability] {
+				capabilities.Add = append(capabilities.Add, capability)
+			} else {
+				removed = append(removed, string(capability))
+			}
+		}
+		if len(removed) > 0 {
+			log.Warnf("Removing the capabilities %s of the container %q of %s, they aren't allowed by the restricted pod security", strings.Join(removed, ", "), container.Name, name)
+		}
+	}
+	security.Capabilities = capabilities
+}
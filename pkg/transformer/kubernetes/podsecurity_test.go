@@ -0,0 +1,86 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestApplyPodSecurity(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:       "web",
+		Image:      "nginx",
+		User:       "0:0",
+		Privileged: true,
+		CapAdd:     []string{"NET_BIND_SERVICE", "SYS_ADMIN"},
+		Port:       []kobject.Ports{{HostPort: 80, ContainerPort: 8080, Protocol: "TCP"}},
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": service}}
+	opt := kobject.ConvertOptions{CreateD: true, PodSecurity: PodSecurityRestricted}
+
+	k := Kubernetes{Opt: opt}
+	objects, err := k.Transform(komposeObject, opt)
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+	if err := ApplyPodSecurity(objects, opt); err != nil {
+		t.Fatalf("ApplyPodSecurity failed: %v", err)
+	}
+
+	yes, no, root := true, false, int64(0)
+	expectedPod := &api.PodSecurityContext{
+		RunAsNonRoot:   &yes,
+		SeccompProfile: &api.SeccompProfile{Type: api.SeccompProfileTypeRuntimeDefault},
+	}
+	expectedContainer := &api.SecurityContext{
+		RunAsGroup:               &root,
+		AllowPrivilegeEscalation: &no,
+		Capabilities:             &api.Capabilities{Add: []api.Capability{"NET_BIND_SERVICE"}, Drop: []api.Capability{"ALL"}},
+	}
+	found := false
+	for _, obj := range objects {
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			found = true
+			podSpec := d.Spec.Template.Spec
+			if !reflect.DeepEqual(podSpec.SecurityContext, expectedPod) {
+				t.Errorf("Expected the pod security context %+v, got %+v", expectedPod, podSpec.SecurityContext)
+			}
+			if !reflect.DeepEqual(podSpec.Containers[0].SecurityContext, expectedContainer) {
+				t.Errorf("Expected the container security context %+v, got %+v", expectedContainer, podSpec.Containers[0].SecurityContext)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected a deployment")
+	}
+}
+
+func TestApplyPodSecurityDisabled(t *testing.T) {
+	pod := &api.Pod{Spec: api.PodSpec{Containers: []api.Container{{Name: "web"}}}}
+	if err := ApplyPodSecurity([]runtime.Object{pod}, kobject.ConvertOptions{}); err != nil {
+		t.Fatalf("ApplyPodSecurity failed: %v", err)
+	}
+	if pod.Spec.SecurityContext != nil || pod.Spec.Containers[0].SecurityContext != nil {
+		t.Errorf("Expected no security context without --pod-security, got %+v", pod.Spec)
+	}
+}
@@ -2,21 +2,25 @@ package kubernetes
 
 import (
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	mapset "github.com/deckarep/golang-set"
 	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 	api "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // PodSpec holds the spec of k8s pod.
 type PodSpec struct {
 	api.PodSpec
+
+	// err is the first error of the options, the next options aren't applied
+	err error
 }
 
 // PodSpecOption holds the function to apply on a PodSpec
@@ -34,7 +38,13 @@ func AddContainer(service kobject.ServiceConfig, opt kobject.ConvertOptions) Pod
 
 		envs, envsFrom, err := ConfigEnvs(service, opt)
 		if err != nil {
-			panic("Unable to load env variables")
+			podSpec.err = errors.Wrapf(err, "Unable to load the environment of service %q", service.Name)
+			return
+		}
+		liveness, readiness, startup, err := configProbes(service)
+		if err != nil {
+			podSpec.err = err
+			return
 		}
 
 		podSpec.Containers = append(podSpec.Containers, api.Container{
@@ -47,8 +57,9 @@ func AddContainer(service kobject.ServiceConfig, opt kobject.ConvertOptions) Pod
 			WorkingDir:     service.WorkingDir,
 			Stdin:          service.Stdin,
 			TTY:            service.Tty,
-			LivenessProbe:  configProbe(service.HealthChecks.Liveness),
-			ReadinessProbe: configProbe(service.HealthChecks.Readiness),
+			LivenessProbe:  liveness,
+			ReadinessProbe: readiness,
+			StartupProbe:   startup,
 		})
 		if service.ImagePullSecret != "" {
 			podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, api.LocalObjectReference{
@@ -66,49 +77,41 @@ func TerminationGracePeriodSeconds(name string, service kobject.ServiceConfig) P
 		if service.StopGracePeriod != "" {
 			podSpec.TerminationGracePeriodSeconds, err = DurationStrToSecondsInt(service.StopGracePeriod)
 			if err != nil {
-				log.Warningf("Failed to parse duration \"%v\" for service \"%v\"", service.StopGracePeriod, name)
+				dropped.Warnf("Ignoring the stop_grace_period %q of service %q, it isn't a duration", service.StopGracePeriod, name)
 			}
 		}
 	}
 }
 
-// ResourcesLimits Configure the resource limits
+// container returns the container of the service in the pod spec, nil when it isn't added yet.
+// The containers of a service group each get the settings of their own service.
+func (podSpec *PodSpec) container(service kobject.ServiceConfig) *api.Container {
+	name := GetContainerName(service)
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == name {
+			return &podSpec.Containers[i]
+		}
+	}
+	return nil
+}
+
+// ResourcesLimits Configure the resource limits of the container of the service
 func ResourcesLimits(service kobject.ServiceConfig) PodSpecOption {
 	return func(podSpec *PodSpec) {
-		if service.MemLimit != 0 || service.CPULimit != 0 {
-			resourceLimit := api.ResourceList{}
-
-			if service.MemLimit != 0 {
-				resourceLimit[api.ResourceMemory] = *resource.NewQuantity(int64(service.MemLimit), "RandomStringForFormat")
-			}
-
-			if service.CPULimit != 0 {
-				resourceLimit[api.ResourceCPU] = *resource.NewMilliQuantity(service.CPULimit, resource.DecimalSI)
-			}
-
-			for i := range podSpec.Containers {
-				podSpec.Containers[i].Resources.Limits = resourceLimit
+		if limits := resourceLimits(service); limits != nil {
+			if container := podSpec.container(service); container != nil {
+				container.Resources.Limits = limits
 			}
 		}
 	}
 }
 
-// ResourcesRequests Configure the resource requests
+// ResourcesRequests Configure the resource requests of the container of the service
 func ResourcesRequests(service kobject.ServiceConfig) PodSpecOption {
 	return func(podSpec *PodSpec) {
-		if service.MemReservation != 0 || service.CPUReservation != 0 {
-			resourceRequests := api.ResourceList{}
-
-			if service.MemReservation != 0 {
-				resourceRequests[api.ResourceMemory] = *resource.NewQuantity(int64(service.MemReservation), "RandomStringForFormat")
-			}
-
-			if service.CPUReservation != 0 {
-				resourceRequests[api.ResourceCPU] = *resource.NewMilliQuantity(service.CPUReservation, resource.DecimalSI)
-			}
-
-			for i := range podSpec.Containers {
-				podSpec.Containers[i].Resources.Requests = resourceRequests
+		if requests := resourceRequests(service); requests != nil {
+			if container := podSpec.container(service); container != nil {
+				container.Resources.Requests = requests
 			}
 		}
 	}
@@ -120,15 +123,6 @@ func SecurityContext(name string, service kobject.ServiceConfig) PodSpecOption {
 		// Configure resource reservations
 		podSecurityContext := &api.PodSecurityContext{}
 
-		//set pid namespace mode
-		if service.Pid != "" {
-			if service.Pid == "host" {
-				// podSecurityContext.HostPID = true
-			} else {
-				log.Warningf("Ignoring PID key for service \"%v\". Invalid value \"%v\".", name, service.Pid)
-			}
-		}
-
 		//set supplementalGroups
 		if service.GroupAdd != nil {
 			podSecurityContext.SupplementalGroups = service.GroupAdd
@@ -147,25 +141,25 @@ func SecurityContext(name string, service kobject.ServiceConfig) PodSpecOption {
 		if service.User != "" {
 			switch userparts := strings.Split(service.User, ":"); len(userparts) {
 			default:
-				log.Warn("Ignoring ill-formed user directive. Must be in format UID or UID:GID.")
+				dropped.Warnf("Ignoring ill-formed user directive. Must be in format UID or UID:GID.")
 			case 1:
 				uid, err := strconv.ParseInt(userparts[0], 10, 64)
 				if err != nil {
-					log.Warn("Ignoring user directive. User to be specified as a UID (numeric).")
+					dropped.Warnf("Ignoring user directive. User to be specified as a UID (numeric).")
 				} else {
 					securityContext.RunAsUser = &uid
 				}
 			case 2:
 				uid, err := strconv.ParseInt(userparts[0], 10, 64)
 				if err != nil {
-					log.Warn("Ignoring user name in user directive. User to be specified as a UID (numeric).")
+					dropped.Warnf("Ignoring user name in user directive. User to be specified as a UID (numeric).")
 				} else {
 					securityContext.RunAsUser = &uid
 				}
 
 				gid, err := strconv.ParseInt(userparts[1], 10, 64)
 				if err != nil {
-					log.Warn("Ignoring group name in user directive. Group to be specified as a GID (numeric).")
+					dropped.Warnf("Ignoring group name in user directive. Group to be specified as a GID (numeric).")
 				} else {
 					securityContext.RunAsGroup = &gid
 				}
@@ -181,8 +175,8 @@ func SecurityContext(name string, service kobject.ServiceConfig) PodSpecOption {
 		}
 
 		// update template only if securityContext is not empty
-		if *securityContext != (api.SecurityContext{}) {
-			podSpec.Containers[0].SecurityContext = securityContext
+		if container := podSpec.container(service); container != nil && *securityContext != (api.SecurityContext{}) {
+			container.SecurityContext = securityContext
 		}
 		if !reflect.DeepEqual(*podSecurityContext, api.PodSecurityContext{}) {
 			podSpec.SecurityContext = podSecurityContext
@@ -190,6 +184,20 @@ func SecurityContext(name string, service kobject.ServiceConfig) PodSpecOption {
 	}
 }
 
+// Sysctls configures the sysctls of the pod
+func Sysctls(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) PodSpecOption {
+	return func(podSpec *PodSpec) {
+		sysctls := ConfigSysctls(name, service, opt.AllowUnsafeSysctls)
+		if len(sysctls) == 0 {
+			return
+		}
+		if podSpec.SecurityContext == nil {
+			podSpec.SecurityContext = &api.PodSecurityContext{}
+		}
+		podSpec.SecurityContext.Sysctls = append(podSpec.SecurityContext.Sysctls, sysctls...)
+	}
+}
+
 // SetVolumeNames method return a set of volume names
 func SetVolumeNames(volumes []api.Volume) mapset.Set {
 	set := mapset.NewSet()
@@ -260,11 +268,9 @@ func SetPorts(service kobject.ServiceConfig) PodSpecOption {
 func ImagePullPolicy(name string, service kobject.ServiceConfig) PodSpecOption {
 	return func(podSpec *PodSpec) {
 		if policy, err := GetImagePullPolicy(name, service.ImagePullPolicy); err != nil {
-			panic(err)
-		} else {
-			for i := range podSpec.Containers {
-				podSpec.Containers[i].ImagePullPolicy = policy
-			}
+			podSpec.err = err
+		} else if container := podSpec.container(service); container != nil {
+			container.ImagePullPolicy = policy
 		}
 	}
 }
@@ -273,7 +279,7 @@ func ImagePullPolicy(name string, service kobject.ServiceConfig) PodSpecOption {
 func RestartPolicy(name string, service kobject.ServiceConfig) PodSpecOption {
 	return func(podSpec *PodSpec) {
 		if restart, err := GetRestartPolicy(name, service.Restart); err != nil {
-			panic(err)
+			podSpec.err = err
 		} else {
 			podSpec.RestartPolicy = restart
 		}
@@ -290,6 +296,79 @@ func HostName(service kobject.ServiceConfig) PodSpecOption {
 	}
 }
 
+// HostAliases configures the /etc/hosts entries of the pod, the containers of a group share them
+func HostAliases(name string, service kobject.ServiceConfig) PodSpecOption {
+	return func(podSpec *PodSpec) {
+		for _, hostAlias := range ConfigHostAliases(name, service) {
+			merged := false
+			for i := range podSpec.HostAliases {
+				if podSpec.HostAliases[i].IP == hostAlias.IP {
+					for _, hostname := range hostAlias.Hostnames {
+						if !slices.Contains(podSpec.HostAliases[i].Hostnames, hostname) {
+							podSpec.HostAliases[i].Hostnames = append(podSpec.HostAliases[i].Hostnames, hostname)
+						}
+					}
+					merged = true
+				}
+			}
+			if !merged {
+				podSpec.HostAliases = append(podSpec.HostAliases, hostAlias)
+			}
+		}
+	}
+}
+
+// DNSConfig configures the DNS of the pod, the containers of a group share it
+func DNSConfig(service kobject.ServiceConfig) PodSpecOption {
+	return func(podSpec *PodSpec) {
+		dnsConfig, dnsPolicy := ConfigDNS(service)
+		if dnsConfig == nil {
+			return
+		}
+		if podSpec.DNSConfig == nil {
+			podSpec.DNSConfig = &api.PodDNSConfig{}
+		}
+		for _, nameserver := range dnsConfig.Nameservers {
+			if !slices.Contains(podSpec.DNSConfig.Nameservers, nameserver) {
+				podSpec.DNSConfig.Nameservers = append(podSpec.DNSConfig.Nameservers, nameserver)
+			}
+		}
+		for _, search := range dnsConfig.Searches {
+			if !slices.Contains(podSpec.DNSConfig.Searches, search) {
+				podSpec.DNSConfig.Searches = append(podSpec.DNSConfig.Searches, search)
+			}
+		}
+		for _, option := range dnsConfig.Options {
+			if !slices.ContainsFunc(podSpec.DNSConfig.Options, func(o api.PodDNSConfigOption) bool { return o.Name == option.Name }) {
+				podSpec.DNSConfig.Options = append(podSpec.DNSConfig.Options, option)
+			}
+		}
+		if dnsPolicy != "" {
+			podSpec.DNSPolicy = dnsPolicy
+		}
+	}
+}
+
+// HostNamespaces configures the host namespaces shared by the pod, shared as soon as a container of a group needs it
+func HostNamespaces(name string, service kobject.ServiceConfig, opt kobject.ConvertOptions) PodSpecOption {
+	return func(podSpec *PodSpec) {
+		hostPID, hostIPC, hostNetwork := ConfigHostNamespaces(name, service, opt.AllowHostNamespaces)
+		podSpec.HostPID = podSpec.HostPID || hostPID
+		podSpec.HostIPC = podSpec.HostIPC || hostIPC
+		podSpec.HostNetwork = podSpec.HostNetwork || hostNetwork
+		if podSpec.HostNetwork && podSpec.DNSPolicy == "" {
+			podSpec.DNSPolicy = api.DNSClusterFirstWithHostNet
+		}
+	}
+}
+
+// EnableServiceLinks configure whether service environment variables are injected into the pod
+func EnableServiceLinks(service kobject.ServiceConfig, opt kobject.ConvertOptions) PodSpecOption {
+	return func(podSpec *PodSpec) {
+		podSpec.EnableServiceLinks = GetEnableServiceLinks(service, opt)
+	}
+}
+
 // DomainName configure the domain name of a pod
 func DomainName(service kobject.ServiceConfig) PodSpecOption {
 	return func(podSpec *PodSpec) {
@@ -299,11 +378,25 @@ func DomainName(service kobject.ServiceConfig) PodSpecOption {
 	}
 }
 
-func configProbe(healthCheck kobject.HealthCheck) *api.Probe {
+// configProbes returns the liveness, readiness and startup probes of the service
+func configProbes(service kobject.ServiceConfig) (liveness, readiness, startup *api.Probe, err error) {
+	if liveness, err = configProbe(service.HealthChecks.Liveness); err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "the liveness probe of service %q", service.Name)
+	}
+	if readiness, err = configProbe(service.HealthChecks.Readiness); err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "the readiness probe of service %q", service.Name)
+	}
+	if startup, err = configProbe(service.HealthChecks.Startup); err != nil {
+		return nil, nil, nil, errors.Wrapf(err, "the startup probe of service %q", service.Name)
+	}
+	return liveness, readiness, startup, nil
+}
+
+func configProbe(healthCheck kobject.HealthCheck) (*api.Probe, error) {
 	probe := api.Probe{}
 	// We check to see if it's blank or disable
 	if reflect.DeepEqual(healthCheck, kobject.HealthCheck{}) || healthCheck.Disable {
-		return nil
+		return nil, nil
 	}
 
 	if len(healthCheck.Test) > 0 {
@@ -315,10 +408,19 @@ func configProbe(healthCheck kobject.HealthCheck) *api.Probe {
 	} else if !reflect.ValueOf(healthCheck.HTTPPath).IsZero() && !reflect.ValueOf(healthCheck.HTTPPort).IsZero() {
 		probe.ProbeHandler = api.ProbeHandler{
 			HTTPGet: &api.HTTPGetAction{
-				Path: healthCheck.HTTPPath,
-				Port: intstr.FromInt(int(healthCheck.HTTPPort)),
+				Path:   healthCheck.HTTPPath,
+				Port:   intstr.FromInt(int(healthCheck.HTTPPort)),
+				Scheme: api.URIScheme(healthCheck.HTTPScheme),
 			},
 		}
+		names := make([]string, 0, len(healthCheck.HTTPHeaders))
+		for name := range healthCheck.HTTPHeaders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			probe.HTTPGet.HTTPHeaders = append(probe.HTTPGet.HTTPHeaders, api.HTTPHeader{Name: name, Value: healthCheck.HTTPHeaders[name]})
+		}
 	} else if !reflect.ValueOf(healthCheck.TCPPort).IsZero() {
 		probe.ProbeHandler = api.ProbeHandler{
 			TCPSocket: &api.TCPSocketAction{
@@ -326,7 +428,7 @@ func configProbe(healthCheck kobject.HealthCheck) *api.Probe {
 			},
 		}
 	} else {
-		panic(errors.New("Health check must contain a command"))
+		return nil, errors.New("Health check must contain a command")
 	}
 
 	probe.TimeoutSeconds = healthCheck.Timeout
@@ -336,7 +438,7 @@ func configProbe(healthCheck kobject.HealthCheck) *api.Probe {
 	// See issue: https://github.com/docker/cli/issues/116
 	// StartPeriod has been added to v3.4 of the compose
 	probe.InitialDelaySeconds = healthCheck.StartPeriod
-	return &probe
+	return &probe, nil
 }
 
 // ServiceAccountName is responsible for setting the service account name to the pod spec
@@ -346,6 +448,23 @@ func ServiceAccountName(serviceAccountName string) PodSpecOption {
 	}
 }
 
+// PriorityClassName is responsible for setting the priority class name to the pod spec
+func PriorityClassName(priorityClassName string) PodSpecOption {
+	return func(podSpec *PodSpec) {
+		podSpec.PriorityClassName = priorityClassName
+	}
+}
+
+// RuntimeClassName is responsible for setting the runtime class name to the pod spec
+func RuntimeClassName(service kobject.ServiceConfig) PodSpecOption {
+	return func(podSpec *PodSpec) {
+		if service.RuntimeClassName != "" {
+			runtimeClassName := service.RuntimeClassName
+			podSpec.RuntimeClassName = &runtimeClassName
+		}
+	}
+}
+
 // TopologySpreadConstraints is responsible for setting the topology spread constraints to the pod spec
 func TopologySpreadConstraints(service kobject.ServiceConfig) PodSpecOption {
 	return func(podSpec *PodSpec) {
@@ -353,14 +472,23 @@ func TopologySpreadConstraints(service kobject.ServiceConfig) PodSpecOption {
 	}
 }
 
-// Append is responsible for adding the pod spec options to the particular pod
+// Append is responsible for adding the pod spec options to the particular pod, the options
+// aren't applied anymore once one of them failed
 func (podSpec *PodSpec) Append(ops ...PodSpecOption) *PodSpec {
 	for _, option := range ops {
+		if podSpec.err != nil {
+			break
+		}
 		option(podSpec)
 	}
 	return podSpec
 }
 
+// Err returns the error of the first option that failed
+func (podSpec *PodSpec) Err() error {
+	return podSpec.err
+}
+
 // Get is responsible for returning the pod spec of a particular pod
 func (podSpec *PodSpec) Get() api.PodSpec {
 	return podSpec.PodSpec
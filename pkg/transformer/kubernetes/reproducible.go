@@ -0,0 +1,100 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"gopkg.in/yaml.v3"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// nonReproducibleAnnotations change with the command line and the kompose binary, not with the compose files
+var nonReproducibleAnnotations = []string{"kompose.cmd", "kompose.version"}
+
+// StripNonReproducible removes from the objects and their pod templates the annotations recording the
+// command line and the version of kompose, so that the same compose files give the same objects
+func StripNonReproducible(objects []runtime.Object, opt kobject.ConvertOptions) error {
+	strip := func(annotations map[string]string) map[string]string {
+		for _, key := range nonReproducibleAnnotations {
+			delete(annotations, key)
+		}
+		if len(annotations) == 0 {
+			return nil
+		}
+		return annotations
+	}
+
+	k := Kubernetes{Opt: opt}
+	for _, obj := range objects {
+		if accessor, err := meta.Accessor(obj); err == nil {
+			accessor.SetAnnotations(strip(accessor.GetAnnotations()))
+		}
+		err := k.UpdateController(obj, func(template *api.PodTemplateSpec) error {
+			template.Annotations = strip(template.Annotations)
+			return nil
+		}, func(*metav1.ObjectMeta) {})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reproducibleData removes the creationTimestamp fields from a marshalled object, and marshals it again
+// with the keys of its maps sorted, in JSON too
+func reproducibleData(data []byte, generateJSON bool, indent int) ([]byte, error) {
+	var obj interface{}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	obj = removeCreationTimestamps(obj)
+
+	if generateJSON {
+		return json.MarshalIndent(obj, "", "  ")
+	}
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(indent)
+	if err := encoder.Encode(obj); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// removeCreationTimestamps removes the creationTimestamp fields of the metadata of the object and of its templates
+func removeCreationTimestamps(obj interface{}) interface{} {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		if metadata, ok := v["metadata"].(map[string]interface{}); ok {
+			delete(metadata, "creationTimestamp")
+		}
+		for key, value := range v {
+			v[key] = removeCreationTimestamps(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = removeCreationTimestamps(value)
+		}
+	}
+	return obj
+}
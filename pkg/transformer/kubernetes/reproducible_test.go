@@ -0,0 +1,103 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestStripNonReproducible(t *testing.T) {
+	annotations := func() map[string]string {
+		return map[string]string{"kompose.cmd": "kompose convert", "kompose.version": "1.35.0", "team": "shop"}
+	}
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Annotations: annotations()},
+		Spec: appsv1.DeploymentSpec{
+			Template: api.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"kompose.cmd": "kompose convert"}}},
+		},
+	}
+	service := &api.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Annotations: annotations()},
+	}
+
+	if err := StripNonReproducible([]runtime.Object{deployment, service}, kobject.ConvertOptions{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, got := range []map[string]string{deployment.Annotations, service.Annotations} {
+		if len(got) != 1 || got["team"] != "shop" {
+			t.Errorf("Expected only the team annotation to be kept, got %v", got)
+		}
+	}
+	if deployment.Spec.Template.Annotations != nil {
+		t.Errorf("Expected the annotations of the pod template to be removed, got %v", deployment.Spec.Template.Annotations)
+	}
+}
+
+func TestReproducibleData(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+	}
+	configMap := &api.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web-env"},
+		Data:       map[string]string{"creationTimestamp": "kept"},
+	}
+
+	data, err := marshal(deployment, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = reproducibleData(data, false, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "creationTimestamp") || !strings.Contains(string(data), "name: web") {
+		t.Errorf("Expected the creation timestamps to be removed, got:\n%s", data)
+	}
+
+	data, err = marshal(configMap, true, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err = reproducibleData(data, true, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{
+  "apiVersion": "v1",
+  "data": {
+    "creationTimestamp": "kept"
+  },
+  "kind": "ConfigMap",
+  "metadata": {
+    "name": "web-env"
+  }
+}`
+	if string(data) != expected {
+		t.Errorf("Expected the JSON keys to be sorted:\n%s\ngot:\n%s", expected, data)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"os"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/pkg/errors"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// SecretsAsSecret generates the compose secrets as Secrets
+	SecretsAsSecret = "secret"
+	// SecretsAsSealed generates the compose secrets as SealedSecrets, encrypted for the sealed-secrets controller
+	SecretsAsSealed = "sealed"
+
+	// SealedSecretsScopeStrict seals a secret for its name and namespace
+	SealedSecretsScopeStrict = "strict"
+	// SealedSecretsScopeNamespaceWide seals a secret for its namespace, it can be renamed
+	SealedSecretsScopeNamespaceWide = "namespace-wide"
+	// SealedSecretsScopeClusterWide seals a secret for the whole cluster
+	SealedSecretsScopeClusterWide = "cluster-wide"
+
+	// sessionKeyBytes is the size of the AES-256 key encrypting each value
+	sessionKeyBytes = 32
+)
+
+// SecretObjects creates the objects of the compose secrets, sealed when --secrets-as=sealed is used
+func (k *Kubernetes) SecretObjects(komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) ([]runtime.Object, error) {
+	secrets, err := k.CreateSecrets(komposeObject)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []runtime.Object
+	if opt.SecretsAs != SecretsAsSealed {
+		for _, secret := range secrets {
+			objects = append(objects, secret)
+		}
+		return objects, nil
+	}
+
+	if len(secrets) == 0 {
+		return nil, nil
+	}
+	key, err := loadSealingKey(opt.SealedSecretsCert)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		sealed, err := sealSecret(rand.Reader, key, secret, komposeObject.Namespace, opt.SealedSecretsScope)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to seal secret %q", secret.Name)
+		}
+		objects = append(objects, sealed)
+	}
+	return objects, nil
+}
+
+// loadSealingKey reads the public key of the sealed-secrets controller from its PEM certificate,
+// as fetched with "kubeseal --fetch-cert"
+func loadSealingKey(certPath string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the sealed secrets certificate")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.Errorf("%s is not a PEM encoded certificate", certPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the certificate %s", certPath)
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("the certificate %s doesn't have an RSA public key", certPath)
+	}
+	return key, nil
+}
+
+// sealSecret encrypts the data of a secret like kubeseal, the label binding the values to
+// the secret depends on the scope
+func sealSecret(rnd io.Reader, key *rsa.PublicKey, secret *api.Secret, namespace, scope string) (*unstructured.Unstructured, error) {
+	var label string
+	annotations := map[string]string{}
+	switch scope {
+	case "", SealedSecretsScopeStrict:
+		if namespace == "" {
+			return nil, errors.Errorf("the %s scope requires the namespace of the secret, set it with --namespace", SealedSecretsScopeStrict)
+		}
+		label = namespace + "/" + secret.Name
+	case SealedSecretsScopeNamespaceWide:
+		if namespace == "" {
+			return nil, errors.Errorf("the %s scope requires the namespace of the secret, set it with --namespace", SealedSecretsScopeNamespaceWide)
+		}
+		label = namespace
+		annotations["sealedsecrets.bitnami.com/namespace-wide"] = "true"
+	case SealedSecretsScopeClusterWide:
+		annotations["sealedsecrets.bitnami.com/cluster-wide"] = "true"
+	default:
+		return nil, errors.Errorf("unknown sealed secrets scope %q", scope)
+	}
+
+	encryptedData := map[string]interface{}{}
+	for name, value := range secret.Data {
+		ciphertext, err := hybridEncrypt(rnd, key, value, []byte(label))
+		if err != nil {
+			return nil, err
+		}
+		encryptedData[name] = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+
+	templateMetadata := map[string]interface{}{"name": secret.Name}
+	if len(secret.Labels) > 0 {
+		labels := map[string]interface{}{}
+		for k, v := range secret.Labels {
+			labels[k] = v
+		}
+		templateMetadata["labels"] = labels
+	}
+
+	sealed := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "bitnami.com/v1alpha1",
+		"kind":       "SealedSecret",
+		"metadata":   map[string]interface{}{"name": secret.Name},
+		"spec": map[string]interface{}{
+			"encryptedData": encryptedData,
+			"template": map[string]interface{}{
+				"metadata": templateMetadata,
+				"type":     string(secret.Type),
+			},
+		},
+	}}
+	sealed.SetLabels(secret.Labels)
+	if len(annotations) > 0 {
+		sealed.SetAnnotations(annotations)
+	}
+	return sealed, nil
+}
+
+// hybridEncrypt encrypts the plaintext with a random AES-256-GCM session key, itself encrypted
+// with RSA-OAEP. The result is the length of the encrypted session key on 2 bytes, the encrypted
+// session key and the encrypted plaintext, the format expected by the sealed-secrets controller.
+func hybridEncrypt(rnd io.Reader, key *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, sessionKeyBytes)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, key, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, 2, 2+len(rsaCiphertext)+len(plaintext)+aead.Overhead())
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+
+	// the session key is used only once, a zero nonce is safe
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ciphertext, nonce, plaintext, nil), nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/kubernetes/kompose/pkg/kobject"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// hybridDecrypt decrypts a value like the sealed-secrets controller
+func hybridDecrypt(t *testing.T, key *rsa.PrivateKey, ciphertext, label []byte) []byte {
+	rsaLen := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext[2:2+rsaLen], label)
+	if err != nil {
+		t.Fatalf("Unable to decrypt the session key: %v", err)
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[2+rsaLen:], nil)
+	if err != nil {
+		t.Fatalf("Unable to decrypt the value: %v", err)
+	}
+	return plaintext
+}
+
+func writeSealingCert(t *testing.T, key *rsa.PrivateKey) string {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return certPath
+}
+
+func TestSecretObjectsSealed(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPath := writeSealingCert(t, key)
+	secretFile := filepath.Join(t.TempDir(), "password.txt")
+	if err := os.WriteFile(secretFile, []byte("s3cr3t"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	komposeObject := kobject.KomposeObject{
+		Namespace: "shop",
+		Secrets:   types.Secrets{"db_password": {File: secretFile}},
+	}
+
+	testCases := map[string]struct {
+		scope      string
+		label      string
+		annotation string
+	}{
+		"Strict":         {SealedSecretsScopeStrict, "shop/db-password", ""},
+		"Namespace wide": {SealedSecretsScopeNamespaceWide, "shop", "sealedsecrets.bitnami.com/namespace-wide"},
+		"Cluster wide":   {SealedSecretsScopeClusterWide, "", "sealedsecrets.bitnami.com/cluster-wide"},
+	}
+	for name, test := range testCases {
+		t.Log("Test case:", name)
+		k := Kubernetes{}
+		objects, err := k.SecretObjects(komposeObject, kobject.ConvertOptions{SecretsAs: SecretsAsSealed, SealedSecretsCert: certPath, SealedSecretsScope: test.scope})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(objects) != 1 {
+			t.Fatalf("Expected 1 SealedSecret, got %d", len(objects))
+		}
+		sealed, ok := objects[0].(*unstructured.Unstructured)
+		if !ok || sealed.GetKind() != "SealedSecret" || sealed.GetName() != "db-password" {
+			t.Fatalf("Expected the db-password SealedSecret, got %v", objects[0])
+		}
+		if test.annotation != "" && sealed.GetAnnotations()[test.annotation] != "true" {
+			t.Errorf("Expected the %s annotation, got %v", test.annotation, sealed.GetAnnotations())
+		}
+
+		value, _, _ := unstructured.NestedString(sealed.Object, "spec", "encryptedData", "db-password")
+		ciphertext, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plaintext := hybridDecrypt(t, key, ciphertext, []byte(test.label)); string(plaintext) != "s3cr3t" {
+			t.Errorf("Expected the sealed value to be s3cr3t, got %q", plaintext)
+		}
+	}
+}
+
+func TestSealSecretStrictWithoutNamespace(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := &api.Secret{Data: map[string][]byte{"password": []byte("s3cr3t")}}
+	secret.Name = "password"
+	if _, err := sealSecret(rand.Reader, &key.PublicKey, secret, "", SealedSecretsScopeStrict); err == nil {
+		t.Errorf("Expected an error sealing a strict scoped secret without namespace")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"path/filepath"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/utils/archive"
+	"github.com/kubernetes/kompose/pkg/utils/cosign"
+	log "github.com/sirupsen/logrus"
+)
+
+// ManifestsBundle is the gzipped tarball of the files written to the output directory, signed with --sign
+const ManifestsBundle = "kompose-manifests.tgz"
+
+// signOutput signs the output with cosign: the package of the chart, written next to the chart directory,
+// the output file, or the bundle of the files written to the output directory
+func signOutput(dirName string, outFile string, files []string, opt kobject.ConvertOptions) error {
+	var signed string
+	switch {
+	case opt.CreateChart:
+		pkg, err := packageHelmChart(dirName, filepath.Dir(filepath.Clean(dirName)))
+		if err != nil {
+			return err
+		}
+		log.Printf("Chart package %q created", pkg)
+		signed = pkg
+	case outFile != "":
+		signed = outFile
+	default:
+		signed = filepath.Join(dirName, ManifestsBundle)
+		if err := archive.CreateGzipTarball(dirName, files, signed); err != nil {
+			return err
+		}
+		log.Printf("Manifests bundle %q created", signed)
+	}
+
+	written, err := cosign.SignBlob(signed, opt.SignKey)
+	if err != nil {
+		return err
+	}
+	for _, file := range written {
+		log.Printf("Signature file %q created", file)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	log "github.com/sirupsen/logrus"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	traefikAPIVersion = "traefik.io/v1alpha1"
+
+	traefikRoutersPrefix  = "traefik.http.routers."
+	traefikServicesPrefix = "traefik.http.services."
+)
+
+// traefikLabels returns the traefik labels of the service, the deploy labels of the swarm stacks
+// overriding the labels of the container
+func traefikLabels(service kobject.ServiceConfig) map[string]string {
+	labels := map[string]string{}
+	for _, source := range []map[string]string{service.Labels, service.DeployLabels} {
+		for key, value := range source {
+			if strings.HasPrefix(key, "traefik.") {
+				labels[key] = value
+			}
+		}
+	}
+	if strings.EqualFold(labels["traefik.enable"], "false") {
+		return nil
+	}
+	return labels
+}
+
+// traefikOptions groups the labels with the prefix by the name following it, as
+// traefik.http.routers.NAME.OPTION, and returns the names in order
+func traefikOptions(labels map[string]string, prefix string) ([]string, map[string]map[string]string) {
+	options := map[string]map[string]string{}
+	var names []string
+	for key, value := range labels {
+		name, option, found := strings.Cut(strings.TrimPrefix(key, prefix), ".")
+		if !strings.HasPrefix(key, prefix) || !found {
+			continue
+		}
+		if _, ok := options[name]; !ok {
+			options[name] = map[string]string{}
+			names = append(names, name)
+		}
+		options[name][strings.ToLower(option)] = value
+	}
+	sort.Strings(names)
+	return names, options
+}
+
+// traefikServicePort returns the port of the Service a traefik service, or the first port, is routed to:
+// the port whose target port is the server port of the traefik service
+func traefikServicePort(svc *api.Service, serverPort string) int64 {
+	if port, err := strconv.ParseInt(serverPort, 10, 32); err == nil {
+		for _, p := range svc.Spec.Ports {
+			if int64(p.TargetPort.IntValue()) == port || int64(p.Port) == port {
+				return int64(p.Port)
+			}
+		}
+		log.Warnf("The server port %s of the traefik labels of service %q isn't a port of the service, routing to port %d", serverPort, svc.Name, svc.Spec.Ports[0].Port)
+	}
+	return int64(svc.Spec.Ports[0].Port)
+}
+
+// traefikMiddlewares returns the references to the middlewares of a router, the middlewares of the docker
+// and swarm providers are Middleware objects in the namespace of the IngressRoute
+func traefikMiddlewares(value string) (refs []interface{}, created []string) {
+	for _, middleware := range strings.Split(value, ",") {
+		middleware = strings.TrimSpace(middleware)
+		if middleware == "" {
+			continue
+		}
+		if name, provider, found := strings.Cut(middleware, "@"); !found || provider == "docker" || provider == "swarm" {
+			middleware = name
+			created = append(created, name)
+		}
+		refs = append(refs, map[string]interface{}{"name": middleware})
+	}
+	return refs, created
+}
+
+// initTraefikIngressRoutes converts the traefik.http.routers and traefik.http.services labels of the
+// service to Traefik IngressRoutes routing to the Service svc, one for each router
+func (k *Kubernetes) initTraefikIngressRoutes(name string, service kobject.ServiceConfig, svc *api.Service) []*unstructured.Unstructured {
+	labels := traefikLabels(service)
+	routers, routerOptions := traefikOptions(labels, traefikRoutersPrefix)
+	if len(routers) == 0 {
+		return nil
+	}
+	services, serviceOptions := traefikOptions(labels, traefikServicesPrefix)
+
+	var routes []*unstructured.Unstructured
+	var middlewares []string
+	for _, router := range routers {
+		options := routerOptions[router]
+		if options["rule"] == "" {
+			dropped.Warnf("Ignoring the traefik router %s of service %q, it has no rule", router, name)
+			continue
+		}
+
+		// the router uses its service, or the only service of the labels
+		serverOptions := map[string]string{}
+		if options["service"] != "" {
+			serverOptions = serviceOptions[options["service"]]
+		} else if len(services) == 1 {
+			serverOptions = serviceOptions[services[0]]
+		}
+		backend := map[string]interface{}{
+			"name": svc.Name,
+			"port": traefikServicePort(svc, serverOptions["loadbalancer.server.port"]),
+		}
+		if scheme := serverOptions["loadbalancer.server.scheme"]; scheme != "" {
+			backend["scheme"] = scheme
+		}
+
+		route := map[string]interface{}{
+			"kind":     "Rule",
+			"match":    options["rule"],
+			"services": []interface{}{backend},
+		}
+		if priority, err := strconv.ParseInt(options["priority"], 10, 64); err == nil {
+			route["priority"] = priority
+		}
+		if refs, created := traefikMiddlewares(options["middlewares"]); len(refs) > 0 {
+			route["middlewares"] = refs
+			middlewares = append(middlewares, created...)
+		}
+
+		spec := map[string]interface{}{"routes": []interface{}{route}}
+		var entryPoints []interface{}
+		for _, entryPoint := range strings.Split(options["entrypoints"], ",") {
+			if entryPoint = strings.TrimSpace(entryPoint); entryPoint != "" {
+				entryPoints = append(entryPoints, entryPoint)
+			}
+		}
+		if len(entryPoints) > 0 {
+			spec["entryPoints"] = entryPoints
+		}
+		tls := map[string]interface{}{}
+		if options["tls.certresolver"] != "" {
+			tls["certResolver"] = options["tls.certresolver"]
+		}
+		if options["tls.options"] != "" {
+			tls["options"] = map[string]interface{}{"name": strings.TrimSuffix(options["tls.options"], "@docker")}
+		}
+		if len(tls) > 0 || strings.EqualFold(options["tls"], "true") {
+			spec["tls"] = tls
+		}
+
+		routeName := name
+		if len(routers) > 1 {
+			routeName = name + "-" + strings.Trim(regexp.MustCompile("[^a-z0-9-]+").ReplaceAllString(strings.ToLower(router), "-"), "-")
+		}
+		ingressRoute := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": traefikAPIVersion,
+			"kind":       "IngressRoute",
+			"metadata":   map[string]interface{}{"name": routeName},
+			"spec":       spec,
+		}}
+		ingressRoute.SetLabels(transformer.ConfigLabels(name))
+		ingressRoute.SetAnnotations(transformer.ConfigAnnotations(service))
+		routes = append(routes, ingressRoute)
+	}
+
+	if len(middlewares) > 0 {
+		sort.Strings(middlewares)
+		log.Warnf("The traefik middlewares %s of service %q must be created as Middleware objects in the namespace of its IngressRoutes", strings.Join(middlewares, ", "), name)
+	}
+	return routes
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestTraefikIngressRoutes(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Name:  "web",
+		Image: "nginx",
+		Port:  []kobject.Ports{{HostPort: 80, ContainerPort: 8080, Protocol: "TCP"}, {HostPort: 9090, ContainerPort: 9090, Protocol: "TCP"}},
+		Labels: map[string]string{
+			"traefik.enable":                                         "true",
+			"traefik.http.routers.web.rule":                          "Host(`example.com`) && PathPrefix(`/api`)",
+			"traefik.http.routers.web.entrypoints":                   "web,websecure",
+			"traefik.http.routers.web.middlewares":                   "auth@docker, compress@file",
+			"traefik.http.routers.web.priority":                      "10",
+			"traefik.http.routers.web.tls.certresolver":              "letsencrypt",
+			"traefik.http.routers.web.service":                       "api",
+			"traefik.http.services.api.loadbalancer.server.port":     "8080",
+			"traefik.http.routers.Metrics.rule":                      "Host(`metrics.example.com`)",
+			"traefik.http.routers.Metrics.service":                   "metrics",
+			"traefik.http.services.metrics.loadbalancer.server.port": "9090",
+			"traefik.http.routers.broken.entrypoints":                "web",
+		},
+	}
+	komposeObject := kobject.KomposeObject{ServiceConfigs: map[string]kobject.ServiceConfig{"web": service}}
+
+	k := Kubernetes{}
+	objs, err := k.Transform(komposeObject, kobject.ConvertOptions{CreateD: true, Replicas: 1})
+	if err != nil {
+		t.Fatalf("k.Transform failed: %v", err)
+	}
+
+	routes := map[string]*unstructured.Unstructured{}
+	for _, obj := range objs {
+		if u, ok := obj.(*unstructured.Unstructured); ok && u.GetKind() == "IngressRoute" {
+			routes[u.GetName()] = u
+		}
+	}
+	if len(routes) != 2 || routes["web-web"] == nil || routes["web-metrics"] == nil {
+		t.Fatalf("Expected the IngressRoutes web-web and web-metrics, got %v", routes)
+	}
+
+	expected := map[string]interface{}{
+		"entryPoints": []interface{}{"web", "websecure"},
+		"routes": []interface{}{
+			map[string]interface{}{
+				"kind":     "Rule",
+				"match":    "Host(`example.com`) && PathPrefix(`/api`)",
+				"priority": int64(10),
+				"middlewares": []interface{}{
+					map[string]interface{}{"name": "auth"},
+					map[string]interface{}{"name": "compress@file"},
+				},
+				"services": []interface{}{
+					map[string]interface{}{"name": "web", "port": int64(80)},
+				},
+			},
+		},
+		"tls": map[string]interface{}{"certResolver": "letsencrypt"},
+	}
+	if spec := routes["web-web"].Object["spec"]; !reflect.DeepEqual(spec, expected) {
+		t.Errorf("Expected the spec %v, got %v", expected, spec)
+	}
+
+	port, _, _ := unstructured.NestedFieldNoCopy(routes["web-metrics"].Object, "spec", "routes")
+	backend := port.([]interface{})[0].(map[string]interface{})["services"].([]interface{})[0]
+	if !reflect.DeepEqual(backend, map[string]interface{}{"name": "web", "port": int64(9090)}) {
+		t.Errorf("Expected the metrics router to route to port 9090, got %v", backend)
+	}
+}
+
+func TestTraefikDisabled(t *testing.T) {
+	service := kobject.ServiceConfig{
+		Labels: map[string]string{
+			"traefik.enable":                "false",
+			"traefik.http.routers.web.rule": "Host(`example.com`)",
+		},
+	}
+	if labels := traefikLabels(service); len(labels) != 0 {
+		t.Errorf("Expected the labels of a disabled service to be ignored, got %v", labels)
+	}
+}
@@ -19,11 +19,14 @@ package openshift
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"sort"
 
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/transformer"
 	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
 	deployapi "github.com/openshift/api/apps/v1"
 	buildapi "github.com/openshift/api/build/v1"
 	imageapi "github.com/openshift/api/image/v1"
@@ -44,6 +47,20 @@ type OpenShift struct {
 	kubernetes.Kubernetes
 }
 
+func init() {
+	transformer.Register("openshift", func(opt kobject.ConvertOptions) transformer.Transformer {
+		// OpenShift inherits from Kubernetes
+		return &OpenShift{Kubernetes: kubernetes.Kubernetes{Opt: opt}}
+	})
+}
+
+const (
+	// BuildStrategyDocker builds the images with the Dockerfile of the services
+	BuildStrategyDocker = "docker"
+	// BuildStrategySource builds the images from the sources with a builder image (S2I)
+	BuildStrategySource = "source"
+)
+
 // list of all unsupported keys for this transformer
 // Keys are names of variables in kobject struct.
 // this is map to make searching for keys easier
@@ -72,7 +89,7 @@ func (o *OpenShift) initImageStream(name string, service kobject.ServiceConfig,
 					Name: service.Image,
 				},
 				ImportPolicy: importPolicy,
-				Name:         GetImageTag(service.Image),
+				Name:         GetBuildOutputTag(service),
 			})
 	}
 
@@ -109,6 +126,17 @@ func initBuildConfig(name string, service kobject.ServiceConfig, repo string, br
 		return nil, errors.Wrap(err, name+"buildconfig cannot be created due to error in creating build context, getAbsBuildContext failed")
 	}
 
+	strategy, err := initBuildStrategy(name, service, envList)
+	if err != nil {
+		return nil, err
+	}
+
+	var imageLabels []buildapi.ImageLabel
+	for label, value := range service.BuildLabels {
+		imageLabels = append(imageLabels, buildapi.ImageLabel{Name: label, Value: value})
+	}
+	sort.Slice(imageLabels, func(i, j int) bool { return imageLabels[i].Name < imageLabels[j].Name })
+
 	bc := &buildapi.BuildConfig{
 		TypeMeta: kapi.TypeMeta{
 			Kind:       "BuildConfig",
@@ -120,9 +148,7 @@ func initBuildConfig(name string, service kobject.ServiceConfig, repo string, br
 			Labels: transformer.ConfigLabels(name),
 		},
 		Spec: buildapi.BuildConfigSpec{
-			Triggers: []buildapi.BuildTriggerPolicy{
-				{Type: "ConfigChange"},
-			},
+			Triggers:  initBuildTriggers(name, service),
 			RunPolicy: "Serial",
 			CommonSpec: buildapi.CommonSpec{
 				Source: buildapi.BuildSource{
@@ -132,17 +158,13 @@ func initBuildConfig(name string, service kobject.ServiceConfig, repo string, br
 					},
 					ContextDir: contextDir,
 				},
-				Strategy: buildapi.BuildStrategy{
-					DockerStrategy: &buildapi.DockerBuildStrategy{
-						DockerfilePath: service.Dockerfile,
-						Env:            envList,
-					},
-				},
+				Strategy: strategy,
 				Output: buildapi.BuildOutput{
 					To: &corev1.ObjectReference{
 						Kind: "ImageStreamTag",
-						Name: name + ":" + GetImageTag(service.Image),
+						Name: name + ":" + GetBuildOutputTag(service),
 					},
+					ImageLabels: imageLabels,
 				},
 			},
 		},
@@ -150,12 +172,79 @@ func initBuildConfig(name string, service kobject.ServiceConfig, repo string, br
 	return bc, nil
 }
 
+// initBuildStrategy returns the Docker strategy building the Dockerfile of the service, or the
+// source (S2I) strategy building the sources with the builder image of the kompose.build.builder-image label
+func initBuildStrategy(name string, service kobject.ServiceConfig, envList []corev1.EnvVar) (buildapi.BuildStrategy, error) {
+	switch service.BuildStrategy {
+	case "", BuildStrategyDocker:
+		if service.BuildTarget != "" {
+			dropped.Warnf("Ignoring the build target %q of service %q, the OpenShift Docker builds build the last stage of the Dockerfile", service.BuildTarget, name)
+		}
+		return buildapi.BuildStrategy{
+			Type: buildapi.DockerBuildStrategyType,
+			DockerStrategy: &buildapi.DockerBuildStrategy{
+				DockerfilePath: service.Dockerfile,
+				// the args are also set in the environment of the build, like in the previous releases
+				Env:       envList,
+				BuildArgs: envList,
+			},
+		}, nil
+	case BuildStrategySource:
+		if service.BuildBuilderImage == "" {
+			return buildapi.BuildStrategy{}, errors.Errorf("the source build of service %q requires a builder image, set it with the kompose.build.builder-image label", name)
+		}
+		if service.Dockerfile != "" {
+			dropped.Warnf("Ignoring the dockerfile of service %q, the source builds don't use a Dockerfile", name)
+		}
+		return buildapi.BuildStrategy{
+			Type: buildapi.SourceBuildStrategyType,
+			SourceStrategy: &buildapi.SourceBuildStrategy{
+				From: corev1.ObjectReference{
+					Kind: "DockerImage",
+					Name: service.BuildBuilderImage,
+				},
+				Env: envList,
+			},
+		}, nil
+	default:
+		return buildapi.BuildStrategy{}, errors.Errorf("unknown build strategy %q of service %q", service.BuildStrategy, name)
+	}
+}
+
+// initBuildTriggers returns the triggers of the BuildConfig, its creation and the webhooks of the
+// kompose.build.webhooks label. The webhooks read their secret from the Secret named <service>-webhook.
+func initBuildTriggers(name string, service kobject.ServiceConfig) []buildapi.BuildTriggerPolicy {
+	triggers := []buildapi.BuildTriggerPolicy{
+		{Type: "ConfigChange"},
+	}
+	webhook := func() *buildapi.WebHookTrigger {
+		return &buildapi.WebHookTrigger{
+			SecretReference: &buildapi.SecretLocalReference{Name: name + "-webhook"},
+		}
+	}
+	for _, hook := range service.BuildWebhooks {
+		switch hook {
+		case "github":
+			triggers = append(triggers, buildapi.BuildTriggerPolicy{Type: buildapi.GitHubWebHookBuildTriggerType, GitHubWebHook: webhook()})
+		case "gitlab":
+			triggers = append(triggers, buildapi.BuildTriggerPolicy{Type: buildapi.GitLabWebHookBuildTriggerType, GitLabWebHook: webhook()})
+		case "bitbucket":
+			triggers = append(triggers, buildapi.BuildTriggerPolicy{Type: buildapi.BitbucketWebHookBuildTriggerType, BitbucketWebHook: webhook()})
+		case "generic":
+			triggers = append(triggers, buildapi.BuildTriggerPolicy{Type: buildapi.GenericWebHookBuildTriggerType, GenericWebHook: webhook()})
+		default:
+			dropped.Warnf("Ignoring the unknown build webhook %q of service %q", hook, name)
+		}
+	}
+	return triggers
+}
+
 // initDeploymentConfig initializes OpenShifts DeploymentConfig object
 func (o *OpenShift) initDeploymentConfig(name string, service kobject.ServiceConfig, replicas int) *deployapi.DeploymentConfig {
 	containerName := []string{name}
 
 	// Properly add tags to the image name
-	tag := GetImageTag(service.Image)
+	tag := GetBuildOutputTag(service)
 
 	// Use ContainerName if it was set
 	if service.ContainerName != "" {
@@ -180,6 +269,7 @@ func (o *OpenShift) initDeploymentConfig(name string, service kobject.ServiceCon
 		},
 		Spec: deployapi.DeploymentConfigSpec{
 			Replicas: int32(replicas),
+			Paused:   service.Paused,
 			Selector: transformer.ConfigLabels(name),
 			//UniqueLabelKey: p.Name,
 			Template: &corev1.PodTemplateSpec{
@@ -221,33 +311,100 @@ func (o *OpenShift) initDeploymentConfig(name string, service kobject.ServiceCon
 	return dc
 }
 
-func (o *OpenShift) initRoute(name string, service kobject.ServiceConfig, port int32) *routeapi.Route {
-	route := &routeapi.Route{
-		TypeMeta: kapi.TypeMeta{
-			Kind:       "Route",
-			APIVersion: "v1",
-		},
-		ObjectMeta: kapi.ObjectMeta{
-			Name:   name,
-			Labels: transformer.ConfigLabels(name),
-		},
-		Spec: routeapi.RouteSpec{
-			Port: &routeapi.RoutePort{
-				TargetPort: intstr.IntOrString{
-					IntVal: port,
-				},
+// initRoutes returns a Route for each host of the kompose.service.expose label, routing its path
+// to the port of the service with the TLS settings of the kompose.service.expose.route labels
+func (o *OpenShift) initRoutes(name string, service kobject.ServiceConfig, port int32, opt kobject.ConvertOptions) ([]*routeapi.Route, error) {
+	tls, err := routeTLSConfig(service.Route, opt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid route of service %s", name)
+	}
+
+	hosts := regexp.MustCompile("[ ,]*,[ ,]*").Split(service.ExposeService, -1)
+	routes := make([]*routeapi.Route, 0, len(hosts))
+	for i, host := range hosts {
+		host, path := transformer.ParseIngressPath(host)
+		routeName := name
+		if i > 0 {
+			routeName = fmt.Sprintf("%s-%d", name, i+1)
+		}
+		route := &routeapi.Route{
+			TypeMeta: kapi.TypeMeta{
+				Kind:       "Route",
+				APIVersion: "v1",
 			},
-			To: routeapi.RouteTargetReference{
-				Kind: "Service",
-				Name: name,
+			ObjectMeta: kapi.ObjectMeta{
+				Name:   routeName,
+				Labels: transformer.ConfigLabels(name),
 			},
-		},
+			Spec: routeapi.RouteSpec{
+				Path: path,
+				Port: &routeapi.RoutePort{
+					TargetPort: intstr.IntOrString{
+						IntVal: port,
+					},
+				},
+				To: routeapi.RouteTargetReference{
+					Kind: "Service",
+					Name: name,
+				},
+				TLS: tls,
+			},
+		}
+		if host != "true" {
+			route.Spec.Host = host
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// routeTLSConfig returns the TLS settings of the routes, reading the PEM files of the certificates
+func routeTLSConfig(config kobject.RouteConfig, opt kobject.ConvertOptions) (*routeapi.TLSConfig, error) {
+	if config.Termination == "" {
+		return nil, nil
+	}
+	tls := &routeapi.TLSConfig{
+		Termination: routeapi.TLSTerminationType(config.Termination),
+	}
+	switch config.InsecureEdgeTerminationPolicy {
+	case "none":
+		tls.InsecureEdgeTerminationPolicy = routeapi.InsecureEdgeTerminationPolicyNone
+	case "allow":
+		tls.InsecureEdgeTerminationPolicy = routeapi.InsecureEdgeTerminationPolicyAllow
+	case "redirect":
+		tls.InsecureEdgeTerminationPolicy = routeapi.InsecureEdgeTerminationPolicyRedirect
 	}
 
-	if service.ExposeService != "true" {
-		route.Spec.Host = service.ExposeService
+	for _, pem := range []struct {
+		file   string
+		target *string
+	}{
+		{config.Certificate, &tls.Certificate},
+		{config.Key, &tls.Key},
+		{config.CACertificate, &tls.CACertificate},
+		{config.DestinationCACertificate, &tls.DestinationCACertificate},
+	} {
+		if pem.file == "" {
+			continue
+		}
+		dir, err := transformer.GetWorkingDir(opt)
+		if err != nil {
+			return nil, err
+		}
+		file := pem.file
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		if opt.RestrictPaths && !transformer.InDir(dir, file) {
+			return nil, errors.Errorf("the certificate %s is outside of the directory of the compose files", pem.file)
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the certificate")
+		}
+		*pem.target = string(content)
 	}
-	return route
+	return tls, nil
 }
 
 // Transform maps komposeObject to openshift objects
@@ -255,12 +412,12 @@ func (o *OpenShift) initRoute(name string, service kobject.ServiceConfig, port i
 func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) ([]runtime.Object, error) {
 	noSupKeys := o.Kubernetes.CheckUnsupportedKey(&komposeObject, unsupportedKey)
 	for _, keyName := range noSupKeys {
-		log.Warningf("OpenShift provider doesn't support %s key - ignoring", keyName)
+		dropped.Warnf("OpenShift provider doesn't support %s key - ignoring", keyName)
 	}
 	// this will hold all the converted data
 	var allobjects []runtime.Object
 
-	if komposeObject.Namespace != "" {
+	if komposeObject.Namespace != "" && opt.CreateNamespace {
 		ns := transformer.CreateNamespace(komposeObject.Namespace)
 		allobjects = append(allobjects, ns)
 	}
@@ -271,13 +428,11 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 	buildBranch := opt.BuildBranch
 
 	if komposeObject.Secrets != nil {
-		secrets, err := o.CreateSecrets(komposeObject)
+		secrets, err := o.SecretObjects(komposeObject, opt)
 		if err != nil {
 			return nil, errors.Wrapf(err, "create secrets error")
 		}
-		for _, item := range secrets {
-			allobjects = append(allobjects, item)
-		}
+		allobjects = append(allobjects, secrets...)
 	}
 
 	sortedKeys := kubernetes.SortedKeys(komposeObject.ServiceConfigs)
@@ -287,10 +442,10 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 
 		//replicas
 		var replica int
-		if opt.IsReplicaSetFlag || service.Replicas == 0 {
+		if opt.IsReplicaSetFlag || service.Replicas == nil {
 			replica = opt.Replicas
 		} else {
-			replica = service.Replicas
+			replica = *service.Replicas
 		}
 
 		// If Deploy.Mode = Global has been set, make replica = 1 when generating DeploymentConfig
@@ -315,13 +470,13 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 			// Build the container!
 			err := transformer.BuildDockerImage(service, name)
 			if err != nil {
-				log.Fatalf("Unable to build Docker container for service %v: %v", name, err)
+				return nil, errors.Wrapf(err, "Unable to build Docker container for service %v", name)
 			}
 
 			// Push the built container to the repo!
-			err = transformer.PushDockerImageWithOpt(service, name, opt)
+			service.Image, err = transformer.PushDockerImageWithOpt(service, name, opt)
 			if err != nil {
-				log.Fatalf("Unable to push Docker image for service %v: %v", name, err)
+				return nil, errors.Wrapf(err, "Unable to push Docker image for service %v", name)
 			}
 		}
 
@@ -333,17 +488,25 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 			}
 
 			if service.CronJobSchedule != "" {
-				cronJob := o.InitCJ(name, service, service.CronJobSchedule, service.CronJobConcurrencyPolicy, service.CronJobBackoffLimit)
+				cronJob := o.InitCJ(name, service, service.CronJobSchedule, service.CronJobConcurrencyPolicy, kubernetes.GetJobBackoffLimit(service))
 				objects = append(objects, cronJob)
+			} else if kubernetes.NeedsJob(service) {
+				job := o.InitJ(name, service, kubernetes.GetJobBackoffLimit(service))
+				objects = append(objects, job)
 			} else {
 				pod := o.InitPod(name, service)
 				objects = append(objects, pod)
 			}
 
-			envConfigMaps := o.PargeEnvFiletoConfigMaps(name, service, opt)
+			envConfigMaps, err := o.PargeEnvFiletoConfigMaps(name, service, opt)
+			if err != nil {
+				return nil, err
+			}
 			objects = append(objects, envConfigMaps...)
 		} else {
-			objects = o.CreateWorkloadAndConfigMapObjects(name, service, opt)
+			if objects, err = o.CreateWorkloadAndConfigMapObjects(name, service, opt); err != nil {
+				return nil, err
+			}
 
 			if opt.CreateDeploymentConfig {
 				objects = append(objects, o.initDeploymentConfig(name, service, replica)) // OpenShift DeploymentConfigs
@@ -355,7 +518,7 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 			// Generate BuildConfig if the parameter has been passed
 			if service.Build != "" && opt.Build == "build-config" {
 				// Get the compose file directory
-				composeFileDir, err = transformer.GetComposeFileDir(opt.InputFiles)
+				composeFileDir, err = transformer.GetWorkingDir(opt)
 				if err != nil {
 					log.Warningf("Error %v in detecting compose file's directory.", err)
 					continue
@@ -397,22 +560,33 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 			}
 		}
 
+		if service.ServiceType != "LoadBalancer" && (service.LoadBalancerIP != "" || service.LoadBalancerClass != "") {
+			dropped.Warnf("Ignoring the load balancer IP and class of service %q, it isn't of type LoadBalancer", name)
+		}
 		if o.PortsExist(service) {
 			if service.ServiceType == "LoadBalancer" {
 				svcs := o.CreateLBService(name, service)
 				for _, svc := range svcs {
-					svc.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyType(service.ServiceExternalTrafficPolicy)
 					objects = append(objects, svc)
 				}
 				if len(svcs) > 1 {
 					log.Warningf("Create multiple service to avoid using mixed protocol in the same service when it's loadbalancer type")
 				}
 			} else {
-				svc := o.CreateService(name, service)
+				svc, err := o.CreateService(name, service)
+				if err != nil {
+					return nil, err
+				}
 				objects = append(objects, svc)
 
 				if service.ExposeService != "" {
-					objects = append(objects, o.initRoute(name, service, svc.Spec.Ports[0].Port))
+					routes, err := o.initRoutes(name, service, svc.Spec.Ports[0].Port, opt)
+					if err != nil {
+						return nil, err
+					}
+					for _, route := range routes {
+						objects = append(objects, route)
+					}
 				}
 				if service.ServiceExternalTrafficPolicy != "" && svc.Spec.Type != corev1.ServiceTypeNodePort {
 					log.Warningf("External Traffic Policy is ignored for the service %v of type %v", name, service.ServiceType)
@@ -425,22 +599,41 @@ func (o *OpenShift) Transform(komposeObject kobject.KomposeObject, opt kobject.C
 				log.Warningf("External Traffic Policy is ignored for the service %v of type Headless", name)
 			}
 		}
-
-		err := o.UpdateKubernetesObjects(name, service, opt, &objects)
+		aliases, err := o.CreateAliasServices(name, service)
 		if err != nil {
+			return nil, err
+		}
+		for _, svc := range aliases {
+			objects = append(objects, svc)
+		}
+
+		if err := o.UpdateKubernetesObjects(name, service, opt, &objects); err != nil {
 			return nil, errors.Wrap(err, "Error transforming Kubernetes objects")
 		}
+		if err := o.ConfigPriorityClass(service, &objects); err != nil {
+			return nil, err
+		}
 
 		allobjects = append(allobjects, objects...)
 	}
 
-	// sort all object so Services are first
-	o.SortServicesFirst(&allobjects)
+	if err := o.ApplyKubernetesPatches(&allobjects, komposeObject); err != nil {
+		return nil, err
+	}
+
+	// sort the objects in the order they are applied in
+	o.SortObjects(&allobjects)
 	o.RemoveDupObjects(&allobjects)
 	if komposeObject.Namespace != "" {
 		transformer.AssignNamespaceToObjects(&allobjects, komposeObject.Namespace)
 	}
 	// o.FixWorkloadVersion(&allobjects)
+	if err := o.ConfigDependencyWaits(&allobjects, komposeObject.ServiceConfigs, opt); err != nil {
+		return nil, err
+	}
+	if err := o.ConfigSyncWaves(&allobjects, komposeObject.ServiceConfigs, opt); err != nil {
+		return nil, err
+	}
 
 	return allobjects, nil
 }
@@ -28,6 +28,8 @@ import (
 	"github.com/kubernetes/kompose/pkg/transformer"
 	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
 	deployapi "github.com/openshift/api/apps/v1"
+	buildapi "github.com/openshift/api/build/v1"
+	routeapi "github.com/openshift/api/route/v1"
 	"github.com/pkg/errors"
 	api "k8s.io/api/core/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -119,7 +121,11 @@ func TestKomposeConvertRoute(t *testing.T) {
 	sc := newServiceConfig()
 	sc.ExposeService = "true"
 	var port int32 = 5555
-	route := o.initRoute(name, sc, port)
+	routes, err := o.initRoutes(name, sc, port, kobject.ConvertOptions{})
+	if err != nil || len(routes) != 1 {
+		t.Fatalf("Expected a single route, got %d and %v", len(routes), err)
+	}
+	route := routes[0]
 
 	if route.ObjectMeta.Name != name {
 		t.Errorf("Expected %s for name, actual %s", name, route.ObjectMeta.Name)
@@ -133,12 +139,60 @@ func TestKomposeConvertRoute(t *testing.T) {
 	if route.Spec.Host != "" {
 		t.Errorf("Expected Spec.Host to not be set, got %s instead", route.Spec.Host)
 	}
+	if route.Spec.TLS != nil {
+		t.Errorf("Expected Spec.TLS to not be set, got %+v instead", route.Spec.TLS)
+	}
+
+	sc.ExposeService = "example.com/api, www.example.com"
+	routes, err = o.initRoutes(name, sc, port, kobject.ConvertOptions{})
+	if err != nil || len(routes) != 2 {
+		t.Fatalf("Expected a route for each host, got %d and %v", len(routes), err)
+	}
+	if routes[0].Name != name || routes[0].Spec.Host != "example.com" || routes[0].Spec.Path != "/api" {
+		t.Errorf("Expected the route %s of example.com/api, got %s of %s%s", name, routes[0].Name, routes[0].Spec.Host, routes[0].Spec.Path)
+	}
+	if routes[1].Name != "app-2" || routes[1].Spec.Host != "www.example.com" || routes[1].Spec.Path != "" {
+		t.Errorf("Expected the route app-2 of www.example.com, got %s of %s%s", routes[1].Name, routes[1].Spec.Host, routes[1].Spec.Path)
+	}
+}
+
+func TestKomposeConvertRouteTLS(t *testing.T) {
+	dir := t.TempDir()
+	for file, content := range map[string]string{"tls.crt": "CERTIFICATE", "tls.key": "KEY", "service-ca.crt": "SERVICE CA"} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opt := kobject.ConvertOptions{InputFiles: []string{filepath.Join(dir, "compose.yaml")}}
 
+	o := OpenShift{}
+	sc := newServiceConfig()
 	sc.ExposeService = "example.com"
-	route = o.initRoute(name, sc, port)
+	sc.Route = kobject.RouteConfig{
+		Termination:                   "reencrypt",
+		InsecureEdgeTerminationPolicy: "redirect",
+		Certificate:                   "tls.crt",
+		Key:                           "tls.key",
+		DestinationCACertificate:      "service-ca.crt",
+	}
+	routes, err := o.initRoutes("app", sc, 8443, opt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := &routeapi.TLSConfig{
+		Termination:                   routeapi.TLSTerminationReencrypt,
+		InsecureEdgeTerminationPolicy: routeapi.InsecureEdgeTerminationPolicyRedirect,
+		Certificate:                   "CERTIFICATE",
+		Key:                           "KEY",
+		DestinationCACertificate:      "SERVICE CA",
+	}
+	if !reflect.DeepEqual(routes[0].Spec.TLS, expected) {
+		t.Errorf("Expected the TLS settings %+v, got %+v", expected, routes[0].Spec.TLS)
+	}
 
-	if route.Spec.Host != sc.ExposeService {
-		t.Errorf("Expected %s for Spec.Host, actual %s", sc.ExposeService, route.Spec.Host)
+	sc.Route.Key = "missing.key"
+	if _, err := o.initRoutes("app", sc, 8443, opt); err == nil {
+		t.Errorf("Expected an error for a missing key")
 	}
 }
 
@@ -361,6 +415,59 @@ func TestInitBuildConfig(t *testing.T) {
 	}
 }
 
+func TestInitBuildStrategy(t *testing.T) {
+	envList := []corev1.EnvVar{{Name: "name", Value: "value"}}
+
+	strategy, err := initBuildStrategy("web", kobject.ServiceConfig{Dockerfile: "Dockerfile.prod"}, envList)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strategy.Type != buildapi.DockerBuildStrategyType || strategy.DockerStrategy.DockerfilePath != "Dockerfile.prod" || !reflect.DeepEqual(strategy.DockerStrategy.BuildArgs, envList) {
+		t.Errorf("Expected a Docker strategy with the build args, got %#v", strategy)
+	}
+
+	strategy, err = initBuildStrategy("web", kobject.ServiceConfig{BuildStrategy: BuildStrategySource, BuildBuilderImage: "registry.access.redhat.com/ubi9/python-311"}, envList)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strategy.Type != buildapi.SourceBuildStrategyType || strategy.SourceStrategy.From.Name != "registry.access.redhat.com/ubi9/python-311" || !reflect.DeepEqual(strategy.SourceStrategy.Env, envList) {
+		t.Errorf("Expected a source strategy with the builder image, got %#v", strategy)
+	}
+
+	if _, err := initBuildStrategy("web", kobject.ServiceConfig{BuildStrategy: BuildStrategySource}, envList); err == nil {
+		t.Errorf("Expected an error for a source build without builder image")
+	}
+}
+
+func TestInitBuildTriggers(t *testing.T) {
+	triggers := initBuildTriggers("web", kobject.ServiceConfig{BuildWebhooks: []string{"github", "generic"}})
+	if len(triggers) != 3 {
+		t.Fatalf("Expected 3 triggers, got %#v", triggers)
+	}
+	if triggers[1].Type != buildapi.GitHubWebHookBuildTriggerType || triggers[1].GitHubWebHook.SecretReference.Name != "web-webhook" {
+		t.Errorf("Expected the GitHub webhook, got %#v", triggers[1])
+	}
+	if triggers[2].Type != buildapi.GenericWebHookBuildTriggerType || triggers[2].GenericWebHook.SecretReference.Name != "web-webhook" {
+		t.Errorf("Expected the generic webhook, got %#v", triggers[2])
+	}
+}
+
+func TestGetBuildOutputTag(t *testing.T) {
+	testCases := []struct {
+		service  kobject.ServiceConfig
+		expected string
+	}{
+		{kobject.ServiceConfig{Image: "foo:bar"}, "bar"},
+		{kobject.ServiceConfig{Image: "foo:bar", BuildOutputTag: "dev"}, "bar"},
+		{kobject.ServiceConfig{Image: "foo:bar", Build: "./app", BuildOutputTag: "dev"}, "dev"},
+	}
+	for _, tc := range testCases {
+		if tag := GetBuildOutputTag(tc.service); tag != tc.expected {
+			t.Errorf("Expected %q, got %q", tc.expected, tag)
+		}
+	}
+}
+
 // TestServiceWithoutPort this tests if Headless Service is created for services without Port (with label)
 func TestServiceWithoutPort(t *testing.T) {
 	service := kobject.ServiceConfig{
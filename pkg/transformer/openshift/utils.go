@@ -1,9 +1,10 @@
 package openshift
 
 import (
-	"github.com/pkg/errors"
-	"os/exec"
 	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
 )
 
 // GetImageTag get tag name from image name
@@ -31,59 +32,31 @@ func GetImageTag(image string) string {
 	return "latest"
 }
 
+// GetBuildOutputTag returns the ImageStream tag of the service, the one of the kompose.build.output-tag
+// label for the services built by a BuildConfig, the tag of the image otherwise
+func GetBuildOutputTag(service kobject.ServiceConfig) string {
+	if service.Build != "" && service.BuildOutputTag != "" {
+		return service.BuildOutputTag
+	}
+	return GetImageTag(service.Image)
+}
+
 // GetAbsBuildContext returns build context relative to project root dir
 func GetAbsBuildContext(context string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-prefix")
-	cmd.Dir = context
-	var out strings.Builder
-	var stderr strings.Builder
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	if err != nil {
-		return "", errors.New(stderr.String())
-	}
-	//convert output of command to string
-	contextDir := strings.Trim(out.String(), "\n")
-	return contextDir, nil
+	return transformer.GetAbsBuildContext(context)
 }
 
 // HasGitBinary checks if the 'git' binary is available on the system
 func HasGitBinary() bool {
-	_, err := exec.LookPath("git")
-	return err == nil
+	return transformer.HasGitBinary()
 }
 
 // GetGitCurrentRemoteURL gets current git remote URI for the current git repo
 func GetGitCurrentRemoteURL(composeFileDir string) (string, error) {
-	cmd := exec.Command("git", "ls-remote", "--get-url")
-	cmd.Dir = composeFileDir
-	var out strings.Builder
-	var stderr strings.Builder
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	if err != nil {
-		return "", errors.New(stderr.String())
-	}
-	url := strings.TrimRight(out.String(), "\n")
-	if !strings.HasSuffix(url, ".git") {
-		url += ".git"
-	}
-	return url, nil
+	return transformer.GetGitCurrentRemoteURL(composeFileDir)
 }
 
 // GetGitCurrentBranch gets current git branch name for the current git repo
 func GetGitCurrentBranch(composeFileDir string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = composeFileDir
-	var out strings.Builder
-	var stderr strings.Builder
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	if err != nil {
-		return "", errors.New(stderr.String())
-	}
-	return strings.TrimRight(out.String(), "\n"), nil
+	return transformer.GetGitCurrentBranch(composeFileDir)
 }
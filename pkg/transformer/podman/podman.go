@@ -0,0 +1,368 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package podman converts the compose files for the single-node Podman hosts, as the kube YAML
+// played by podman kube play and the systemd Quadlet units running it.
+package podman
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"github.com/kubernetes/kompose/pkg/transformer"
+	"github.com/kubernetes/kompose/pkg/transformer/kubernetes"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// QuadletKube runs the kube YAML of the project with a .kube unit
+	QuadletKube = "kube"
+	// QuadletContainer runs each container with a .container unit
+	QuadletContainer = "container"
+)
+
+// playedKinds are the kinds podman kube play creates, the other objects are dropped
+var playedKinds = map[string]bool{
+	"Pod":                   true,
+	"Deployment":            true,
+	"DaemonSet":             true,
+	"Job":                   true,
+	"PersistentVolumeClaim": true,
+	"ConfigMap":             true,
+	"Secret":                true,
+}
+
+// Podman implements the Transformer and Printer interfaces for the Podman hosts
+type Podman struct {
+	// Podman converts the services as Kubernetes does, keeping the objects podman plays
+	kubernetes.Kubernetes
+}
+
+func init() {
+	transformer.Register("podman", func(opt kobject.ConvertOptions) transformer.Transformer {
+		return &Podman{Kubernetes: kubernetes.Kubernetes{Opt: opt}}
+	})
+}
+
+// Transform converts the services to the objects podman kube play creates, the published ports
+// are published on the host as the host ports of the containers
+func (p *Podman) Transform(komposeObject kobject.KomposeObject, opt kobject.ConvertOptions) ([]runtime.Object, error) {
+	services := make(map[string]kobject.ServiceConfig, len(komposeObject.ServiceConfigs))
+	for name, service := range komposeObject.ServiceConfigs {
+		service.ExposeContainerToHost = true
+		services[name] = service
+	}
+	komposeObject.ServiceConfigs = services
+
+	objects, err := p.Kubernetes.Transform(komposeObject, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var played []runtime.Object
+	dropped := map[string]bool{}
+	for _, obj := range objects {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if playedKinds[kind] {
+			played = append(played, obj)
+		} else {
+			dropped[kind] = true
+		}
+	}
+	for _, kind := range sortedKeys(dropped) {
+		// the services are reached on the network of the project, the ports are published by the containers
+		if kind == "Service" {
+			continue
+		}
+		log.Warnf("Podman doesn't play the %s objects, ignoring them", kind)
+	}
+	return played, nil
+}
+
+// PrintList writes the kube YAML of the objects and the Quadlet units running it to the output
+// directory, or prints them each after a comment naming its file
+func (p *Podman) PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error {
+	files, err := p.quadletFiles(objects, opt)
+	if err != nil {
+		return err
+	}
+
+	if opt.ToStdout {
+		for _, file := range files {
+			fmt.Printf("# %s\n%s\n", file.name, file.content)
+		}
+		return nil
+	}
+
+	dir := opt.OutFile
+	if dir == "" {
+		dir = "."
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		return errors.Errorf("the podman provider writes several files, --out %s must be a directory", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "failed to create a directory")
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			return errors.Wrapf(err, "unable to write %s", path)
+		}
+		log.Printf("Podman file %q created", path)
+	}
+	return nil
+}
+
+// file is a file generated for podman
+type file struct {
+	name    string
+	content string
+}
+
+// quadletFiles returns the files of the project: its network unit, and either its kube YAML and the
+// .kube unit playing it or a .container unit for each container
+func (p *Podman) quadletFiles(objects []runtime.Object, opt kobject.ConvertOptions) ([]file, error) {
+	project := projectName(opt)
+	network := project + ".network"
+	files := []file{{name: network, content: unit(section{"Network", nil})}}
+
+	if opt.Quadlet == QuadletContainer {
+		units, err := p.containerUnits(objects, network)
+		if err != nil {
+			return nil, err
+		}
+		return append(files, units...), nil
+	}
+
+	data, err := kubernetes.MarshalObjects(objects, opt.YAMLIndent)
+	if err != nil {
+		return nil, err
+	}
+	yaml := project + ".yaml"
+	files = append(files,
+		file{name: yaml, content: string(data)},
+		file{name: project + ".kube", content: unit(
+			section{"Unit", []string{"Description=The " + project + " compose project converted by kompose"}},
+			section{"Kube", []string{"Yaml=" + yaml, "Network=" + network}},
+			section{"Install", []string{"WantedBy=default.target"}},
+		)},
+	)
+	return files, nil
+}
+
+// containerUnits returns a .container unit for each container of the workloads, the environment
+// variables of the ConfigMaps are written in the units
+func (p *Podman) containerUnits(objects []runtime.Object, network string) ([]file, error) {
+	configMaps := map[string]map[string]string{}
+	for _, obj := range objects {
+		if configMap, ok := obj.(*api.ConfigMap); ok {
+			configMaps[configMap.Name] = configMap.Data
+		}
+	}
+
+	var files []file
+	for _, obj := range objects {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		err = p.UpdateController(obj, func(template *api.PodTemplateSpec) error {
+			for _, container := range template.Spec.Containers {
+				name := accessor.GetName()
+				if len(template.Spec.Containers) > 1 {
+					name += "-" + container.Name
+				}
+				files = append(files, file{
+					name:    name + ".container",
+					content: containerUnit(name, container, template.Spec, configMaps, network),
+				})
+			}
+			return nil
+		}, func(*metav1.ObjectMeta) {})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// containerUnit returns the .container unit of the container of the pod spec
+func containerUnit(name string, container api.Container, spec api.PodSpec, configMaps map[string]map[string]string, network string) string {
+	keys := []string{"ContainerName=" + name, "Image=" + container.Image}
+	if len(container.Command) > 0 {
+		entrypoint := container.Command[0]
+		if len(container.Command) > 1 {
+			data, _ := json.Marshal(container.Command)
+			entrypoint = string(data)
+		}
+		keys = append(keys, "Entrypoint="+entrypoint)
+	}
+	if len(container.Args) > 0 {
+		keys = append(keys, "Exec="+quoteArgs(container.Args))
+	}
+	if container.WorkingDir != "" {
+		keys = append(keys, "WorkingDir="+container.WorkingDir)
+	}
+
+	for _, envFrom := range container.EnvFrom {
+		if envFrom.ConfigMapRef == nil {
+			log.Warnf("The environment of the container %s comes from a secret, set it in its unit", name)
+			continue
+		}
+		data := configMaps[envFrom.ConfigMapRef.Name]
+		for _, key := range sortedKeys(data) {
+			keys = append(keys, "Environment="+quoteArgs([]string{envFrom.Prefix + key + "=" + data[key]}))
+		}
+	}
+	for _, env := range container.Env {
+		value := env.Value
+		if env.ValueFrom != nil {
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				value = configMaps[ref.Name][ref.Key]
+			} else {
+				log.Warnf("The environment variable %s of the container %s doesn't have a value, set it in its unit", env.Name, name)
+				continue
+			}
+		}
+		keys = append(keys, "Environment="+quoteArgs([]string{env.Name + "=" + value}))
+	}
+
+	for _, port := range container.Ports {
+		if port.HostPort == 0 {
+			continue
+		}
+		publish := fmt.Sprintf("%d:%d", port.HostPort, port.ContainerPort)
+		if port.HostIP != "" {
+			publish = port.HostIP + ":" + publish
+		}
+		if port.Protocol == api.ProtocolUDP {
+			publish += "/udp"
+		}
+		keys = append(keys, "PublishPort="+publish)
+	}
+
+	volumes := map[string]api.Volume{}
+	for _, volume := range spec.Volumes {
+		volumes[volume.Name] = volume
+	}
+	for _, mount := range container.VolumeMounts {
+		volume := volumes[mount.Name]
+		var source string
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			source = volume.PersistentVolumeClaim.ClaimName + ":"
+		case volume.HostPath != nil:
+			source = volume.HostPath.Path + ":"
+		case volume.EmptyDir != nil:
+		default:
+			log.Warnf("The volume %s of the container %s isn't a claim, a host path or an empty directory, ignoring it", mount.Name, name)
+			continue
+		}
+		target := source + mount.MountPath
+		if mount.ReadOnly {
+			target += ":ro"
+		}
+		keys = append(keys, "Volume="+target)
+	}
+	keys = append(keys, "Network="+network)
+
+	restart := "always"
+	switch spec.RestartPolicy {
+	case api.RestartPolicyOnFailure:
+		restart = "on-failure"
+	case api.RestartPolicyNever:
+		restart = "no"
+	}
+	return unit(
+		section{"Unit", []string{"Description=The " + name + " container converted by kompose"}},
+		section{"Container", keys},
+		section{"Service", []string{"Restart=" + restart}},
+		section{"Install", []string{"WantedBy=default.target"}},
+	)
+}
+
+// section is a section of a systemd unit and its keys
+type section struct {
+	name string
+	keys []string
+}
+
+// unit returns the content of a systemd unit
+func unit(sections ...section) string {
+	var b strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[%s]\n", s.name)
+		for _, key := range s.keys {
+			b.WriteString(key + "\n")
+		}
+	}
+	return b.String()
+}
+
+// quoteArgs joins the arguments as systemd splits them, quoting the ones with spaces, quotes or backslashes
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\"'\\") {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// projectName returns the name of the directory of the compose files, the files and units are named after it
+func projectName(opt kobject.ConvertOptions) string {
+	dir, err := transformer.GetWorkingDir(opt)
+	if err != nil {
+		return "kompose"
+	}
+	name := strings.ToLower(filepath.Base(dir))
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+	if strings.Trim(name, "-.") == "" {
+		return "kompose"
+	}
+	return name
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podman
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newKomposeObject() kobject.KomposeObject {
+	return kobject.KomposeObject{
+		ServiceConfigs: map[string]kobject.ServiceConfig{
+			"web": {
+				Name:          "web",
+				ContainerName: "web",
+				Image:         "nginx:1.27",
+				Environment:   []kobject.EnvVar{{Name: "MODE", Value: "production"}},
+				Port:          []kobject.Ports{{HostPort: 8080, ContainerPort: 80, Protocol: string(api.ProtocolTCP)}},
+				ExposeService: "example.com",
+			},
+		},
+	}
+}
+
+func TestTransform(t *testing.T) {
+	p := Podman{}
+	objects, err := p.Transform(newKomposeObject(), kobject.ConvertOptions{CreateD: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var kinds []string
+	for _, obj := range objects {
+		kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+		if deployment, ok := obj.(*appsv1.Deployment); ok {
+			ports := deployment.Spec.Template.Spec.Containers[0].Ports
+			if len(ports) != 1 || ports[0].HostPort != 8080 || ports[0].ContainerPort != 80 {
+				t.Errorf("Expected the published port 8080:80 to be a host port, got %+v", ports)
+			}
+		}
+	}
+	if strings.Join(kinds, " ") != "Deployment" {
+		t.Errorf("Expected the Service and the Ingress to be dropped, got %v", kinds)
+	}
+}
+
+func TestQuadletFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Shop")
+	p := Podman{}
+	opt := kobject.ConvertOptions{CreateD: true, YAMLIndent: 2, InputFiles: []string{filepath.Join(dir, "compose.yaml")}}
+	objects, err := p.Transform(newKomposeObject(), opt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	files, err := p.quadletFiles(objects, opt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+	if strings.Join(names, " ") != "shop.network shop.yaml shop.kube" {
+		t.Fatalf("Expected the network, the kube YAML and the kube unit of the project, got %v", names)
+	}
+	if !strings.Contains(files[1].content, "kind: Deployment") || !strings.Contains(files[1].content, "hostPort: 8080") {
+		t.Errorf("Expected the kube YAML of the Deployment, got:\n%s", files[1].content)
+	}
+	expected := `[Unit]
+Description=The shop compose project converted by kompose
+
+[Kube]
+Yaml=shop.yaml
+Network=shop.network
+
+[Install]
+WantedBy=default.target
+`
+	if files[2].content != expected {
+		t.Errorf("Expected the kube unit:\n%s\ngot:\n%s", expected, files[2].content)
+	}
+
+	out := t.TempDir()
+	opt.OutFile = out
+	if err := p.PrintList(objects, opt); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+}
+
+func TestContainerUnits(t *testing.T) {
+	configMap := &api.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web-env"},
+		Data:       map[string]string{"LOG_LEVEL": "debug"},
+	}
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: appsv1.DeploymentSpec{
+			Template: api.PodTemplateSpec{
+				Spec: api.PodSpec{
+					Containers: []api.Container{{
+						Name:    "web",
+						Image:   "nginx:1.27",
+						Command: []string{"nginx"},
+						Args:    []string{"-g", "daemon off;"},
+						EnvFrom: []api.EnvFromSource{{ConfigMapRef: &api.ConfigMapEnvSource{LocalObjectReference: api.LocalObjectReference{Name: "web-env"}}}},
+						Env:     []api.EnvVar{{Name: "MODE", Value: "production"}},
+						Ports:   []api.ContainerPort{{ContainerPort: 80, HostPort: 8080}, {ContainerPort: 9000}},
+						VolumeMounts: []api.VolumeMount{
+							{Name: "data", MountPath: "/data"},
+							{Name: "conf", MountPath: "/etc/nginx/conf.d", ReadOnly: true},
+							{Name: "cache", MountPath: "/var/cache/nginx"},
+						},
+					}},
+					Volumes: []api.Volume{
+						{Name: "data", VolumeSource: api.VolumeSource{PersistentVolumeClaim: &api.PersistentVolumeClaimVolumeSource{ClaimName: "web-data"}}},
+						{Name: "conf", VolumeSource: api.VolumeSource{HostPath: &api.HostPathVolumeSource{Path: "/srv/nginx"}}},
+						{Name: "cache", VolumeSource: api.VolumeSource{EmptyDir: &api.EmptyDirVolumeSource{}}},
+					},
+				},
+			},
+		},
+	}
+
+	p := Podman{}
+	files, err := p.containerUnits([]runtime.Object{configMap, deployment}, "shop.network")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(files) != 1 || files[0].name != "web.container" {
+		t.Fatalf("Expected the unit web.container, got %+v", files)
+	}
+	expected := `[Unit]
+Description=The web container converted by kompose
+
+[Container]
+ContainerName=web
+Image=nginx:1.27
+Entrypoint=nginx
+Exec=-g "daemon off;"
+Environment=LOG_LEVEL=debug
+Environment=MODE=production
+PublishPort=8080:80
+Volume=web-data:/data
+Volume=/srv/nginx:/etc/nginx/conf.d:ro
+Volume=/var/cache/nginx
+Network=shop.network
+
+[Service]
+Restart=always
+
+[Install]
+WantedBy=default.target
+`
+	if files[0].content != expected {
+		t.Errorf("Expected the container unit:\n%s\ngot:\n%s", expected, files[0].content)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Provider creates the transformer of a provider for the options of a conversion
+type Provider func(opt kobject.ConvertOptions) Transformer
+
+// Printer is implemented by the transformers printing their objects themselves,
+// the objects of the other transformers are printed as Kubernetes manifests
+type Printer interface {
+	PrintList(objects []runtime.Object, opt kobject.ConvertOptions) error
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// Register makes a provider selectable with --provider, the names are case insensitive.
+// It is meant to be called from the init function of the provider's package, and panics
+// when the name is already registered.
+func Register(name string, provider Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	name = strings.ToLower(name)
+	if _, ok := providers[name]; ok {
+		panic(fmt.Sprintf("provider %q is registered twice", name))
+	}
+	providers[name] = provider
+}
+
+// Get returns the transformer of the provider for the options of a conversion
+func Get(name string, opt kobject.ConvertOptions) (Transformer, error) {
+	providersMu.RLock()
+	provider, ok := providers[strings.ToLower(name)]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%s is an unsupported provider. Supported providers are: '%s'.", name, strings.Join(Providers(), "', '"))
+	}
+	return provider(opt), nil
+}
+
+// Providers returns the sorted names of the registered providers
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
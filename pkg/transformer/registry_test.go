@@ -0,0 +1,67 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transformer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type fakeTransformer struct {
+	opt kobject.ConvertOptions
+}
+
+func (f *fakeTransformer) Transform(kobject.KomposeObject, kobject.ConvertOptions) ([]runtime.Object, error) {
+	return nil, nil
+}
+
+func TestRegister(t *testing.T) {
+	Register("Fake", func(opt kobject.ConvertOptions) Transformer {
+		return &fakeTransformer{opt: opt}
+	})
+	defer func() {
+		providersMu.Lock()
+		delete(providers, "fake")
+		providersMu.Unlock()
+	}()
+
+	opt := kobject.ConvertOptions{Replicas: 3}
+	got, err := Get("FAKE", opt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fake, ok := got.(*fakeTransformer); !ok || !reflect.DeepEqual(fake.opt, opt) {
+		t.Errorf("Expected the fake transformer created with the options, got %#v", got)
+	}
+
+	if names := Providers(); !reflect.DeepEqual(names, []string{"fake"}) {
+		t.Errorf("Expected the fake provider only, got %v", names)
+	}
+	if _, err := Get("nomad", opt); err == nil {
+		t.Errorf("Expected an error for an unregistered provider")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic when registering a provider twice")
+		}
+	}()
+	Register("fake", func(opt kobject.ConvertOptions) Transformer { return nil })
+}
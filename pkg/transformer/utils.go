@@ -17,6 +17,8 @@ limitations under the License.
 package transformer
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"os/exec"
@@ -28,6 +30,9 @@ import (
 	dockerlib "github.com/fsouza/go-dockerclient"
 	"github.com/kubernetes/kompose/pkg/kobject"
 	"github.com/kubernetes/kompose/pkg/utils/docker"
+	"github.com/kubernetes/kompose/pkg/utils/dropped"
+	"github.com/kubernetes/kompose/pkg/utils/redact"
+	"github.com/kubernetes/kompose/pkg/utils/remote"
 	"github.com/kubernetes/kompose/pkg/version"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -39,6 +44,24 @@ import (
 // Selector used as labels and selector
 const Selector = "io.kompose.service"
 
+// OriginalNameAnnotation records the compose name of a resource whose name was sanitized
+const OriginalNameAnnotation = "kompose.original-name"
+
+// MaxNameLength is the length of the DNS labels naming the Services, the ConfigMaps, the volumes and the containers
+const MaxNameLength = 63
+
+// TruncateName returns the name when it isn't longer than max. A longer name is truncated and suffixed
+// with the first 8 characters of the SHA-256 of the whole name, so that the names sharing their prefix
+// don't collide, and the same name is always truncated the same way.
+func TruncateName(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := "-" + hex.EncodeToString(sum[:])[0:8]
+	return strings.TrimRight(name[0:max-len(suffix)], "-.") + suffix
+}
+
 // Exists returns true if a file path exists.
 // Otherwise, returns false.
 func Exists(p string) bool {
@@ -100,7 +123,7 @@ func parseVolume(volume string) (name, host, container, mode string, err error)
 	// See https://github.com/kubernetes/kompose/issues/176
 	// Otherwise, check to see if "rw" or "ro" has been passed
 	if possibleAccessMode == "z" || possibleAccessMode == "Z" {
-		log.Warnf("Volume mount \"%s\" will be mounted without labeling support. :z or :Z not supported", volume)
+		dropped.Warnf("Volume mount \"%s\" will be mounted without labeling support. :z or :Z not supported", volume)
 		mode = ""
 		volumeStrings = volumeStrings[:len(volumeStrings)-1]
 	} else if possibleAccessMode == "rw" || possibleAccessMode == "ro" {
@@ -180,7 +203,7 @@ func parseWindowsVolume(volume string) (name, host, container, mode string, err
 	// See https://github.com/kubernetes/kompose/issues/176
 	// Otherwise, check to see if "rw" or "ro" has been passed
 	if mode == "z" || mode == "Z" {
-		log.Warnf("Volume mount \"%s\" will be mounted without labeling support. :z or :Z not supported", volume)
+		dropped.Warnf("Volume mount \"%s\" will be mounted without labeling support. :z or :Z not supported", volume)
 		mode = ""
 	}
 
@@ -253,7 +276,10 @@ func ConfigAnnotations(service kobject.ServiceConfig) map[string]string {
 		annotations[key] = value
 	}
 
-	annotations["kompose.cmd"] = strings.Join(os.Args, " ")
+	annotations["kompose.cmd"] = strings.Join(redact.Args(os.Args), " ")
+	if service.OriginalName != "" {
+		annotations[OriginalNameAnnotation] = service.OriginalName
+	}
 	versionCmd := exec.Command("kompose", "version")
 	out, err := versionCmd.Output()
 	if err != nil {
@@ -278,17 +304,22 @@ func ConfigAnnotations(service kobject.ServiceConfig) map[string]string {
 	return annotations
 }
 
-// Print either prints to stdout or to file/s
-func Print(name, path string, trailing string, data []byte, toStdout, generateJSON bool, f *os.File, provider string) (string, error) {
-	file := ""
-	// TODO: we should refactor / change this hack in the future once we have a better solution
-	re := regexp.MustCompile(`(?s)status:\n.*`)
-	data = re.ReplaceAll(data, nil)
+// FileName returns the name of the file of an object, NAME-TRAILING.yaml, or TRAILING.yaml without name
+func FileName(name, trailing string, generateJSON bool) string {
+	ext := "yaml"
 	if generateJSON {
-		file = fmt.Sprintf("%s-%s.json", name, trailing)
-	} else {
-		file = fmt.Sprintf("%s-%s.yaml", name, trailing)
+		ext = "json"
+	}
+	if name == "" {
+		return fmt.Sprintf("%s.%s", trailing, ext)
 	}
+	return fmt.Sprintf("%s-%s.%s", name, trailing, ext)
+}
+
+// Print either prints to stdout or to file/s
+func Print(name, path string, trailing string, data []byte, toStdout, generateJSON bool, f *os.File, provider string) (string, error) {
+	file := FileName(name, trailing, generateJSON)
+	data = StripStatus(data)
 	if toStdout {
 		fmt.Fprintf(os.Stdout, "%s\n", string(data))
 		return "", nil
@@ -309,6 +340,13 @@ func Print(name, path string, trailing string, data []byte, toStdout, generateJS
 	return file, nil
 }
 
+// StripStatus removes the status of a marshalled object, it must be the last field of the object
+func StripStatus(data []byte) []byte {
+	// TODO: we should refactor / change this hack in the future once we have a better solution
+	re := regexp.MustCompile(`(?s)status:\n.*`)
+	return re.ReplaceAll(data, nil)
+}
+
 // If Openshift, change to OpenShift!
 func formatProviderName(provider string) string {
 	if strings.EqualFold(provider, "openshift") {
@@ -354,6 +392,36 @@ func GetComposeFileDir(inputFiles []string) (string, error) {
 	return filepath.Dir(inputFile), nil
 }
 
+// InDir tells whether the path, relative to dir when it isn't absolute, is in dir, without climbing above it with ..
+func InDir(dir, path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// GetWorkingDir returns the directory the relative paths of the compose files, like the env_files or the
+// bind mounts, are resolved against: the --context directory when given, else the current directory for the
+// compose files read from stdin or fetched from URLs, else the directory of the first compose file, which
+// is in the clone of the repository for git.
+func GetWorkingDir(opt kobject.ConvertOptions) (string, error) {
+	if opt.Context != "" {
+		return filepath.Abs(opt.Context)
+	}
+	if len(opt.InputFiles) > 0 && (opt.InputFiles[0] == "-" || remote.IsURL(opt.InputFiles[0])) {
+		return os.Getwd()
+	}
+	if len(opt.InputFiles) > 0 && remote.IsGit(opt.InputFiles[0]) {
+		local, err := remote.Fetch(opt.InputFiles[0])
+		if err != nil {
+			return "", err
+		}
+		return filepath.Dir(local), nil
+	}
+	return GetComposeFileDir(opt.InputFiles)
+}
+
 // BuildDockerImage builds docker image
 func BuildDockerImage(service kobject.ServiceConfig, name string) error {
 	wd, err := os.Getwd()
@@ -409,14 +477,18 @@ func BuildDockerImage(service kobject.ServiceConfig, name string) error {
 	return nil
 }
 
-// PushDockerImageWithOpt pushes docker image
-func PushDockerImageWithOpt(service kobject.ServiceConfig, serviceName string, opt kobject.ConvertOptions) error {
+// PushDockerImageWithOpt pushes docker image, it returns the reference of the pushed image the manifests
+// use, tagged into the registry given with --push-image-registry and by digest with --push-image-digest
+func PushDockerImageWithOpt(service kobject.ServiceConfig, serviceName string, opt kobject.ConvertOptions) (string, error) {
 	if !opt.PushImage {
 		// Don't do anything if registry is specified but push is disabled, just WARN about it
 		if opt.PushImageRegistry != "" {
 			log.Warnf("Push image registry '%s' is specified but push image is disabled, skipping pushing to repository", opt.PushImageRegistry)
 		}
-		return nil
+		if opt.PushImageDigest {
+			log.Warnf("Push image digest is specified but push image is disabled, keeping the image '%s'", service.Image)
+		}
+		return service.Image, nil
 	}
 
 	log.Infof("Push image is enabled. Attempting to push image '%s'", service.Image)
@@ -425,17 +497,17 @@ func PushDockerImageWithOpt(service kobject.ServiceConfig, serviceName string, o
 	// else, let's push the image
 	if service.Image == "" {
 		log.Warnf("No image name has been passed for service %s, skipping pushing to repository", serviceName)
-		return nil
+		return service.Image, nil
 	}
 
 	image, err := docker.ParseImage(service.Image, opt.PushImageRegistry)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	client, err := docker.Client()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if opt.PushImageRegistry != "" {
@@ -444,17 +516,23 @@ func PushDockerImageWithOpt(service kobject.ServiceConfig, serviceName string, o
 		err = tag.TagImage(image)
 
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
 
 	push := docker.Push{Client: *client}
 	err = push.PushImage(image)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	if opt.PushImageDigest {
+		return push.RepoDigest(image)
+	}
+	if opt.PushImageRegistry != "" {
+		return image.Remote, nil
+	}
+	return service.Image, nil
 }
 
 // CreateNamespace creates a Kubernetes namespace, which can be used in both:
@@ -471,7 +549,22 @@ func CreateNamespace(namespace string) *api.Namespace {
 	}
 }
 
-// AssignNamespaceToObjects will add the namespace metadata to each object
+// clusterScopedKinds are the kinds of the generated objects which don't belong to a namespace
+var clusterScopedKinds = map[string]bool{
+	"Namespace":        true,
+	"PersistentVolume": true,
+	"StorageClass":     true,
+	"PriorityClass":    true,
+	"RuntimeClass":     true,
+	"GatewayClass":     true,
+}
+
+// IsClusterScoped tells whether the objects of the kind don't belong to a namespace
+func IsClusterScoped(kind string) bool {
+	return clusterScopedKinds[kind]
+}
+
+// AssignNamespaceToObjects will add the namespace metadata to each namespaced object
 func AssignNamespaceToObjects(objs *[]runtime.Object, namespace string) {
 	ns := "default"
 	if namespace != "" {
@@ -479,7 +572,7 @@ func AssignNamespaceToObjects(objs *[]runtime.Object, namespace string) {
 	}
 	var result []runtime.Object
 	for _, obj := range *objs {
-		if us, ok := obj.(metav1.Object); ok {
+		if us, ok := obj.(metav1.Object); ok && !clusterScopedKinds[obj.GetObjectKind().GroupVersionKind().Kind] {
 			us.SetNamespace(ns)
 		}
 		result = append(result, obj)
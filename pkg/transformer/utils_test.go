@@ -18,8 +18,12 @@ package transformer
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/kubernetes/kompose/pkg/kobject"
 )
 
 func TestFormatProviderName(t *testing.T) {
@@ -348,3 +352,64 @@ func TestGetComposeFileDir(t *testing.T) {
 		t.Errorf("Expected $PWD/foobar, got %v", output)
 	}
 }
+
+func TestGetWorkingDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		opt      kobject.ConvertOptions
+		expected string
+	}{
+		{kobject.ConvertOptions{InputFiles: []string{"foobar/compose.yaml"}}, filepath.Join(wd, "foobar")},
+		{kobject.ConvertOptions{InputFiles: []string{"-"}}, wd},
+		{kobject.ConvertOptions{InputFiles: []string{"https://example.com/compose.yaml"}}, wd},
+		{kobject.ConvertOptions{InputFiles: []string{"-"}, Context: "project"}, filepath.Join(wd, "project")},
+	}
+	for _, tc := range testCases {
+		dir, err := GetWorkingDir(tc.opt)
+		if err != nil {
+			t.Errorf("Unexpected error for %v: %v", tc.opt.InputFiles, err)
+		}
+		if dir != tc.expected {
+			t.Errorf("Expected %s for %v, got %s", tc.expected, tc.opt.InputFiles, dir)
+		}
+	}
+}
+
+func TestConfigAnnotationsOriginalName(t *testing.T) {
+	service := kobject.ServiceConfig{Name: "web_app", OriginalName: "web_app", WithKomposeAnnotation: true}
+	if got := ConfigAnnotations(service)[OriginalNameAnnotation]; got != "web_app" {
+		t.Errorf("Got %q, expected %q", got, "web_app")
+	}
+
+	service.WithKomposeAnnotation = false
+	if _, ok := ConfigAnnotations(service)[OriginalNameAnnotation]; ok {
+		t.Errorf("Expected %s annotation to be removed", OriginalNameAnnotation)
+	}
+}
+
+func TestTruncateName(t *testing.T) {
+	if got := TruncateName("web", MaxNameLength); got != "web" {
+		t.Errorf("Expected a short name to be kept, got %q", got)
+	}
+
+	prefix := strings.Repeat("a", 60)
+	one := TruncateName(prefix+"-one", MaxNameLength)
+	two := TruncateName(prefix+"-two", MaxNameLength)
+	if len(one) != MaxNameLength || !strings.HasPrefix(one, strings.Repeat("a", 54)+"-") {
+		t.Errorf("Expected the name truncated to %d characters with a hash suffix, got %q", MaxNameLength, one)
+	}
+	if one == two {
+		t.Errorf("Expected the names sharing their prefix not to collide, got %q", one)
+	}
+	if again := TruncateName(prefix+"-one", MaxNameLength); again != one {
+		t.Errorf("Expected the truncation to be deterministic, got %q and %q", one, again)
+	}
+
+	// the truncated prefix doesn't end with a dash before the hash
+	if got := TruncateName(strings.Repeat("a", 53)+"--tail-of-a-long-name", MaxNameLength); strings.Contains(got, "--") {
+		t.Errorf("Expected the dashes before the hash to be trimmed, got %q", got)
+	}
+}
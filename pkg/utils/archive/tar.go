@@ -18,6 +18,7 @@ package archive
 
 import (
 	"archive/tar"
+	"compress/gzip"
 	"io"
 	"os"
 	"path/filepath"
@@ -88,3 +89,51 @@ func CreateTarball(source, target string) error {
 			return err
 		})
 }
+
+// CreateGzipTarball writes the files to a gzipped tarball at target, named relatively to dir
+func CreateGzipTarball(dir string, files []string, target string) error {
+	tarfile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer tarfile.Close()
+
+	gz := gzip.NewWriter(tarfile)
+	tarball := tar.NewWriter(gz)
+	for _, path := range files {
+		if err := addFile(tarball, dir, path); err != nil {
+			return err
+		}
+	}
+	if err := tarball.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// addFile writes the file to the tarball, named relatively to dir
+func addFile(tarball *tar.Writer, dir, path string) error {
+	name, err := filepath.Rel(dir, path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(name)
+	if err := tarball.WriteHeader(header); err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(tarball, file)
+	return err
+}
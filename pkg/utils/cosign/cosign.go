@@ -0,0 +1,60 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cosign signs the generated files with cosign, keyless or with a key file.
+package cosign
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Args returns the arguments of cosign sign-blob signing the file, the signature is written to FILE.sig
+// and the bundle with the transparency log entry to FILE.bundle. Without key, the file is signed keyless
+// with the OIDC identity of the user and the certificate is written to FILE.pem.
+func Args(file, key string) []string {
+	args := []string{"sign-blob", "--yes", "--output-signature", file + ".sig", "--bundle", file + ".bundle"}
+	if key != "" {
+		args = append(args, "--key", key)
+	} else {
+		args = append(args, "--output-certificate", file+".pem")
+	}
+	return append(args, file)
+}
+
+// SignBlob signs the file with cosign and returns the files written next to it
+func SignBlob(file, key string) ([]string, error) {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return nil, errors.New("cosign is not installed! Please install cosign to sign the generated files")
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("cosign", Args(file, key)...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Errorf("unable to sign %s: %s", file, strings.TrimSpace(stderr.String()))
+	}
+	log.Debugf("cosign sign-blob output:\n%s", stderr.String())
+
+	signed := []string{file + ".sig", file + ".bundle"}
+	if key == "" {
+		signed = append(signed, file+".pem")
+	}
+	return signed, nil
+}
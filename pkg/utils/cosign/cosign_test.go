@@ -0,0 +1,40 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cosign
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	testCases := map[string]struct {
+		key      string
+		expected string
+	}{
+		"keyless":  {"", "sign-blob --yes --output-signature out/shop-0.1.0.tgz.sig --bundle out/shop-0.1.0.tgz.bundle --output-certificate out/shop-0.1.0.tgz.pem out/shop-0.1.0.tgz"},
+		"key file": {"cosign.key", "sign-blob --yes --output-signature out/shop-0.1.0.tgz.sig --bundle out/shop-0.1.0.tgz.bundle --key cosign.key out/shop-0.1.0.tgz"},
+	}
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := strings.Join(Args("out/shop-0.1.0.tgz", test.key), " ")
+			if got != test.expected {
+				t.Errorf("Expected the arguments %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
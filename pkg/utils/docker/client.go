@@ -18,6 +18,7 @@ package docker
 
 import (
 	"os"
+	"path/filepath"
 
 	docker "github.com/fsouza/go-dockerclient"
 )
@@ -36,8 +37,13 @@ func Client() (*docker.Client, error) {
 		// DOCKER_HOST, DOCKER_TLS_VERIFY, DOCKER_CERT_PATH
 		client, err = docker.NewClientFromEnv()
 	} else {
-		// Default unix socket end-point
-		endpoint := "unix:///var/run/docker.sock"
+		// Default unix socket end-point, the Docker compatible API of Podman when Docker isn't running
+		endpoint := "unix://" + dockerSocket
+		if _, err := os.Stat(dockerSocket); err != nil {
+			if socket := podmanSocket(); socket != "" {
+				endpoint = "unix://" + socket
+			}
+		}
 		client, err = docker.NewClient(endpoint)
 	}
 	if err != nil {
@@ -46,3 +52,19 @@ func Client() (*docker.Client, error) {
 
 	return client, nil
 }
+
+const dockerSocket = "/var/run/docker.sock"
+
+// podmanSocket returns the socket of the rootless Podman service, else of the rootful one, if it exists
+func podmanSocket() string {
+	sockets := []string{"/run/podman/podman.sock"}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		sockets = append([]string{filepath.Join(runtimeDir, "podman", "podman.sock")}, sockets...)
+	}
+	for _, socket := range sockets {
+		if _, err := os.Stat(socket); err == nil {
+			return socket
+		}
+	}
+	return ""
+}
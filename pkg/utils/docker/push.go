@@ -18,6 +18,7 @@ package docker
 
 import (
 	"bytes"
+	"strings"
 
 	dockerlib "github.com/fsouza/go-dockerclient"
 	"github.com/pkg/errors"
@@ -79,6 +80,26 @@ func (c *Push) PushImage(image Image) error {
 	return nil
 }
 
+// RepoDigest returns the reference by digest of a pushed image, as repository@sha256:...
+func (c *Push) RepoDigest(image Image) (string, error) {
+	inspected, err := c.Client.InspectImage(image.Remote)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to inspect image '%s'", image.Remote)
+	}
+	// the repositories of Docker Hub are recorded without the registry
+	repository := strings.TrimPrefix(strings.TrimPrefix(image.Repository, "docker.io/"), "library/")
+	for _, repoDigest := range inspected.RepoDigests {
+		name, digest, found := strings.Cut(repoDigest, "@")
+		if !found {
+			continue
+		}
+		if name == image.Repository || strings.TrimPrefix(strings.TrimPrefix(name, "docker.io/"), "library/") == repository {
+			return image.Repository + "@" + digest, nil
+		}
+	}
+	return "", errors.Errorf("no digest of image '%s' pushed to repository '%s'", image.Name, image.Repository)
+}
+
 // handleDockerRegistry adapt legacy docker registry address
 // After docker login to docker.io, there must be https://index.docker.io/v1/ in config.json of authentication
 // Reference: https://docs.docker.com/engine/api/v1.23/
@@ -0,0 +1,76 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dropped reports the compose settings dropped or approximated by the conversion. They are
+// logged as warnings, and recorded for --strict and the conversion summary.
+package dropped
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/kubernetes/kompose/pkg/utils/redact"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	mu        sync.Mutex
+	recorders []*Recorder
+)
+
+// Recorder records the settings dropped while it is started
+type Recorder struct {
+	mu       sync.Mutex
+	settings []string
+}
+
+// Start returns a recorder of the settings dropped until it is stopped
+func Start() *Recorder {
+	r := &Recorder{}
+	mu.Lock()
+	defer mu.Unlock()
+	recorders = append(recorders, r)
+	return r
+}
+
+// Stop stops recording the dropped settings
+func (r *Recorder) Stop() {
+	mu.Lock()
+	defer mu.Unlock()
+	recorders = slices.DeleteFunc(recorders, func(recorder *Recorder) bool { return recorder == r })
+}
+
+// Settings returns the messages of the settings dropped so far
+func (r *Recorder) Settings() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.settings...)
+}
+
+// Warnf logs the warning about a dropped setting and records it in the started recorders, masked as in the logs
+func Warnf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	log.Warn(message)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, r := range recorders {
+		r.mu.Lock()
+		r.settings = append(r.settings, redact.String(message))
+		r.mu.Unlock()
+	}
+}
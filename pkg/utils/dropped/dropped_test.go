@@ -0,0 +1,43 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dropped
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/utils/redact"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestRecorder(t *testing.T) {
+	release := redact.Register("hunter22")
+	defer release()
+
+	Warnf("Ignoring before the recorder")
+	r := Start()
+	log.Warn("Ignoring a plain warning")
+	Warnf("Ignoring sysctl %q of service %q", "kernel.shmmax", "web")
+	Warnf("Ignoring the password %s", "hunter22")
+	r.Stop()
+	Warnf("Ignoring after the recorder")
+
+	expected := []string{`Ignoring sysctl "kernel.shmmax" of service "web"`, "Ignoring the password ******"}
+	if got := r.Settings(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the dropped settings %v, got %v", expected, got)
+	}
+}
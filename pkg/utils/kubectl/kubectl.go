@@ -0,0 +1,105 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubectl runs kubectl against the cluster of its current context.
+package kubectl
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// List returns the objects as a JSON v1 List, kubectl reads it from its standard input with -f -
+func List(objects []runtime.Object) ([]byte, error) {
+	items := make([]json.RawMessage, 0, len(objects))
+	for _, obj := range objects {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to marshal the objects for kubectl")
+		}
+		items = append(items, data)
+	}
+	return json.Marshal(map[string]interface{}{"apiVersion": "v1", "kind": "List", "items": items})
+}
+
+// Command returns the kubectl command with the arguments, it fails when kubectl isn't installed
+func Command(args ...string) (*exec.Cmd, error) {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return nil, errors.New("kubectl is not installed! Please install kubectl to access the cluster")
+	}
+	return exec.Command("kubectl", args...), nil
+}
+
+// Errors returns the error lines of the standard error of kubectl, which goes on after the failed
+// objects, or the whole standard error when there are none
+func Errors(stderr string) []string {
+	var messages []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if strings.HasPrefix(line, "Error") {
+			messages = append(messages, strings.TrimSpace(line))
+		}
+	}
+	if len(messages) == 0 {
+		messages = []string{strings.TrimSpace(stderr)}
+	}
+	return messages
+}
+
+// Run runs kubectl with the arguments and the objects as input, it returns its standard output
+func Run(objects []runtime.Object, args ...string) ([]byte, error) {
+	list, err := List(objects)
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := Command(args...)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(list)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.Bytes(), errors.New(strings.Join(Errors(stderr.String()), "\n  "))
+	}
+	return stdout.Bytes(), nil
+}
+
+// APIVersions returns the group/versions served by the API server, as apps/v1, with kubectl api-versions
+func APIVersions() (map[string]bool, error) {
+	cmd, err := Command("api-versions")
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(strings.Join(Errors(stderr.String()), "\n  "))
+	}
+	versions := map[string]bool{}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			versions[line] = true
+		}
+	}
+	return versions, nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact masks the secrets and the values of the env files in the logs and the reports of the
+// conversion.
+package redact
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Mask replaces the redacted values
+const Mask = "******"
+
+// MinLength is the length under which the registered values aren't masked in the messages, the short
+// values, like the ports and the booleans, would mask the rest of the messages
+const MinLength = 4
+
+// valueFlags are the flags of the command line given a KEY=VALUE whose value is redacted
+var valueFlags = map[string]bool{"--env": true, "--build-arg": true}
+
+var (
+	// Show keeps the values in the logs and the reports, set with --show-secrets for the local debugging
+	Show bool
+
+	mu sync.RWMutex
+	// registered counts the conversions which registered each value
+	registered = map[string]int{}
+	// values are the registered values, the longest first
+	values []string
+)
+
+// Register adds the values masked in the log messages during a conversion, the returned function
+// forgets them at its end. The values registered by several conversions are kept until the last
+// one ends.
+func Register(vals ...string) (release func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	var added []string
+	for _, value := range vals {
+		if len(value) < MinLength || contains(added, value) {
+			continue
+		}
+		registered[value]++
+		added = append(added, value)
+	}
+	sortValues()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, value := range added {
+				if registered[value]--; registered[value] <= 0 {
+					delete(registered, value)
+				}
+			}
+			sortValues()
+		})
+	}
+}
+
+// Reset forgets the registered values
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	registered = map[string]int{}
+	values = nil
+}
+
+// sortValues lists the registered values, the longest first, mu is held
+func sortValues() {
+	values = values[:0]
+	for value := range registered {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+}
+
+// String masks the registered values in s, unless Show is set
+func String(s string) string {
+	if Show {
+		return s
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, value := range values {
+		s = strings.ReplaceAll(s, value, Mask)
+	}
+	return s
+}
+
+// Value returns the mask in place of the value, unless Show is set or the value is empty
+func Value(value string) string {
+	if Show || value == "" {
+		return value
+	}
+	return Mask
+}
+
+// Args masks the values of the KEY=VALUE given to --env and --build-arg in the arguments of a command
+// line, as --env KEY=VALUE or --env=KEY=VALUE. The command lines are kept in the generated files, the
+// values are masked even with Show.
+func Args(args []string) []string {
+	masked := make([]string, len(args))
+	for i, arg := range args {
+		masked[i] = arg
+		if i > 0 && valueFlags[args[i-1]] {
+			masked[i] = maskPair(arg)
+			continue
+		}
+		if flag, pair, ok := strings.Cut(arg, "="); ok && valueFlags[flag] {
+			masked[i] = flag + "=" + maskPair(pair)
+		}
+	}
+	return masked
+}
+
+// maskPair masks the value of KEY=VALUE
+func maskPair(pair string) string {
+	key, _, ok := strings.Cut(pair, "=")
+	if !ok {
+		return pair
+	}
+	return key + "=" + Mask
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Hook is a logrus hook masking the registered values in the messages and the string fields of the
+// entries, before they are written or collected by the other hooks
+type Hook struct{}
+
+func (Hook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (Hook) Fire(entry *log.Entry) error {
+	entry.Message = String(entry.Message)
+	for key, value := range entry.Data {
+		if s, ok := value.(string); ok {
+			entry.Data[key] = String(s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestArgs(t *testing.T) {
+	testCases := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"separate value": {[]string{"kompose", "convert", "--env", "TOKEN=s3cr3t"}, "kompose convert --env TOKEN=******"},
+		"joined value":   {[]string{"kompose", "convert", "--env=TOKEN=s3cr3t"}, "kompose convert --env=TOKEN=******"},
+		"build argument": {[]string{"docker", "build", "--build-arg", "NPM_TOKEN=abcd", "."}, "docker build --build-arg NPM_TOKEN=****** ."},
+		"other flags":    {[]string{"kompose", "convert", "--env-file", "prod.env", "--out", "k8s"}, "kompose convert --env-file prod.env --out k8s"},
+	}
+	for name, test := range testCases {
+		t.Run(name, func(t *testing.T) {
+			got := strings.Join(Args(test.args), " ")
+			if got != test.expected {
+				t.Errorf("Expected the arguments %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	release := Register("s3cr3t", "s3cr3t-longer", "on", "s3cr3t")
+	defer release()
+
+	got := String("connecting with s3cr3t-longer, then s3cr3t, on port 80")
+	if expected := "connecting with ******, then ******, on port 80"; got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	Show = true
+	defer func() { Show = false }()
+	if got := String("s3cr3t"); got != "s3cr3t" {
+		t.Errorf("Expected the value to be shown, got %q", got)
+	}
+	if got := Value("s3cr3t"); got != "s3cr3t" {
+		t.Errorf("Expected the value to be shown, got %q", got)
+	}
+}
+
+func TestRegisterRelease(t *testing.T) {
+	first := Register("hunter22", "s3cr3t")
+	second := Register("hunter22")
+
+	first()
+	first()
+	if got := String("hunter22 s3cr3t"); got != "****** s3cr3t" {
+		t.Errorf("Expected the value of the running conversion to stay masked, got %q", got)
+	}
+	second()
+	if got := String("hunter22 s3cr3t"); got != "hunter22 s3cr3t" {
+		t.Errorf("Expected the values to be forgotten, got %q", got)
+	}
+}
+
+func TestHook(t *testing.T) {
+	release := Register("hunter22")
+	defer release()
+
+	var out bytes.Buffer
+	logger := log.New()
+	logger.SetOutput(&out)
+	logger.AddHook(Hook{})
+	logger.WithField("password", "hunter22").Warnf("Ignoring the value hunter22 of PASSWORD")
+
+	if strings.Contains(out.String(), "hunter22") {
+		t.Errorf("Expected the value to be masked, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "Ignoring the value ****** of PASSWORD") {
+		t.Errorf("Expected the masked message, got %q", out.String())
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry inspects the images in their registry with skopeo, without pulling them.
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// cacheTTL is how long the configuration of an image referenced by a tag is cached,
+// the images referenced by a digest can't change and are cached for good
+const cacheTTL = 24 * time.Hour
+
+// Config is the part of the configuration of an image used by the conversion
+type Config struct {
+	// ExposedPorts are the ports of the EXPOSE instructions, as PORT/PROTOCOL
+	ExposedPorts []string `json:"exposedPorts,omitempty"`
+	// User is the user of the USER instruction, as USER[:GROUP]
+	User string `json:"user,omitempty"`
+	// Entrypoint and Cmd are the command the containers run
+	Entrypoint []string `json:"entrypoint,omitempty"`
+	Cmd        []string `json:"cmd,omitempty"`
+}
+
+// cacheEntry is the cached configuration of an image
+type cacheEntry struct {
+	Config    Config    `json:"config"`
+	Inspected time.Time `json:"inspected"`
+}
+
+// cacheMu serializes the reads and the writes of the cache file
+var cacheMu sync.Mutex
+
+// skopeo runs skopeo with the arguments and returns its standard output
+func skopeo(args ...string) ([]byte, error) {
+	if _, err := exec.LookPath("skopeo"); err != nil {
+		return nil, errors.New("skopeo is not installed! Please install skopeo to inspect the images in their registry")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("skopeo", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.New(strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// Inspect returns the configuration of the image, read from its registry with the credentials of
+// docker login or skopeo login. The configurations are cached in the cache directory of the user.
+func Inspect(image string) (Config, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	cache := loadCache()
+	if entry, ok := cache[image]; ok && (strings.Contains(image, "@") || time.Since(entry.Inspected) < cacheTTL) {
+		log.Debugf("Configuration of the image %s read from the cache", image)
+		return entry.Config, nil
+	}
+
+	output, err := skopeo("inspect", "--config", "docker://"+image)
+	if err != nil {
+		return Config{}, errors.Errorf("unable to inspect the image %s: %s", image, err)
+	}
+	var inspected struct {
+		Config struct {
+			ExposedPorts map[string]struct{}
+			User         string
+			Entrypoint   []string
+			Cmd          []string
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(output, &inspected); err != nil {
+		return Config{}, errors.Wrapf(err, "unable to read the configuration of the image %s", image)
+	}
+	config := Config{
+		User:       inspected.Config.User,
+		Entrypoint: inspected.Config.Entrypoint,
+		Cmd:        inspected.Config.Cmd,
+	}
+	for port := range inspected.Config.ExposedPorts {
+		config.ExposedPorts = append(config.ExposedPorts, port)
+	}
+	sort.Strings(config.ExposedPorts)
+
+	cache[image] = cacheEntry{Config: config, Inspected: time.Now()}
+	saveCache(cache)
+	return config, nil
+}
+
+// Digest returns the digest of the manifest the image references in its registry, as sha256:...
+// The digests of the tags change when the images are pushed again, they aren't cached.
+func Digest(image string) (string, error) {
+	output, err := skopeo("inspect", "--format", "{{.Digest}}", "docker://"+image)
+	if err != nil {
+		return "", errors.Errorf("unable to resolve the digest of the image %s: %s", image, err)
+	}
+	digest := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", errors.Errorf("unable to resolve the digest of the image %s: unexpected digest %q", image, digest)
+	}
+	return digest, nil
+}
+
+// cachePath returns the path of the file caching the configurations of the images
+func cachePath() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "kompose", "images.json")
+}
+
+// loadCache reads the cached configurations, a missing or unreadable cache is empty
+func loadCache() map[string]cacheEntry {
+	cache := map[string]cacheEntry{}
+	data, err := os.ReadFile(cachePath())
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		log.Debugf("Ignoring the unreadable image cache %s: %s", cachePath(), err)
+		return map[string]cacheEntry{}
+	}
+	return cache
+}
+
+// saveCache writes the cached configurations, the images are inspected again when it fails
+func saveCache(cache map[string]cacheEntry) {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(cachePath()), 0755); err == nil {
+			err = os.WriteFile(cachePath(), data, 0644)
+		}
+	}
+	if err != nil {
+		log.Debugf("Unable to write the image cache %s: %s", cachePath(), err)
+	}
+}
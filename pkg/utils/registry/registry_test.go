@@ -0,0 +1,108 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSkopeo installs a skopeo script in the PATH and an empty cache directory, the arguments
+// of each call are appended to the returned file
+func fakeSkopeo(t *testing.T, script string) string {
+	dir := t.TempDir()
+	content := "#!/bin/sh\necho \"$@\" >> " + filepath.Join(dir, "args") + "\n" + script
+	if err := os.WriteFile(filepath.Join(dir, "skopeo"), []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	return filepath.Join(dir, "args")
+}
+
+func TestInspect(t *testing.T) {
+	args := fakeSkopeo(t, `echo '{"architecture":"amd64","config":{"User":"101:101","ExposedPorts":{"8080/tcp":{},"53/udp":{}},"Entrypoint":["/docker-entrypoint.sh"],"Cmd":["nginx"]}}'
+`)
+
+	expected := Config{
+		ExposedPorts: []string{"53/udp", "8080/tcp"},
+		User:         "101:101",
+		Entrypoint:   []string{"/docker-entrypoint.sh"},
+		Cmd:          []string{"nginx"},
+	}
+	for i := 0; i < 2; i++ {
+		config, err := Inspect("nginx:1.27")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, config)
+		}
+	}
+
+	calls, err := os.ReadFile(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(calls)) != "inspect --config docker://nginx:1.27" {
+		t.Errorf("Expected a single inspection of the configuration, the second one being cached, got %q", calls)
+	}
+}
+
+func TestInspectError(t *testing.T) {
+	fakeSkopeo(t, `echo "manifest unknown" >&2
+exit 1
+`)
+
+	_, err := Inspect("example.com/missing:latest")
+	if err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+		t.Errorf("Expected the error of skopeo, got %v", err)
+	}
+}
+
+func TestInspectWithoutSkopeo(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := Inspect("nginx")
+	if err == nil || !strings.Contains(err.Error(), "skopeo is not installed") {
+		t.Errorf("Expected skopeo to be missing, got %v", err)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	args := fakeSkopeo(t, `echo "sha256:0123456789abcdef"
+`)
+
+	digest, err := Digest("nginx:1.27")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if digest != "sha256:0123456789abcdef" {
+		t.Errorf("Expected the digest of the manifest, got %q", digest)
+	}
+
+	calls, err := os.ReadFile(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(calls)) != "inspect --format {{.Digest}} docker://nginx:1.27" {
+		t.Errorf("Expected an inspection of the manifest, got %q", calls)
+	}
+}
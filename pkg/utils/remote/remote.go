@@ -0,0 +1,218 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remote fetches the compose files given as http(s) URLs or in git repositories.
+package remote
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// gitPrefix prefixes the compose files of git repositories, as git::REPO[//PATH][?ref=REF]
+const gitPrefix = "git::"
+
+// MaxFileSize is the largest compose file fetched from a URL
+const MaxFileSize = 10 << 20
+
+// defaultComposeFiles are looked up in the repository when the path of the compose file isn't given
+var defaultComposeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+var (
+	fetchedMu sync.Mutex
+	// fetched are the local copies of the files fetched by the process
+	fetched = map[string]string{}
+	// httpClient fetches the compose files given as URLs
+	httpClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// IsRemote tells whether the compose file is fetched, from an http(s) URL or a git repository
+func IsRemote(file string) bool {
+	return IsURL(file) || IsGit(file)
+}
+
+// IsURL tells whether the compose file is an http(s) URL
+func IsURL(file string) bool {
+	return strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://")
+}
+
+// IsGit tells whether the compose file is in a git repository, as git::REPO[//PATH][?ref=REF]
+func IsGit(file string) bool {
+	return strings.HasPrefix(file, gitPrefix)
+}
+
+// BaseName returns the name of the remote compose file, without its directory
+func BaseName(file string) string {
+	if IsGit(file) {
+		_, filePath, _ := parseGit(file)
+		if filePath != "" {
+			return path.Base(filePath)
+		}
+		return defaultComposeFiles[0]
+	}
+	if u, err := url.Parse(file); err == nil {
+		if name := path.Base(u.Path); name != "/" && name != "." && name != ".." {
+			return name
+		}
+	}
+	return defaultComposeFiles[0]
+}
+
+// Fetch returns the local copy of the compose file, fetched once per process into its own temporary
+// directory. The local files are returned unchanged.
+func Fetch(file string) (string, error) {
+	if !IsRemote(file) {
+		return file, nil
+	}
+	fetchedMu.Lock()
+	defer fetchedMu.Unlock()
+	if local, ok := fetched[file]; ok {
+		return local, nil
+	}
+
+	dir, err := os.MkdirTemp("", "kompose-remote-")
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create the directory of the fetched files")
+	}
+	var local string
+	if IsGit(file) {
+		local, err = fetchGit(file, dir)
+	} else {
+		local, err = fetchURL(file, dir)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	log.Debugf("Compose file %s fetched to %s", file, local)
+	fetched[file] = local
+	return local, nil
+}
+
+// fetchURL downloads the compose file of the URL into dir
+func fetchURL(file string, dir string) (string, error) {
+	resp, err := httpClient.Get(file)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to fetch %s", file)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unable to fetch %s: %s", file, resp.Status)
+	}
+
+	local := filepath.Join(dir, BaseName(file))
+	out, err := os.Create(local)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+	// one more byte than the limit is read to tell the files larger than it
+	n, err := io.Copy(out, io.LimitReader(resp.Body, MaxFileSize+1))
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to fetch %s", file)
+	}
+	if n > MaxFileSize {
+		return "", errors.Errorf("unable to fetch %s: the file is larger than %d bytes", file, MaxFileSize)
+	}
+	return local, nil
+}
+
+// parseGit splits git::REPO[//PATH][?ref=REF] into the repository, the path of the compose file and the ref
+func parseGit(file string) (repo string, filePath string, ref string) {
+	repo = strings.TrimPrefix(file, gitPrefix)
+	if i := strings.LastIndex(repo, "?ref="); i >= 0 {
+		repo, ref = repo[:i], repo[i+len("?ref="):]
+	}
+	// the // of the scheme doesn't separate the path
+	start := 0
+	if i := strings.Index(repo, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	if i := strings.Index(repo[start:], "//"); i >= 0 {
+		repo, filePath = repo[:start+i], repo[start+i+len("//"):]
+	}
+	return repo, filePath, ref
+}
+
+// fetchGit clones the repository of the compose file into dir, the repository is cloned with its
+// history truncated to the ref
+func fetchGit(file string, dir string) (string, error) {
+	repo, filePath, ref := parseGit(file)
+	// the repository and the ref would be read as options of git
+	if repo == "" || strings.HasPrefix(repo, "-") {
+		return "", errors.Errorf("invalid git repository %q", repo)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return "", errors.Errorf("invalid git ref %q", ref)
+	}
+	local := dir
+	if filePath != "" {
+		local = filepath.Join(dir, filepath.FromSlash(filePath))
+		if !within(dir, local) {
+			return "", errors.Errorf("the compose file %s is outside of the repository %s", filePath, repo)
+		}
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", errors.New("Git is not installed! Please install Git to fetch the compose files of git repositories")
+	}
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", repo, dir)
+	if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return "", errors.Errorf("unable to clone %s: %s", repo, strings.TrimSpace(string(output)))
+	}
+
+	if filePath != "" {
+		if _, err := os.Stat(local); err != nil {
+			return "", errors.Errorf("no compose file %s in %s", filePath, repo)
+		}
+		// the links of the repository may point out of it
+		resolvedDir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return "", err
+		}
+		if resolved, err := filepath.EvalSymlinks(local); err != nil || !within(resolvedDir, resolved) {
+			return "", errors.Errorf("the compose file %s is outside of the repository %s", filePath, repo)
+		}
+		return local, nil
+	}
+	for _, name := range defaultComposeFiles {
+		local := filepath.Join(dir, name)
+		if _, err := os.Stat(local); err == nil {
+			return local, nil
+		}
+	}
+	return "", errors.Errorf("no compose file in %s", repo)
+}
+
+// within tells whether the path is in the directory, once cleaned
+func within(dir string, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes/kompose/pkg/testutils"
+)
+
+func TestParseGit(t *testing.T) {
+	testCases := []struct {
+		file, repo, path, ref string
+	}{
+		{"git::https://github.com/org/repo.git//deploy/compose.yaml?ref=v1", "https://github.com/org/repo.git", "deploy/compose.yaml", "v1"},
+		{"git::https://github.com/org/repo.git", "https://github.com/org/repo.git", "", ""},
+		{"git::git@github.com:org/repo.git//compose.yaml", "git@github.com:org/repo.git", "compose.yaml", ""},
+		{"git::/srv/repo?ref=main", "/srv/repo", "", "main"},
+	}
+	for _, tc := range testCases {
+		repo, path, ref := parseGit(tc.file)
+		if repo != tc.repo || path != tc.path || ref != tc.ref {
+			t.Errorf("Expected %q %q %q for %q, got %q %q %q", tc.repo, tc.path, tc.ref, tc.file, repo, path, ref)
+		}
+	}
+}
+
+func TestBaseName(t *testing.T) {
+	testCases := map[string]string{
+		"https://example.com/shop/compose.prod.yaml?raw=1":                "compose.prod.yaml",
+		"https://example.com/":                                            "compose.yaml",
+		"git::https://github.com/org/repo.git//deploy/docker-compose.yml": "docker-compose.yml",
+		"git::https://github.com/org/repo.git":                            "compose.yaml",
+	}
+	for file, expected := range testCases {
+		if name := BaseName(file); name != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, file, name)
+		}
+	}
+}
+
+func TestFetchURL(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shop/compose.yaml" && r.URL.Path != "/large.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Path == "/large.yaml" {
+			fmt.Fprint(w, strings.Repeat("#", MaxFileSize+1))
+			return
+		}
+		fmt.Fprint(w, "services:\n  web:\n    image: nginx\n")
+	}))
+	defer server.Close()
+
+	local, err := Fetch(server.URL + "/shop/compose.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filepath.Base(local) != "compose.yaml" {
+		t.Errorf("Expected the local copy to keep the name of the file, got %s", local)
+	}
+	data, err := os.ReadFile(local)
+	if err != nil || string(data) != "services:\n  web:\n    image: nginx\n" {
+		t.Errorf("Expected the content of the compose file, got %q %v", data, err)
+	}
+
+	if _, err := Fetch(server.URL + "/large.yaml"); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("Expected an error for a file larger than %d bytes, got %v", MaxFileSize, err)
+	}
+	if _, err := Fetch(server.URL + "/missing.yaml"); err == nil {
+		t.Errorf("Expected an error for a missing compose file")
+	}
+	if local, _ := Fetch("compose.yaml"); local != "compose.yaml" {
+		t.Errorf("Expected the local file unchanged, got %s", local)
+	}
+}
+
+func TestFetchGit(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir := testutils.CreateLocalGitDirectory(t)
+	defer os.RemoveAll(dir)
+	testutils.CreateSubdir(t, dir, "deploy")
+	cmd := testutils.NewCommand(`echo "services: {}" > deploy/compose.yaml && git add deploy && git commit --no-gpg-sign -q -m compose`)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, output)
+	}
+
+	local, err := Fetch("git::" + dir + "//deploy/compose.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, err := os.ReadFile(local); err != nil || string(data) != "services: {}\n" {
+		t.Errorf("Expected the compose file of the clone, got %q %v", data, err)
+	}
+
+	if _, err := Fetch("git::" + dir + "//missing/compose.yaml"); err == nil {
+		t.Errorf("Expected an error for a missing compose file in the repository")
+	}
+	if _, err := Fetch("git::" + dir + "//../../etc/passwd"); err == nil || !strings.Contains(err.Error(), "outside of the repository") {
+		t.Errorf("Expected an error for a compose file outside of the repository, got %v", err)
+	}
+	if _, err := Fetch("git::--upload-pack=touch /tmp/pwned//compose.yaml"); err == nil || !strings.Contains(err.Error(), "invalid git repository") {
+		t.Errorf("Expected an error for a repository read as an option, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"github.com/kubernetes/kompose/pkg/utils/kubectl"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Server sends the objects to the cluster of the current kubectl context with a server-side dry-run,
+// nothing is persisted. The admission webhooks and the validation of the custom resources run as
+// they would when the objects are applied, their errors are returned.
+func Server(objects []runtime.Object) error {
+	output, err := kubectl.Run(objects, "apply", "--dry-run=server", "-f", "-")
+	log.Debugf("kubectl apply --dry-run=server output:\n%s", output)
+	if err != nil {
+		return errors.Errorf("the cluster rejected the generated objects:\n  %s", err)
+	}
+	return nil
+}
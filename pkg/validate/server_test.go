@@ -0,0 +1,80 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// fakeKubectl installs a kubectl script in the PATH, saving its arguments and its input in the returned directory
+func fakeKubectl(t *testing.T, script string) string {
+	dir := t.TempDir()
+	content := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\ncat > " + filepath.Join(dir, "input") + "\n" + script
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestServer(t *testing.T) {
+	dir := fakeKubectl(t, `echo "deployment.apps/web created (server dry run)"
+`)
+	if err := Server([]runtime.Object{newDeployment("web")}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(args)) != "apply --dry-run=server -f -" {
+		t.Errorf("Expected a server-side dry-run, got %q", args)
+	}
+
+	input, err := os.ReadFile(filepath.Join(dir, "input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list struct {
+		Kind  string
+		Items []map[string]interface{}
+	}
+	if err := json.Unmarshal(input, &list); err != nil {
+		t.Fatalf("Expected a JSON list, got %v", err)
+	}
+	if list.Kind != "List" || len(list.Items) != 1 || list.Items[0]["kind"] != "Deployment" {
+		t.Errorf("Expected a List of the Deployment, got %s", input)
+	}
+}
+
+func TestServerRejected(t *testing.T) {
+	fakeKubectl(t, `echo "service/web created (server dry run)"
+echo 'Error from server (Forbidden): error when creating "STDIN": admission webhook "policy.example.com" denied the request: latest tag' >&2
+exit 1
+`)
+	err := Server([]runtime.Object{newDeployment("web")})
+	if err == nil || !strings.Contains(err.Error(), `admission webhook "policy.example.com" denied the request`) {
+		t.Errorf("Expected the error of the admission webhook, got %v", err)
+	}
+}
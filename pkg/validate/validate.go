@@ -0,0 +1,509 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validate checks the generated objects before they are written, as the API server of the target
+// Kubernetes version would when they are applied.
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	deployapi "github.com/openshift/api/apps/v1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	hpa "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	api "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// maxAnnotationsSize is the maximum total size of the annotations of an object
+	maxAnnotationsSize = 256 * 1024
+	// maxDataSize is the maximum size of the data of a ConfigMap or a Secret
+	maxDataSize = 1024 * 1024
+	// maxCronJobNameLength leaves room for the suffix of the names of the Jobs
+	maxCronJobNameLength = 52
+)
+
+// Objects validates the objects for the Kubernetes version, MAJOR.MINOR, DefaultKubernetesVersion when empty.
+// It returns the errors of all the objects, with the paths of the invalid fields.
+func Objects(objects []runtime.Object, kubernetesVersion string) error {
+	if kubernetesVersion == "" {
+		kubernetesVersion = DefaultKubernetesVersion
+	}
+	version, err := ParseVersion(kubernetesVersion)
+	if err != nil {
+		return err
+	}
+
+	var messages []string
+	for _, obj := range objects {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		name := ""
+		if accessor, err := meta.Accessor(obj); err == nil {
+			name = accessor.GetName()
+		}
+		var errs field.ErrorList
+		if err := validateServed(gvk, version); err != nil {
+			errs = append(errs, err)
+		}
+		errs = append(errs, validateObject(obj, version)...)
+		for _, err := range errs {
+			messages = append(messages, fmt.Sprintf("%s %s: %s", gvk.Kind, name, err.Error()))
+		}
+	}
+	if len(messages) > 0 {
+		return errors.Errorf("the generated objects are invalid for Kubernetes %s:\n  %s", version, strings.Join(messages, "\n  "))
+	}
+	return nil
+}
+
+// validateObject validates the fields of the built-in objects kompose generates
+func validateObject(obj runtime.Object, version Version) field.ErrorList {
+	metaPath := field.NewPath("metadata")
+	specPath := field.NewPath("spec")
+	templatePath := specPath.Child("template")
+
+	switch t := obj.(type) {
+	case *api.Pod:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		return append(errs, validatePodSpec(&t.Spec, nil, version, specPath)...)
+	case *appsv1.Deployment:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		errs = append(errs, validateReplicas(t.Spec.Replicas, specPath.Child("replicas"))...)
+		errs = append(errs, validateSelector(t.Spec.Selector, &t.Spec.Template, specPath)...)
+		return append(errs, validatePodTemplate(&t.Spec.Template, []api.RestartPolicy{api.RestartPolicyAlways}, version, templatePath)...)
+	case *appsv1.StatefulSet:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		errs = append(errs, validateReplicas(t.Spec.Replicas, specPath.Child("replicas"))...)
+		errs = append(errs, validateSelector(t.Spec.Selector, &t.Spec.Template, specPath)...)
+		return append(errs, validatePodTemplate(&t.Spec.Template, []api.RestartPolicy{api.RestartPolicyAlways}, version, templatePath)...)
+	case *appsv1.DaemonSet:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		errs = append(errs, validateSelector(t.Spec.Selector, &t.Spec.Template, specPath)...)
+		return append(errs, validatePodTemplate(&t.Spec.Template, []api.RestartPolicy{api.RestartPolicyAlways}, version, templatePath)...)
+	case *api.ReplicationController:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		errs = append(errs, validateReplicas(t.Spec.Replicas, specPath.Child("replicas"))...)
+		if t.Spec.Template == nil {
+			return append(errs, field.Required(templatePath, ""))
+		}
+		errs = append(errs, validateSelector(&metav1.LabelSelector{MatchLabels: t.Spec.Selector}, t.Spec.Template, specPath)...)
+		return append(errs, validatePodTemplate(t.Spec.Template, []api.RestartPolicy{api.RestartPolicyAlways}, version, templatePath)...)
+	case *deployapi.DeploymentConfig:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		if t.Spec.Template == nil {
+			return append(errs, field.Required(templatePath, ""))
+		}
+		return append(errs, validatePodTemplate(t.Spec.Template, []api.RestartPolicy{api.RestartPolicyAlways}, version, templatePath)...)
+	case *batchv1.Job:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		return append(errs, validatePodTemplate(&t.Spec.Template, []api.RestartPolicy{api.RestartPolicyOnFailure, api.RestartPolicyNever}, version, templatePath)...)
+	case *batchv1.CronJob:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		if len(t.Name) > maxCronJobNameLength {
+			errs = append(errs, field.TooLong(metaPath.Child("name"), t.Name, maxCronJobNameLength))
+		}
+		if t.Spec.Schedule == "" {
+			errs = append(errs, field.Required(specPath.Child("schedule"), ""))
+		}
+		jobTemplatePath := specPath.Child("jobTemplate", "spec", "template")
+		return append(errs, validatePodTemplate(&t.Spec.JobTemplate.Spec.Template, []api.RestartPolicy{api.RestartPolicyOnFailure, api.RestartPolicyNever}, version, jobTemplatePath)...)
+	case *api.Service:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1035Label, metaPath)
+		return append(errs, validateServiceSpec(&t.Spec, specPath)...)
+	case *api.ConfigMap:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		size := 0
+		for key, value := range t.Data {
+			errs = append(errs, validateDataKey(key, field.NewPath("data").Key(key))...)
+			if _, ok := t.BinaryData[key]; ok {
+				errs = append(errs, field.Invalid(field.NewPath("data").Key(key), key, "duplicate of a binaryData key"))
+			}
+			size += len(value)
+		}
+		for key, value := range t.BinaryData {
+			errs = append(errs, validateDataKey(key, field.NewPath("binaryData").Key(key))...)
+			size += len(value)
+		}
+		if size > maxDataSize {
+			errs = append(errs, field.TooLong(field.NewPath(""), "", maxDataSize))
+		}
+		return errs
+	case *api.Secret:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		size := 0
+		for key, value := range t.Data {
+			errs = append(errs, validateDataKey(key, field.NewPath("data").Key(key))...)
+			size += len(value)
+		}
+		for key, value := range t.StringData {
+			errs = append(errs, validateDataKey(key, field.NewPath("stringData").Key(key))...)
+			size += len(value)
+		}
+		if size > maxDataSize {
+			errs = append(errs, field.TooLong(field.NewPath("data"), "", maxDataSize))
+		}
+		return errs
+	case *api.PersistentVolumeClaim:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		if len(t.Spec.AccessModes) == 0 {
+			errs = append(errs, field.Required(specPath.Child("accessModes"), "at least 1 access mode is required"))
+		}
+		if _, ok := t.Spec.Resources.Requests[api.ResourceStorage]; !ok {
+			errs = append(errs, field.Required(specPath.Child("resources", "requests", string(api.ResourceStorage)), ""))
+		}
+		return errs
+	case *api.Namespace:
+		return validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Label, metaPath)
+	case *networkingv1.Ingress:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		return append(errs, validateIngressSpec(&t.Spec, specPath)...)
+	case *hpa.HorizontalPodAutoscaler:
+		errs := validateObjectMeta(&t.ObjectMeta, validation.IsDNS1123Subdomain, metaPath)
+		if t.Spec.ScaleTargetRef.Kind == "" {
+			errs = append(errs, field.Required(specPath.Child("scaleTargetRef", "kind"), ""))
+		}
+		if t.Spec.ScaleTargetRef.Name == "" {
+			errs = append(errs, field.Required(specPath.Child("scaleTargetRef", "name"), ""))
+		}
+		if t.Spec.MaxReplicas < 1 {
+			errs = append(errs, field.Invalid(specPath.Child("maxReplicas"), t.Spec.MaxReplicas, "must be greater than or equal to 1"))
+		}
+		if min := t.Spec.MinReplicas; min != nil {
+			if *min < 1 {
+				errs = append(errs, field.Invalid(specPath.Child("minReplicas"), *min, "must be greater than or equal to 1"))
+			} else if *min > t.Spec.MaxReplicas {
+				errs = append(errs, field.Invalid(specPath.Child("maxReplicas"), t.Spec.MaxReplicas, "must be greater than or equal to `minReplicas`"))
+			}
+		}
+		return errs
+	}
+	// the other objects are only checked with their metadata, the custom resources aren't known
+	if accessor, err := meta.Accessor(obj); err == nil {
+		return validateObjectMeta(&metav1.ObjectMeta{Name: accessor.GetName(), Namespace: accessor.GetNamespace(), Labels: accessor.GetLabels(), Annotations: accessor.GetAnnotations()}, validation.IsDNS1123Subdomain, metaPath)
+	}
+	return nil
+}
+
+// validateObjectMeta validates the name with nameFn, the namespace, the labels and the annotations
+func validateObjectMeta(objectMeta *metav1.ObjectMeta, nameFn func(string) []string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if objectMeta.Name == "" {
+		errs = append(errs, field.Required(path.Child("name"), "name is required"))
+	} else {
+		for _, msg := range nameFn(objectMeta.Name) {
+			errs = append(errs, field.Invalid(path.Child("name"), objectMeta.Name, msg))
+		}
+	}
+	if objectMeta.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(objectMeta.Namespace) {
+			errs = append(errs, field.Invalid(path.Child("namespace"), objectMeta.Namespace, msg))
+		}
+	}
+	errs = append(errs, validateLabels(objectMeta.Labels, path.Child("labels"))...)
+
+	size := 0
+	for key, value := range objectMeta.Annotations {
+		for _, msg := range validation.IsQualifiedName(strings.ToLower(key)) {
+			errs = append(errs, field.Invalid(path.Child("annotations"), key, msg))
+		}
+		size += len(key) + len(value)
+	}
+	if size > maxAnnotationsSize {
+		errs = append(errs, field.TooLong(path.Child("annotations"), "", maxAnnotationsSize))
+	}
+	return errs
+}
+
+func validateLabels(labels map[string]string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for key, value := range labels {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = append(errs, field.Invalid(path, key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = append(errs, field.Invalid(path.Key(key), value, msg))
+		}
+	}
+	return errs
+}
+
+func validateReplicas(replicas *int32, path *field.Path) field.ErrorList {
+	if replicas != nil && *replicas < 0 {
+		return field.ErrorList{field.Invalid(path, *replicas, "must be greater than or equal to 0")}
+	}
+	return nil
+}
+
+// validateSelector checks that the selector of a workload is given and selects the pods of its template
+func validateSelector(selector *metav1.LabelSelector, template *api.PodTemplateSpec, path *field.Path) field.ErrorList {
+	if selector == nil || (len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0) {
+		return field.ErrorList{field.Required(path.Child("selector"), "")}
+	}
+	errs := validateLabels(selector.MatchLabels, path.Child("selector", "matchLabels"))
+	parsed, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return append(errs, field.Invalid(path.Child("selector"), selector, err.Error()))
+	}
+	if !parsed.Matches(labels.Set(template.Labels)) {
+		errs = append(errs, field.Invalid(path.Child("template", "metadata", "labels"), template.Labels, "`selector` does not match template `labels`"))
+	}
+	return errs
+}
+
+// validatePodTemplate validates the labels and the pod spec of the template, restarted with one of the policies
+func validatePodTemplate(template *api.PodTemplateSpec, restartPolicies []api.RestartPolicy, version Version, path *field.Path) field.ErrorList {
+	errs := validateLabels(template.Labels, path.Child("metadata", "labels"))
+	return append(errs, validatePodSpec(&template.Spec, restartPolicies, version, path.Child("spec"))...)
+}
+
+// validatePodSpec validates the containers and the volumes of a pod, restarted with one of the policies when given
+func validatePodSpec(spec *api.PodSpec, restartPolicies []api.RestartPolicy, version Version, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	volumes := sets.New[string]()
+	for i, volume := range spec.Volumes {
+		volumePath := path.Child("volumes").Index(i).Child("name")
+		for _, msg := range validation.IsDNS1123Label(volume.Name) {
+			errs = append(errs, field.Invalid(volumePath, volume.Name, msg))
+		}
+		if volumes.Has(volume.Name) {
+			errs = append(errs, field.Duplicate(volumePath, volume.Name))
+		}
+		volumes.Insert(volume.Name)
+	}
+
+	if len(spec.Containers) == 0 {
+		errs = append(errs, field.Required(path.Child("containers"), ""))
+	}
+	names := sets.New[string]()
+	for i := range spec.InitContainers {
+		errs = append(errs, validateContainer(&spec.InitContainers[i], names, volumes, version, path.Child("initContainers").Index(i))...)
+	}
+	for i := range spec.Containers {
+		errs = append(errs, validateContainer(&spec.Containers[i], names, volumes, version, path.Child("containers").Index(i))...)
+	}
+
+	supported := []api.RestartPolicy{api.RestartPolicyAlways, api.RestartPolicyOnFailure, api.RestartPolicyNever}
+	if restartPolicies != nil {
+		supported = restartPolicies
+	}
+	if spec.RestartPolicy != "" && !contains(supported, spec.RestartPolicy) {
+		values := make([]string, 0, len(supported))
+		for _, policy := range supported {
+			values = append(values, string(policy))
+		}
+		errs = append(errs, field.NotSupported(path.Child("restartPolicy"), spec.RestartPolicy, values))
+	}
+	return errs
+}
+
+// validateContainer validates a container, its name must be unique among names and its mounts use the volumes
+func validateContainer(container *api.Container, names sets.Set[string], volumes sets.Set[string], version Version, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Label(container.Name) {
+		errs = append(errs, field.Invalid(path.Child("name"), container.Name, msg))
+	}
+	if names.Has(container.Name) {
+		errs = append(errs, field.Duplicate(path.Child("name"), container.Name))
+	}
+	names.Insert(container.Name)
+	if container.Image == "" {
+		errs = append(errs, field.Required(path.Child("image"), ""))
+	}
+
+	portNames := sets.New[string]()
+	for i, port := range container.Ports {
+		portPath := path.Child("ports").Index(i)
+		if port.Name != "" {
+			for _, msg := range validation.IsValidPortName(port.Name) {
+				errs = append(errs, field.Invalid(portPath.Child("name"), port.Name, msg))
+			}
+			if portNames.Has(port.Name) {
+				errs = append(errs, field.Duplicate(portPath.Child("name"), port.Name))
+			}
+			portNames.Insert(port.Name)
+		}
+		for _, msg := range validation.IsValidPortNum(int(port.ContainerPort)) {
+			errs = append(errs, field.Invalid(portPath.Child("containerPort"), port.ContainerPort, msg))
+		}
+		if port.HostPort != 0 {
+			for _, msg := range validation.IsValidPortNum(int(port.HostPort)) {
+				errs = append(errs, field.Invalid(portPath.Child("hostPort"), port.HostPort, msg))
+			}
+		}
+		errs = append(errs, validateProtocol(port.Protocol, portPath.Child("protocol"))...)
+	}
+
+	for i, env := range container.Env {
+		envPath := path.Child("env").Index(i).Child("name")
+		if env.Name == "" {
+			errs = append(errs, field.Required(envPath, ""))
+			continue
+		}
+		for _, msg := range validateEnvVarName(env.Name, version) {
+			errs = append(errs, field.Invalid(envPath, env.Name, msg))
+		}
+	}
+
+	for i, mount := range container.VolumeMounts {
+		mountPath := path.Child("volumeMounts").Index(i)
+		if !volumes.Has(mount.Name) {
+			errs = append(errs, field.NotFound(mountPath.Child("name"), mount.Name))
+		}
+		if mount.MountPath == "" {
+			errs = append(errs, field.Required(mountPath.Child("mountPath"), ""))
+		}
+	}
+	return errs
+}
+
+// validateEnvVarName validates the name of a variable, any printable ASCII character but '=' is allowed
+// from Kubernetes 1.30
+func validateEnvVarName(name string, version Version) []string {
+	if version.Less(Version{1, 30}) {
+		return validation.IsEnvVarName(name)
+	}
+	for _, r := range name {
+		if r < ' ' || r > '~' || r == '=' {
+			return []string{"a valid environment variable name must consist only of printable ASCII characters other than '='"}
+		}
+	}
+	return nil
+}
+
+func validateProtocol(protocol api.Protocol, path *field.Path) field.ErrorList {
+	supported := []api.Protocol{api.ProtocolTCP, api.ProtocolUDP, api.ProtocolSCTP}
+	if protocol != "" && !contains(supported, protocol) {
+		return field.ErrorList{field.NotSupported(path, protocol, []string{string(api.ProtocolTCP), string(api.ProtocolUDP), string(api.ProtocolSCTP)})}
+	}
+	return nil
+}
+
+func validateServiceSpec(spec *api.ServiceSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	switch spec.Type {
+	case "", api.ServiceTypeClusterIP, api.ServiceTypeNodePort, api.ServiceTypeLoadBalancer, api.ServiceTypeExternalName:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("type"), spec.Type, []string{string(api.ServiceTypeClusterIP), string(api.ServiceTypeNodePort), string(api.ServiceTypeLoadBalancer), string(api.ServiceTypeExternalName)}))
+	}
+	if len(spec.Ports) == 0 && spec.ClusterIP != api.ClusterIPNone && spec.Type != api.ServiceTypeExternalName {
+		errs = append(errs, field.Required(path.Child("ports"), ""))
+	}
+
+	names := sets.New[string]()
+	ports := sets.New[string]()
+	for i, port := range spec.Ports {
+		portPath := path.Child("ports").Index(i)
+		if len(spec.Ports) > 1 && port.Name == "" {
+			errs = append(errs, field.Required(portPath.Child("name"), "when multiple ports are specified"))
+		}
+		if port.Name != "" {
+			for _, msg := range validation.IsDNS1123Label(port.Name) {
+				errs = append(errs, field.Invalid(portPath.Child("name"), port.Name, msg))
+			}
+			if names.Has(port.Name) {
+				errs = append(errs, field.Duplicate(portPath.Child("name"), port.Name))
+			}
+			names.Insert(port.Name)
+		}
+		for _, msg := range validation.IsValidPortNum(int(port.Port)) {
+			errs = append(errs, field.Invalid(portPath.Child("port"), port.Port, msg))
+		}
+		errs = append(errs, validateProtocol(port.Protocol, portPath.Child("protocol"))...)
+		key := fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+		if ports.Has(key) {
+			errs = append(errs, field.Duplicate(portPath, key))
+		}
+		ports.Insert(key)
+
+		switch port.TargetPort.Type {
+		case intstr.Int:
+			if port.TargetPort.IntVal != 0 {
+				for _, msg := range validation.IsValidPortNum(port.TargetPort.IntValue()) {
+					errs = append(errs, field.Invalid(portPath.Child("targetPort"), port.TargetPort.IntVal, msg))
+				}
+			}
+		case intstr.String:
+			for _, msg := range validation.IsValidPortName(port.TargetPort.StrVal) {
+				errs = append(errs, field.Invalid(portPath.Child("targetPort"), port.TargetPort.StrVal, msg))
+			}
+		}
+	}
+	return append(errs, validateLabels(spec.Selector, path.Child("selector"))...)
+}
+
+func validateIngressSpec(spec *networkingv1.IngressSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, rule := range spec.Rules {
+		rulePath := path.Child("rules").Index(i)
+		if rule.Host != "" {
+			host := strings.TrimPrefix(rule.Host, "*.")
+			for _, msg := range validation.IsDNS1123Subdomain(host) {
+				errs = append(errs, field.Invalid(rulePath.Child("host"), rule.Host, msg))
+			}
+		}
+		if rule.HTTP == nil {
+			continue
+		}
+		for j, httpPath := range rule.HTTP.Paths {
+			pathPath := rulePath.Child("http", "paths").Index(j)
+			if httpPath.PathType == nil {
+				errs = append(errs, field.Required(pathPath.Child("pathType"), "pathType must be specified"))
+			}
+			if !strings.HasPrefix(httpPath.Path, "/") {
+				errs = append(errs, field.Invalid(pathPath.Child("path"), httpPath.Path, "must be an absolute path"))
+			}
+			if service := httpPath.Backend.Service; service != nil {
+				if service.Name == "" {
+					errs = append(errs, field.Required(pathPath.Child("backend", "service", "name"), ""))
+				}
+				if service.Port.Name == "" && service.Port.Number == 0 {
+					errs = append(errs, field.Required(pathPath.Child("backend", "service", "port"), "port name or number is required"))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func validateDataKey(key string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range validation.IsConfigMapKey(key) {
+		errs = append(errs, field.Invalid(path, key, msg))
+	}
+	return errs
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	api "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func newDeployment(name string) *appsv1.Deployment {
+	labels := map[string]string{"io.kompose.service": name}
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: api.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: api.PodSpec{
+					Containers: []api.Container{{Name: name, Image: "nginx"}},
+				},
+			},
+		},
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected Version
+		valid    bool
+	}{
+		{"1.31", Version{1, 31}, true},
+		{"v1.25.3", Version{1, 25}, true},
+		{"1", Version{}, false},
+		{"1.x", Version{}, false},
+	}
+	for _, tc := range testCases {
+		version, err := ParseVersion(tc.version)
+		if (err == nil) != tc.valid || version != tc.expected {
+			t.Errorf("Expected %v %v for %q, got %v %v", tc.expected, tc.valid, tc.version, version, err)
+		}
+	}
+}
+
+func TestObjectsValid(t *testing.T) {
+	service := &api.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: api.ServiceSpec{
+			Selector: map[string]string{"io.kompose.service": "web"},
+			Ports:    []api.ServicePort{{Name: "80", Port: 80, TargetPort: intstr.FromInt32(80)}},
+		},
+	}
+	if err := Objects([]runtime.Object{newDeployment("web"), service}, ""); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestObjectsInvalid(t *testing.T) {
+	deployment := newDeployment("web")
+	deployment.Spec.Template.Labels = map[string]string{"app": "other"}
+	deployment.Spec.Template.Spec.Containers[0].Image = ""
+	deployment.Spec.Template.Spec.Containers[0].VolumeMounts = []api.VolumeMount{{Name: "data", MountPath: "/data"}}
+
+	service := &api.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "Web_Front"},
+		Spec: api.ServiceSpec{
+			Ports: []api.ServicePort{{Port: 80}, {Port: 70000}},
+		},
+	}
+
+	err := Objects([]runtime.Object{deployment, service}, "1.31")
+	if err == nil {
+		t.Fatalf("Expected the objects to be invalid")
+	}
+	for _, expected := range []string{
+		"Deployment web: spec.template.metadata.labels: Invalid value",
+		"Deployment web: spec.template.spec.containers[0].image: Required value",
+		"Deployment web: spec.template.spec.containers[0].volumeMounts[0].name: Not found: \"data\"",
+		"Service Web_Front: metadata.name: Invalid value",
+		"Service Web_Front: spec.ports[0].name: Required value",
+		"Service Web_Front: spec.ports[1].port: Invalid value: 70000",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in the error, got %v", expected, err)
+		}
+	}
+}
+
+func TestObjectsServed(t *testing.T) {
+	deployment := newDeployment("web")
+	deployment.APIVersion = "extensions/v1beta1"
+	err := Objects([]runtime.Object{deployment}, "1.31")
+	if err == nil || !strings.Contains(err.Error(), "Deployment is no longer served from Kubernetes 1.16") {
+		t.Errorf("Expected the removed apiVersion to be reported, got %v", err)
+	}
+	deployment.APIVersion = "apps/v1alpha1"
+	err = Objects([]runtime.Object{deployment}, "1.31")
+	if err == nil || !strings.Contains(err.Error(), "apiVersion: Unsupported value: \"apps/v1alpha1\"") {
+		t.Errorf("Expected the unknown apiVersion to be reported, got %v", err)
+	}
+
+	if err := Objects([]runtime.Object{newDeployment("web")}, "1.x"); err == nil {
+		t.Errorf("Expected an error for an invalid Kubernetes version")
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	if msgs := validateEnvVarName("my var", Version{1, 29}); len(msgs) == 0 {
+		t.Errorf("Expected the name to be invalid before 1.30")
+	}
+	if msgs := validateEnvVarName("my var", Version{1, 30}); len(msgs) != 0 {
+		t.Errorf("Expected the name to be valid from 1.30, got %v", msgs)
+	}
+	if msgs := validateEnvVarName("A=B", Version{1, 31}); len(msgs) == 0 {
+		t.Errorf("Expected '=' to be invalid")
+	}
+}
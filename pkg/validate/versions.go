@@ -0,0 +1,143 @@
+/*
+Copyright 2017 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// DefaultKubernetesVersion is the version of the Kubernetes API compiled in kompose
+const DefaultKubernetesVersion = "1.31"
+
+// Version is a minor version of Kubernetes
+type Version struct {
+	Major int
+	Minor int
+}
+
+// ParseVersion parses a Kubernetes version as [v]MAJOR.MINOR[.PATCH]
+func ParseVersion(version string) (Version, error) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return Version{}, errors.Errorf("invalid Kubernetes version %q, expected MAJOR.MINOR", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Version{}, errors.Errorf("invalid Kubernetes version %q, expected MAJOR.MINOR", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Version{}, errors.Errorf("invalid Kubernetes version %q, expected MAJOR.MINOR", version)
+	}
+	return Version{Major: major, Minor: minor}, nil
+}
+
+// Less tells whether the version is older than other
+func (v Version) Less(other Version) bool {
+	return v.Major < other.Major || (v.Major == other.Major && v.Minor < other.Minor)
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// served is the range of Kubernetes versions serving a kind in a group version, removed is zero
+// while the group version is served
+type served struct {
+	introduced Version
+	removed    Version
+}
+
+// servedKinds are the Kubernetes versions serving the built-in kinds kompose generates, the custom
+// resources are not checked
+var servedKinds = map[schema.GroupVersionKind]served{
+	{Version: "v1", Kind: "Pod"}:                                                {introduced: Version{1, 0}},
+	{Version: "v1", Kind: "Service"}:                                            {introduced: Version{1, 0}},
+	{Version: "v1", Kind: "ConfigMap"}:                                          {introduced: Version{1, 2}},
+	{Version: "v1", Kind: "Secret"}:                                             {introduced: Version{1, 0}},
+	{Version: "v1", Kind: "PersistentVolumeClaim"}:                              {introduced: Version{1, 0}},
+	{Version: "v1", Kind: "Namespace"}:                                          {introduced: Version{1, 0}},
+	{Version: "v1", Kind: "ReplicationController"}:                              {introduced: Version{1, 0}},
+	{Version: "v1", Kind: "ServiceAccount"}:                                     {introduced: Version{1, 0}},
+	{Group: "apps", Version: "v1", Kind: "Deployment"}:                          {introduced: Version{1, 9}},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"}:                           {introduced: Version{1, 9}},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"}:                         {introduced: Version{1, 9}},
+	{Group: "apps", Version: "v1", Kind: "ReplicaSet"}:                          {introduced: Version{1, 9}},
+	{Group: "apps", Version: "v1beta2", Kind: "Deployment"}:                     {introduced: Version{1, 8}, removed: Version{1, 16}},
+	{Group: "extensions", Version: "v1beta1", Kind: "Deployment"}:               {introduced: Version{1, 2}, removed: Version{1, 16}},
+	{Group: "extensions", Version: "v1beta1", Kind: "DaemonSet"}:                {introduced: Version{1, 2}, removed: Version{1, 16}},
+	{Group: "batch", Version: "v1", Kind: "Job"}:                                {introduced: Version{1, 2}},
+	{Group: "batch", Version: "v1", Kind: "CronJob"}:                            {introduced: Version{1, 21}},
+	{Group: "batch", Version: "v1beta1", Kind: "CronJob"}:                       {introduced: Version{1, 8}, removed: Version{1, 25}},
+	{Group: "autoscaling", Version: "v1", Kind: "HorizontalPodAutoscaler"}:      {introduced: Version{1, 2}},
+	{Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"}:      {introduced: Version{1, 23}},
+	{Group: "autoscaling", Version: "v2beta2", Kind: "HorizontalPodAutoscaler"}: {introduced: Version{1, 12}, removed: Version{1, 26}},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}:                {introduced: Version{1, 19}},
+	{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"}:           {introduced: Version{1, 14}, removed: Version{1, 22}},
+	{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}:                  {introduced: Version{1, 1}, removed: Version{1, 22}},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}:          {introduced: Version{1, 7}},
+	{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"}:               {introduced: Version{1, 21}},
+	{Group: "policy", Version: "v1beta1", Kind: "PodDisruptionBudget"}:          {introduced: Version{1, 5}, removed: Version{1, 25}},
+	{Group: "scheduling.k8s.io", Version: "v1", Kind: "PriorityClass"}:          {introduced: Version{1, 14}},
+	{Group: "node.k8s.io", Version: "v1", Kind: "RuntimeClass"}:                 {introduced: Version{1, 20}},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"}:           {introduced: Version{1, 8}},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"}:    {introduced: Version{1, 8}},
+}
+
+// validateServed checks that the kind is served in its group version by the Kubernetes version
+func validateServed(gvk schema.GroupVersionKind, version Version) *field.Error {
+	path := field.NewPath("apiVersion")
+	if kind, ok := servedKinds[gvk]; ok {
+		if version.Less(kind.introduced) {
+			return field.Invalid(path, gvk.GroupVersion().String(), fmt.Sprintf("%s is served from Kubernetes %s", gvk.Kind, kind.introduced))
+		}
+		if kind.removed != (Version{}) && !version.Less(kind.removed) {
+			return field.Invalid(path, gvk.GroupVersion().String(), fmt.Sprintf("%s is no longer served from Kubernetes %s", gvk.Kind, kind.removed))
+		}
+		return nil
+	}
+	// a built-in kind in a group version unknown to the table isn't served
+	for known := range servedKinds {
+		if known.Group == gvk.Group && known.Kind == gvk.Kind {
+			return field.NotSupported(path, gvk.GroupVersion().String(), servedGroupVersions(gvk.GroupKind(), version))
+		}
+	}
+	return nil
+}
+
+// servedGroupVersions returns the group versions serving the kind in the Kubernetes version
+func servedGroupVersions(groupKind schema.GroupKind, version Version) []string {
+	var groupVersions []string
+	for known, kind := range servedKinds {
+		if known.GroupKind() != groupKind || version.Less(kind.introduced) {
+			continue
+		}
+		if kind.removed != (Version{}) && !version.Less(kind.removed) {
+			continue
+		}
+		groupVersions = append(groupVersions, known.GroupVersion().String())
+	}
+	sort.Strings(groupVersions)
+	return groupVersions
+}